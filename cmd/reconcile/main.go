@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	app "github.com/MayCMF/core/src"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+)
+
+var (
+	configFile string
+	modelFile  string
+	repair     bool
+)
+
+func init() {
+	flag.StringVar(&configFile, "c", "./configs/config.toml", "Configuration file(.json,.yaml,.toml)")
+	flag.StringVar(&modelFile, "m", "./configs/model.conf", "Casbin's access control model(.conf)")
+	flag.BoolVar(&repair, "repair", false, "Remove file records without content and orphaned content")
+}
+
+// Reconcile the filemanager records with the storage, the report is written
+// to stdout as JSON and the exit code is 1 when mismatches were found
+func main() {
+	flag.Parse()
+
+	err := config.LoadGlobal(configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	cfg := config.Global()
+	cfg.Casbin.Model = modelFile
+	cfg.Gorm.Debug = false
+
+	// Stdout is kept for the report
+	logger.SetOutput(os.Stderr)
+
+	ctx := logger.NewTraceIDContext(context.Background(), util.NewTraceID())
+	container, release := app.BuildContainer()
+
+	var code int
+	err = container.Invoke(func(bReconcile controllers.IReconcile) error {
+		report, err := bReconcile.Reconcile(ctx, repair)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+		if !repair && (len(report.Missing) > 0 || len(report.Orphans) > 0) {
+			code = 1
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = 2
+	}
+
+	release()
+	os.Exit(code)
+}
@@ -18,12 +18,38 @@ func handleError(err error) {
 func InjectControllers(container *dig.Container) error {
 	_ = container.Provide(implement.NewLogin)
 	_ = container.Provide(func(b *implement.Login) controllers.ILogin { return b })
+	_ = container.Provide(implement.NewMFA)
+	_ = container.Provide(func(b *implement.MFA) controllers.IMFA { return b })
+	_ = container.Provide(implement.NewSession)
+	_ = container.Provide(func(b *implement.Session) controllers.ISession { return b })
+	_ = container.Provide(implement.NewAccessToken)
+	_ = container.Provide(func(b *implement.AccessToken) controllers.IAccessToken { return b })
+	_ = container.Provide(implement.NewLDAP)
+	_ = container.Provide(func(b *implement.LDAP) controllers.IAuthProvider { return b })
+	_ = container.Provide(implement.NewOIDC)
+	_ = container.Provide(func(b *implement.OIDC) controllers.IOIDC { return b })
+	_ = container.Provide(implement.NewOAuth)
+	_ = container.Provide(func(b *implement.OAuth) controllers.IOAuth { return b })
+	_ = container.Provide(implement.NewOAuthClient)
+	_ = container.Provide(func(b *implement.OAuthClient) controllers.IOAuthClient { return b })
+	_ = container.Provide(implement.NewOAuthScope)
+	_ = container.Provide(func(b *implement.OAuthScope) controllers.IOAuthScope { return b })
 	_ = container.Provide(implement.NewPermission)
 	_ = container.Provide(func(b *implement.Permission) controllers.IPermission { return b })
 	_ = container.Provide(implement.NewRole)
 	_ = container.Provide(func(b *implement.Role) controllers.IRole { return b })
 	_ = container.Provide(implement.NewUser)
 	_ = container.Provide(func(b *implement.User) controllers.IUser { return b })
+	_ = container.Provide(implement.NewPasswordReset)
+	_ = container.Provide(func(b *implement.PasswordReset) controllers.IPasswordReset { return b })
+	_ = container.Provide(implement.NewPasswordPolicy)
+	_ = container.Provide(func(b *implement.PasswordPolicy) controllers.IPasswordPolicy { return b })
+	_ = container.Provide(implement.NewEmailVerification)
+	_ = container.Provide(func(b *implement.EmailVerification) controllers.IEmailVerification { return b })
+	_ = container.Provide(implement.NewInvitation)
+	_ = container.Provide(func(b *implement.Invitation) controllers.IInvitation { return b })
+	_ = container.Provide(implement.NewRegistration)
+	_ = container.Provide(func(b *implement.Registration) controllers.IRegistration { return b })
 	return nil
 }
 
@@ -35,5 +61,29 @@ func InjectStarage(container *dig.Container) error {
 	_ = container.Provide(func(m *imodel.Role) model.IRole { return m })
 	_ = container.Provide(imodel.NewUser)
 	_ = container.Provide(func(m *imodel.User) model.IUser { return m })
+	_ = container.Provide(imodel.NewUserMFA)
+	_ = container.Provide(func(m *imodel.UserMFA) model.IUserMFA { return m })
+	_ = container.Provide(imodel.NewUserSession)
+	_ = container.Provide(func(m *imodel.UserSession) model.IUserSession { return m })
+	_ = container.Provide(imodel.NewLoginHistory)
+	_ = container.Provide(func(m *imodel.LoginHistory) model.ILoginHistory { return m })
+	_ = container.Provide(imodel.NewAccessToken)
+	_ = container.Provide(func(m *imodel.AccessToken) model.IAccessToken { return m })
+	_ = container.Provide(imodel.NewUserIdentity)
+	_ = container.Provide(func(m *imodel.UserIdentity) model.IUserIdentity { return m })
+	_ = container.Provide(imodel.NewOAuthClient)
+	_ = container.Provide(func(m *imodel.OAuthClient) model.IOAuthClient { return m })
+	_ = container.Provide(imodel.NewOAuthScope)
+	_ = container.Provide(func(m *imodel.OAuthScope) model.IOAuthScope { return m })
+	_ = container.Provide(imodel.NewOAuthGrant)
+	_ = container.Provide(func(m *imodel.OAuthGrant) model.IOAuthGrant { return m })
+	_ = container.Provide(imodel.NewOAuthCode)
+	_ = container.Provide(func(m *imodel.OAuthCode) model.IOAuthCode { return m })
+	_ = container.Provide(imodel.NewUserToken)
+	_ = container.Provide(func(m *imodel.UserToken) model.IUserToken { return m })
+	_ = container.Provide(imodel.NewPasswordHistory)
+	_ = container.Provide(func(m *imodel.PasswordHistory) model.IPasswordHistory { return m })
+	_ = container.Provide(imodel.NewInvitation)
+	_ = container.Provide(func(m *imodel.Invitation) model.IInvitation { return m })
 	return nil
 }
@@ -1,35 +1,164 @@
 package account
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
 	"github.com/MayCMF/core/src/common/config"
 	"github.com/MayCMF/core/src/common/auth"
 	"github.com/MayCMF/core/src/common/auth/jwtauth"
 	"github.com/MayCMF/core/src/common/auth/jwtauth/store/buntdb"
 	"github.com/MayCMF/core/src/common/auth/jwtauth/store/redis"
+	"github.com/MayCMF/core/src/common/logger"
 	jwt "github.com/dgrijalva/jwt-go"
 )
 
-// InitAuth - Initialize user authentication
-func InitAuth() (auth.Auther, error) {
+// Keys of the sample configuration, known to anyone
+var sampleSecrets = []string{"MAYCMS", "MayCMF-files"}
+
+// InitSecret - Initialize the token signing key of the HMAC signature
+// methods and the secret of the MFA challenges, OIDC state and signed file
+// URLs. Random keys are generated and stored when none are configured, the
+// sample keys are refused as anyone could sign with them
+func InitSecret() error {
+	cfg := config.Global()
+	for _, key := range []string{cfg.JWTAuth.SigningKey, cfg.JWTAuth.SecretKey, cfg.FileManager.SigningKey} {
+		for _, sample := range sampleSecrets {
+			if key == sample {
+				return errors.New("the sample key " + sample + " can not be used, set another key or leave it empty to generate one")
+			}
+		}
+	}
+
+	// The asymmetric signature methods sign with the key set instead
+	switch cfg.JWTAuth.SigningMethod {
+	case "RS256", "ES256", "EdDSA":
+	default:
+		err := initKey(&cfg.JWTAuth.SigningKey, cfg.JWTAuth.SigningFile, "jwt_auth.signing_key or jwt_auth.signing_file")
+		if err != nil {
+			return err
+		}
+	}
+	return initKey(&cfg.JWTAuth.SecretKey, cfg.JWTAuth.SecretFile, "jwt_auth.secret_key or jwt_auth.secret_file")
+}
+
+// Set an empty key from the stored one
+func initKey(key *string, file, names string) error {
+	if *key != "" {
+		return nil
+	} else if file == "" {
+		return errors.New(names + " is required")
+	}
+
+	secret, err := loadSecret(file)
+	if err != nil {
+		return err
+	}
+	*key = secret
+	return nil
+}
+
+// Read the stored secret, a new one is stored when there is none
+func loadSecret(name string) (string, error) {
+	buf, err := ioutil.ReadFile(name)
+	if err == nil {
+		if secret := strings.TrimSpace(string(buf)); secret != "" {
+			return secret, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	secret := hex.EncodeToString(b)
+
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(name, []byte(secret), 0600); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// InitKeySet - Initialize the asymmetric signing keys, nil for the HMAC
+// signature methods which sign with the shared signing key
+func InitKeySet() (*jwtauth.KeySet, error) {
+	cfg := config.Global().JWTAuth
+
+	var method jwt.SigningMethod
+	switch cfg.SigningMethod {
+	case "RS256":
+		method = jwt.SigningMethodRS256
+	case "ES256":
+		method = jwt.SigningMethodES256
+	case "EdDSA":
+		method = jwtauth.SigningMethodEdDSA
+	default:
+		return nil, nil
+	}
+
+	// Replaced keys verify the tokens they signed until the tokens expire
+	overlap := cfg.KeyOverlap
+	if overlap < cfg.Expired {
+		overlap = cfg.Expired
+	}
+
+	keySet, err := jwtauth.NewKeySet(cfg.KeyDir, method,
+		jwtauth.SetKeyRotation(time.Duration(cfg.KeyRotation)*time.Second),
+		jwtauth.SetKeyOverlap(time.Duration(overlap)*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	keySet.Start(func(err error) {
+		logger.Errorf(context.Background(), "Rotate JWT signing keys error: %s", err.Error())
+	})
+	return keySet, nil
+}
+
+// InitAuth - Initialize user authentication, tokens are signed with the key
+// set when it is not nil
+func InitAuth(keySet *jwtauth.KeySet) (auth.Auther, error) {
 	cfg := config.Global().JWTAuth
 
 	var opts []jwtauth.Option
 	opts = append(opts, jwtauth.SetExpired(cfg.Expired))
-	opts = append(opts, jwtauth.SetSigningKey([]byte(cfg.SigningKey)))
-	opts = append(opts, jwtauth.SetKeyfunc(func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, auth.ErrInvalidToken
-		}
-		return []byte(cfg.SigningKey), nil
-	}))
+	if cfg.RefreshExpired > 0 {
+		opts = append(opts, jwtauth.SetRefreshExpired(cfg.RefreshExpired))
+	}
 
-	switch cfg.SigningMethod {
-	case "HS256":
-		opts = append(opts, jwtauth.SetSigningMethod(jwt.SigningMethodHS256))
-	case "HS384":
-		opts = append(opts, jwtauth.SetSigningMethod(jwt.SigningMethodHS384))
-	case "HS512":
-		opts = append(opts, jwtauth.SetSigningMethod(jwt.SigningMethodHS512))
+	if keySet != nil {
+		opts = append(opts, jwtauth.SetKeySet(keySet))
+	} else {
+		opts = append(opts, jwtauth.SetSigningKey([]byte(cfg.SigningKey)))
+		opts = append(opts, jwtauth.SetKeyfunc(func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, auth.ErrInvalidToken
+			}
+			return []byte(cfg.SigningKey), nil
+		}))
+
+		switch cfg.SigningMethod {
+		case "HS256":
+			opts = append(opts, jwtauth.SetSigningMethod(jwt.SigningMethodHS256))
+		case "HS384":
+			opts = append(opts, jwtauth.SetSigningMethod(jwt.SigningMethodHS384))
+		case "HS512":
+			opts = append(opts, jwtauth.SetSigningMethod(jwt.SigningMethodHS512))
+		}
 	}
 
 	var store jwtauth.Storer
@@ -52,3 +181,123 @@ func InitAuth() (auth.Auther, error) {
 
 	return jwtauth.New(store, opts...), nil
 }
+
+var (
+	errScopeNotSupported         = errors.New("scoped tokens are not supported")
+	errImpersonationNotSupported = errors.New("impersonation tokens are not supported")
+	errChallengeNotSupported     = errors.New("single-use challenges are not supported")
+)
+
+// NewTokenAuther - Accept personal access tokens and API keys next to the
+// tokens issued by the authentication a
+func NewTokenAuther(a auth.Auther, bAccessToken controllers.IAccessToken) auth.Auther {
+	return &TokenAuther{
+		Auther:         a,
+		AccessTokenBll: bAccessToken,
+	}
+}
+
+// TokenAuther - Authentication of access tokens, the other tokens are
+// handled by the wrapped authentication
+type TokenAuther struct {
+	auth.Auther
+	AccessTokenBll controllers.IAccessToken
+}
+
+// ParseScopedUserUUID - Resolve user ID and the casbin subject of the token
+// scopes, scoped tokens are refused while casbin can not enforce their scopes
+func (a *TokenAuther) ParseScopedUserUUID(ctx context.Context, accessToken string) (string, string, error) {
+	userUUID, scope, err := a.parseScopedUserUUID(ctx, accessToken)
+	if err != nil {
+		return "", "", err
+	} else if scope != "" && !config.Global().Casbin.Enable {
+		return "", "", auth.ErrInvalidToken
+	}
+	return userUUID, scope, nil
+}
+
+func (a *TokenAuther) parseScopedUserUUID(ctx context.Context, accessToken string) (string, string, error) {
+	if controllers.IsAccessToken(accessToken) {
+		return a.AccessTokenBll.Verify(ctx, accessToken)
+	}
+
+	if sa, ok := a.Auther.(auth.ScopedAuther); ok {
+		return sa.ParseScopedUserUUID(ctx, accessToken)
+	}
+	userUUID, err := a.Auther.ParseUserUUID(ctx, accessToken)
+	return userUUID, "", err
+}
+
+// GenerateScopedToken - Generate a token limited to the casbin subject scope
+func (a *TokenAuther) GenerateScopedToken(ctx context.Context, userUUID, scope string) (auth.TokenInfo, error) {
+	if sa, ok := a.Auther.(auth.ScopedAuther); ok {
+		return sa.GenerateScopedToken(ctx, userUUID, scope)
+	}
+	return nil, errScopeNotSupported
+}
+
+// ParseScopedRefreshToken - Resolve user ID and the casbin subject of the scopes of a refresh token
+func (a *TokenAuther) ParseScopedRefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	if sa, ok := a.Auther.(auth.ScopedAuther); ok {
+		return sa.ParseScopedRefreshToken(ctx, refreshToken)
+	}
+	userUUID, err := a.Auther.ParseRefreshToken(ctx, refreshToken)
+	return userUUID, "", err
+}
+
+// GenerateImpersonationToken - Generate a token of the user for the impersonator
+func (a *TokenAuther) GenerateImpersonationToken(ctx context.Context, userUUID, impersonatorUUID string, expired int) (auth.TokenInfo, error) {
+	if ia, ok := a.Auther.(auth.ImpersonatingAuther); ok {
+		return ia.GenerateImpersonationToken(ctx, userUUID, impersonatorUUID, expired)
+	}
+	return nil, errImpersonationNotSupported
+}
+
+// UseChallenge - Use up a challenge
+func (a *TokenAuther) UseChallenge(ctx context.Context, challengeID string, expiration time.Duration) (bool, error) {
+	if ca, ok := a.Auther.(auth.ChallengeAuther); ok {
+		return ca.UseChallenge(ctx, challengeID, expiration)
+	}
+	return false, errChallengeNotSupported
+}
+
+// ChallengeUsed - Check whether a challenge was used
+func (a *TokenAuther) ChallengeUsed(ctx context.Context, challengeID string) (bool, error) {
+	if ca, ok := a.Auther.(auth.ChallengeAuther); ok {
+		return ca.ChallengeUsed(ctx, challengeID)
+	}
+	return false, errChallengeNotSupported
+}
+
+// ParseImpersonatorUUID - Access tokens are always used by their user
+func (a *TokenAuther) ParseImpersonatorUUID(ctx context.Context, accessToken string) (string, error) {
+	if controllers.IsAccessToken(accessToken) {
+		return "", nil
+	}
+	if ia, ok := a.Auther.(auth.ImpersonatingAuther); ok {
+		return ia.ParseImpersonatorUUID(ctx, accessToken)
+	}
+	return "", nil
+}
+
+// ParseUserUUID - Resolve user ID
+func (a *TokenAuther) ParseUserUUID(ctx context.Context, accessToken string) (string, error) {
+	userUUID, _, err := a.ParseScopedUserUUID(ctx, accessToken)
+	return userUUID, err
+}
+
+// ParseSessionID - Access tokens do not belong to a login session
+func (a *TokenAuther) ParseSessionID(ctx context.Context, accessToken string) (string, error) {
+	if controllers.IsAccessToken(accessToken) {
+		return "", nil
+	}
+	return a.Auther.ParseSessionID(ctx, accessToken)
+}
+
+// DestroyToken - Access tokens are only revoked by deleting them
+func (a *TokenAuther) DestroyToken(ctx context.Context, accessToken string) error {
+	if controllers.IsAccessToken(accessToken) {
+		return nil
+	}
+	return a.Auther.DestroyToken(ctx, accessToken)
+}
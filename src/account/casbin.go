@@ -41,8 +41,9 @@ func InitCasbinEnforcer(container *dig.Container) error {
 		return nil
 	}
 
-	return container.Invoke(func(e *casbin.SyncedEnforcer, bRole controllers.IRole, bUser controllers.IUser) error {
-		adapter := NewCasbinAdapter(bRole, bUser)
+	return container.Invoke(func(e *casbin.SyncedEnforcer, bRole controllers.IRole, bUser controllers.IUser, bAccessToken controllers.IAccessToken,
+		bOAuthScope controllers.IOAuthScope, bOAuthClient controllers.IOAuthClient, bOAuth controllers.IOAuth) error {
+		adapter := NewCasbinAdapter(bRole, bUser, bAccessToken, bOAuthScope, bOAuthClient, bOAuth)
 
 		if cfg.AutoLoad {
 			e.InitWithModelAndAdapter(e.GetModel(), adapter)
@@ -76,17 +77,32 @@ func ReleaseCasbinEnforcer(container *dig.Container) {
 }
 
 // NewCasbinAdapter - Create a casbin adapter
-func NewCasbinAdapter(bRole controllers.IRole, bUser controllers.IUser) *CasbinAdapter {
+func NewCasbinAdapter(
+	bRole controllers.IRole,
+	bUser controllers.IUser,
+	bAccessToken controllers.IAccessToken,
+	bOAuthScope controllers.IOAuthScope,
+	bOAuthClient controllers.IOAuthClient,
+	bOAuth controllers.IOAuth,
+) *CasbinAdapter {
 	return &CasbinAdapter{
-		RoleBll: bRole,
-		UserBll: bUser,
+		RoleBll:        bRole,
+		UserBll:        bUser,
+		AccessTokenBll: bAccessToken,
+		OAuthScopeBll:  bOAuthScope,
+		OAuthClientBll: bOAuthClient,
+		OAuthBll:       bOAuth,
 	}
 }
 
 // CasbinAdapter - Casbin adapter
 type CasbinAdapter struct {
-	RoleBll controllers.IRole
-	UserBll controllers.IUser
+	RoleBll        controllers.IRole
+	UserBll        controllers.IUser
+	AccessTokenBll controllers.IAccessToken
+	OAuthScopeBll  controllers.IOAuthScope
+	OAuthClientBll controllers.IOAuthClient
+	OAuthBll       controllers.IOAuth
 }
 
 // LoadPolicy - Load all policy rules from the storage.
@@ -103,6 +119,18 @@ func (a *CasbinAdapter) LoadPolicy(model model.Model) error {
 		logger.Errorf(ctx, "Load casbin user policy error: %s", err.Error())
 		return err
 	}
+
+	err = a.loadAccessTokenPolicy(ctx, model)
+	if err != nil {
+		logger.Errorf(ctx, "Load casbin access token policy error: %s", err.Error())
+		return err
+	}
+
+	err = a.loadOAuthPolicy(ctx, model)
+	if err != nil {
+		logger.Errorf(ctx, "Load casbin oauth policy error: %s", err.Error())
+		return err
+	}
 	return nil
 }
 
@@ -152,6 +180,73 @@ func (a *CasbinAdapter) loadUserPolicy(ctx context.Context, model model.Model) e
 	return nil
 }
 
+func (a *CasbinAdapter) loadAccessTokenPolicy(ctx context.Context, model model.Model) error {
+	result, err := a.AccessTokenBll.Query(ctx, schema.AccessTokenQueryParam{})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range result.Data {
+		resources, err := a.RoleBll.GetPermissionResources(ctx, &schema.Role{Permissions: item.Scopes})
+		if err != nil {
+			return err
+		}
+
+		subject := controllers.AccessTokenSubject(item.UUID)
+		for _, ritem := range resources {
+			line := fmt.Sprintf("p,%s,%s,%s", subject, ritem.Path, ritem.Method)
+			persist.LoadPolicyLine(line, model)
+		}
+	}
+
+	return nil
+}
+
+func (a *CasbinAdapter) loadOAuthPolicy(ctx context.Context, model model.Model) error {
+	scopeResult, err := a.OAuthScopeBll.Query(ctx, schema.OAuthScopeQueryParam{})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range scopeResult.Data {
+		resources, err := a.RoleBll.GetPermissionResources(ctx, &schema.Role{Permissions: item.Permissions})
+		if err != nil {
+			return err
+		}
+
+		subject := controllers.OAuthScopeSubject(item.Name)
+		for _, ritem := range resources {
+			line := fmt.Sprintf("p,%s,%s,%s", subject, ritem.Path, ritem.Method)
+			persist.LoadPolicyLine(line, model)
+		}
+	}
+
+	clientResult, err := a.OAuthClientBll.Query(ctx, schema.OAuthClientQueryParam{})
+	if err != nil {
+		return err
+	}
+
+	clients := make(map[string]*schema.OAuthClient)
+	for _, item := range clientResult.Data {
+		clients[item.UUID] = item
+	}
+
+	grants, err := a.OAuthBll.QueryGrants(ctx, schema.OAuthGrantQueryParam{})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range grants {
+		subject := controllers.OAuthGrantSubject(item.UUID)
+		for _, name := range controllers.OAuthGrantScopes(clients[item.ClientUUID], item) {
+			line := fmt.Sprintf("g,%s,%s", subject, controllers.OAuthScopeSubject(name))
+			persist.LoadPolicyLine(line, model)
+		}
+	}
+
+	return nil
+}
+
 // SavePolicy saves all policy rules to the storage.
 func (a *CasbinAdapter) SavePolicy(model model.Model) error {
 	return nil
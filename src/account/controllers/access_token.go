@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// Prefixes telling access tokens apart from JWTs
+const (
+	PersonalAccessTokenPrefix = "mpat_"
+	APIKeyPrefix              = "mkey_"
+)
+
+// Number of characters of a token kept to recognize it
+const accessTokenHintLength = 10
+
+// IAccessToken - Access token business logic interface
+type IAccessToken interface {
+	// Query data
+	Query(ctx context.Context, params schema.AccessTokenQueryParam, opts ...schema.AccessTokenQueryOptions) (*schema.AccessTokenQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.AccessToken, error)
+	// Create a token, the plain token is only returned here
+	Create(ctx context.Context, item schema.AccessToken) (*schema.AccessToken, error)
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+	// Delete a personal access token of a user
+	DeletePersonal(ctx context.Context, userUUID, UUID string) error
+	// Resolve the user and the casbin subject of the scopes of a token
+	Verify(ctx context.Context, token string) (userUUID, scope string, err error)
+	// Load the casbin policy of the token scopes
+	LoadPolicy(ctx context.Context, item *schema.AccessToken) error
+}
+
+// IsAccessToken - Check whether a bearer token is an access token
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix) ||
+		strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// AccessTokenSubject - Casbin subject holding the scopes of an access token
+func AccessTokenSubject(UUID string) string {
+	return "token:" + UUID
+}
+
+// NewAccessTokenString - Generate a random token of a kind, its hash and
+// the hint stored to recognize it
+func NewAccessTokenString(kind string) (token, hash, hint string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+
+	prefix := PersonalAccessTokenPrefix
+	if kind == schema.AccessTokenAPIKey {
+		prefix = APIKeyPrefix
+	}
+	token = prefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashAccessToken(token), token[:accessTokenHintLength], nil
+}
+
+// HashAccessToken - Hash of a token as stored
+func HashAccessToken(token string) string {
+	return util.SHA256HashString(token)
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IAuthProvider - External directory checking the passwords of logins before
+// the local users
+type IAuthProvider interface {
+	// Authenticate a user, a nil user without error leaves the login to the local users
+	Authenticate(ctx context.Context, userName, password string) (*schema.User, error)
+}
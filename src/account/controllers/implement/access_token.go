@@ -0,0 +1,246 @@
+package implement
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/auth"
+	"github.com/MayCMF/core/src/common/config"
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/casbin/casbin/v2"
+)
+
+// The last used time is only written once per interval
+const accessTokenTouchInterval = time.Minute
+
+// NewAccessToken - Create an access token management instance
+func NewAccessToken(
+	e *casbin.SyncedEnforcer,
+	mAccessToken model.IAccessToken,
+	mUser model.IUser,
+	mRole model.IRole,
+	bRole controllers.IRole,
+) *AccessToken {
+	return &AccessToken{
+		Enforcer:         e,
+		AccessTokenModel: mAccessToken,
+		UserModel:        mUser,
+		RoleModel:        mRole,
+		RoleBll:          bRole,
+	}
+}
+
+// AccessToken - Personal access token and API key management
+type AccessToken struct {
+	Enforcer         *casbin.SyncedEnforcer
+	AccessTokenModel model.IAccessToken
+	UserModel        model.IUser
+	RoleModel        model.IRole
+	RoleBll          controllers.IRole
+}
+
+// Query - Query data
+func (a *AccessToken) Query(ctx context.Context, params schema.AccessTokenQueryParam, opts ...schema.AccessTokenQueryOptions) (*schema.AccessTokenQueryResult, error) {
+	return a.AccessTokenModel.Query(ctx, params, opts...)
+}
+
+// Get - Get specified data
+func (a *AccessToken) Get(ctx context.Context, UUID string) (*schema.AccessToken, error) {
+	item, err := a.AccessTokenModel.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+
+	return item, nil
+}
+
+// Check that the scopes are granted to the owner by one of its roles
+func (a *AccessToken) checkScopes(ctx context.Context, userUUID string, scopes schema.RolePermissions) error {
+	result, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
+		UserUUID: userUUID,
+	}, schema.RoleQueryOptions{
+		IncludePermissions: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	actions := make(map[string]map[string]bool)
+	resources := make(map[string]map[string]bool)
+	for _, role := range result.Data {
+		for _, item := range role.Permissions {
+			if actions[item.PermissionID] == nil {
+				actions[item.PermissionID] = make(map[string]bool)
+				resources[item.PermissionID] = make(map[string]bool)
+			}
+			for _, v := range item.Actions {
+				actions[item.PermissionID][v] = true
+			}
+			for _, v := range item.Resources {
+				resources[item.PermissionID][v] = true
+			}
+		}
+	}
+
+	for _, item := range scopes {
+		if _, ok := actions[item.PermissionID]; !ok {
+			return errors.New400Response("Scope exceeds the permissions of the user")
+		}
+		for _, v := range item.Actions {
+			if !actions[item.PermissionID][v] {
+				return errors.New400Response("Scope exceeds the permissions of the user")
+			}
+		}
+		for _, v := range item.Resources {
+			if !resources[item.PermissionID][v] {
+				return errors.New400Response("Scope exceeds the permissions of the user")
+			}
+		}
+	}
+	return nil
+}
+
+// Create - Create a token, the plain token is only returned here
+func (a *AccessToken) Create(ctx context.Context, item schema.AccessToken) (*schema.AccessToken, error) {
+	if common.CheckIsRootUser(ctx, item.UserUUID) {
+		return nil, errors.New400Response("Root user not allowed to create access tokens")
+	}
+
+	user, err := a.UserModel.Get(ctx, item.UserUUID)
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, errors.New400Response("User does not exist")
+	} else if user.Status != 1 {
+		return nil, errors.New400Response("User is disabled")
+	}
+
+	if item.ExpiresAt != nil && !item.ExpiresAt.After(time.Now()) {
+		return nil, errors.New400Response("Expiration time must be in the future")
+	} else if !config.Global().Casbin.Enable {
+		// The scopes would not limit the token
+		return nil, errors.New400Response("Access tokens need casbin to be enabled")
+	}
+
+	err = a.checkScopes(ctx, item.UserUUID, item.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, hint, err := controllers.NewAccessTokenString(item.Kind)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	item.UUID = util.MustUUID()
+	item.TokenHash = hash
+	item.Hint = hint
+	item.LastUsedAt = nil
+	item.Creator, _ = icontext.FromUserUUID(ctx)
+	err = a.AccessTokenModel.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Global().Casbin.Enable {
+		err = a.LoadPolicy(ctx, &item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nitem, err := a.Get(ctx, item.UUID)
+	if err != nil {
+		return nil, err
+	}
+	nitem.Token = token
+	return nitem, nil
+}
+
+// Delete - Delete data, the token stops working at once
+func (a *AccessToken) Delete(ctx context.Context, UUID string) error {
+	oldItem, err := a.AccessTokenModel.Get(ctx, UUID)
+	if err != nil {
+		return err
+	} else if oldItem == nil {
+		return errors.ErrNotFound
+	}
+
+	err = a.AccessTokenModel.Delete(ctx, UUID)
+	if err != nil {
+		return err
+	}
+
+	if config.Global().Casbin.Enable {
+		_, _ = a.Enforcer.DeletePermissionsForUser(controllers.AccessTokenSubject(UUID))
+	}
+	return nil
+}
+
+// DeletePersonal - Delete a personal access token of a user
+func (a *AccessToken) DeletePersonal(ctx context.Context, userUUID, UUID string) error {
+	item, err := a.AccessTokenModel.Get(ctx, UUID)
+	if err != nil {
+		return err
+	} else if item == nil || item.UserUUID != userUUID || item.Kind != schema.AccessTokenPersonal {
+		return errors.ErrNotFound
+	}
+	return a.Delete(ctx, UUID)
+}
+
+// Verify - Resolve the user and the casbin subject of the scopes of a token
+func (a *AccessToken) Verify(ctx context.Context, token string) (string, string, error) {
+	result, err := a.AccessTokenModel.Query(ctx, schema.AccessTokenQueryParam{
+		TokenHash: controllers.HashAccessToken(token),
+	})
+	if err != nil {
+		return "", "", err
+	} else if len(result.Data) == 0 {
+		return "", "", auth.ErrInvalidToken
+	}
+
+	now := time.Now()
+	item := result.Data[0]
+	if item.ExpiresAt != nil && !item.ExpiresAt.After(now) {
+		return "", "", auth.ErrInvalidToken
+	}
+
+	user, err := a.UserModel.Get(ctx, item.UserUUID)
+	if err != nil {
+		return "", "", err
+	} else if user == nil || user.Status != 1 {
+		return "", "", auth.ErrInvalidToken
+	}
+
+	if item.LastUsedAt == nil || now.Sub(*item.LastUsedAt) > accessTokenTouchInterval {
+		err = a.AccessTokenModel.UpdateLastUsed(ctx, item.UUID, now)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return item.UserUUID, controllers.AccessTokenSubject(item.UUID), nil
+}
+
+// LoadPolicy - Load the casbin policy of the token scopes
+func (a *AccessToken) LoadPolicy(ctx context.Context, item *schema.AccessToken) error {
+	resources, err := a.RoleBll.GetPermissionResources(ctx, &schema.Role{Permissions: item.Scopes})
+	if err != nil {
+		return err
+	}
+
+	subject := controllers.AccessTokenSubject(item.UUID)
+	_, _ = a.Enforcer.DeletePermissionsForUser(subject)
+	for _, ritem := range resources {
+		_, _ = a.Enforcer.AddPermissionForUser(subject, ritem.Path, ritem.Method)
+	}
+	return nil
+}
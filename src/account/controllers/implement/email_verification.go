@@ -0,0 +1,126 @@
+package implement
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/mailer"
+)
+
+// NewEmailVerification - Create an email verification instance
+func NewEmailVerification(
+	m mailer.Mailer,
+	t *mailer.Templates,
+	mUser model.IUser,
+	mUserToken model.IUserToken,
+) *EmailVerification {
+	return &EmailVerification{
+		Mailer:         m,
+		Templates:      t,
+		UserModel:      mUser,
+		UserTokenModel: mUserToken,
+	}
+}
+
+// EmailVerification - Email verification by mailed links
+type EmailVerification struct {
+	Mailer         mailer.Mailer
+	Templates      *mailer.Templates
+	UserModel      model.IUser
+	UserTokenModel model.IUserToken
+}
+
+// Send - Mail a verification link to the email address of a user
+func (a *EmailVerification) Send(ctx context.Context, user *schema.User) error {
+	if user.Email == "" {
+		return nil
+	}
+
+	expired := config.Global().UserEmail.VerifyExpired
+	if expired <= 0 {
+		expired = 86400
+	}
+
+	token, expiresAt, err := createUserToken(ctx, a.UserTokenModel, schema.UserTokenEmailVerification, user, expired)
+	if err != nil {
+		return err
+	}
+
+	return sendMail(ctx, a.Mailer, a.Templates, schema.UserTokenEmailVerification, user.Email, userTokenMail{
+		User:      user,
+		Email:     user.Email,
+		URL:       controllers.UserTokenURL(config.Global().UserEmail.VerifyURL, token),
+		ExpiresAt: expiresAt.Format(time.RFC1123),
+	})
+}
+
+// Resend - Mail a verification link to the unverified email address of a user
+func (a *EmailVerification) Resend(ctx context.Context, userUUID string) error {
+	if common.CheckIsRootUser(ctx, userUUID) {
+		return errors.New400Response("Root user has no email address")
+	}
+
+	user, err := a.UserModel.Get(ctx, userUUID)
+	if err != nil {
+		return err
+	} else if user == nil {
+		return errors.ErrInvalidUser
+	} else if user.Email == "" {
+		return errors.New400Response("User has no email address")
+	} else if user.EmailVerified {
+		return errors.New400Response("Email address is already verified")
+	}
+	return a.Send(ctx, user.CleanSecure())
+}
+
+// ResendTo - Mail a verification link to an unverified email address of a
+// user that cannot login before verifying it, nothing tells whether the
+// address belongs to a user
+func (a *EmailVerification) ResendTo(ctx context.Context, email string) error {
+	result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+		Email: email,
+	})
+	if err != nil {
+		return err
+	} else if len(result.Data) == 0 {
+		return nil
+	}
+
+	user := result.Data[0]
+	if user.EmailVerified || user.Status != 1 {
+		return nil
+	}
+
+	err = a.Send(ctx, user.CleanSecure())
+	if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("Email verification"), logger.SetSpanFuncName("ResendTo")).
+			Errorf("Mail verification link to %s error: %s", user.UUID, err.Error())
+	}
+	return nil
+}
+
+// Verify - Confirm an email address with the token of a verification link,
+// links mailed to a former address of the user are refused
+func (a *EmailVerification) Verify(ctx context.Context, token string) error {
+	item, err := takeUserToken(ctx, a.UserTokenModel, schema.UserTokenEmailVerification, token)
+	if err != nil {
+		return err
+	} else if item == nil {
+		return errors.ErrInvalidVerifyToken
+	}
+
+	ok, err := a.UserModel.VerifyEmail(ctx, item.UserUUID, item.Email)
+	if err != nil {
+		return err
+	} else if !ok {
+		return errors.ErrInvalidVerifyToken
+	}
+	return nil
+}
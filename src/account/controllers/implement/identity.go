@@ -0,0 +1,70 @@
+package implement
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// Query the IDs of roles by name, unknown roles are skipped
+func queryRoleIDs(ctx context.Context, mRole model.IRole, names []string) ([]string, error) {
+	var roleIDs []string
+	for _, name := range names {
+		result, err := mRole.Query(ctx, schema.RoleQueryParam{
+			Name: name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Data {
+			roleIDs = append(roleIDs, item.UUID)
+		}
+	}
+	return roleIDs, nil
+}
+
+// Update the roles of a user managed by an identity provider to the granted
+// ones, the roles outside of the managed ones are kept
+func syncManagedRoles(ctx context.Context, bUser controllers.IUser, user *schema.User, granted, managed []string) (*schema.User, error) {
+	if len(managed) == 0 {
+		return user, nil
+	}
+
+	isManaged := make(map[string]bool)
+	for _, roleID := range managed {
+		isManaged[roleID] = true
+	}
+
+	current := make(map[string]bool)
+	var roles schema.UserRoles
+	for _, item := range user.Roles {
+		current[item.RoleID] = true
+		if !isManaged[item.RoleID] {
+			roles = append(roles, item)
+		}
+	}
+
+	changed := false
+	isGranted := make(map[string]bool)
+	for _, roleID := range granted {
+		if isGranted[roleID] {
+			continue
+		}
+		isGranted[roleID] = true
+		roles = append(roles, &schema.UserRole{RoleID: roleID})
+		changed = changed || !current[roleID]
+	}
+	for roleID := range current {
+		changed = changed || (isManaged[roleID] && !isGranted[roleID])
+	}
+	if !changed {
+		return user, nil
+	}
+
+	item := *user
+	item.Password = ""
+	item.Roles = roles
+	return bUser.Update(ctx, user.UUID, item)
+}
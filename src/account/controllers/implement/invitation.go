@@ -0,0 +1,154 @@
+package implement
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/mailer"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// NewInvitation - Create an invitation management instance
+func NewInvitation(
+	m mailer.Mailer,
+	t *mailer.Templates,
+	mInvitation model.IInvitation,
+	mRole model.IRole,
+) *Invitation {
+	return &Invitation{
+		Mailer:          m,
+		Templates:       t,
+		InvitationModel: mInvitation,
+		RoleModel:       mRole,
+	}
+}
+
+// Invitation - Invitation management
+type Invitation struct {
+	Mailer          mailer.Mailer
+	Templates       *mailer.Templates
+	InvitationModel model.IInvitation
+	RoleModel       model.IRole
+}
+
+// Mail of an invitation
+type invitationMail struct {
+	Note      string // Note of the administrator
+	URL       string // Sign-up link
+	ExpiresAt string // Expiration time of the link
+}
+
+// Query - Query data
+func (a *Invitation) Query(ctx context.Context, params schema.InvitationQueryParam, opts ...schema.InvitationQueryOptions) (*schema.InvitationQueryResult, error) {
+	return a.InvitationModel.Query(ctx, params, opts...)
+}
+
+// Get - Get specified data
+func (a *Invitation) Get(ctx context.Context, UUID string) (*schema.Invitation, error) {
+	item, err := a.InvitationModel.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+func (a *Invitation) checkRoles(ctx context.Context, roleIDs []string) error {
+	result, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
+		UUIDs: roleIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]bool)
+	for _, item := range result.Data {
+		exists[item.UUID] = true
+	}
+	for _, roleID := range roleIDs {
+		if !exists[roleID] {
+			return errors.New400Response("Role does not exist")
+		}
+	}
+	return nil
+}
+
+// Create - Create an invitation, the link is only returned here and mailed
+// to the address of the invitation
+func (a *Invitation) Create(ctx context.Context, item schema.Invitation) (*schema.Invitation, error) {
+	err := a.checkRoles(ctx, item.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := config.Global().Register
+	now := time.Now()
+	if item.ExpiresAt.IsZero() {
+		expired := cfg.InvitationExpired
+		if expired <= 0 {
+			expired = 604800
+		}
+		item.ExpiresAt = now.Add(time.Duration(expired) * time.Second)
+	} else if !item.ExpiresAt.After(now) {
+		return nil, errors.New400Response("Expiration time must be in the future")
+	}
+
+	if item.MaxUses < 0 {
+		return nil, errors.New400Response("Maximum number of sign-ups must not be negative")
+	} else if item.Email != "" {
+		// An address signs up a single user
+		item.MaxUses = 1
+	}
+
+	token, hash, err := controllers.NewUserToken()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	item.UUID = util.MustUUID()
+	item.Uses = 0
+	item.TokenHash = hash
+	err = a.InvitationModel.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	nitem, err := a.Get(ctx, item.UUID)
+	if err != nil {
+		return nil, err
+	}
+	nitem.Token = token
+	nitem.URL = controllers.UserTokenURL(cfg.InvitationURL, token)
+
+	if nitem.Email != "" {
+		err = sendMail(ctx, a.Mailer, a.Templates, "invitation", nitem.Email, invitationMail{
+			Note:      nitem.Note,
+			URL:       nitem.URL,
+			ExpiresAt: nitem.ExpiresAt.Format(time.RFC1123),
+		})
+		if err != nil {
+			// The administrator passes the returned link on instead
+			logger.StartSpan(ctx, logger.SetSpanTitle("Invitation"), logger.SetSpanFuncName("Create")).
+				Errorf("Mail invitation %s error: %s", nitem.UUID, err.Error())
+		}
+	}
+	return nitem, nil
+}
+
+// Delete - Delete data, the link stops working
+func (a *Invitation) Delete(ctx context.Context, UUID string) error {
+	oldItem, err := a.InvitationModel.Get(ctx, UUID)
+	if err != nil {
+		return err
+	} else if oldItem == nil {
+		return errors.ErrNotFound
+	}
+	return a.InvitationModel.Delete(ctx, UUID)
+}
@@ -0,0 +1,306 @@
+package implement
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/auth/ldap"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// Provider name of the identities and creator of the users of the directory
+const ldapProvider = "ldap"
+
+// NewLDAP - Create an LDAP login instance
+func NewLDAP(
+	mUser model.IUser,
+	mRole model.IRole,
+	mUserIdentity model.IUserIdentity,
+	bUser controllers.IUser,
+) *LDAP {
+	return &LDAP{
+		UserModel:         mUser,
+		RoleModel:         mRole,
+		UserIdentityModel: mUserIdentity,
+		UserBll:           bUser,
+	}
+}
+
+// LDAP - Login with the users of an LDAP directory or Active Directory
+type LDAP struct {
+	UserModel         model.IUser
+	RoleModel         model.IRole
+	UserIdentityModel model.IUserIdentity
+	UserBll           controllers.IUser
+
+	lock      sync.Mutex
+	key       string
+	directory *ldap.Directory
+}
+
+// Get the directory of the configuration, it is created again when the
+// configuration changes
+func (a *LDAP) getDirectory(cfg config.LDAP) (*ldap.Directory, error) {
+	// fmt prints the maps sorted by key
+	key := fmt.Sprintf("%+v", cfg)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.directory != nil && a.key == key {
+		return a.directory, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.CACert != "" {
+		b, err := ioutil.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(b) {
+			return nil, errors.New("no certificate in the LDAP CA file")
+		}
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10
+	}
+	a.directory = ldap.NewDirectory(ldap.Config{
+		URL:               cfg.URL,
+		StartTLS:          cfg.StartTLS,
+		TLSConfig:         tlsConfig,
+		Timeout:           time.Duration(timeout) * time.Second,
+		BindDN:            cfg.BindDN,
+		BindPassword:      cfg.BindPassword,
+		BaseDN:            cfg.BaseDN,
+		UserFilter:        cfg.UserFilter,
+		UserNameAttribute: cfg.UserNameAttribute,
+		RealNameAttribute: cfg.RealNameAttribute,
+		EmailAttribute:    cfg.EmailAttribute,
+		GroupAttribute:    cfg.GroupAttribute,
+		GroupBaseDN:       cfg.GroupBaseDN,
+		GroupFilter:       cfg.GroupFilter,
+	})
+	a.key = key
+	return a.directory, nil
+}
+
+// Authenticate - Check a password with the directory, the users it does not
+// know and every user while it is unavailable are left to the local users
+func (a *LDAP) Authenticate(ctx context.Context, userName, password string) (*schema.User, error) {
+	cfg := config.Global().LDAP
+	if !cfg.Enable {
+		return nil, nil
+	}
+
+	d, err := a.getDirectory(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := d.Authenticate(ctx, userName, password)
+	if err == ldap.ErrInvalidCredentials {
+		// The users of the directory cannot login with a local password
+		user, err := a.getLinkedUser(ctx, userName)
+		if err != nil || user == nil {
+			return nil, err
+		}
+		return user, errors.ErrInvalidPassword
+	} else if err == ldap.ErrUserNotFound {
+		return nil, nil
+	} else if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("LDAP login"), logger.SetSpanFuncName("Authenticate")).
+			Warnf("Authenticate %s error: %s", userName, err.Error())
+		return nil, nil
+	}
+
+	user, err := a.resolveUser(ctx, cfg, identity)
+	if err != nil || user == nil {
+		return user, err
+	}
+
+	granted, managed, err := a.mapRoles(ctx, cfg, identity)
+	if err != nil {
+		return user, err
+	}
+	return syncManagedRoles(ctx, a.UserBll, user, granted, managed)
+}
+
+// Get the local user of a user name if it is linked to the directory
+func (a *LDAP) getLinkedUser(ctx context.Context, userName string) (*schema.User, error) {
+	result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+		UserName: userName,
+	})
+	if err != nil || len(result.Data) == 0 {
+		return nil, err
+	}
+
+	user := result.Data[0]
+	identities, err := a.UserIdentityModel.Query(ctx, schema.UserIdentityQueryParam{
+		UserUUID: user.UUID,
+		Provider: ldapProvider,
+	})
+	if err != nil || len(identities) == 0 {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Find the user of a directory entry, existing users are linked by their
+// username and unknown users are created
+func (a *LDAP) resolveUser(ctx context.Context, cfg config.LDAP, identity *ldap.Identity) (*schema.User, error) {
+	subject := strings.ToLower(identity.DN)
+	identities, err := a.UserIdentityModel.Query(ctx, schema.UserIdentityQueryParam{
+		Provider: ldapProvider,
+		Subject:  subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range identities {
+		user, err := a.UserModel.Get(ctx, item.UserUUID, schema.UserQueryOptions{IncludeRoles: true})
+		if err != nil {
+			return nil, err
+		} else if user != nil {
+			return user, nil
+		}
+
+		// The user has been deleted
+		err = a.UserIdentityModel.Delete(ctx, item.UUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if identity.UserName == common.GetRootUser().UserName {
+		return nil, nil
+	}
+
+	result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+		UserName: identity.UserName,
+	}, schema.UserQueryOptions{IncludeRoles: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var user *schema.User
+	if len(result.Data) > 0 {
+		// Users created from the directory are linked again after their entry moved
+		user = result.Data[0]
+		if !cfg.LinkByUserName && user.Creator != ldapProvider {
+			return nil, nil
+		}
+	} else if !cfg.AutoCreate {
+		return nil, errors.New400Response("User does not exist")
+	} else {
+		user, err = a.createUser(ctx, cfg, identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = a.UserIdentityModel.Create(ctx, schema.UserIdentity{
+		UUID:     util.MustUUID(),
+		UserUUID: user.UUID,
+		Provider: ldapProvider,
+		Subject:  subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("LDAP login"), logger.SetSpanFuncName("resolveUser")).
+		Infof("Link %s to user %s", identity.DN, user.UserName)
+	return user, nil
+}
+
+// Create the user of a directory entry with the mapped or default roles, the
+// user gets a random password it never learns
+func (a *LDAP) createUser(ctx context.Context, cfg config.LDAP, identity *ldap.Identity) (*schema.User, error) {
+	roleIDs, _, err := a.mapRoles(ctx, cfg, identity)
+	if err != nil {
+		return nil, err
+	} else if len(roleIDs) == 0 {
+		roleIDs, err = queryRoleIDs(ctx, a.RoleModel, cfg.DefaultRoles)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(roleIDs) == 0 {
+		return nil, errors.New400Response("No role is granted to the user")
+	}
+
+	if identity.Email != "" {
+		result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+			Email: identity.Email,
+		})
+		if err != nil {
+			return nil, err
+		} else if len(result.Data) > 0 {
+			return nil, errors.New400Response("Email is already used by another user")
+		}
+	}
+
+	item := schema.User{
+		UserName: identity.UserName,
+		RealName: identity.RealName,
+		Password: util.MustUUID() + util.MustUUID(),
+		Email:    identity.Email,
+		Status:   1,
+		Creator:  ldapProvider,
+		// The directory administrators manage the addresses
+		EmailVerified: identity.Email != "",
+	}
+	if item.RealName == "" {
+		item.RealName = identity.UserName
+	}
+	for _, roleID := range roleIDs {
+		item.Roles = append(item.Roles, &schema.UserRole{RoleID: roleID})
+	}
+	return a.UserBll.Create(ctx, item)
+}
+
+// Roles granted by the groups of an entry and every role managed by the
+// mapping, groups are mapped by their DN or name
+func (a *LDAP) mapRoles(ctx context.Context, cfg config.LDAP, identity *ldap.Identity) (granted, managed []string, err error) {
+	if len(cfg.RoleMapping) == 0 {
+		return nil, nil, nil
+	}
+
+	var grantedNames, managedNames []string
+	for group, name := range cfg.RoleMapping {
+		managedNames = append(managedNames, name)
+		for _, dn := range identity.Groups {
+			if strings.EqualFold(group, dn) || strings.EqualFold(group, ldap.RDNValue(dn)) {
+				grantedNames = append(grantedNames, name)
+				break
+			}
+		}
+	}
+
+	granted, err = queryRoleIDs(ctx, a.RoleModel, grantedNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	managed, err = queryRoleIDs(ctx, a.RoleModel, managedNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	return granted, managed, nil
+}
@@ -2,38 +2,73 @@ package implement
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/MayCMF/core/src/account/controllers"
 	"github.com/MayCMF/core/src/account/model"
 	"github.com/MayCMF/core/src/account/schema"
+	acontrollers "github.com/MayCMF/core/src/audit/controllers"
+	aschema "github.com/MayCMF/core/src/audit/schema"
 	"github.com/MayCMF/core/src/common"
 	"github.com/MayCMF/core/src/common/auth"
+	"github.com/MayCMF/core/src/common/auth/lockout"
+	"github.com/MayCMF/core/src/common/auth/password"
+	"github.com/MayCMF/core/src/common/config"
+	icontext "github.com/MayCMF/core/src/common/context"
 	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
 	"github.com/MayCMF/core/src/common/util"
 	"github.com/LyricTian/captcha"
+	jwt "github.com/dgrijalva/jwt-go"
 )
 
+// Audience of the challenge tokens between the two login steps
+const mfaAudience = "mfa"
+
 // NewLogin - Create a login management instance
 func NewLogin(
 	a auth.Auther,
+	p *password.Password,
 	mUser model.IUser,
 	mRole model.IRole,
 	mPermission model.IPermission,
+	bMFA controllers.IMFA,
+	bSession controllers.ISession,
+	bPasswordPolicy controllers.IPasswordPolicy,
+	bAuthProvider controllers.IAuthProvider,
+	bAudit acontrollers.IAudit,
+	g *lockout.Guard,
 ) *Login {
 	return &Login{
-		Auth:            a,
-		UserModel:       mUser,
-		RoleModel:       mRole,
-		PermissionModel: mPermission,
+		Auth:              a,
+		Password:          p,
+		Lockout:           g,
+		UserModel:         mUser,
+		RoleModel:         mRole,
+		PermissionModel:   mPermission,
+		MFABll:            bMFA,
+		SessionBll:        bSession,
+		PasswordPolicyBll: bPasswordPolicy,
+		AuthProvider:      bAuthProvider,
+		AuditBll:          bAudit,
 	}
 }
 
 // Login - Login management
 type Login struct {
-	UserModel       model.IUser
-	RoleModel       model.IRole
-	PermissionModel model.IPermission
-	Auth            auth.Auther
+	UserModel         model.IUser
+	RoleModel         model.IRole
+	PermissionModel   model.IPermission
+	Auth              auth.Auther
+	Password          *password.Password
+	Lockout           *lockout.Guard
+	MFABll            controllers.IMFA
+	SessionBll        controllers.ISession
+	PasswordPolicyBll controllers.IPasswordPolicy
+	AuthProvider      controllers.IAuthProvider
+	AuditBll          acontrollers.IAudit
 }
 
 // GetCaptcha - Get graphic verification code information
@@ -61,14 +96,163 @@ func (a *Login) ResCaptcha(ctx context.Context, w http.ResponseWriter, captchaID
 	return nil
 }
 
-// Verify - Login authentication
+// Verify - Login authentication, failed attempts are recorded in the login
+// history and too many of them delay and lock further attempts
 func (a *Login) Verify(ctx context.Context, userName, password string) (*schema.User, error) {
+	ip, _ := clientInfo(ctx)
+	if wait := beginAttempt(ctx, a.Lockout, userName, ip); wait > 0 {
+		err := loginLockedError(wait)
+		a.recordFailure(ctx, "", userName, err)
+		return nil, err
+	}
+
+	item, err := a.verify(ctx, userName, password)
+	if err != nil {
+		var userUUID string
+		if item != nil {
+			userUUID = item.UUID
+		}
+		if err == errors.ErrInvalidUserName || err == errors.ErrInvalidPassword {
+			endAttempt(ctx, a.Lockout, userName, ip, false)
+		}
+		a.recordFailure(ctx, userUUID, userName, err)
+		return nil, err
+	}
+
+	// The second step finishes the attempt, so that logging in with the
+	// password does not forget the failed codes. It is counted again for
+	// the IP, so the password step no longer is.
+	pending, _, err := a.mfaPending(ctx, item)
+	if err != nil {
+		return nil, err
+	} else if !pending {
+		endAttempt(ctx, a.Lockout, userName, ip, true)
+	} else {
+		passAttempt(ctx, a.Lockout, userName, ip)
+	}
+	return item, nil
+}
+
+// Refuse a login during a delay or lockout
+func loginLockedError(wait time.Duration) error {
+	sec := int64((wait + time.Second - 1) / time.Second)
+	return errors.NewResponse(429, fmt.Sprintf("Too many failed logins, please try again in %d seconds", sec), 429)
+}
+
+// Count a login attempt and get the time to wait before it is allowed, the
+// login goes on if the lockout storage fails
+func beginAttempt(ctx context.Context, g *lockout.Guard, userName, ip string) time.Duration {
+	if g == nil {
+		return 0
+	}
+
+	wait, err := g.Begin(ctx, userName, ip)
+	if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("Login lockout"), logger.SetSpanFuncName("beginAttempt")).
+			Warnf("Count login attempt of %s error: %s", userName, err.Error())
+		return 0
+	}
+	return wait
+}
+
+// Finish a counted login attempt
+func endAttempt(ctx context.Context, g *lockout.Guard, userName, ip string, success bool) {
+	if g == nil {
+		return
+	}
+
+	var err error
+	if success {
+		err = g.Succeed(ctx, userName, ip)
+	} else {
+		err = g.Fail(ctx, userName, ip)
+	}
+	if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("Login lockout"), logger.SetSpanFuncName("endAttempt")).
+			Warnf("Finish login attempt of %s error: %s", userName, err.Error())
+	}
+}
+
+// Finish the first step of a counted login attempt that needs a second one
+func passAttempt(ctx context.Context, g *lockout.Guard, userName, ip string) {
+	if g == nil {
+		return
+	}
+
+	if err := g.Pass(ctx, userName, ip); err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("Login lockout"), logger.SetSpanFuncName("passAttempt")).
+			Warnf("Pass login attempt of %s error: %s", userName, err.Error())
+	}
+}
+
+// Get the user name of a user ID
+func (a *Login) getUserName(ctx context.Context, userUUID string) (string, error) {
+	if common.CheckIsRootUser(ctx, userUUID) {
+		return common.GetRootUser().UserName, nil
+	}
+
+	user, err := a.UserModel.Get(ctx, userUUID)
+	if err != nil {
+		return "", err
+	} else if user == nil {
+		return "", errors.ErrNotFound
+	}
+	return user.UserName, nil
+}
+
+// GetLockout - Get the login lockout of a user
+func (a *Login) GetLockout(ctx context.Context, userUUID string) (*schema.LoginLockout, error) {
+	userName, err := a.getUserName(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := new(schema.LoginLockout)
+	if a.Lockout == nil {
+		return item, nil
+	}
+
+	wait, err := a.Lockout.Locked(ctx, userName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if wait > 0 {
+		lockedUntil := time.Now().Add(wait)
+		item.Locked = true
+		item.LockedUntil = &lockedUntil
+	}
+	return item, nil
+}
+
+// Unlock - Lift the login lockout of a user
+func (a *Login) Unlock(ctx context.Context, userUUID string) error {
+	userName, err := a.getUserName(ctx, userUUID)
+	if err != nil {
+		return err
+	} else if a.Lockout == nil {
+		return nil
+	}
+	return errors.WithStack(a.Lockout.Unlock(ctx, userName))
+}
+
+// The user is returned with the error when the user name is known
+func (a *Login) verify(ctx context.Context, userName, password string) (*schema.User, error) {
 	// Check if it is a superuser
 	root := common.GetRootUser()
 	if userName == root.UserName && root.Password == password {
 		return root, nil
 	}
 
+	// The users of an external directory skip the local password checks
+	item, err := a.AuthProvider.Authenticate(ctx, userName, password)
+	if err != nil {
+		return item, err
+	} else if item != nil {
+		if item.Status != 1 {
+			return item, errors.ErrUserDisable
+		}
+		return item, nil
+	}
+
 	result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
 		UserName: userName,
 	})
@@ -78,37 +262,439 @@ func (a *Login) Verify(ctx context.Context, userName, password string) (*schema.
 		return nil, errors.ErrInvalidUserName
 	}
 
-	item := result.Data[0]
-	if item.Password != util.SHA1HashString(password) {
-		return nil, errors.ErrInvalidPassword
+	item = result.Data[0]
+	ok, rehash, err := a.Password.Verify(item.Password, password)
+	if err != nil {
+		return item, errors.WithStack(err)
+	} else if !ok {
+		return item, errors.ErrInvalidPassword
 	} else if item.Status != 1 {
-		return nil, errors.ErrUserDisable
+		return item, errors.ErrUserDisable
+	} else if !item.EmailVerified && item.SelfRegistered() && config.Global().Register.VerifyEmail {
+		return item, errors.ErrEmailNotVerified
 	}
 
+	if rehash {
+		if forceReset(item.Password) {
+			return item, errors.ErrPasswordExpired
+		}
+		a.rehashPassword(ctx, item, password)
+	}
+
+	if a.PasswordPolicyBll.Expired(ctx, item) {
+		return item, errors.ErrPasswordChangeRequired
+	}
 	return item, nil
 }
 
-// GenerateToken - Generate token
+// Record a failed login attempt, internal errors are not exposed as reasons
+func (a *Login) recordFailure(ctx context.Context, userUUID, userName string, err error) {
+	reason := errors.ErrInternalServer.Error()
+	if resErr := errors.UnWrapResponse(err); resErr != nil {
+		reason = resErr.Message
+	}
+
+	a.SessionBll.RecordLogin(ctx, schema.LoginHistory{
+		UserUUID: userUUID,
+		UserName: userName,
+		Reason:   reason,
+	})
+}
+
+// Legacy hashes are refused instead of upgraded when resets are forced
+func forceReset(encoded string) bool {
+	return config.Global().Password.ForceResetLegacy && password.IsLegacy(encoded)
+}
+
+// Replace the stored hash of a verified password by one of the current
+// algorithm, the login goes on if it fails
+func (a *Login) rehashPassword(ctx context.Context, item *schema.User, plain string) {
+	encoded, err := a.Password.Hash(plain)
+	if err == nil {
+		err = a.UserModel.UpdatePasswordHash(ctx, item.UUID, encoded)
+	}
+	if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("User login"), logger.SetSpanFuncName("rehashPassword")).
+			Warnf("Rehash password of %s error: %s", item.UUID, err.Error())
+	}
+}
+
+// GenerateToken - Generate the token of a successful login, a session is
+// opened and the login is recorded in the login history
 func (a *Login) GenerateToken(ctx context.Context, userUUID string) (*schema.LoginTokenInfo, error) {
 	tokenInfo, err := a.Auth.GenerateToken(ctx, userUUID)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	item := &schema.LoginTokenInfo{
-		AccessToken: tokenInfo.GetAccessToken(),
-		TokenType:   tokenInfo.GetTokenType(),
-		ExpiresAt:   tokenInfo.GetExpiresAt(),
+	err = a.SessionBll.Create(ctx, userUUID, tokenInfo.GetSessionID())
+	if err != nil {
+		return nil, err
 	}
-	return item, nil
+
+	item := schema.LoginHistory{
+		UserUUID: userUUID,
+		Success:  true,
+	}
+	if common.CheckIsRootUser(ctx, userUUID) {
+		item.UserName = common.GetRootUser().UserName
+	} else if user, err := a.UserModel.Get(ctx, userUUID); err == nil && user != nil {
+		item.UserName = user.UserName
+	}
+	a.SessionBll.RecordLogin(ctx, item)
+
+	return newLoginTokenInfo(tokenInfo), nil
+}
+
+// RefreshToken - Rotate a refresh token of a user that is still allowed to login
+func (a *Login) RefreshToken(ctx context.Context, refreshToken string) (*schema.LoginTokenInfo, error) {
+	userUUID, err := a.Auth.ParseRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if err == auth.ErrInvalidToken {
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	// Scoped refresh tokens belong to OAuth2 clients and are only rotated
+	// by the token endpoint
+	if sa, ok := a.Auth.(auth.ScopedAuther); ok {
+		if _, scope, err := sa.ParseScopedRefreshToken(ctx, refreshToken); err != nil {
+			return nil, errors.WithStack(err)
+		} else if scope != "" {
+			return nil, errors.ErrInvalidToken
+		}
+	}
+
+	if !common.CheckIsRootUser(ctx, userUUID) {
+		if _, err := a.getAndCheckUser(ctx, userUUID); err != nil {
+			return nil, err
+		}
+	}
+
+	tokenInfo, err := a.Auth.RefreshToken(ctx, refreshToken)
+	if err != nil {
+		switch err {
+		case auth.ErrReusedToken:
+			logger.StartSpan(ctx, logger.SetSpanTitle("Refresh token"), logger.SetSpanFuncName("RefreshToken")).
+				Warnf("Refresh token of %s reused, its token family is revoked", userUUID)
+			return nil, errors.ErrInvalidToken
+		case auth.ErrInvalidToken:
+			return nil, errors.ErrInvalidToken
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	err = a.SessionBll.Touch(ctx, tokenInfo.GetSessionID())
+	if err != nil {
+		return nil, err
+	}
+	return newLoginTokenInfo(tokenInfo), nil
+}
+
+func newLoginTokenInfo(tokenInfo auth.TokenInfo) *schema.LoginTokenInfo {
+	return &schema.LoginTokenInfo{
+		AccessToken:  tokenInfo.GetAccessToken(),
+		TokenType:    tokenInfo.GetTokenType(),
+		ExpiresAt:    tokenInfo.GetExpiresAt(),
+		RefreshToken: tokenInfo.GetRefreshToken(),
+	}
+}
+
+// Check whether a user finishes the login with a second step, and whether it
+// enabled two-factor authentication or has to enable it during the login
+func (a *Login) mfaPending(ctx context.Context, user *schema.User) (bool, bool, error) {
+	if common.CheckIsRootUser(ctx, user.UUID) {
+		return false, false, nil
+	}
+
+	enabled, err := a.MFABll.Enabled(ctx, user.UUID)
+	if err != nil {
+		return false, false, err
+	} else if enabled {
+		return true, true, nil
+	}
+
+	required, err := a.MFABll.Required(ctx, user.UUID)
+	if err != nil {
+		return false, false, err
+	}
+	return required, false, nil
+}
+
+// CreateMFAChallenge - Start the second login step of a verified user, no
+// challenge is returned when the user does not need two-factor authentication
+func (a *Login) CreateMFAChallenge(ctx context.Context, user *schema.User) (*schema.LoginMFAChallenge, error) {
+	pending, enabled, err := a.mfaPending(ctx, user)
+	if err != nil {
+		return nil, err
+	} else if !pending {
+		return nil, nil
+	}
+
+	expired := config.Global().MFA.ChallengeExpired
+	if expired <= 0 {
+		expired = 300
+	}
+	expiresAt := time.Now().Add(time.Duration(expired) * time.Second).Unix()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{
+		Audience:  mfaAudience,
+		Subject:   user.UUID,
+		ExpiresAt: expiresAt,
+		Id:        util.MustUUID(),
+		IssuedAt:  time.Now().Unix(),
+	}).SignedString(mfaSigningKey())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &schema.LoginMFAChallenge{
+		MFAToken:  token,
+		Enroll:    !enabled,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// VerifyMFA - Finish a login with a TOTP or recovery code, a user enrolling
+// during the login confirms the enrolment and gets its recovery codes
+func (a *Login) VerifyMFA(ctx context.Context, params schema.LoginMFAParam) (*schema.User, *schema.LoginTokenInfo, error) {
+	user, claims, err := a.checkMFAToken(ctx, params.MFAToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enabled, err := a.MFABll.Enabled(ctx, user.UUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var codes *schema.MFARecoveryCodes
+	if enabled {
+		err = a.MFABll.Verify(ctx, user.UUID, params.Code)
+	} else {
+		codes, err = a.MFABll.Confirm(ctx, user.UUID, params.Code)
+	}
+	if err != nil {
+		a.recordFailure(ctx, user.UUID, user.UserName, err)
+		return nil, nil, err
+	}
+
+	err = a.useMFAChallenge(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokenInfo, err := a.GenerateToken(ctx, user.UUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if codes != nil {
+		tokenInfo.RecoveryCodes = codes.Codes
+	}
+	return user, tokenInfo, nil
+}
+
+// EnrollMFA - Start the TOTP enrolment of a user that has to enable it to login
+func (a *Login) EnrollMFA(ctx context.Context, mfaToken string) (*schema.MFAEnrollment, error) {
+	user, _, err := a.checkMFAToken(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	return a.MFABll.Enroll(ctx, user.UUID)
+}
+
+// Challenge tokens are signed with a key of their own so that they are
+// never accepted as access tokens
+func mfaSigningKey() []byte {
+	return []byte(util.HMACSHA256HashString(config.Global().JWTAuth.SecretKey, mfaAudience))
+}
+
+func (a *Login) checkMFAToken(ctx context.Context, mfaToken string) (*schema.User, *jwt.StandardClaims, error) {
+	claims := new(jwt.StandardClaims)
+	token, err := jwt.ParseWithClaims(mfaToken, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.ErrInvalidMFAToken
+		}
+		return mfaSigningKey(), nil
+	})
+	if err != nil || !token.Valid || !claims.VerifyAudience(mfaAudience, true) || claims.Id == "" {
+		return nil, nil, errors.ErrInvalidMFAToken
+	}
+
+	// Refused before the code is checked, so that no recovery code is used up
+	ca, ok := a.Auth.(auth.ChallengeAuther)
+	if !ok {
+		return nil, nil, errors.New400Response("Single-use challenges are not supported")
+	}
+	used, err := ca.ChallengeUsed(ctx, claims.Id)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	} else if used {
+		return nil, nil, errors.ErrInvalidMFAToken
+	}
+
+	user, err := a.getAndCheckUser(ctx, claims.Subject)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, claims, nil
+}
+
+// A challenge finishes a single login, it is used up until it expires
+func (a *Login) useMFAChallenge(ctx context.Context, claims *jwt.StandardClaims) error {
+	ca, ok := a.Auth.(auth.ChallengeAuther)
+	if !ok {
+		return errors.New400Response("Single-use challenges are not supported")
+	}
+
+	ok, err := ca.UseChallenge(ctx, claims.Id, time.Until(time.Unix(claims.ExpiresAt, 0))+time.Minute)
+	if err != nil {
+		return errors.WithStack(err)
+	} else if !ok {
+		return errors.ErrInvalidMFAToken
+	}
+	return nil
 }
 
-// DestroyToken - Destroy token
+// DestroyToken - Destroy token and close its session
 func (a *Login) DestroyToken(ctx context.Context, tokenString string) error {
-	err := a.Auth.DestroyToken(ctx, tokenString)
+	sessionID, err := a.Auth.ParseSessionID(ctx, tokenString)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = a.Auth.DestroyToken(ctx, tokenString)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	return a.SessionBll.Remove(ctx, sessionID)
+}
+
+// Impersonate - Generate a token acting as an enabled user for the impersonator,
+// the token expires shortly and can neither be refreshed nor impersonate again
+func (a *Login) Impersonate(ctx context.Context, impersonatorUUID, userUUID string) (*schema.LoginTokenInfo, error) {
+	if _, ok := icontext.FromImpersonator(ctx); ok {
+		return nil, errors.New400Response("Impersonation tokens can not impersonate")
+	} else if userUUID == impersonatorUUID {
+		return nil, errors.New400Response("Users can not impersonate themselves")
+	} else if common.CheckIsRootUser(ctx, userUUID) {
+		return nil, errors.New400Response("The root user can not be impersonated")
+	}
+
+	ia, ok := a.Auth.(auth.ImpersonatingAuther)
+	if !ok {
+		return nil, errors.New400Response("Impersonation is not supported")
+	}
+
+	user, err := a.UserModel.Get(ctx, userUUID, schema.UserQueryOptions{
+		IncludeRoles: true,
+	})
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, errors.ErrNotFound
+	} else if user.Status != 1 {
+		return nil, errors.ErrUserDisable
+	}
+
+	err = a.checkImpersonation(ctx, impersonatorUUID, user)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo, err := ia.GenerateImpersonationToken(ctx, userUUID, impersonatorUUID, config.Global().JWTAuth.ImpersonationExpired)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The token is a session of the impersonator, so that it is revoked with
+	// the sessions of the impersonator
+	err = a.SessionBll.Create(ctx, impersonatorUUID, tokenInfo.GetSessionID())
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.AuditBll.Record(ctx, aschema.AuditEntityUser, aschema.AuditActionImpersonate, userUUID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Impersonation"), logger.SetSpanFuncName("Impersonate")).
+		Warnf("Impersonate %s (%s)", user.UserName, userUUID)
+	return newLoginTokenInfo(tokenInfo), nil
+}
+
+// Users may only impersonate users that have no role they have not, so that
+// impersonation never grants more permissions; the root user impersonates anyone
+func (a *Login) checkImpersonation(ctx context.Context, impersonatorUUID string, user *schema.User) error {
+	ok, err := hasRoles(ctx, a.UserModel, impersonatorUUID, user)
+	if err != nil {
+		return err
+	} else if !ok {
+		return errors.New400Response("Users can only impersonate users without further roles")
+	}
+	return nil
+}
+
+// Check that the acting user has every role of a user (with its roles), the
+// root user has all of them
+func hasRoles(ctx context.Context, mUser model.IUser, actorUUID string, user *schema.User) (bool, error) {
+	if common.CheckIsRootUser(ctx, actorUUID) {
+		return true, nil
+	}
+
+	actor, err := mUser.Get(ctx, actorUUID, schema.UserQueryOptions{
+		IncludeRoles: true,
+	})
+	if err != nil {
+		return false, err
+	} else if actor == nil {
+		return false, nil
+	}
+
+	roles := make(map[string]bool)
+	for _, roleID := range actor.Roles.ToRoleIDs() {
+		roles[roleID] = true
+	}
+	for _, roleID := range user.Roles.ToRoleIDs() {
+		if !roles[roleID] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// EndImpersonation - Destroy an impersonation token before it expires
+func (a *Login) EndImpersonation(ctx context.Context, tokenString string) error {
+	impersonatorUUID, ok := icontext.FromImpersonator(ctx)
+	if !ok {
+		return errors.New400Response("The token does not impersonate a user")
+	}
+
+	sessionID, err := a.Auth.ParseSessionID(ctx, tokenString)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = a.Auth.DestroyToken(ctx, tokenString)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = a.SessionBll.Remove(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	userUUID, _ := icontext.FromUserUUID(ctx)
+	err = a.AuditBll.Record(ctx, aschema.AuditEntityUser, aschema.AuditActionImpersonateEnd, userUUID, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Impersonation"), logger.SetSpanFuncName("EndImpersonation")).
+		Infof("End impersonation of %s by %s", userUUID, impersonatorUUID)
 	return nil
 }
 
@@ -143,9 +729,12 @@ func (a *Login) GetLoginInfo(ctx context.Context, userUUID string) (*schema.User
 	}
 
 	loginInfo := &schema.UserLoginInfo{
-		UserName: user.UserName,
-		RealName: user.RealName,
+		UserName:      user.UserName,
+		RealName:      user.RealName,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
 	}
+	loginInfo.ImpersonatedBy, _ = icontext.FromImpersonator(ctx)
 
 	if roleIDs := user.Roles.ToRoleIDs(); len(roleIDs) > 0 {
 		roles, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
@@ -234,10 +823,62 @@ func (a *Login) UpdatePassword(ctx context.Context, userUUID string, params sche
 	user, err := a.getAndCheckUser(ctx, userUUID)
 	if err != nil {
 		return err
-	} else if util.SHA1HashString(params.OldPassword) != user.Password {
+	}
+
+	ok, _, err := a.Password.Verify(user.Password, params.OldPassword)
+	if err != nil {
+		return errors.WithStack(err)
+	} else if !ok {
 		return errors.New400Response("Old password is incorrect")
 	}
+	return a.setPassword(ctx, user, params.NewPassword)
+}
 
-	params.NewPassword = util.SHA1HashString(params.NewPassword)
-	return a.UserModel.UpdatePassword(ctx, userUUID, params.NewPassword)
+// ChangePassword - Change an expired password or a password refused for its
+// legacy hash before login, the change counts as a login attempt
+func (a *Login) ChangePassword(ctx context.Context, params schema.PasswordChangeParam) error {
+	ip, _ := clientInfo(ctx)
+	if wait := beginAttempt(ctx, a.Lockout, params.UserName, ip); wait > 0 {
+		err := loginLockedError(wait)
+		a.recordFailure(ctx, "", params.UserName, err)
+		return err
+	}
+
+	item, err := a.verify(ctx, params.UserName, params.OldPassword)
+	if err != nil && err != errors.ErrPasswordChangeRequired && err != errors.ErrPasswordExpired {
+		var userUUID string
+		if item != nil {
+			userUUID = item.UUID
+		}
+		if err == errors.ErrInvalidUserName || err == errors.ErrInvalidPassword {
+			endAttempt(ctx, a.Lockout, params.UserName, ip, false)
+		}
+		a.recordFailure(ctx, userUUID, params.UserName, err)
+		return err
+	}
+	endAttempt(ctx, a.Lockout, params.UserName, ip, true)
+
+	if common.CheckIsRootUser(ctx, item.UUID) {
+		return errors.New400Response("Root user not allowed to update the password")
+	}
+	return a.setPassword(ctx, item, params.NewPassword)
+}
+
+// Set a new password of a user that meets the password policy
+func (a *Login) setPassword(ctx context.Context, user *schema.User, plain string) error {
+	err := a.PasswordPolicyBll.Check(ctx, user, plain)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := a.Password.Hash(plain)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = a.UserModel.UpdatePassword(ctx, user.UUID, encoded)
+	if err != nil {
+		return err
+	}
+	return a.PasswordPolicyBll.Record(ctx, user.UUID, encoded)
 }
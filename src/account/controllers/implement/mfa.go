@@ -0,0 +1,312 @@
+package implement
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image/png"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/auth/lockout"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/pquerna/otp/totp"
+)
+
+// Size of the enrolment QR code in pixels
+const qrCodeSize = 256
+
+// NewMFA - Create a two-factor authentication management instance
+func NewMFA(
+	mUserMFA model.IUserMFA,
+	mUser model.IUser,
+	mRole model.IRole,
+	g *lockout.Guard,
+) *MFA {
+	return &MFA{
+		UserMFAModel: mUserMFA,
+		UserModel:    mUser,
+		RoleModel:    mRole,
+		Lockout:      g,
+	}
+}
+
+// MFA - Two-factor authentication management
+type MFA struct {
+	UserMFAModel model.IUserMFA
+	UserModel    model.IUser
+	RoleModel    model.IRole
+	Lockout      *lockout.Guard
+}
+
+// Get - Get the two-factor authentication state of a user
+func (a *MFA) Get(ctx context.Context, userUUID string) (*schema.MFAStatus, error) {
+	required, err := a.Required(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := a.UserMFAModel.Get(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &schema.MFAStatus{Required: required}
+	if item != nil && item.Enabled {
+		status.Enabled = true
+		status.RecoveryCodes = len(item.RecoveryCodes)
+	}
+	return status, nil
+}
+
+// Required - Check whether a role of the user makes two-factor authentication mandatory
+func (a *MFA) Required(ctx context.Context, userUUID string) (bool, error) {
+	result, err := a.RoleModel.Query(ctx, schema.RoleQueryParam{
+		UserUUID: userUUID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, item := range result.Data {
+		if item.MFARequired {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Enabled - Check whether the user has a confirmed TOTP enrolment
+func (a *MFA) Enabled(ctx context.Context, userUUID string) (bool, error) {
+	item, err := a.UserMFAModel.Get(ctx, userUUID)
+	if err != nil {
+		return false, err
+	}
+	return item != nil && item.Enabled, nil
+}
+
+// Enroll - Start a TOTP enrolment, a pending enrolment is replaced by the new secret
+func (a *MFA) Enroll(ctx context.Context, userUUID string) (*schema.MFAEnrollment, error) {
+	if common.CheckIsRootUser(ctx, userUUID) {
+		return nil, errors.New400Response("Root user not allowed to enroll two-factor authentication")
+	}
+
+	user, err := a.UserModel.Get(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	} else if user == nil {
+		return nil, errors.ErrInvalidUser
+	}
+
+	if ok, err := a.Enabled(ctx, userUUID); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, errors.New400Response("Two-factor authentication is already enabled")
+	}
+
+	issuer := config.Global().MFA.Issuer
+	if issuer == "" {
+		issuer = "MayCMF"
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: user.UserName,
+		Period:      controllers.TOTPPeriod,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = a.UserMFAModel.Save(ctx, schema.UserMFA{
+		UserUUID: userUUID,
+		Secret:   key.Secret(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.MFAEnrollment{
+		Secret: key.Secret(),
+		URL:    key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// Check a code as an attempt of the login lockout of the user, so that wrong
+// codes delay and lock further logins and codes like wrong passwords do
+func (a *MFA) attempt(ctx context.Context, userUUID string, fn func() error) error {
+	if a.Lockout == nil {
+		return fn()
+	}
+
+	user, err := a.UserModel.Get(ctx, userUUID)
+	if err != nil {
+		return err
+	} else if user == nil {
+		return errors.ErrNotFound
+	}
+
+	ip, _ := clientInfo(ctx)
+	if wait := beginAttempt(ctx, a.Lockout, user.UserName, ip); wait > 0 {
+		return loginLockedError(wait)
+	}
+
+	err = fn()
+	if err == nil {
+		endAttempt(ctx, a.Lockout, user.UserName, ip, true)
+	} else if err == errors.ErrInvalidMFACode {
+		endAttempt(ctx, a.Lockout, user.UserName, ip, false)
+	}
+	return err
+}
+
+// Confirm - Enable a pending enrolment with a first code of the authenticator app
+func (a *MFA) Confirm(ctx context.Context, userUUID, code string) (*schema.MFARecoveryCodes, error) {
+	var codes *schema.MFARecoveryCodes
+	err := a.attempt(ctx, userUUID, func() error {
+		var err error
+		codes, err = a.confirm(ctx, userUUID, code)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (a *MFA) confirm(ctx context.Context, userUUID, code string) (*schema.MFARecoveryCodes, error) {
+	item, err := a.UserMFAModel.Get(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.New400Response("Two-factor authentication is not enrolled")
+	} else if item.Enabled {
+		return nil, errors.New400Response("Two-factor authentication is already enabled")
+	}
+
+	counter, ok := controllers.ValidateTOTP(item.Secret, code, time.Now(), item.LastCounter)
+	if !ok {
+		return nil, errors.ErrInvalidMFACode
+	}
+
+	item.Enabled = true
+	item.LastCounter = counter
+	return a.saveRecoveryCodes(ctx, item)
+}
+
+// Verify - Verify a TOTP code or use up a recovery code of an enabled user
+func (a *MFA) Verify(ctx context.Context, userUUID, code string) error {
+	return a.attempt(ctx, userUUID, func() error {
+		return a.verify(ctx, userUUID, code)
+	})
+}
+
+func (a *MFA) verify(ctx context.Context, userUUID, code string) error {
+	item, err := a.getEnabled(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	if counter, ok := controllers.ValidateTOTP(item.Secret, code, time.Now(), item.LastCounter); ok {
+		item.LastCounter = counter
+		return a.UserMFAModel.Save(ctx, *item)
+	}
+
+	hash := controllers.HashRecoveryCode(userUUID, code)
+	for i, v := range item.RecoveryCodes {
+		if v == hash {
+			item.RecoveryCodes = append(item.RecoveryCodes[:i], item.RecoveryCodes[i+1:]...)
+			return a.UserMFAModel.Save(ctx, *item)
+		}
+	}
+	return errors.ErrInvalidMFACode
+}
+
+// Disable - Disable the two-factor authentication, not allowed while a role requires it
+func (a *MFA) Disable(ctx context.Context, userUUID, code string) error {
+	if required, err := a.Required(ctx, userUUID); err != nil {
+		return err
+	} else if required {
+		return errors.New400Response("Two-factor authentication is required by a role of the user")
+	}
+
+	if err := a.Verify(ctx, userUUID, code); err != nil {
+		return err
+	}
+	return a.UserMFAModel.Delete(ctx, userUUID)
+}
+
+// RegenerateRecoveryCodes - Replace all recovery codes after a valid code
+func (a *MFA) RegenerateRecoveryCodes(ctx context.Context, userUUID, code string) (*schema.MFARecoveryCodes, error) {
+	if err := a.Verify(ctx, userUUID, code); err != nil {
+		return nil, err
+	}
+
+	item, err := a.getEnabled(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	return a.saveRecoveryCodes(ctx, item)
+}
+
+// Reset - Remove the two-factor authentication of a user
+func (a *MFA) Reset(ctx context.Context, userUUID string) error {
+	item, err := a.UserMFAModel.Get(ctx, userUUID)
+	if err != nil {
+		return err
+	} else if item == nil {
+		return errors.ErrNotFound
+	}
+	return a.UserMFAModel.Delete(ctx, userUUID)
+}
+
+func (a *MFA) getEnabled(ctx context.Context, userUUID string) (*schema.UserMFA, error) {
+	item, err := a.UserMFAModel.Get(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	} else if item == nil || !item.Enabled {
+		return nil, errors.New400Response("Two-factor authentication is not enabled")
+	}
+	return item, nil
+}
+
+// Store the hashes of new recovery codes, the plain codes are returned once
+func (a *MFA) saveRecoveryCodes(ctx context.Context, item *schema.UserMFA) (*schema.MFARecoveryCodes, error) {
+	n := config.Global().MFA.RecoveryCodes
+	if n <= 0 {
+		n = 10
+	} else if n > 30 {
+		// Limit of the recovery codes column
+		n = 30
+	}
+
+	codes, err := controllers.NewRecoveryCodes(n)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	item.RecoveryCodes = make([]string, len(codes))
+	for i, code := range codes {
+		item.RecoveryCodes[i] = controllers.HashRecoveryCode(item.UserUUID, code)
+	}
+
+	err = a.UserMFAModel.Save(ctx, *item)
+	if err != nil {
+		return nil, err
+	}
+	return &schema.MFARecoveryCodes{Codes: codes}, nil
+}
@@ -0,0 +1,578 @@
+package implement
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/auth"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/casbin/casbin/v2"
+)
+
+// NewOAuth - Create an OAuth2 authorization server instance
+func NewOAuth(
+	e *casbin.SyncedEnforcer,
+	a auth.Auther,
+	mOAuthClient model.IOAuthClient,
+	mOAuthScope model.IOAuthScope,
+	mOAuthGrant model.IOAuthGrant,
+	mOAuthCode model.IOAuthCode,
+	mUser model.IUser,
+) *OAuth {
+	return &OAuth{
+		Enforcer:         e,
+		Auth:             a,
+		OAuthClientModel: mOAuthClient,
+		OAuthScopeModel:  mOAuthScope,
+		OAuthGrantModel:  mOAuthGrant,
+		OAuthCodeModel:   mOAuthCode,
+		UserModel:        mUser,
+	}
+}
+
+// OAuth - OAuth2 authorization server
+type OAuth struct {
+	Enforcer         *casbin.SyncedEnforcer
+	Auth             auth.Auther
+	OAuthClientModel model.IOAuthClient
+	OAuthScopeModel  model.IOAuthScope
+	OAuthGrantModel  model.IOAuthGrant
+	OAuthCodeModel   model.IOAuthCode
+	UserModel        model.IUser
+}
+
+// Validated authorization request
+type oauthAuthorization struct {
+	Client      *schema.OAuthClient
+	RedirectURI string
+	Scopes      schema.OAuthScopes
+}
+
+func (a *OAuth) checkAuthorization(ctx context.Context, userUUID string, params schema.OAuthAuthorizeParam) (*oauthAuthorization, error) {
+	if common.CheckIsRootUser(ctx, userUUID) {
+		return nil, errors.New400Response("Root user not allowed to authorize clients")
+	}
+
+	client, err := a.OAuthClientModel.Get(ctx, params.ClientID)
+	if err != nil {
+		return nil, err
+	} else if client == nil || client.Status != 1 {
+		return nil, errors.New400Response("Invalid client")
+	} else if !client.HasGrantType(schema.OAuthGrantAuthorizationCode) {
+		return nil, errors.New400Response("Client is not allowed to use the authorization code grant")
+	}
+
+	redirectURI := params.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	} else if !client.HasRedirectURI(redirectURI) {
+		return nil, errors.New400Response("Invalid redirect URI")
+	}
+
+	if params.ResponseType != "code" {
+		return nil, errors.New400Response("Unsupported response type")
+	}
+
+	// Public clients cannot keep a secret, PKCE binds the code to the client instance
+	if params.CodeChallenge != "" {
+		if params.CodeChallengeMethod != "S256" {
+			return nil, errors.New400Response("Unsupported code challenge method")
+		} else if l := len(params.CodeChallenge); l < 43 || l > 128 {
+			return nil, errors.New400Response("Invalid code challenge")
+		}
+	} else if client.Public {
+		return nil, errors.New400Response("Code challenge is required")
+	}
+
+	names := controllers.ParseOAuthScope(params.Scope)
+	if len(names) == 0 {
+		names = client.Scopes
+	}
+	allowed := make(map[string]bool)
+	for _, v := range client.Scopes {
+		allowed[v] = true
+	}
+	for _, v := range names {
+		if !allowed[v] {
+			return nil, errors.New400Response("Invalid scope")
+		}
+	}
+
+	result, err := a.OAuthScopeModel.Query(ctx, schema.OAuthScopeQueryParam{
+		Names: names,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(result.Data) == 0 {
+		return nil, errors.New400Response("Invalid scope")
+	}
+
+	return &oauthAuthorization{
+		Client:      client,
+		RedirectURI: redirectURI,
+		Scopes:      result.Data,
+	}, nil
+}
+
+// Grant exactly the scopes to a client, every set of scopes approved by the
+// user or requested by a client acting as its user has a grant of its own, so
+// that the tokens only carry the scopes of their request
+func (a *OAuth) grant(ctx context.Context, clientUUID, userUUID string, scopes []string) (*schema.OAuthGrant, error) {
+	grants, err := a.OAuthGrantModel.Query(ctx, schema.OAuthGrantQueryParam{
+		ClientUUID: clientUUID,
+		UserUUID:   userUUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, v := range scopes {
+		wanted[v] = true
+	}
+
+	var item *schema.OAuthGrant
+	for _, grant := range grants {
+		if sameScopes(wanted, grant.Scopes) {
+			item = grant
+			break
+		}
+	}
+
+	if item == nil {
+		item = &schema.OAuthGrant{
+			UUID:       util.MustUUID(),
+			ClientUUID: clientUUID,
+			UserUUID:   userUUID,
+			Scopes:     scopes,
+		}
+		err = a.OAuthGrantModel.Create(ctx, *item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Global().Casbin.Enable {
+		err = a.LoadGrantPolicy(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
+
+func sameScopes(wanted map[string]bool, scopes []string) bool {
+	found := make(map[string]bool)
+	for _, v := range scopes {
+		if !wanted[v] {
+			return false
+		}
+		found[v] = true
+	}
+	return len(found) == len(wanted)
+}
+
+// Authorization - Check the authorization request of a client and describe it for the consent screen
+func (a *OAuth) Authorization(ctx context.Context, userUUID string, params schema.OAuthAuthorizeParam) (*schema.OAuthConsent, error) {
+	authz, err := a.checkAuthorization(ctx, userUUID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := a.OAuthGrantModel.Query(ctx, schema.OAuthGrantQueryParam{
+		ClientUUID: authz.Client.UUID,
+		UserUUID:   userUUID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The user approved all scopes before, possibly in several requests
+	scopes := make(map[string]bool)
+	for _, grant := range grants {
+		for _, v := range grant.Scopes {
+			scopes[v] = true
+		}
+	}
+	granted := true
+	for _, v := range authz.Scopes {
+		if !scopes[v.Name] {
+			granted = false
+			break
+		}
+	}
+
+	return &schema.OAuthConsent{
+		ClientID:    authz.Client.UUID,
+		ClientName:  authz.Client.Name,
+		RedirectURI: authz.RedirectURI,
+		Scopes:      authz.Scopes,
+		Granted:     granted,
+	}, nil
+}
+
+func oauthRedirectURI(redirectURI string, values map[string]string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	q := u.Query()
+	for k, v := range values {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Authorize - Answer the authorization request of a client, the redirect URI holds the code or the denial
+func (a *OAuth) Authorize(ctx context.Context, userUUID string, params schema.OAuthAuthorizeParam) (*schema.OAuthRedirect, error) {
+	authz, err := a.checkAuthorization(ctx, userUUID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if !params.Approve {
+		return &schema.OAuthRedirect{
+			RedirectURI: oauthRedirectURI(authz.RedirectURI, map[string]string{
+				"error": controllers.OAuthErrAccessDenied,
+				"state": params.State,
+			}),
+		}, nil
+	}
+
+	scopes := make([]string, len(authz.Scopes))
+	for i, item := range authz.Scopes {
+		scopes[i] = item.Name
+	}
+
+	grant, err := a.grant(ctx, authz.Client.UUID, userUUID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	code, hash, err := controllers.NewOAuthSecret()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	expired := config.Global().OAuth.CodeExpired
+	if expired <= 0 {
+		expired = 60
+	}
+
+	err = a.OAuthCodeModel.DeleteExpired(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.OAuthCodeModel.Create(ctx, schema.OAuthCode{
+		UUID:          util.MustUUID(),
+		CodeHash:      hash,
+		GrantUUID:     grant.UUID,
+		Scopes:        scopes,
+		RedirectURI:   params.RedirectURI,
+		CodeChallenge: params.CodeChallenge,
+		ExpiresAt:     time.Now().Add(time.Duration(expired) * time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.OAuthRedirect{
+		RedirectURI: oauthRedirectURI(authz.RedirectURI, map[string]string{
+			"code":  code,
+			"state": params.State,
+		}),
+	}, nil
+}
+
+func (a *OAuth) authenticateClient(ctx context.Context, clientID, clientSecret string) (*schema.OAuthClient, error) {
+	if clientID == "" {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidClient, "Client authentication failed")
+	}
+
+	client, err := a.OAuthClientModel.Get(ctx, clientID)
+	if err != nil {
+		return nil, err
+	} else if client == nil || client.Status != 1 {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidClient, "Client authentication failed")
+	}
+
+	if client.Public {
+		if clientSecret != "" {
+			return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidClient, "Public clients have no secret")
+		}
+		return client, nil
+	}
+
+	hash := controllers.HashOAuthSecret(clientSecret)
+	if clientSecret == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidClient, "Client authentication failed")
+	}
+	return client, nil
+}
+
+// Token - Issue tokens to an authenticated client
+func (a *OAuth) Token(ctx context.Context, clientID, clientSecret string, params schema.OAuthTokenParam) (*schema.OAuthTokenInfo, error) {
+	client, err := a.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch params.GrantType {
+	case schema.OAuthGrantAuthorizationCode, schema.OAuthGrantClientCredentials, schema.OAuthGrantRefreshToken:
+	default:
+		return nil, controllers.NewOAuthError(controllers.OAuthErrUnsupportedGrantType, "Unsupported grant type")
+	}
+
+	if !client.HasGrantType(params.GrantType) {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrUnauthorizedClient, "Client is not allowed to use the grant type")
+	}
+
+	sa, ok := a.Auth.(auth.ScopedAuther)
+	if !ok {
+		return nil, errors.New("scoped tokens are not supported")
+	}
+
+	switch params.GrantType {
+	case schema.OAuthGrantAuthorizationCode:
+		return a.exchangeCode(ctx, sa, client, params)
+	case schema.OAuthGrantRefreshToken:
+		return a.refreshToken(ctx, sa, client, params)
+	}
+	return a.clientCredentials(ctx, sa, client, params)
+}
+
+// The user of a grant must still be allowed to login
+func (a *OAuth) checkUser(ctx context.Context, userUUID string) error {
+	user, err := a.UserModel.Get(ctx, userUUID)
+	if err != nil {
+		return err
+	} else if user == nil || user.Status != 1 {
+		return controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "User is not allowed to login")
+	}
+	return nil
+}
+
+func (a *OAuth) exchangeCode(ctx context.Context, sa auth.ScopedAuther, client *schema.OAuthClient, params schema.OAuthTokenParam) (*schema.OAuthTokenInfo, error) {
+	if params.Code == "" {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidRequest, "Code is required")
+	}
+
+	code, err := a.OAuthCodeModel.Take(ctx, controllers.HashOAuthSecret(params.Code))
+	if err != nil {
+		return nil, err
+	} else if code == nil || code.ExpiresAt.Before(time.Now()) {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "Invalid code")
+	} else if code.RedirectURI != params.RedirectURI {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "Redirect URI does not match")
+	}
+
+	if code.CodeChallenge != "" {
+		sum := sha256.Sum256([]byte(params.CodeVerifier))
+		challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+		if subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) != 1 {
+			return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "Invalid code verifier")
+		}
+	}
+
+	// The tokens carry the scopes approved for the code, not more
+	approved := make(map[string]bool)
+	for _, v := range code.Scopes {
+		approved[v] = true
+	}
+	grant, err := a.OAuthGrantModel.Get(ctx, code.GrantUUID)
+	if err != nil {
+		return nil, err
+	} else if grant == nil || grant.ClientUUID != client.UUID || !sameScopes(approved, grant.Scopes) {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "Invalid code")
+	}
+
+	err = a.checkUser(ctx, grant.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo, err := sa.GenerateScopedToken(ctx, grant.UserUUID, controllers.OAuthGrantSubject(grant.UUID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newOAuthTokenInfo(tokenInfo, client, grant), nil
+}
+
+func (a *OAuth) refreshToken(ctx context.Context, sa auth.ScopedAuther, client *schema.OAuthClient, params schema.OAuthTokenParam) (*schema.OAuthTokenInfo, error) {
+	userUUID, scope, err := sa.ParseScopedRefreshToken(ctx, params.RefreshToken)
+	if err != nil {
+		if err == auth.ErrInvalidToken {
+			return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "Invalid refresh token")
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	grantUUID, ok := controllers.ParseOAuthGrantSubject(scope)
+	if !ok {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "Invalid refresh token")
+	}
+
+	grant, err := a.OAuthGrantModel.Get(ctx, grantUUID)
+	if err != nil {
+		return nil, err
+	} else if grant == nil || grant.ClientUUID != client.UUID || grant.UserUUID != userUUID {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "Invalid refresh token")
+	}
+
+	// The tokens keep the scopes of the grant, narrowing them is not supported
+	if names := controllers.ParseOAuthScope(params.Scope); len(names) > 0 {
+		granted := make(map[string]bool)
+		for _, v := range controllers.OAuthGrantScopes(client, grant) {
+			granted[v] = true
+		}
+		for _, v := range names {
+			if !granted[v] {
+				return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidScope, "Scope was not granted")
+			}
+		}
+	}
+
+	err = a.checkUser(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo, err := sa.RefreshToken(ctx, params.RefreshToken)
+	if err != nil {
+		if err == auth.ErrInvalidToken || err == auth.ErrReusedToken {
+			return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidGrant, "Invalid refresh token")
+		}
+		return nil, errors.WithStack(err)
+	}
+	return newOAuthTokenInfo(tokenInfo, client, grant), nil
+}
+
+// The client acts as its service user with the requested scopes, all scopes
+// of the client when none are requested
+func (a *OAuth) clientCredentials(ctx context.Context, sa auth.ScopedAuther, client *schema.OAuthClient, params schema.OAuthTokenParam) (*schema.OAuthTokenInfo, error) {
+	names := controllers.ParseOAuthScope(params.Scope)
+	if len(names) == 0 {
+		names = client.Scopes
+	}
+	allowed := make(map[string]bool)
+	for _, v := range client.Scopes {
+		allowed[v] = true
+	}
+	for _, v := range names {
+		if !allowed[v] {
+			return nil, controllers.NewOAuthError(controllers.OAuthErrInvalidScope, "Invalid scope")
+		}
+	}
+
+	if client.UserUUID == "" || common.CheckIsRootUser(ctx, client.UserUUID) {
+		return nil, controllers.NewOAuthError(controllers.OAuthErrUnauthorizedClient, "Client has no user to act as")
+	}
+
+	err := a.checkUser(ctx, client.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := a.grant(ctx, client.UUID, client.UserUUID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo, err := sa.GenerateScopedToken(ctx, client.UserUUID, controllers.OAuthGrantSubject(grant.UUID))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// A client holding credentials requests new tokens instead of refreshing them
+	item := newOAuthTokenInfo(tokenInfo, client, grant)
+	item.RefreshToken = ""
+	return item, nil
+}
+
+func newOAuthTokenInfo(tokenInfo auth.TokenInfo, client *schema.OAuthClient, grant *schema.OAuthGrant) *schema.OAuthTokenInfo {
+	item := &schema.OAuthTokenInfo{
+		AccessToken: tokenInfo.GetAccessToken(),
+		TokenType:   tokenInfo.GetTokenType(),
+		ExpiresIn:   tokenInfo.GetExpiresAt() - time.Now().Unix(),
+		Scope:       strings.Join(controllers.OAuthGrantScopes(client, grant), " "),
+	}
+	if client.HasGrantType(schema.OAuthGrantRefreshToken) {
+		item.RefreshToken = tokenInfo.GetRefreshToken()
+	}
+	return item
+}
+
+// QueryGrants - Query the grants of a user
+func (a *OAuth) QueryGrants(ctx context.Context, params schema.OAuthGrantQueryParam) (schema.OAuthGrants, error) {
+	grants, err := a.OAuthGrantModel.Query(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]*schema.OAuthClient)
+	for _, item := range grants {
+		client, ok := clients[item.ClientUUID]
+		if !ok {
+			client, err = a.OAuthClientModel.Get(ctx, item.ClientUUID)
+			if err != nil {
+				return nil, err
+			}
+			clients[item.ClientUUID] = client
+		}
+		if client != nil {
+			item.ClientName = client.Name
+		}
+	}
+	return grants, nil
+}
+
+// RevokeGrant - Revoke a grant of a user, the tokens of the client stop working at once
+func (a *OAuth) RevokeGrant(ctx context.Context, userUUID, UUID string) error {
+	item, err := a.OAuthGrantModel.Get(ctx, UUID)
+	if err != nil {
+		return err
+	} else if item == nil || item.UserUUID != userUUID {
+		return errors.ErrNotFound
+	}
+
+	err = a.OAuthGrantModel.Delete(ctx, UUID)
+	if err != nil {
+		return err
+	}
+
+	if config.Global().Casbin.Enable {
+		_, _ = a.Enforcer.DeleteUser(controllers.OAuthGrantSubject(UUID))
+	}
+	return nil
+}
+
+// LoadGrantPolicy - Load the casbin policy of a grant
+func (a *OAuth) LoadGrantPolicy(ctx context.Context, item *schema.OAuthGrant) error {
+	client, err := a.OAuthClientModel.Get(ctx, item.ClientUUID)
+	if err != nil {
+		return err
+	}
+
+	subject := controllers.OAuthGrantSubject(item.UUID)
+	_, _ = a.Enforcer.DeleteRolesForUser(subject)
+	for _, v := range controllers.OAuthGrantScopes(client, item) {
+		_, _ = a.Enforcer.AddRoleForUser(subject, controllers.OAuthScopeSubject(v))
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package implement
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/config"
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/casbin/casbin/v2"
+)
+
+// NewOAuthClient - Create an OAuth2 client management instance
+func NewOAuthClient(
+	e *casbin.SyncedEnforcer,
+	mOAuthClient model.IOAuthClient,
+	mOAuthScope model.IOAuthScope,
+	mOAuthGrant model.IOAuthGrant,
+	mUser model.IUser,
+	bOAuth controllers.IOAuth,
+) *OAuthClient {
+	return &OAuthClient{
+		Enforcer:         e,
+		OAuthClientModel: mOAuthClient,
+		OAuthScopeModel:  mOAuthScope,
+		OAuthGrantModel:  mOAuthGrant,
+		UserModel:        mUser,
+		OAuthBll:         bOAuth,
+	}
+}
+
+// OAuthClient - OAuth2 client management
+type OAuthClient struct {
+	Enforcer         *casbin.SyncedEnforcer
+	OAuthClientModel model.IOAuthClient
+	OAuthScopeModel  model.IOAuthScope
+	OAuthGrantModel  model.IOAuthGrant
+	UserModel        model.IUser
+	OAuthBll         controllers.IOAuth
+}
+
+// Query - Query data
+func (a *OAuthClient) Query(ctx context.Context, params schema.OAuthClientQueryParam, opts ...schema.OAuthClientQueryOptions) (*schema.OAuthClientQueryResult, error) {
+	return a.OAuthClientModel.Query(ctx, params, opts...)
+}
+
+// Get - Get specified data
+func (a *OAuthClient) Get(ctx context.Context, UUID string) (*schema.OAuthClient, error) {
+	item, err := a.OAuthClientModel.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+
+	return item, nil
+}
+
+func (a *OAuthClient) checkClient(ctx context.Context, item schema.OAuthClient) error {
+	grantTypes := make(map[string]bool)
+	for _, v := range item.GrantTypes {
+		switch v {
+		case schema.OAuthGrantAuthorizationCode, schema.OAuthGrantClientCredentials, schema.OAuthGrantRefreshToken:
+			grantTypes[v] = true
+		default:
+			return errors.New400Response("Unsupported grant type")
+		}
+	}
+
+	if grantTypes[schema.OAuthGrantAuthorizationCode] && len(item.RedirectURIs) == 0 {
+		return errors.New400Response("Redirect URI is required")
+	} else if grantTypes[schema.OAuthGrantRefreshToken] && !grantTypes[schema.OAuthGrantAuthorizationCode] {
+		return errors.New400Response("Refresh tokens are only issued with the authorization code grant")
+	}
+	for _, v := range item.RedirectURIs {
+		u, err := url.Parse(v)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Fragment != "" {
+			return errors.New400Response("Invalid redirect URI")
+		}
+	}
+
+	// The client credentials grant acts as a user the client can keep secret
+	if grantTypes[schema.OAuthGrantClientCredentials] {
+		if item.Public {
+			return errors.New400Response("Public clients are not allowed to use the client credentials grant")
+		} else if item.UserUUID == "" {
+			return errors.New400Response("User is required by the client credentials grant")
+		} else if common.CheckIsRootUser(ctx, item.UserUUID) {
+			return errors.New400Response("Root user not allowed to be acted as by clients")
+		}
+
+		user, err := a.UserModel.Get(ctx, item.UserUUID, schema.UserQueryOptions{
+			IncludeRoles: true,
+		})
+		if err != nil {
+			return err
+		} else if user == nil {
+			return errors.New400Response("User does not exist")
+		}
+
+		// The client must not act with more permissions than its manager has
+		actorUUID, _ := icontext.FromUserUUID(ctx)
+		ok, err := hasRoles(ctx, a.UserModel, actorUUID, user)
+		if err != nil {
+			return err
+		} else if !ok {
+			return errors.New400Response("Clients can only act as users without further roles")
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, v := range item.Scopes {
+		names[v] = true
+	}
+	result, err := a.OAuthScopeModel.Query(ctx, schema.OAuthScopeQueryParam{
+		Names: item.Scopes,
+	})
+	if err != nil {
+		return err
+	} else if len(result.Data) != len(names) {
+		return errors.New400Response("Scope does not exist")
+	}
+	return nil
+}
+
+// Create - Create a client, the plain secret is only returned here
+func (a *OAuthClient) Create(ctx context.Context, item schema.OAuthClient) (*schema.OAuthClient, error) {
+	err := a.checkClient(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret string
+	item.SecretHash = ""
+	if !item.Public {
+		secret, item.SecretHash, err = controllers.NewOAuthSecret()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	item.UUID = util.MustUUID()
+	item.Creator, _ = icontext.FromUserUUID(ctx)
+	err = a.OAuthClientModel.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	nitem, err := a.Get(ctx, item.UUID)
+	if err != nil {
+		return nil, err
+	}
+	nitem.Secret = secret
+	return nitem, nil
+}
+
+// Update - Update data, a client becoming confidential gets a secret and the
+// grants are limited to the scopes left to the client
+func (a *OAuthClient) Update(ctx context.Context, UUID string, item schema.OAuthClient) (*schema.OAuthClient, error) {
+	oldItem, err := a.OAuthClientModel.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if oldItem == nil {
+		return nil, errors.ErrNotFound
+	}
+
+	err = a.checkClient(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	item.UUID = UUID
+	err = a.OAuthClientModel.Update(ctx, UUID, item)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret string
+	if item.Public && oldItem.SecretHash != "" {
+		err = a.OAuthClientModel.UpdateSecret(ctx, UUID, "")
+	} else if !item.Public && oldItem.SecretHash == "" {
+		var hash string
+		secret, hash, err = controllers.NewOAuthSecret()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		err = a.OAuthClientModel.UpdateSecret(ctx, UUID, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Global().Casbin.Enable {
+		err = a.loadGrantPolicy(ctx, UUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nitem, err := a.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	}
+	nitem.Secret = secret
+	return nitem, nil
+}
+
+func (a *OAuthClient) loadGrantPolicy(ctx context.Context, clientUUID string) error {
+	grants, err := a.OAuthGrantModel.Query(ctx, schema.OAuthGrantQueryParam{
+		ClientUUID: clientUUID,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, item := range grants {
+		err = a.OAuthBll.LoadGrantPolicy(ctx, item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResetSecret - Replace the secret of a client, the new plain secret is only returned here
+func (a *OAuthClient) ResetSecret(ctx context.Context, UUID string) (*schema.OAuthClient, error) {
+	item, err := a.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if item.Public {
+		return nil, errors.New400Response("Public clients have no secret")
+	}
+
+	secret, hash, err := controllers.NewOAuthSecret()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	err = a.OAuthClientModel.UpdateSecret(ctx, UUID, hash)
+	if err != nil {
+		return nil, err
+	}
+	item.Secret = secret
+	return item, nil
+}
+
+// Delete - Delete a client and its grants, the tokens of the client stop working at once
+func (a *OAuthClient) Delete(ctx context.Context, UUID string) error {
+	oldItem, err := a.OAuthClientModel.Get(ctx, UUID)
+	if err != nil {
+		return err
+	} else if oldItem == nil {
+		return errors.ErrNotFound
+	}
+
+	grants, err := a.OAuthGrantModel.Query(ctx, schema.OAuthGrantQueryParam{
+		ClientUUID: UUID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = a.OAuthClientModel.Delete(ctx, UUID)
+	if err != nil {
+		return err
+	}
+
+	err = a.OAuthGrantModel.DeleteByClient(ctx, UUID)
+	if err != nil {
+		return err
+	}
+
+	if config.Global().Casbin.Enable {
+		for _, item := range grants {
+			_, _ = a.Enforcer.DeleteUser(controllers.OAuthGrantSubject(item.UUID))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package implement
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/config"
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	comschema "github.com/MayCMF/core/src/common/schema"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/casbin/casbin/v2"
+)
+
+// NewOAuthScope - Create an OAuth2 scope management instance
+func NewOAuthScope(
+	e *casbin.SyncedEnforcer,
+	mOAuthScope model.IOAuthScope,
+	bRole controllers.IRole,
+) *OAuthScope {
+	return &OAuthScope{
+		Enforcer:        e,
+		OAuthScopeModel: mOAuthScope,
+		RoleBll:         bRole,
+	}
+}
+
+// OAuthScope - OAuth2 scope management
+type OAuthScope struct {
+	Enforcer        *casbin.SyncedEnforcer
+	OAuthScopeModel model.IOAuthScope
+	RoleBll         controllers.IRole
+}
+
+// Query - Query data
+func (a *OAuthScope) Query(ctx context.Context, params schema.OAuthScopeQueryParam, opts ...schema.OAuthScopeQueryOptions) (*schema.OAuthScopeQueryResult, error) {
+	return a.OAuthScopeModel.Query(ctx, params, opts...)
+}
+
+// Get - Get specified data
+func (a *OAuthScope) Get(ctx context.Context, UUID string) (*schema.OAuthScope, error) {
+	item, err := a.OAuthScopeModel.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+
+	return item, nil
+}
+
+// A scope token of RFC 6749: printable ASCII except space, quote and backslash
+func isValidOAuthScopeName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, c := range name {
+		if c < 0x21 || c > 0x7e || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *OAuthScope) checkName(ctx context.Context, item schema.OAuthScope) error {
+	if !isValidOAuthScopeName(item.Name) {
+		return errors.New400Response("Invalid scope name")
+	}
+
+	result, err := a.OAuthScopeModel.Query(ctx, schema.OAuthScopeQueryParam{
+		Name: item.Name,
+	}, schema.OAuthScopeQueryOptions{
+		PageParam: &comschema.PaginationParam{PageIndex: -1},
+	})
+	if err != nil {
+		return err
+	} else if result.PageResult.Total > 0 {
+		return errors.New400Response("Scope name already exists")
+	}
+	return nil
+}
+
+// Create - Create data
+func (a *OAuthScope) Create(ctx context.Context, item schema.OAuthScope) (*schema.OAuthScope, error) {
+	err := a.checkName(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	item.UUID = util.MustUUID()
+	item.Creator, _ = icontext.FromUserUUID(ctx)
+	err = a.OAuthScopeModel.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Global().Casbin.Enable {
+		err = a.LoadPolicy(ctx, &item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a.Get(ctx, item.UUID)
+}
+
+// Update - Update data, the name is requested by clients and kept
+func (a *OAuthScope) Update(ctx context.Context, UUID string, item schema.OAuthScope) (*schema.OAuthScope, error) {
+	oldItem, err := a.OAuthScopeModel.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if oldItem == nil {
+		return nil, errors.ErrNotFound
+	} else if oldItem.Name != item.Name {
+		return nil, errors.New400Response("Scope name is not allowed to change")
+	}
+
+	item.UUID = UUID
+	err = a.OAuthScopeModel.Update(ctx, UUID, item)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Global().Casbin.Enable {
+		err = a.LoadPolicy(ctx, &item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a.Get(ctx, UUID)
+}
+
+// Delete - Delete data, the grants of the scope lose its permissions
+func (a *OAuthScope) Delete(ctx context.Context, UUID string) error {
+	oldItem, err := a.OAuthScopeModel.Get(ctx, UUID)
+	if err != nil {
+		return err
+	} else if oldItem == nil {
+		return errors.ErrNotFound
+	}
+
+	err = a.OAuthScopeModel.Delete(ctx, UUID)
+	if err != nil {
+		return err
+	}
+
+	if config.Global().Casbin.Enable {
+		_, _ = a.Enforcer.DeletePermissionsForUser(controllers.OAuthScopeSubject(oldItem.Name))
+	}
+	return nil
+}
+
+// LoadPolicy - Load the casbin policy of a scope
+func (a *OAuthScope) LoadPolicy(ctx context.Context, item *schema.OAuthScope) error {
+	resources, err := a.RoleBll.GetPermissionResources(ctx, &schema.Role{Permissions: item.Permissions})
+	if err != nil {
+		return err
+	}
+
+	subject := controllers.OAuthScopeSubject(item.Name)
+	_, _ = a.Enforcer.DeletePermissionsForUser(subject)
+	for _, ritem := range resources {
+		_, _ = a.Enforcer.AddPermissionForUser(subject, ritem.Path, ritem.Method)
+	}
+	return nil
+}
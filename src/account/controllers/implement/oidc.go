@@ -0,0 +1,405 @@
+package implement
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/auth/oidc"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// NewOIDC - Create an OpenID Connect login instance
+func NewOIDC(
+	mUser model.IUser,
+	mRole model.IRole,
+	mUserIdentity model.IUserIdentity,
+	bUser controllers.IUser,
+	bSession controllers.ISession,
+) *OIDC {
+	return &OIDC{
+		UserModel:         mUser,
+		RoleModel:         mRole,
+		UserIdentityModel: mUserIdentity,
+		UserBll:           bUser,
+		SessionBll:        bSession,
+		providers:         make(map[string]*oidcProvider),
+	}
+}
+
+// OIDC - Login with external OpenID Connect identity providers
+type OIDC struct {
+	UserModel         model.IUser
+	RoleModel         model.IRole
+	UserIdentityModel model.IUserIdentity
+	UserBll           controllers.IUser
+	SessionBll        controllers.ISession
+
+	lock      sync.Mutex
+	providers map[string]*oidcProvider
+}
+
+// Discovered identity provider of a configuration
+type oidcProvider struct {
+	key      string
+	provider *oidc.Provider
+}
+
+// State of a login kept by the browser between the start and the callback
+type oidcState struct {
+	Provider  string `json:"p"`
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"`
+	ExpiresAt int64  `json:"e"`
+}
+
+func signOIDCState(payload string) string {
+	return util.HMACSHA256HashString(config.Global().JWTAuth.SecretKey, "oidc:"+payload)
+}
+
+func encodeOIDCState(item oidcState) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(util.JSONMarshalToString(item)))
+	return payload + "." + signOIDCState(payload)
+}
+
+func decodeOIDCState(signed string) (*oidcState, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 || subtle.ConstantTimeCompare([]byte(signOIDCState(signed[:i])), []byte(signed[i+1:])) != 1 {
+		return nil, false
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(signed[:i])
+	if err != nil {
+		return nil, false
+	}
+
+	var item oidcState
+	if err := json.Unmarshal(b, &item); err != nil {
+		return nil, false
+	}
+	return &item, true
+}
+
+// Get the identity provider of a configuration, it is discovered again
+// when the configuration changes
+func (a *OIDC) provider(ctx context.Context, cfg config.OIDCProvider) (*oidc.Provider, error) {
+	key := strings.Join([]string{cfg.Issuer, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, strings.Join(cfg.Scopes, " ")}, "\n")
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if item, ok := a.providers[cfg.Name]; ok && item.key == key {
+		return item.provider, nil
+	}
+
+	p, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	a.providers[cfg.Name] = &oidcProvider{key: key, provider: p}
+	return p, nil
+}
+
+// Providers - Query the configured identity providers
+func (a *OIDC) Providers(ctx context.Context) []*schema.OIDCProvider {
+	providers := config.Global().OIDC.Providers
+	list := make([]*schema.OIDCProvider, len(providers))
+	for i, item := range providers {
+		list[i] = &schema.OIDCProvider{
+			Name:  item.Name,
+			Title: item.Title,
+		}
+	}
+	return list
+}
+
+// AuthCodeURL - Start a login at an identity provider
+func (a *OIDC) AuthCodeURL(ctx context.Context, provider string) (string, string, error) {
+	cfg, ok := config.Global().OIDC.GetProvider(provider)
+	if !ok {
+		return "", "", errors.ErrNotFound
+	}
+
+	p, err := a.provider(ctx, cfg)
+	if err != nil {
+		return "", "", err
+	}
+
+	expired := config.Global().OIDC.StateExpired
+	if expired <= 0 {
+		expired = 600
+	}
+	item := oidcState{
+		Provider:  provider,
+		State:     oidc.NewRandom(),
+		Nonce:     oidc.NewRandom(),
+		Verifier:  oidc.NewVerifier(),
+		ExpiresAt: time.Now().Add(time.Duration(expired) * time.Second).Unix(),
+	}
+	return p.AuthCodeURL(item.State, item.Nonce, item.Verifier), encodeOIDCState(item), nil
+}
+
+// Login - Finish a login with the authorization code, failures are recorded
+// in the login history
+func (a *OIDC) Login(ctx context.Context, provider, code, state, signedState string) (*schema.User, error) {
+	user, claims, err := a.login(ctx, provider, code, state, signedState)
+	if err != nil {
+		item := schema.LoginHistory{
+			Reason: errors.ErrInternalServer.Error(),
+		}
+		if resErr := errors.UnWrapResponse(err); resErr != nil {
+			item.Reason = resErr.Message
+		}
+		if user != nil {
+			item.UserUUID, item.UserName = user.UUID, user.UserName
+		} else if claims != nil {
+			item.UserName = provider + ":" + claims.Subject()
+		}
+		a.SessionBll.RecordLogin(ctx, item)
+		return nil, err
+	}
+	return user, nil
+}
+
+func (a *OIDC) login(ctx context.Context, provider, code, state, signedState string) (*schema.User, oidc.Claims, error) {
+	cfg, ok := config.Global().OIDC.GetProvider(provider)
+	if !ok {
+		return nil, nil, errors.ErrNotFound
+	}
+
+	item, ok := decodeOIDCState(signedState)
+	if !ok || item.Provider != provider || item.State == "" || item.State != state ||
+		time.Now().Unix() > item.ExpiresAt {
+		return nil, nil, errors.New400Response("Invalid or expired login state")
+	} else if code == "" {
+		return nil, nil, errors.New400Response("Login refused by the identity provider")
+	}
+
+	p, err := a.provider(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawIDToken, err := p.Exchange(ctx, code, item.Verifier)
+	if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("OIDC login"), logger.SetSpanFuncName("Login")).
+			Warnf("Exchange the code of %s error: %s", provider, err.Error())
+		return nil, nil, errors.New400Response("Login refused by the identity provider")
+	}
+
+	claims, err := p.Verify(ctx, rawIDToken, item.Nonce)
+	if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("OIDC login"), logger.SetSpanFuncName("Login")).
+			Warnf("Verify the id token of %s error: %s", provider, err.Error())
+		return nil, nil, errors.New400Response("Invalid id token")
+	}
+
+	user, err := a.resolveUser(ctx, cfg, claims)
+	if err != nil {
+		return user, claims, err
+	} else if user.Status != 1 {
+		return user, claims, errors.New400Response("User is disabled")
+	}
+
+	user, err = a.syncRoles(ctx, cfg, claims, user)
+	if err != nil {
+		return user, claims, err
+	}
+	return user, claims, nil
+}
+
+// Find the user of an identity, existing users are linked by their verified
+// email address and unknown users are created
+func (a *OIDC) resolveUser(ctx context.Context, cfg config.OIDCProvider, claims oidc.Claims) (*schema.User, error) {
+	identities, err := a.UserIdentityModel.Query(ctx, schema.UserIdentityQueryParam{
+		Provider: cfg.Name,
+		Subject:  claims.Subject(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, identity := range identities {
+		user, err := a.UserModel.Get(ctx, identity.UserUUID, schema.UserQueryOptions{IncludeRoles: true})
+		if err != nil {
+			return nil, err
+		} else if user != nil {
+			return user, nil
+		}
+
+		// The user has been deleted
+		err = a.UserIdentityModel.Delete(ctx, identity.UUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var user *schema.User
+	email := claims.String("email")
+	if email != "" {
+		result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+			Email: email,
+		}, schema.UserQueryOptions{IncludeRoles: true})
+		if err != nil {
+			return nil, err
+		} else if len(result.Data) > 0 {
+			if !cfg.LinkByEmail || !claims.Bool("email_verified") {
+				return nil, errors.New400Response("Email is already used by another user")
+			}
+			user = result.Data[0]
+		}
+	}
+
+	if user == nil {
+		if !cfg.AutoCreate {
+			return nil, errors.New400Response("User does not exist")
+		}
+
+		user, err = a.createUser(ctx, cfg, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = a.UserIdentityModel.Create(ctx, schema.UserIdentity{
+		UUID:     util.MustUUID(),
+		UserUUID: user.UUID,
+		Provider: cfg.Name,
+		Subject:  claims.Subject(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("OIDC login"), logger.SetSpanFuncName("Login")).
+		Infof("Link %s of %s to user %s", claims.Subject(), cfg.Name, user.UserName)
+	return user, nil
+}
+
+// Create the user of an identity with the mapped or default roles, the user
+// gets a random password it never learns
+func (a *OIDC) createUser(ctx context.Context, cfg config.OIDCProvider, claims oidc.Claims) (*schema.User, error) {
+	roleIDs, _, err := a.mapRoles(ctx, cfg, claims)
+	if err != nil {
+		return nil, err
+	} else if len(roleIDs) == 0 {
+		roleIDs, err = queryRoleIDs(ctx, a.RoleModel, cfg.DefaultRoles)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(roleIDs) == 0 {
+		return nil, errors.New400Response("No role is granted to the user")
+	}
+
+	userName, err := a.newUserName(ctx, cfg, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	item := schema.User{
+		UserName: userName,
+		RealName: claims.String("name"),
+		Password: util.MustUUID() + util.MustUUID(),
+		Email:    claims.String("email"),
+		Status:   1,
+		Creator:  "oidc:" + cfg.Name,
+		// Identity providers verify the addresses they claim so
+		EmailVerified: claims.Bool("email_verified"),
+	}
+	if item.RealName == "" {
+		item.RealName = userName
+	}
+	for _, roleID := range roleIDs {
+		item.Roles = append(item.Roles, &schema.UserRole{RoleID: roleID})
+	}
+	return a.UserBll.Create(ctx, item)
+}
+
+// Username of a new user from its claims, suffixed when it is already taken
+func (a *OIDC) newUserName(ctx context.Context, cfg config.OIDCProvider, claims oidc.Claims) (string, error) {
+	var userName string
+	if cfg.UserNameClaim != "" {
+		userName = claims.String(cfg.UserNameClaim)
+	} else if userName = claims.String("preferred_username"); userName == "" {
+		userName = claims.String("email")
+	}
+	if userName == "" {
+		userName = cfg.Name + "_" + claims.Subject()
+	}
+	if len(userName) > 64 {
+		userName = userName[:64]
+	}
+
+	for i := 0; i < 3; i++ {
+		result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+			UserName: userName,
+		})
+		if err != nil {
+			return "", err
+		} else if len(result.Data) == 0 && userName != common.GetRootUser().UserName {
+			return userName, nil
+		}
+
+		suffix := "_" + util.MustUUID()[:8]
+		if len(userName)+len(suffix) > 64 {
+			userName = userName[:64-len(suffix)]
+		}
+		userName += suffix
+	}
+	return "", errors.New400Response("Uername already exists")
+}
+
+// Roles granted by the role claim and every role managed by the mapping
+func (a *OIDC) mapRoles(ctx context.Context, cfg config.OIDCProvider, claims oidc.Claims) (granted, managed []string, err error) {
+	if cfg.RoleClaim == "" || len(cfg.RoleMapping) == 0 {
+		return nil, nil, nil
+	}
+
+	var grantedNames, managedNames []string
+	for _, name := range cfg.RoleMapping {
+		managedNames = append(managedNames, name)
+	}
+	for _, v := range claims.Strings(cfg.RoleClaim) {
+		if name, ok := cfg.RoleMapping[v]; ok {
+			grantedNames = append(grantedNames, name)
+		}
+	}
+
+	granted, err = queryRoleIDs(ctx, a.RoleModel, grantedNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	managed, err = queryRoleIDs(ctx, a.RoleModel, managedNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	return granted, managed, nil
+}
+
+// Update the mapped roles of a user to the claims of its login, the roles
+// outside of the mapping are kept
+func (a *OIDC) syncRoles(ctx context.Context, cfg config.OIDCProvider, claims oidc.Claims, user *schema.User) (*schema.User, error) {
+	granted, managed, err := a.mapRoles(ctx, cfg, claims)
+	if err != nil {
+		return user, err
+	}
+	return syncManagedRoles(ctx, a.UserBll, user, granted, managed)
+}
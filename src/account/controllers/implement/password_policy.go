@@ -0,0 +1,141 @@
+package implement
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/auth/password"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+)
+
+// NewPasswordPolicy - Create a password policy instance
+func NewPasswordPolicy(
+	policy *password.Policy,
+	p *password.Password,
+	mPasswordHistory model.IPasswordHistory,
+) *PasswordPolicy {
+	return &PasswordPolicy{
+		Policy:               policy,
+		Password:             p,
+		PasswordHistoryModel: mPasswordHistory,
+	}
+}
+
+// PasswordPolicy - Password policy and password history
+type PasswordPolicy struct {
+	Policy               *password.Policy
+	Password             *password.Password
+	PasswordHistoryModel model.IPasswordHistory
+}
+
+func (a *PasswordPolicy) violations(plain string, userInputs ...string) []schema.PasswordViolation {
+	violations := make([]schema.PasswordViolation, 0)
+	for _, v := range a.Policy.Check(plain, userInputs...) {
+		violations = append(violations, schema.PasswordViolation{
+			Code:    v.Code,
+			Message: v.Message,
+		})
+	}
+	return violations
+}
+
+// Check - Check a new password of a user, the user is nil or has no UUID
+// while it is created
+func (a *PasswordPolicy) Check(ctx context.Context, user *schema.User, plain string) error {
+	var userInputs []string
+	if user != nil {
+		userInputs = []string{user.UserName, user.RealName, user.Email}
+	}
+	violations := a.violations(plain, userInputs...)
+
+	if user != nil && user.UUID != "" {
+		reused, err := a.reused(ctx, user, plain)
+		if err != nil {
+			return err
+		} else if reused {
+			violations = append(violations, schema.PasswordViolation{
+				Code:    password.ViolationReused,
+				Message: "Password is one of the last passwords of the user",
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.New400DetailResponse("Password does not meet the password policy", violations)
+	}
+	return nil
+}
+
+// The current password counts as used even when the history is empty,
+// e.g. for users created before the history was kept
+func (a *PasswordPolicy) reused(ctx context.Context, user *schema.User, plain string) (bool, error) {
+	n := config.Global().Password.History
+	if n <= 0 {
+		return false, nil
+	}
+
+	hashes, err := a.PasswordHistoryModel.Query(ctx, user.UUID, n)
+	if err != nil {
+		return false, err
+	}
+	if user.Password != "" {
+		hashes = append([]string{user.Password}, hashes...)
+	}
+
+	seen := make(map[string]bool)
+	for _, encoded := range hashes {
+		if seen[encoded] {
+			continue
+		}
+		seen[encoded] = true
+
+		ok, _, err := a.Password.Verify(encoded, plain)
+		if err == nil && ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Evaluate - Score a password and list the rules it breaks
+func (a *PasswordPolicy) Evaluate(ctx context.Context, params schema.PasswordCheckParam) *schema.PasswordCheckResult {
+	userInputs := []string{params.UserName, params.RealName, params.Email}
+	return &schema.PasswordCheckResult{
+		Score:      a.Policy.Strength(params.Password, userInputs...),
+		Violations: a.violations(params.Password, userInputs...),
+	}
+}
+
+// Record - Record a new password hash of a user, keeping the configured
+// number of passwords
+func (a *PasswordPolicy) Record(ctx context.Context, userUUID, encoded string) error {
+	n := config.Global().Password.History
+	if n <= 0 {
+		return nil
+	}
+
+	err := a.PasswordHistoryModel.Create(ctx, userUUID, encoded)
+	if err != nil {
+		return err
+	}
+	return a.PasswordHistoryModel.Prune(ctx, userUUID, n)
+}
+
+// Expired - Check whether the password of a user is older than the maximum
+// age, the root user is exempt
+func (a *PasswordPolicy) Expired(ctx context.Context, user *schema.User) bool {
+	maxAge := config.Global().Password.MaxAge
+	if maxAge <= 0 || common.CheckIsRootUser(ctx, user.UUID) {
+		return false
+	}
+
+	changed := user.PasswordChangedAt
+	if changed.IsZero() {
+		changed = user.CreatedAt
+	}
+	return time.Since(changed) > time.Duration(maxAge)*24*time.Hour
+}
@@ -0,0 +1,140 @@
+package implement
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/auth/password"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/mailer"
+)
+
+// NewPasswordReset - Create a password reset instance
+func NewPasswordReset(
+	p *password.Password,
+	m mailer.Mailer,
+	t *mailer.Templates,
+	mUser model.IUser,
+	mUserToken model.IUserToken,
+	bSession controllers.ISession,
+	bPasswordPolicy controllers.IPasswordPolicy,
+) *PasswordReset {
+	return &PasswordReset{
+		Password:          p,
+		Mailer:            m,
+		Templates:         t,
+		UserModel:         mUser,
+		UserTokenModel:    mUserToken,
+		SessionBll:        bSession,
+		PasswordPolicyBll: bPasswordPolicy,
+	}
+}
+
+// PasswordReset - Password reset by mailed links
+type PasswordReset struct {
+	Password          *password.Password
+	Mailer            mailer.Mailer
+	Templates         *mailer.Templates
+	UserModel         model.IUser
+	UserTokenModel    model.IUserToken
+	SessionBll        controllers.ISession
+	PasswordPolicyBll controllers.IPasswordPolicy
+}
+
+// Forgot - Mail a reset link to the user of an email address, nothing tells
+// whether the address belongs to a user
+func (a *PasswordReset) Forgot(ctx context.Context, email string) error {
+	result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+		Email: email,
+	})
+	if err != nil {
+		return err
+	} else if len(result.Data) == 0 || result.Data[0].Status != 1 {
+		return nil
+	}
+	user := result.Data[0]
+
+	expired := config.Global().UserEmail.ResetExpired
+	if expired <= 0 {
+		expired = 3600
+	}
+
+	token, expiresAt, err := createUserToken(ctx, a.UserTokenModel, schema.UserTokenPasswordReset, user, expired)
+	if err != nil {
+		return err
+	}
+
+	err = sendMail(ctx, a.Mailer, a.Templates, schema.UserTokenPasswordReset, user.Email, userTokenMail{
+		User:      user.CleanSecure(),
+		Email:     user.Email,
+		URL:       controllers.UserTokenURL(config.Global().UserEmail.ResetURL, token),
+		ExpiresAt: expiresAt.Format(time.RFC1123),
+	})
+	if err != nil {
+		// A failure would tell that the address belongs to a user
+		logger.StartSpan(ctx, logger.SetSpanTitle("Password reset"), logger.SetSpanFuncName("Forgot")).
+			Errorf("Mail password reset link to %s error: %s", user.UUID, err.Error())
+	}
+	return nil
+}
+
+// Reset - Set a new password with the token of a reset link, the user is
+// logged out everywhere
+func (a *PasswordReset) Reset(ctx context.Context, params schema.PasswordResetParam) error {
+	item, err := takeUserToken(ctx, a.UserTokenModel, schema.UserTokenPasswordReset, params.Token)
+	if err != nil {
+		return err
+	} else if item == nil {
+		return errors.ErrInvalidResetToken
+	}
+
+	user, err := a.UserModel.Get(ctx, item.UserUUID)
+	if err != nil {
+		return err
+	} else if user == nil || user.Status != 1 {
+		return errors.ErrInvalidResetToken
+	}
+
+	// A rejected password leaves the link usable for another try
+	err = a.PasswordPolicyBll.Check(ctx, user, params.Password)
+	if err != nil {
+		if cerr := a.UserTokenModel.Create(ctx, *item); cerr != nil {
+			return cerr
+		}
+		return err
+	}
+
+	encoded, err := a.Password.Hash(params.Password)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = a.UserModel.UpdatePassword(ctx, user.UUID, encoded)
+	if err != nil {
+		return err
+	}
+
+	err = a.PasswordPolicyBll.Record(ctx, user.UUID, encoded)
+	if err != nil {
+		return err
+	}
+
+	// Other links mailed before are of no use anymore
+	err = a.UserTokenModel.DeleteByUser(ctx, schema.UserTokenPasswordReset, user.UUID)
+	if err != nil {
+		return err
+	}
+
+	// The link proves the user receives the mails of the address
+	_, err = a.UserModel.VerifyEmail(ctx, user.UUID, item.Email)
+	if err != nil {
+		return err
+	}
+
+	return a.SessionBll.RevokeAll(ctx, user.UUID)
+}
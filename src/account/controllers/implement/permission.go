@@ -5,6 +5,8 @@ import (
 
 	"github.com/MayCMF/core/src/account/model"
 	"github.com/MayCMF/core/src/account/schema"
+	acontrollers "github.com/MayCMF/core/src/audit/controllers"
+	aschema "github.com/MayCMF/core/src/audit/schema"
 	"github.com/MayCMF/core/src/common"
 	"github.com/MayCMF/core/src/common/errors"
 	comschema "github.com/MayCMF/core/src/common/schema"
@@ -16,10 +18,12 @@ import (
 func NewPermission(
 	trans transaction.ITrans,
 	mPermission model.IPermission,
+	bAudit acontrollers.IAudit,
 ) *Permission {
 	return &Permission{
 		TransModel:      trans,
 		PermissionModel: mPermission,
+		AuditBll:        bAudit,
 	}
 }
 
@@ -27,6 +31,7 @@ func NewPermission(
 type Permission struct {
 	TransModel      transaction.ITrans
 	PermissionModel model.IPermission
+	AuditBll        acontrollers.IAudit
 }
 
 // Query - Get Data
@@ -73,10 +78,15 @@ func (a *Permission) getParentPath(ctx context.Context, parentID string) (string
 }
 
 func (a *Permission) getUpdate(ctx context.Context, UUID string) (*schema.Permission, error) {
-	return a.Get(ctx, UUID, schema.PermissionQueryOptions{
+	return a.Get(ctx, UUID, a.getAuditOptions())
+}
+
+// Options of the items compared by the audit log
+func (a *Permission) getAuditOptions() schema.PermissionQueryOptions {
+	return schema.PermissionQueryOptions{
 		IncludeActions:   true,
 		IncludeResources: true,
-	})
+	}
 }
 
 func (a *Permission) checkName(ctx context.Context, item schema.Permission) error {
@@ -107,12 +117,23 @@ func (a *Permission) Create(ctx context.Context, item schema.Permission) (*schem
 
 	item.ParentPath = parentPath
 	item.UUID = util.MustUUID()
-	err = a.PermissionModel.Create(ctx, item)
+	var nitem *schema.Permission
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.PermissionModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, item.UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityPermission, aschema.AuditActionCreate, nitem.UUID, nil, nitem)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return a.getUpdate(ctx, item.UUID)
+	return nitem, nil
 }
 
 // Update - update Permission
@@ -121,7 +142,7 @@ func (a *Permission) Update(ctx context.Context, UUID string, item schema.Permis
 		return nil, errors.ErrInvalidParent
 	}
 
-	oldItem, err := a.PermissionModel.Get(ctx, UUID)
+	oldItem, err := a.PermissionModel.Get(ctx, UUID, a.getAuditOptions())
 	if err != nil {
 		return nil, err
 	} else if oldItem == nil {
@@ -133,6 +154,7 @@ func (a *Permission) Update(ctx context.Context, UUID string, item schema.Permis
 	}
 	item.ParentPath = oldItem.ParentPath
 
+	var nitem *schema.Permission
 	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
 		// If the parent is updated, you need to update the current node and the parent path below the node.
 		if item.ParentID != oldItem.ParentID {
@@ -160,17 +182,26 @@ func (a *Permission) Update(ctx context.Context, UUID string, item schema.Permis
 			}
 		}
 
-		return a.PermissionModel.Update(ctx, UUID, item)
+		err := a.PermissionModel.Update(ctx, UUID, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityPermission, aschema.AuditActionUpdate, UUID, oldItem, nitem)
 	})
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, UUID)
+	return nitem, nil
 }
 
 // Delete - Delete permission
 func (a *Permission) Delete(ctx context.Context, UUID string) error {
-	oldItem, err := a.PermissionModel.Get(ctx, UUID)
+	oldItem, err := a.PermissionModel.Get(ctx, UUID, a.getAuditOptions())
 	if err != nil {
 		return err
 	} else if oldItem == nil {
@@ -186,5 +217,11 @@ func (a *Permission) Delete(ctx context.Context, UUID string) error {
 		return errors.ErrNotAllowDeleteWithChild
 	}
 
-	return a.PermissionModel.Delete(ctx, UUID)
+	return common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.PermissionModel.Delete(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityPermission, aschema.AuditActionDelete, UUID, oldItem, nil)
+	})
 }
@@ -0,0 +1,175 @@
+package implement
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+)
+
+// NewRegistration - Create a self-registration instance
+func NewRegistration(
+	mInvitation model.IInvitation,
+	mRole model.IRole,
+	mUser model.IUser,
+	bUser controllers.IUser,
+) *Registration {
+	return &Registration{
+		InvitationModel: mInvitation,
+		RoleModel:       mRole,
+		UserModel:       mUser,
+		UserBll:         bUser,
+	}
+}
+
+// Registration - Self-registration
+type Registration struct {
+	InvitationModel model.IInvitation
+	RoleModel       model.IRole
+	UserModel       model.IUser
+	UserBll         controllers.IUser
+}
+
+// Configured mode, unknown modes disable the registration
+func registerMode() string {
+	switch mode := config.Global().Register.Mode; mode {
+	case schema.RegisterOpen, schema.RegisterInvite:
+		return mode
+	}
+	return schema.RegisterDisabled
+}
+
+// Get a valid invitation by the token of its link
+func (a *Registration) getInvitation(ctx context.Context, token string) (*schema.Invitation, error) {
+	result, err := a.InvitationModel.Query(ctx, schema.InvitationQueryParam{
+		TokenHash: controllers.HashUserToken(token),
+	})
+	if err != nil {
+		return nil, err
+	} else if len(result.Data) == 0 || !result.Data[0].Valid(time.Now()) {
+		return nil, errors.ErrInvalidInvitation
+	}
+	return result.Data[0], nil
+}
+
+// Role IDs of the roles that still exist
+func (a *Registration) queryRoleIDs(ctx context.Context, params schema.RoleQueryParam) ([]string, error) {
+	result, err := a.RoleModel.Query(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]string, len(result.Data))
+	for i, item := range result.Data {
+		roleIDs[i] = item.UUID
+	}
+	return roleIDs, nil
+}
+
+// Info - Get the settings of the sign-up form, of an invitation if its token is given
+func (a *Registration) Info(ctx context.Context, invitation string) (*schema.RegisterInfo, error) {
+	info := &schema.RegisterInfo{
+		Mode:    registerMode(),
+		Captcha: config.Global().Register.Captcha,
+	}
+
+	if invitation != "" && info.Mode != schema.RegisterDisabled {
+		item, err := a.getInvitation(ctx, invitation)
+		if err != nil {
+			return nil, err
+		}
+		info.Email = item.Email
+	}
+	return info, nil
+}
+
+// Register - Sign up a user with the roles of its invitation or the default
+// roles, the email address is verified by the link mailed to it
+func (a *Registration) Register(ctx context.Context, params schema.RegisterParam) (*schema.User, error) {
+	mode := registerMode()
+	if mode == schema.RegisterDisabled {
+		return nil, errors.New400Response("Registration is disabled")
+	}
+
+	item := schema.User{
+		UserName: params.UserName,
+		RealName: params.RealName,
+		Password: params.Password,
+		Phone:    params.Phone,
+		Email:    params.Email,
+		Status:   1,
+		Creator:  schema.UserCreatorRegister,
+	}
+
+	var invitation *schema.Invitation
+	var roleIDs []string
+	var err error
+	if params.Invitation != "" {
+		invitation, err = a.getInvitation(ctx, params.Invitation)
+		if err != nil {
+			return nil, err
+		}
+
+		// The invitation was mailed to its address
+		if invitation.Email != "" {
+			if !strings.EqualFold(invitation.Email, params.Email) {
+				return nil, errors.New400Response("Email address does not match the invitation")
+			}
+			item.Email = invitation.Email
+			item.EmailVerified = true
+		}
+
+		item.Creator = schema.UserCreatorInvitationPrefix + invitation.UUID
+		roleIDs, err = a.queryRoleIDs(ctx, schema.RoleQueryParam{UUIDs: invitation.Roles})
+	} else if mode == schema.RegisterInvite {
+		return nil, errors.New400Response("An invitation is required to sign up")
+	} else {
+		for _, name := range config.Global().Register.DefaultRoles {
+			ids, err := a.queryRoleIDs(ctx, schema.RoleQueryParam{Name: name})
+			if err != nil {
+				return nil, err
+			}
+			roleIDs = append(roleIDs, ids...)
+		}
+	}
+	if err != nil {
+		return nil, err
+	} else if len(roleIDs) == 0 {
+		return nil, errors.New400Response("No role is granted to new users")
+	}
+	for _, roleID := range roleIDs {
+		item.Roles = append(item.Roles, &schema.UserRole{RoleID: roleID})
+	}
+
+	result, err := a.UserModel.Query(ctx, schema.UserQueryParam{
+		Email: item.Email,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(result.Data) > 0 {
+		return nil, errors.New400Response("Email address is already registered")
+	}
+
+	if invitation != nil {
+		ok, err := a.InvitationModel.IncUses(ctx, invitation.UUID)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, errors.ErrInvalidInvitation
+		}
+	}
+
+	user, err := a.UserBll.Create(ctx, item)
+	if err != nil {
+		if invitation != nil {
+			_ = a.InvitationModel.DecUses(ctx, invitation.UUID)
+		}
+		return nil, err
+	}
+	return user.CleanSecure(), nil
+}
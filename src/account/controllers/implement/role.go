@@ -6,24 +6,32 @@ import (
 	"github.com/casbin/casbin/v2"
 	"github.com/MayCMF/core/src/account/model"
 	"github.com/MayCMF/core/src/account/schema"
+	acontrollers "github.com/MayCMF/core/src/audit/controllers"
+	aschema "github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common"
 	"github.com/MayCMF/core/src/common/config"
 	"github.com/MayCMF/core/src/common/errors"
 	comschema "github.com/MayCMF/core/src/common/schema"
 	"github.com/MayCMF/core/src/common/util"
+	transaction "github.com/MayCMF/core/src/transaction/model"
 )
 
 // NewRole - Create a role management instance
 func NewRole(
 	e *casbin.SyncedEnforcer,
+	trans transaction.ITrans,
 	mRole model.IRole,
 	mPermission model.IPermission,
 	mUser model.IUser,
+	bAudit acontrollers.IAudit,
 ) *Role {
 	return &Role{
 		Enforcer:        e,
+		TransModel:      trans,
 		RoleModel:       mRole,
 		PermissionModel: mPermission,
 		UserModel:       mUser,
+		AuditBll:        bAudit,
 		DeleteHook: func(ctx context.Context, bRole *Role, UUID string) error {
 			if config.Global().Casbin.Enable {
 				_, _ = bRole.Enforcer.DeletePermissionsForUser(UUID)
@@ -45,9 +53,11 @@ func NewRole(
 // Role Manage Role
 type Role struct {
 	Enforcer        *casbin.SyncedEnforcer
+	TransModel      transaction.ITrans
 	RoleModel       model.IRole
 	PermissionModel model.IPermission
 	UserModel       model.IUser
+	AuditBll        acontrollers.IAudit
 	DeleteHook      func(context.Context, *Role, string) error
 	SaveHook        func(context.Context, *Role, *schema.Role) error
 }
@@ -84,20 +94,17 @@ func (a *Role) checkName(ctx context.Context, name string) error {
 }
 
 func (a *Role) getUpdate(ctx context.Context, UUID string) (*schema.Role, error) {
-	nitem, err := a.Get(ctx, UUID, schema.RoleQueryOptions{
+	return a.Get(ctx, UUID, schema.RoleQueryOptions{
 		IncludePermissions: true,
 	})
-	if err != nil {
-		return nil, err
-	}
+}
 
+// Apply a saved role to the policy, only once the change is committed
+func (a *Role) saved(ctx context.Context, item *schema.Role) error {
 	if hook := a.SaveHook; hook != nil {
-		if err := hook(ctx, a, nitem); err != nil {
-			return nil, err
-		}
+		return hook(ctx, a, item)
 	}
-
-	return nitem, nil
+	return nil
 }
 
 // Create - Create Role
@@ -108,17 +115,35 @@ func (a *Role) Create(ctx context.Context, item schema.Role) (*schema.Role, erro
 	}
 
 	item.UUID = util.MustUUID()
-	err = a.RoleModel.Create(ctx, item)
+	var nitem *schema.Role
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.RoleModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, item.UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityRole, aschema.AuditActionCreate, nitem.UUID, nil, nitem)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return a.getUpdate(ctx, item.UUID)
+	err = a.saved(ctx, nitem)
+	if err != nil {
+		return nil, err
+	}
+	return nitem, nil
 }
 
 // Update - update role
 func (a *Role) Update(ctx context.Context, UUID string, item schema.Role) (*schema.Role, error) {
-	oldItem, err := a.RoleModel.Get(ctx, UUID)
+	oldItem, err := a.RoleModel.Get(ctx, UUID, schema.RoleQueryOptions{
+		IncludePermissions: true,
+	})
 	if err != nil {
 		return nil, err
 	} else if oldItem == nil {
@@ -130,17 +155,35 @@ func (a *Role) Update(ctx context.Context, UUID string, item schema.Role) (*sche
 		}
 	}
 
-	err = a.RoleModel.Update(ctx, UUID, item)
+	var nitem *schema.Role
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.RoleModel.Update(ctx, UUID, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityRole, aschema.AuditActionUpdate, UUID, oldItem, nitem)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return a.getUpdate(ctx, UUID)
+	err = a.saved(ctx, nitem)
+	if err != nil {
+		return nil, err
+	}
+	return nitem, nil
 }
 
 // Delete - delete role
 func (a *Role) Delete(ctx context.Context, UUID string) error {
-	oldItem, err := a.RoleModel.Get(ctx, UUID)
+	oldItem, err := a.RoleModel.Get(ctx, UUID, schema.RoleQueryOptions{
+		IncludePermissions: true,
+	})
 	if err != nil {
 		return err
 	} else if oldItem == nil {
@@ -159,15 +202,19 @@ func (a *Role) Delete(ctx context.Context, UUID string) error {
 		return errors.New400Response("This role has been assigned to the user and is not allowed to delete")
 	}
 
-	err = a.RoleModel.Delete(ctx, UUID)
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.RoleModel.Delete(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityRole, aschema.AuditActionDelete, UUID, oldItem, nil)
+	})
 	if err != nil {
 		return err
 	}
 
 	if hook := a.DeleteHook; hook != nil {
-		if err := hook(ctx, a, UUID); err != nil {
-			return err
-		}
+		return hook(ctx, a, UUID)
 	}
 	return nil
 }
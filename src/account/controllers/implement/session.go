@@ -0,0 +1,170 @@
+package implement
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/auth"
+	"github.com/MayCMF/core/src/common/config"
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+)
+
+// Size of the user agent columns
+const maxUserAgent = 512
+
+// NewSession - Create a session management instance
+func NewSession(
+	a auth.Auther,
+	mUserSession model.IUserSession,
+	mLoginHistory model.ILoginHistory,
+) *Session {
+	return &Session{
+		Auth:              a,
+		UserSessionModel:  mUserSession,
+		LoginHistoryModel: mLoginHistory,
+	}
+}
+
+// Session - Session and login history management
+type Session struct {
+	Auth              auth.Auther
+	UserSessionModel  model.IUserSession
+	LoginHistoryModel model.ILoginHistory
+}
+
+// A session lasts as long as its last refresh token
+func sessionExpiresAt(t time.Time) time.Time {
+	expired := config.Global().JWTAuth.RefreshExpired
+	if expired <= 0 {
+		expired = 30 * 24 * 3600
+	}
+	return t.Add(time.Duration(expired) * time.Second)
+}
+
+// Client of the request in the context
+func clientInfo(ctx context.Context) (ip, userAgent string) {
+	ip, _ = icontext.FromClientIP(ctx)
+	userAgent, _ = icontext.FromUserAgent(ctx)
+	if len(userAgent) > maxUserAgent {
+		userAgent = userAgent[:maxUserAgent]
+	}
+	return
+}
+
+// Create - Record the session of newly issued tokens, tokens issued without
+// a session (no token storage) are not tracked
+func (a *Session) Create(ctx context.Context, userUUID, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	now := time.Now()
+	ip, userAgent := clientInfo(ctx)
+	return a.UserSessionModel.Create(ctx, schema.UserSession{
+		UUID:       sessionID,
+		UserUUID:   userUUID,
+		IP:         ip,
+		UserAgent:  userAgent,
+		LastSeenAt: now,
+		ExpiresAt:  sessionExpiresAt(now),
+	})
+}
+
+// Touch - Update the last seen time and client of a session after a token refresh
+func (a *Session) Touch(ctx context.Context, sessionID string) error {
+	item, err := a.UserSessionModel.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	} else if item == nil {
+		return nil
+	}
+
+	now := time.Now()
+	item.IP, item.UserAgent = clientInfo(ctx)
+	item.LastSeenAt = now
+	item.ExpiresAt = sessionExpiresAt(now)
+	return a.UserSessionModel.Update(ctx, sessionID, *item)
+}
+
+// Remove - Forget a session whose tokens are destroyed
+func (a *Session) Remove(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return a.UserSessionModel.Delete(ctx, sessionID)
+}
+
+// Query - Query the active sessions of a user, the session of the access
+// token (if any) is marked as the current one
+func (a *Session) Query(ctx context.Context, userUUID, accessToken string) (schema.UserSessions, error) {
+	var currentID string
+	if accessToken != "" {
+		if v, err := a.Auth.ParseSessionID(ctx, accessToken); err == nil {
+			currentID = v
+		}
+	}
+
+	list, err := a.UserSessionModel.Query(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range list {
+		item.Current = currentID != "" && item.UUID == currentID
+	}
+	return list, nil
+}
+
+// Revoke - Revoke a session of a user, its tokens stop working at once
+func (a *Session) Revoke(ctx context.Context, userUUID, sessionID string) error {
+	item, err := a.UserSessionModel.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	} else if item == nil || item.UserUUID != userUUID {
+		return errors.ErrNotFound
+	}
+	return a.revoke(ctx, sessionID)
+}
+
+// RevokeAll - Revoke every session of a user (logout everywhere)
+func (a *Session) RevokeAll(ctx context.Context, userUUID string) error {
+	list, err := a.UserSessionModel.Query(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range list {
+		if err := a.revoke(ctx, item.UUID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Session) revoke(ctx context.Context, sessionID string) error {
+	err := a.Auth.RevokeSession(ctx, sessionID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return a.UserSessionModel.Delete(ctx, sessionID)
+}
+
+// RecordLogin - Record a login attempt with the client of the request, a
+// failure to record it does not fail the login
+func (a *Session) RecordLogin(ctx context.Context, item schema.LoginHistory) {
+	item.IP, item.UserAgent = clientInfo(ctx)
+	err := a.LoginHistoryModel.Create(ctx, item)
+	if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("User login"), logger.SetSpanFuncName("RecordLogin")).
+			Warnf("Record login of %s error: %s", item.UserName, err.Error())
+	}
+}
+
+// QueryLoginHistory - Query the login history
+func (a *Session) QueryLoginHistory(ctx context.Context, params schema.LoginHistoryQueryParam, opts ...schema.LoginHistoryQueryOptions) (*schema.LoginHistoryQueryResult, error) {
+	return a.LoginHistoryModel.Query(ctx, params, opts...)
+}
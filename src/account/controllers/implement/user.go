@@ -2,32 +2,55 @@ package implement
 
 import (
 	"context"
+	"time"
 
 	"github.com/casbin/casbin/v2"
+	"github.com/MayCMF/core/src/account/controllers"
 	"github.com/MayCMF/core/src/account/model"
 	"github.com/MayCMF/core/src/account/schema"
+	acontrollers "github.com/MayCMF/core/src/audit/controllers"
+	aschema "github.com/MayCMF/core/src/audit/schema"
 	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/auth/password"
 	"github.com/MayCMF/core/src/common/config"
 	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
 	comschema "github.com/MayCMF/core/src/common/schema"
 	"github.com/MayCMF/core/src/common/util"
+	fcontrollers "github.com/MayCMF/core/src/filemanager/controllers"
+	fschema "github.com/MayCMF/core/src/filemanager/schema"
+	transaction "github.com/MayCMF/core/src/transaction/model"
 )
 
 // NewUser - Create a new user
 func NewUser(
 	e *casbin.SyncedEnforcer,
+	trans transaction.ITrans,
+	p *password.Password,
 	mUser model.IUser,
 	mRole model.IRole,
+	bFileUsage fcontrollers.IFileUsage,
+	bSession controllers.ISession,
+	bEmailVerification controllers.IEmailVerification,
+	bPasswordPolicy controllers.IPasswordPolicy,
+	bAudit acontrollers.IAudit,
 ) *User {
 	return &User{
-		Enforcer:  e,
-		UserModel: mUser,
-		RoleModel: mRole,
+		Enforcer:             e,
+		TransModel:           trans,
+		Password:             p,
+		UserModel:            mUser,
+		RoleModel:            mRole,
+		FileUsageBll:         bFileUsage,
+		SessionBll:           bSession,
+		EmailVerificationBll: bEmailVerification,
+		PasswordPolicyBll:    bPasswordPolicy,
+		AuditBll:             bAudit,
 		DeleteHook: func(ctx context.Context, bUser *User, UUID string) error {
 			if config.Global().Casbin.Enable {
 				_, _ = bUser.Enforcer.DeleteUser(UUID)
 			}
-			return nil
+			return bUser.SessionBll.RevokeAll(ctx, UUID)
 		},
 		SaveHook: func(ctx context.Context, bUser *User, item *schema.User) error {
 			if config.Global().Casbin.Enable {
@@ -40,6 +63,11 @@ func NewUser(
 					_, _ = bUser.Enforcer.DeleteUser(item.UUID)
 				}
 			}
+
+			// A disabled user is logged out everywhere
+			if item.Status != 1 {
+				return bUser.SessionBll.RevokeAll(ctx, item.UUID)
+			}
 			return nil
 		},
 	}
@@ -47,11 +75,18 @@ func NewUser(
 
 // User - Manage User
 type User struct {
-	Enforcer   *casbin.SyncedEnforcer
-	UserModel  model.IUser
-	RoleModel  model.IRole
-	DeleteHook func(context.Context, *User, string) error
-	SaveHook   func(context.Context, *User, *schema.User) error
+	Enforcer             *casbin.SyncedEnforcer
+	TransModel           transaction.ITrans
+	Password             *password.Password
+	UserModel            model.IUser
+	RoleModel            model.IRole
+	FileUsageBll         fcontrollers.IFileUsage
+	SessionBll           controllers.ISession
+	EmailVerificationBll controllers.IEmailVerification
+	PasswordPolicyBll    controllers.IPasswordPolicy
+	AuditBll             acontrollers.IAudit
+	DeleteHook           func(context.Context, *User, string) error
+	SaveHook             func(context.Context, *User, *schema.User) error
 }
 
 // Query - Query data
@@ -122,15 +157,24 @@ func (a *User) getUpdate(ctx context.Context, UUID string) (*schema.User, error)
 		return nil, err
 	}
 
-	if hook := a.SaveHook; hook != nil {
-		if err := hook(ctx, a, nitem); err != nil {
-			return nil, err
-		}
+	// Keep the avatar file from being deleted while the user has it
+	err = a.FileUsageBll.Track(ctx, fschema.FileUsageUser, UUID, map[string]string{
+		"avatar": nitem.Avatar,
+	})
+	if err != nil {
+		return nil, err
 	}
-
 	return nitem, nil
 }
 
+// Apply a saved user to the policy and sessions, only once the change is committed
+func (a *User) saved(ctx context.Context, item *schema.User) error {
+	if hook := a.SaveHook; hook != nil {
+		return hook(ctx, a, item)
+	}
+	return nil
+}
+
 // Create - Create user
 func (a *User) Create(ctx context.Context, item schema.User) (*schema.User, error) {
 	if item.Password == "" {
@@ -142,19 +186,65 @@ func (a *User) Create(ctx context.Context, item schema.User) (*schema.User, erro
 		return nil, err
 	}
 
-	item.Password = util.SHA1HashString(item.Password)
+	err = a.PasswordPolicyBll.Check(ctx, &item, item.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Password, err = a.Password.Hash(item.Password)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	item.UUID = util.MustUUID()
-	err = a.UserModel.Create(ctx, item)
+	item.PasswordChangedAt = time.Now()
+	var nitem *schema.User
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.UserModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		err = a.PasswordPolicyBll.Record(ctx, item.UUID, item.Password)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, item.UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityUser, aschema.AuditActionCreate, nitem.UUID, nil, nitem)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.saved(ctx, nitem)
 	if err != nil {
 		return nil, err
 	}
 
-	return a.getUpdate(ctx, item.UUID)
+	if !nitem.EmailVerified {
+		a.sendVerification(ctx, nitem)
+	}
+	return nitem, nil
+}
+
+// Mail a verification link to a new email address, the user is saved anyway
+// as the link can be sent again
+func (a *User) sendVerification(ctx context.Context, item *schema.User) {
+	err := a.EmailVerificationBll.Send(ctx, item)
+	if err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("User"), logger.SetSpanFuncName("sendVerification")).
+			Errorf("Mail verification link to %s error: %s", item.UUID, err.Error())
+	}
 }
 
 // Update - Update User
 func (a *User) Update(ctx context.Context, UUID string, item schema.User) (*schema.User, error) {
-	oldItem, err := a.UserModel.Get(ctx, UUID)
+	oldItem, err := a.UserModel.Get(ctx, UUID, schema.UserQueryOptions{
+		IncludeRoles: true,
+	})
 	if err != nil {
 		return nil, err
 	} else if oldItem == nil {
@@ -166,38 +256,98 @@ func (a *User) Update(ctx context.Context, UUID string, item schema.User) (*sche
 		}
 	}
 
+	// The password age only changes with the password
+	item.PasswordChangedAt = time.Time{}
 	if item.Password != "" {
-		item.Password = util.SHA1HashString(item.Password)
+		err = a.PasswordPolicyBll.Check(ctx, &schema.User{
+			UUID:     UUID,
+			UserName: item.UserName,
+			RealName: item.RealName,
+			Email:    item.Email,
+			Password: oldItem.Password,
+		}, item.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		item.Password, err = a.Password.Hash(item.Password)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		item.PasswordChangedAt = time.Now()
+	}
+
+	// The verification is kept until the email address changes
+	emailChanged := oldItem.Email != item.Email
+	if !emailChanged {
+		item.EmailVerified = oldItem.EmailVerified
+	}
+
+	var nitem *schema.User
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.UserModel.Update(ctx, UUID, item)
+		if err != nil {
+			return err
+		}
+
+		if item.Password != "" {
+			err = a.PasswordPolicyBll.Record(ctx, UUID, item.Password)
+			if err != nil {
+				return err
+			}
+		}
+
+		nitem, err = a.getUpdate(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityUser, aschema.AuditActionUpdate, UUID, oldItem, nitem)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	err = a.UserModel.Update(ctx, UUID, item)
+	err = a.saved(ctx, nitem)
 	if err != nil {
 		return nil, err
 	}
 
-	return a.getUpdate(ctx, UUID)
+	if emailChanged && !nitem.EmailVerified {
+		a.sendVerification(ctx, nitem)
+	}
+	return nitem, nil
 }
 
 // Delete - Delete User
 func (a *User) Delete(ctx context.Context, UUID string) error {
-	oldItem, err := a.UserModel.Get(ctx, UUID)
+	oldItem, err := a.UserModel.Get(ctx, UUID, schema.UserQueryOptions{
+		IncludeRoles: true,
+	})
 	if err != nil {
 		return err
 	} else if oldItem == nil {
 		return errors.ErrNotFound
 	}
 
-	err = a.UserModel.Delete(ctx, UUID)
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.UserModel.Delete(ctx, UUID)
+		if err != nil {
+			return err
+		}
+
+		err = a.FileUsageBll.Untrack(ctx, fschema.FileUsageUser, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityUser, aschema.AuditActionDelete, UUID, oldItem, nil)
+	})
 	if err != nil {
 		return err
 	}
 
 	if hook := a.DeleteHook; hook != nil {
-		if err := hook(ctx, a, UUID); err != nil {
-			return err
-		}
+		return hook(ctx, a, UUID)
 	}
-
 	return nil
 }
 
@@ -211,20 +361,20 @@ func (a *User) UpdateStatus(ctx context.Context, UUID string, status int) error
 	} else if oldItem == nil {
 		return errors.ErrNotFound
 	}
+	before := *oldItem
 	oldItem.Status = status
 
-	err = a.UserModel.UpdateStatus(ctx, UUID, status)
-	if err != nil {
-		return err
-	}
-
-	if hook := a.SaveHook; hook != nil {
-		if err := hook(ctx, a, oldItem); err != nil {
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.UserModel.UpdateStatus(ctx, UUID, status)
+		if err != nil {
 			return err
 		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityUser, acontrollers.AuditStatusAction(status), UUID, &before, oldItem)
+	})
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return a.saved(ctx, oldItem)
 }
 
 // LoadPolicy - Load user permission policy
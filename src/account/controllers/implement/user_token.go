@@ -0,0 +1,82 @@
+package implement
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/model"
+	"github.com/MayCMF/core/src/account/schema"
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/mailer"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// Mail of a user token
+type userTokenMail struct {
+	User      *schema.User // Recipient
+	Email     string       // Address of the recipient
+	URL       string       // Link holding the token
+	ExpiresAt string       // Expiration time of the link
+}
+
+// Create a token of a kind for the email address of a user, the pending
+// tokens of the kind of the user are replaced
+func createUserToken(ctx context.Context, m model.IUserToken, kind string, user *schema.User, expired int) (string, time.Time, error) {
+	err := m.DeleteExpired(ctx)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	err = m.DeleteByUser(ctx, kind, user.UUID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	token, hash, err := controllers.NewUserToken()
+	if err != nil {
+		return "", time.Time{}, errors.WithStack(err)
+	}
+
+	expiresAt := time.Now().Add(time.Duration(expired) * time.Second)
+	err = m.Create(ctx, schema.UserToken{
+		UUID:      util.MustUUID(),
+		Kind:      kind,
+		UserUUID:  user.UUID,
+		Email:     user.Email,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Take the token of a link, nil when it is unknown, used or expired
+func takeUserToken(ctx context.Context, m model.IUserToken, kind, token string) (*schema.UserToken, error) {
+	item, err := m.Take(ctx, kind, controllers.HashUserToken(token))
+	if err != nil {
+		return nil, err
+	} else if item == nil || item.ExpiresAt.Before(time.Now()) {
+		return nil, nil
+	}
+	return item, nil
+}
+
+// Mail a template in the language of the client
+func sendMail(ctx context.Context, m mailer.Mailer, t *mailer.Templates, name, email string, data interface{}) error {
+	lang, _ := icontext.FromLanguage(ctx)
+	msg, err := t.Render(lang, name, data)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	msg.To = []string{email}
+	err = m.Send(ctx, msg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
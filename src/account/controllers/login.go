@@ -15,14 +15,32 @@ type ILogin interface {
 	ResCaptcha(ctx context.Context, w http.ResponseWriter, captchaID string, width, height int) error
 	// Login authentication
 	Verify(ctx context.Context, userName, password string) (*schema.User, error)
+	// Start the two-factor step of a login if the user needs it
+	CreateMFAChallenge(ctx context.Context, user *schema.User) (*schema.LoginMFAChallenge, error)
+	// Finish a login with a two-factor code
+	VerifyMFA(ctx context.Context, params schema.LoginMFAParam) (*schema.User, *schema.LoginTokenInfo, error)
+	// Start the TOTP enrolment required to finish a login
+	EnrollMFA(ctx context.Context, mfaToken string) (*schema.MFAEnrollment, error)
 	// Generate token
 	GenerateToken(ctx context.Context, userUUID string) (*schema.LoginTokenInfo, error)
+	// Exchange a refresh token for new tokens
+	RefreshToken(ctx context.Context, refreshToken string) (*schema.LoginTokenInfo, error)
 	// Destroy token
 	DestroyToken(ctx context.Context, tokenString string) error
+	// Generate a short-lived token of the user for the impersonator
+	Impersonate(ctx context.Context, impersonatorUUID, userUUID string) (*schema.LoginTokenInfo, error)
+	// Destroy the impersonation token of the context
+	EndImpersonation(ctx context.Context, tokenString string) error
 	// Get user login information
 	GetLoginInfo(ctx context.Context, userUUID string) (*schema.UserLoginInfo, error)
 	// Query the user's permission Permission tree
 	QueryUserPermissionTree(ctx context.Context, userUUID string) ([]*schema.PermissionTree, error)
+	// Get the login lockout of a user
+	GetLockout(ctx context.Context, userUUID string) (*schema.LoginLockout, error)
+	// Lift the login lockout of a user
+	Unlock(ctx context.Context, userUUID string) error
 	// Update user login password
 	UpdatePassword(ctx context.Context, userUUID string, params schema.UpdatePasswordParam) error
+	// Change an expired password before login
+	ChangePassword(ctx context.Context, params schema.PasswordChangeParam) error
 }
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// TOTP parameters understood by every authenticator app
+const (
+	TOTPPeriod = 30
+	TOTPSkew   = 1
+)
+
+// IMFA - Two-factor authentication business logic interface
+type IMFA interface {
+	// Get the two-factor authentication state of a user
+	Get(ctx context.Context, userUUID string) (*schema.MFAStatus, error)
+	// Check whether a role of the user makes two-factor authentication mandatory
+	Required(ctx context.Context, userUUID string) (bool, error)
+	// Check whether the user has a confirmed TOTP enrolment
+	Enabled(ctx context.Context, userUUID string) (bool, error)
+	// Start a TOTP enrolment with a new secret
+	Enroll(ctx context.Context, userUUID string) (*schema.MFAEnrollment, error)
+	// Confirm a pending enrolment with a first code
+	Confirm(ctx context.Context, userUUID, code string) (*schema.MFARecoveryCodes, error)
+	// Verify a TOTP or recovery code of an enabled user
+	Verify(ctx context.Context, userUUID, code string) error
+	// Disable the two-factor authentication with a valid code
+	Disable(ctx context.Context, userUUID, code string) error
+	// Replace the recovery codes with a valid code
+	RegenerateRecoveryCodes(ctx context.Context, userUUID, code string) (*schema.MFARecoveryCodes, error)
+	// Remove the two-factor authentication of a user (lost device)
+	Reset(ctx context.Context, userUUID string) error
+}
+
+// ValidateTOTP - Check a TOTP code around time t, only a time step after
+// lastCounter is accepted so that a code can not be replayed
+func ValidateTOTP(secret, code string, t time.Time, lastCounter int64) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != int(otp.DigitsSix) {
+		return 0, false
+	}
+
+	counter := t.Unix() / TOTPPeriod
+	for i := int64(-TOTPSkew); i <= TOTPSkew; i++ {
+		c := counter + i
+		if c <= lastCounter {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(c*TOTPPeriod, 0), totp.ValidateOpts{
+			Period:    TOTPPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// Alphabet of recovery codes, without look-alike characters
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// NewRecoveryCodes - Generate n random recovery codes (xxxxx-xxxxx)
+func NewRecoveryCodes(n int) ([]string, error) {
+	// Bytes above the last multiple of the alphabet size are dropped to keep
+	// every character equally likely
+	max := byte(256 / len(recoveryAlphabet) * len(recoveryAlphabet))
+	buf := make([]byte, 1)
+
+	codes := make([]string, n)
+	for i := range codes {
+		code := make([]byte, 0, 11)
+		for len(code) < 11 {
+			if len(code) == 5 {
+				code = append(code, '-')
+				continue
+			}
+			if _, err := rand.Read(buf); err != nil {
+				return nil, err
+			} else if buf[0] >= max {
+				continue
+			}
+			code = append(code, recoveryAlphabet[int(buf[0])%len(recoveryAlphabet)])
+		}
+		codes[i] = string(code)
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode - Hash of a recovery code of a user, the case and the
+// separators typed by the user are ignored
+func HashRecoveryCode(userUUID, code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return util.HMACSHA256HashString(userUUID, code)
+}
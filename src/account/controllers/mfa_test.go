@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	// RFC 6238 appendix B secret ("12345678901234567890"), last six digits
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	now := time.Unix(1111111109, 0)
+
+	counter, ok := ValidateTOTP(secret, "081804", now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1111111109/TOTPPeriod), counter)
+
+	// Previous and next time steps are accepted
+	_, ok = ValidateTOTP(secret, "081804", now.Add(TOTPPeriod*time.Second), 0)
+	assert.True(t, ok)
+	_, ok = ValidateTOTP(secret, "081804", now.Add(-TOTPPeriod*time.Second), 0)
+	assert.True(t, ok)
+	_, ok = ValidateTOTP(secret, "081804", now.Add(2*TOTPPeriod*time.Second), 0)
+	assert.False(t, ok)
+
+	// A code is only accepted once
+	_, ok = ValidateTOTP(secret, "081804", now, counter)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "081805", now, 0)
+	assert.False(t, ok)
+	_, ok = ValidateTOTP(secret, "81804", now, 0)
+	assert.False(t, ok)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(codes))
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Equal(t, 11, len(code))
+		assert.Equal(t, "-", code[5:6])
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	hash := HashRecoveryCode("user", codes[0])
+	assert.Equal(t, hash, HashRecoveryCode("user", strings.ToUpper(strings.Replace(codes[0], "-", " ", 1))))
+	assert.NotEqual(t, hash, HashRecoveryCode("other", codes[0]))
+}
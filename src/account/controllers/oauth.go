@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// IOAuthClient - OAuth2 client business logic interface
+type IOAuthClient interface {
+	// Query data
+	Query(ctx context.Context, params schema.OAuthClientQueryParam, opts ...schema.OAuthClientQueryOptions) (*schema.OAuthClientQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.OAuthClient, error)
+	// Create a client, the plain secret is only returned here
+	Create(ctx context.Context, item schema.OAuthClient) (*schema.OAuthClient, error)
+	// Update data
+	Update(ctx context.Context, UUID string, item schema.OAuthClient) (*schema.OAuthClient, error)
+	// Replace the secret of a client, the new plain secret is only returned here
+	ResetSecret(ctx context.Context, UUID string) (*schema.OAuthClient, error)
+	// Delete a client and its grants
+	Delete(ctx context.Context, UUID string) error
+}
+
+// IOAuthScope - OAuth2 scope business logic interface
+type IOAuthScope interface {
+	// Query data
+	Query(ctx context.Context, params schema.OAuthScopeQueryParam, opts ...schema.OAuthScopeQueryOptions) (*schema.OAuthScopeQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.OAuthScope, error)
+	// Create data
+	Create(ctx context.Context, item schema.OAuthScope) (*schema.OAuthScope, error)
+	// Update data
+	Update(ctx context.Context, UUID string, item schema.OAuthScope) (*schema.OAuthScope, error)
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+	// Load the casbin policy of a scope
+	LoadPolicy(ctx context.Context, item *schema.OAuthScope) error
+}
+
+// IOAuth - OAuth2 authorization server business logic interface
+type IOAuth interface {
+	// Check the authorization request of a client and describe it for the consent screen
+	Authorization(ctx context.Context, userUUID string, params schema.OAuthAuthorizeParam) (*schema.OAuthConsent, error)
+	// Answer the authorization request of a client, the redirect URI holds the code or the denial
+	Authorize(ctx context.Context, userUUID string, params schema.OAuthAuthorizeParam) (*schema.OAuthRedirect, error)
+	// Issue tokens to an authenticated client
+	Token(ctx context.Context, clientID, clientSecret string, params schema.OAuthTokenParam) (*schema.OAuthTokenInfo, error)
+	// Query the grants of a user
+	QueryGrants(ctx context.Context, params schema.OAuthGrantQueryParam) (schema.OAuthGrants, error)
+	// Revoke a grant of a user, the tokens of the client stop working at once
+	RevokeGrant(ctx context.Context, userUUID, UUID string) error
+	// Load the casbin policy of a grant
+	LoadGrantPolicy(ctx context.Context, item *schema.OAuthGrant) error
+}
+
+// OAuthScopeSubject - Casbin subject holding the permissions of a scope
+func OAuthScopeSubject(name string) string {
+	return "oauth_scope:" + name
+}
+
+// OAuthGrantSubject - Casbin subject of the tokens issued for a grant
+func OAuthGrantSubject(UUID string) string {
+	return "oauth_grant:" + UUID
+}
+
+// ParseOAuthGrantSubject - Get the grant of a token scope
+func ParseOAuthGrantSubject(scope string) (string, bool) {
+	if !strings.HasPrefix(scope, "oauth_grant:") {
+		return "", false
+	}
+	return strings.TrimPrefix(scope, "oauth_grant:"), true
+}
+
+// OAuthGrantScopes - Scopes of a grant still allowed to its client, a
+// disabled client keeps none
+func OAuthGrantScopes(client *schema.OAuthClient, grant *schema.OAuthGrant) []string {
+	if client == nil || client.Status != 1 || client.UUID != grant.ClientUUID {
+		return nil
+	}
+
+	allowed := make(map[string]bool)
+	for _, v := range client.Scopes {
+		allowed[v] = true
+	}
+
+	var scopes []string
+	for _, v := range grant.Scopes {
+		if allowed[v] {
+			scopes = append(scopes, v)
+		}
+	}
+	return scopes
+}
+
+// NewOAuthSecret - Generate a random client secret or authorization code and its hash
+func NewOAuthSecret() (secret, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+
+	secret = base64.RawURLEncoding.EncodeToString(b)
+	return secret, HashOAuthSecret(secret), nil
+}
+
+// HashOAuthSecret - Hash of a client secret or authorization code as stored
+func HashOAuthSecret(secret string) string {
+	return util.SHA256HashString(secret)
+}
+
+// ParseOAuthScope - Split a space separated scope parameter
+func ParseOAuthScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+// OAuth2 error codes (RFC 6749)
+const (
+	OAuthErrInvalidRequest       = "invalid_request"
+	OAuthErrInvalidClient        = "invalid_client"
+	OAuthErrInvalidGrant         = "invalid_grant"
+	OAuthErrUnauthorizedClient   = "unauthorized_client"
+	OAuthErrUnsupportedGrantType = "unsupported_grant_type"
+	OAuthErrInvalidScope         = "invalid_scope"
+	OAuthErrAccessDenied         = "access_denied"
+)
+
+// OAuthError - Error answered to a client in the format of RFC 6749
+type OAuthError struct {
+	StatusCode  int
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// NewOAuthError - Create an error answered with 400 (401 for invalid clients)
+func NewOAuthError(code, description string) error {
+	status := http.StatusBadRequest
+	if code == OAuthErrInvalidClient {
+		status = http.StatusUnauthorized
+	}
+	return &OAuthError{
+		StatusCode:  status,
+		Code:        code,
+		Description: description,
+	}
+}
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IOIDC - OpenID Connect login business logic interface
+type IOIDC interface {
+	// Query the configured identity providers
+	Providers(ctx context.Context) []*schema.OIDCProvider
+	// Start a login at an identity provider, the signed state has to be kept
+	// by the browser and handed back with the callback
+	AuthCodeURL(ctx context.Context, provider string) (authURL, signedState string, err error)
+	// Finish a login with the authorization code, the user is created or
+	// linked on the first login and gets the roles mapped from the claims
+	Login(ctx context.Context, provider, code, state, signedState string) (*schema.User, error)
+}
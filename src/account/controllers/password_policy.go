@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IPasswordPolicy - Password policy business logic interface
+type IPasswordPolicy interface {
+	// Check a new password of a user against the policy and the last passwords of the user
+	Check(ctx context.Context, user *schema.User, password string) error
+	// Score a password and list the rules it breaks
+	Evaluate(ctx context.Context, params schema.PasswordCheckParam) *schema.PasswordCheckResult
+	// Record a new password hash of a user in the password history
+	Record(ctx context.Context, userUUID, encoded string) error
+	// Check whether the password of a user is older than the maximum age
+	Expired(ctx context.Context, user *schema.User) bool
+}
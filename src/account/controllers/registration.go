@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IRegistration - Self-registration business logic interface
+type IRegistration interface {
+	// Get the settings of the sign-up form, of an invitation if its token is given
+	Info(ctx context.Context, invitation string) (*schema.RegisterInfo, error)
+	// Sign up a user
+	Register(ctx context.Context, params schema.RegisterParam) (*schema.User, error)
+}
+
+// IInvitation - Invitation business logic interface
+type IInvitation interface {
+	// Query data
+	Query(ctx context.Context, params schema.InvitationQueryParam, opts ...schema.InvitationQueryOptions) (*schema.InvitationQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.Invitation, error)
+	// Create an invitation, the link is only returned here
+	Create(ctx context.Context, item schema.Invitation) (*schema.Invitation, error)
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// ISession - Session and login history business logic interface
+type ISession interface {
+	// Record the session of newly issued tokens
+	Create(ctx context.Context, userUUID, sessionID string) error
+	// Update the last seen time and client of a session after a token refresh
+	Touch(ctx context.Context, sessionID string) error
+	// Forget a session whose tokens are destroyed
+	Remove(ctx context.Context, sessionID string) error
+	// Query the active sessions of a user, the session of the access token is marked
+	Query(ctx context.Context, userUUID, accessToken string) (schema.UserSessions, error)
+	// Revoke a session of a user
+	Revoke(ctx context.Context, userUUID, sessionID string) error
+	// Revoke every session of a user
+	RevokeAll(ctx context.Context, userUUID string) error
+	// Record a login attempt
+	RecordLogin(ctx context.Context, item schema.LoginHistory)
+	// Query the login history
+	QueryLoginHistory(ctx context.Context, params schema.LoginHistoryQueryParam, opts ...schema.LoginHistoryQueryOptions) (*schema.LoginHistoryQueryResult, error)
+}
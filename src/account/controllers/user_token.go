@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// IPasswordReset - Password reset business logic interface
+type IPasswordReset interface {
+	// Mail a reset link to the user of an email address
+	Forgot(ctx context.Context, email string) error
+	// Set a new password with the token of a reset link
+	Reset(ctx context.Context, params schema.PasswordResetParam) error
+}
+
+// IEmailVerification - Email verification business logic interface
+type IEmailVerification interface {
+	// Mail a verification link to the email address of a user
+	Send(ctx context.Context, user *schema.User) error
+	// Mail a verification link to the unverified email address of a user
+	Resend(ctx context.Context, userUUID string) error
+	// Mail a verification link to an unverified email address of a user
+	// that cannot login before verifying it
+	ResendTo(ctx context.Context, email string) error
+	// Confirm an email address with the token of a verification link
+	Verify(ctx context.Context, token string) error
+}
+
+// NewUserToken - Generate a random user token and its hash
+func NewUserToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashUserToken(token), nil
+}
+
+// HashUserToken - Hash of a user token as stored
+func HashUserToken(token string) string {
+	return util.SHA256HashString(token)
+}
+
+// UserTokenURL - Add a user token to the URL of a frontend page as query
+// parameter, URLs with a fragment get it in the fragment
+func UserTokenURL(pageURL, token string) string {
+	sep := "?"
+	if i := strings.Index(pageURL, "#"); i >= 0 {
+		if strings.Contains(pageURL[i:], "?") {
+			sep = "&"
+		}
+	} else if strings.Contains(pageURL, "?") {
+		sep = "&"
+	}
+	return pageURL + sep + "token=" + url.QueryEscape(token)
+}
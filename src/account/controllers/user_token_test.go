@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserTokenURL(t *testing.T) {
+	assert.Equal(t, "https://cms.example.com/reset?token=a%2Bb", UserTokenURL("https://cms.example.com/reset", "a+b"))
+	assert.Equal(t, "https://cms.example.com/reset?lang=en&token=abc", UserTokenURL("https://cms.example.com/reset?lang=en", "abc"))
+	assert.Equal(t, "https://cms.example.com/?v=1#/reset?token=abc", UserTokenURL("https://cms.example.com/?v=1#/reset", "abc"))
+	assert.Equal(t, "https://cms.example.com/#/reset?lang=en&token=abc", UserTokenURL("https://cms.example.com/#/reset?lang=en", "abc"))
+}
+
+func TestUserToken(t *testing.T) {
+	token, hash, err := NewUserToken()
+	assert.Nil(t, err)
+	assert.Len(t, token, 43)
+	assert.Equal(t, HashUserToken(token), hash)
+	assert.NotEqual(t, token, hash)
+}
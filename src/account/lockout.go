@@ -0,0 +1,48 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/common/auth/lockout"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/logger"
+)
+
+// InitLockout - Initialize the login brute-force protection, nil when it is disabled
+func InitLockout() *lockout.Guard {
+	cfg := config.Global().Lockout
+	if !cfg.Enable {
+		return nil
+	}
+
+	var store lockout.Store
+	if cfg.Store == "redis" {
+		rc := config.Global().Redis
+		store = lockout.NewRedisStore(&lockout.RedisConfig{
+			Addr:      rc.Addr,
+			Password:  rc.Password,
+			DB:        cfg.RedisDB,
+			KeyPrefix: cfg.RedisPrefix,
+		})
+	} else {
+		store = lockout.NewMemoryStore()
+	}
+
+	opts := []lockout.Option{
+		lockout.SetMaxAttempts(cfg.MaxAttempts),
+		lockout.SetMaxIPAttempts(cfg.MaxIPAttempts),
+		lockout.SetDelay(time.Duration(cfg.Delay)*time.Second, time.Duration(cfg.MaxDelay)*time.Second),
+		lockout.SetEventHandler(func(ctx context.Context, e lockout.Event) {
+			logger.StartSpan(ctx, logger.SetSpanTitle("Login lockout"), logger.SetSpanFuncName(e.Kind)).
+				Warnf("Login %s", e.String())
+		}),
+	}
+	if cfg.Window > 0 {
+		opts = append(opts, lockout.SetWindow(time.Duration(cfg.Window)*time.Second))
+	}
+	if cfg.LockDuration > 0 {
+		opts = append(opts, lockout.SetLockDuration(time.Duration(cfg.LockDuration)*time.Second))
+	}
+	return lockout.New(store, opts...)
+}
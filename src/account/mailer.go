@@ -0,0 +1,40 @@
+package account
+
+import (
+	"fmt"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/mailer"
+)
+
+// InitMailer - Initialize the mailer of the configured driver
+func InitMailer() (mailer.Mailer, error) {
+	cfg := config.Global().Mailer
+
+	switch cfg.Driver {
+	case "smtp":
+		return mailer.NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.From,
+			mailer.SetSMTPAuth(cfg.SMTPUserName, cfg.SMTPPassword),
+			mailer.SetSMTPTLS(cfg.SMTPTLS),
+		), nil
+	case "file":
+		return mailer.NewFile(cfg.FileDir, cfg.From), nil
+	case "memory":
+		return mailer.NewMemory(cfg.From), nil
+	case "log", "":
+		return mailer.NewLog(cfg.From), nil
+	}
+	return nil, fmt.Errorf("unknown mailer driver %s", cfg.Driver)
+}
+
+// InitMailTemplates - Load the mail templates, mails of languages without
+// templates use the default i18n language
+func InitMailTemplates() (*mailer.Templates, error) {
+	cfg := config.Global()
+
+	defaultLanguage := cfg.I18n.Default
+	if defaultLanguage == "" {
+		defaultLanguage = "en"
+	}
+	return mailer.NewTemplates(cfg.Mailer.TemplateDir, defaultLanguage)
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IAccessToken - Access token storage interface
+type IAccessToken interface {
+	// Query data
+	Query(ctx context.Context, params schema.AccessTokenQueryParam, opts ...schema.AccessTokenQueryOptions) (*schema.AccessTokenQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.AccessToken, error)
+	// Create data
+	Create(ctx context.Context, item schema.AccessToken) error
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+	// Update the time of the last authenticated request
+	UpdateLastUsed(ctx context.Context, UUID string, lastUsedAt time.Time) error
+}
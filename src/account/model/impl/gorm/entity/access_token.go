@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/jinzhu/gorm"
+)
+
+// GetAccessTokenDB - Get access token storage
+func GetAccessTokenDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, AccessToken{})
+}
+
+// SchemaAccessToken - Access token object
+type SchemaAccessToken schema.AccessToken
+
+// ToAccessToken - Convert to access token entity
+func (a SchemaAccessToken) ToAccessToken() *AccessToken {
+	scopes := util.JSONMarshalToString(a.Scopes)
+	item := &AccessToken{
+		UUID:       a.UUID,
+		UserUUID:   &a.UserUUID,
+		Kind:       &a.Kind,
+		Name:       &a.Name,
+		Hint:       &a.Hint,
+		TokenHash:  &a.TokenHash,
+		Scopes:     &scopes,
+		ExpiresAt:  a.ExpiresAt,
+		LastUsedAt: a.LastUsedAt,
+		Creator:    &a.Creator,
+	}
+	return item
+}
+
+// AccessToken - Access token entity
+type AccessToken struct {
+	entity.Model
+	UUID       string     `gorm:"column:record_id;size:36;index;"`  // Record internal code
+	UserUUID   *string    `gorm:"column:user_uuid;size:36;index;"`  // Owner
+	Kind       *string    `gorm:"column:kind;size:16;index;"`       // Kind (personal/api_key)
+	Name       *string    `gorm:"column:name;size:64;"`             // Name
+	Hint       *string    `gorm:"column:hint;size:16;"`             // First characters of the token
+	TokenHash  *string    `gorm:"column:token_hash;size:64;index;"` // SHA-256 of the token
+	Scopes     *string    `gorm:"column:scopes;type:text;"`         // Scopes (JSON)
+	ExpiresAt  *time.Time `gorm:"column:expires_at;"`               // Expiration time
+	LastUsedAt *time.Time `gorm:"column:last_used_at;"`             // Time of the last authenticated request
+	Creator    *string    `gorm:"column:creator;size:36;"`          // Creator
+}
+
+func (a AccessToken) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a AccessToken) TableName() string {
+	return a.Model.TableName("access_token")
+}
+
+// ToSchemaAccessToken - Convert to access token object
+func (a AccessToken) ToSchemaAccessToken() *schema.AccessToken {
+	item := &schema.AccessToken{
+		UUID:       a.UUID,
+		UserUUID:   *a.UserUUID,
+		Kind:       *a.Kind,
+		Name:       *a.Name,
+		Hint:       *a.Hint,
+		TokenHash:  *a.TokenHash,
+		ExpiresAt:  a.ExpiresAt,
+		LastUsedAt: a.LastUsedAt,
+		Creator:    *a.Creator,
+		CreatedAt:  a.CreatedAt,
+	}
+	_ = util.JSONUnmarshal([]byte(*a.Scopes), &item.Scopes)
+	return item
+}
+
+// AccessTokens - Access token entity list
+type AccessTokens []*AccessToken
+
+// ToSchemaAccessTokens - Convert to access token object list
+func (a AccessTokens) ToSchemaAccessTokens() []*schema.AccessToken {
+	list := make([]*schema.AccessToken, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaAccessToken()
+	}
+	return list
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/jinzhu/gorm"
+)
+
+// GetInvitationDB - Get invitation storage
+func GetInvitationDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, Invitation{})
+}
+
+// SchemaInvitation - Invitation object
+type SchemaInvitation schema.Invitation
+
+// ToInvitation - Convert to invitation entity
+func (a SchemaInvitation) ToInvitation() *Invitation {
+	roles := util.JSONMarshalToString(a.Roles)
+	item := &Invitation{
+		UUID:      a.UUID,
+		Note:      &a.Note,
+		Email:     &a.Email,
+		Roles:     &roles,
+		MaxUses:   &a.MaxUses,
+		Uses:      &a.Uses,
+		ExpiresAt: &a.ExpiresAt,
+		TokenHash: &a.TokenHash,
+		Creator:   &a.Creator,
+	}
+	return item
+}
+
+// Invitation - Invitation entity
+type Invitation struct {
+	entity.Model
+	UUID      string     `gorm:"column:record_id;size:36;index;"`  // Record internal code
+	Note      *string    `gorm:"column:note;size:255;"`            // Note of the administrator
+	Email     *string    `gorm:"column:email;size:255;"`           // Only address allowed to sign up
+	Roles     *string    `gorm:"column:roles;type:text;"`          // Role IDs (JSON)
+	MaxUses   *int       `gorm:"column:max_uses;"`                 // Maximum number of sign-ups (0: unlimited)
+	Uses      *int       `gorm:"column:uses;"`                     // Number of sign-ups
+	ExpiresAt *time.Time `gorm:"column:expires_at;index;"`         // Expiration time
+	TokenHash *string    `gorm:"column:token_hash;size:64;index;"` // SHA-256 of the token
+	Creator   *string    `gorm:"column:creator;size:36;"`          // Creator
+}
+
+func (a Invitation) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a Invitation) TableName() string {
+	return a.Model.TableName("invitation")
+}
+
+// ToSchemaInvitation - Convert to invitation object
+func (a Invitation) ToSchemaInvitation() *schema.Invitation {
+	item := &schema.Invitation{
+		UUID:      a.UUID,
+		Note:      *a.Note,
+		Email:     *a.Email,
+		MaxUses:   *a.MaxUses,
+		Uses:      *a.Uses,
+		ExpiresAt: *a.ExpiresAt,
+		TokenHash: *a.TokenHash,
+		Creator:   *a.Creator,
+		CreatedAt: a.CreatedAt,
+	}
+	_ = util.JSONUnmarshal([]byte(*a.Roles), &item.Roles)
+	return item
+}
+
+// Invitations - Invitation entity list
+type Invitations []*Invitation
+
+// ToSchemaInvitations - Convert to invitation object list
+func (a Invitations) ToSchemaInvitations() []*schema.Invitation {
+	list := make([]*schema.Invitation, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaInvitation()
+	}
+	return list
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"context"
+	"strings"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/jinzhu/gorm"
+)
+
+// GetUserMFADB - Get user two-factor authentication storage
+func GetUserMFADB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, UserMFA{})
+}
+
+// SchemaUserMFA - User two-factor authentication object
+type SchemaUserMFA schema.UserMFA
+
+// ToUserMFA - Convert to user two-factor authentication entity
+func (a SchemaUserMFA) ToUserMFA() *UserMFA {
+	codes := strings.Join(a.RecoveryCodes, ",")
+	item := &UserMFA{
+		UserUUID:      a.UserUUID,
+		Secret:        &a.Secret,
+		Enabled:       &a.Enabled,
+		LastCounter:   &a.LastCounter,
+		RecoveryCodes: &codes,
+	}
+	return item
+}
+
+// UserMFA - User two-factor authentication entity
+type UserMFA struct {
+	entity.Model
+	UserUUID      string  `gorm:"column:user_uuid;size:36;index;"`  // User UUID
+	Secret        *string `gorm:"column:secret;size:64;"`           // Base32 TOTP secret
+	Enabled       *bool   `gorm:"column:enabled;"`                  // Enrolment confirmed
+	LastCounter   *int64  `gorm:"column:last_counter;"`             // Time step of the last accepted code
+	RecoveryCodes *string `gorm:"column:recovery_codes;size:2048;"` // Recovery code hashes (multiple separated by commas)
+}
+
+func (a UserMFA) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a UserMFA) TableName() string {
+	return a.Model.TableName("user_mfa")
+}
+
+// ToSchemaUserMFA - Convert to user two-factor authentication object
+func (a UserMFA) ToSchemaUserMFA() *schema.UserMFA {
+	item := &schema.UserMFA{
+		UserUUID:    a.UserUUID,
+		Secret:      *a.Secret,
+		Enabled:     *a.Enabled,
+		LastCounter: *a.LastCounter,
+		CreatedAt:   a.CreatedAt,
+	}
+	if v := *a.RecoveryCodes; v != "" {
+		item.RecoveryCodes = strings.Split(v, ",")
+	}
+	return item
+}
@@ -0,0 +1,284 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/jinzhu/gorm"
+)
+
+// GetOAuthClientDB - Get OAuth2 client storage
+func GetOAuthClientDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, OAuthClient{})
+}
+
+// SchemaOAuthClient - OAuth2 client object
+type SchemaOAuthClient schema.OAuthClient
+
+// ToOAuthClient - Convert to OAuth2 client entity
+func (a SchemaOAuthClient) ToOAuthClient() *OAuthClient {
+	redirectURIs := util.JSONMarshalToString(a.RedirectURIs)
+	grantTypes := util.JSONMarshalToString(a.GrantTypes)
+	scopes := util.JSONMarshalToString(a.Scopes)
+	item := &OAuthClient{
+		UUID:         a.UUID,
+		Name:         &a.Name,
+		Public:       &a.Public,
+		RedirectURIs: &redirectURIs,
+		GrantTypes:   &grantTypes,
+		Scopes:       &scopes,
+		UserUUID:     &a.UserUUID,
+		Status:       &a.Status,
+		SecretHash:   &a.SecretHash,
+		Creator:      &a.Creator,
+	}
+	return item
+}
+
+// OAuthClient - OAuth2 client entity
+type OAuthClient struct {
+	entity.Model
+	UUID         string  `gorm:"column:record_id;size:36;index;"` // Record internal code
+	Name         *string `gorm:"column:name;size:64;index;"`      // Name
+	Public       *bool   `gorm:"column:public;"`                  // Public client without a secret
+	RedirectURIs *string `gorm:"column:redirect_uris;type:text;"` // Allowed redirect URIs (JSON)
+	GrantTypes   *string `gorm:"column:grant_types;size:255;"`    // Allowed grant types (JSON)
+	Scopes       *string `gorm:"column:scopes;type:text;"`        // Scopes the client may request (JSON)
+	UserUUID     *string `gorm:"column:user_uuid;size:36;index;"` // User acted as by the client credentials grant
+	Status       *int    `gorm:"column:status;index;"`            // Status (1: Enable 2: Disable)
+	SecretHash   *string `gorm:"column:secret_hash;size:64;"`     // SHA-256 of the secret
+	Creator      *string `gorm:"column:creator;size:36;"`         // Creator
+}
+
+func (a OAuthClient) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a OAuthClient) TableName() string {
+	return a.Model.TableName("oauth_client")
+}
+
+// ToSchemaOAuthClient - Convert to OAuth2 client object
+func (a OAuthClient) ToSchemaOAuthClient() *schema.OAuthClient {
+	item := &schema.OAuthClient{
+		UUID:       a.UUID,
+		Name:       *a.Name,
+		Public:     *a.Public,
+		UserUUID:   *a.UserUUID,
+		Status:     *a.Status,
+		SecretHash: *a.SecretHash,
+		Creator:    *a.Creator,
+		CreatedAt:  a.CreatedAt,
+	}
+	_ = util.JSONUnmarshal([]byte(*a.RedirectURIs), &item.RedirectURIs)
+	_ = util.JSONUnmarshal([]byte(*a.GrantTypes), &item.GrantTypes)
+	_ = util.JSONUnmarshal([]byte(*a.Scopes), &item.Scopes)
+	return item
+}
+
+// OAuthClients - OAuth2 client entity list
+type OAuthClients []*OAuthClient
+
+// ToSchemaOAuthClients - Convert to OAuth2 client object list
+func (a OAuthClients) ToSchemaOAuthClients() []*schema.OAuthClient {
+	list := make([]*schema.OAuthClient, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaOAuthClient()
+	}
+	return list
+}
+
+// GetOAuthScopeDB - Get OAuth2 scope storage
+func GetOAuthScopeDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, OAuthScope{})
+}
+
+// SchemaOAuthScope - OAuth2 scope object
+type SchemaOAuthScope schema.OAuthScope
+
+// ToOAuthScope - Convert to OAuth2 scope entity
+func (a SchemaOAuthScope) ToOAuthScope() *OAuthScope {
+	permissions := util.JSONMarshalToString(a.Permissions)
+	item := &OAuthScope{
+		UUID:        a.UUID,
+		Name:        &a.Name,
+		Title:       &a.Title,
+		Memo:        &a.Memo,
+		Permissions: &permissions,
+		Creator:     &a.Creator,
+	}
+	return item
+}
+
+// OAuthScope - OAuth2 scope entity
+type OAuthScope struct {
+	entity.Model
+	UUID        string  `gorm:"column:record_id;size:36;index;"` // Record internal code
+	Name        *string `gorm:"column:name;size:64;index;"`      // Name requested by the clients
+	Title       *string `gorm:"column:title;size:100;"`          // Title
+	Memo        *string `gorm:"column:memo;size:1024;"`          // Description
+	Permissions *string `gorm:"column:permissions;type:text;"`   // Granted permission resources (JSON)
+	Creator     *string `gorm:"column:creator;size:36;"`         // Creator
+}
+
+func (a OAuthScope) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a OAuthScope) TableName() string {
+	return a.Model.TableName("oauth_scope")
+}
+
+// ToSchemaOAuthScope - Convert to OAuth2 scope object
+func (a OAuthScope) ToSchemaOAuthScope() *schema.OAuthScope {
+	item := &schema.OAuthScope{
+		UUID:      a.UUID,
+		Name:      *a.Name,
+		Title:     *a.Title,
+		Memo:      *a.Memo,
+		Creator:   *a.Creator,
+		CreatedAt: a.CreatedAt,
+	}
+	_ = util.JSONUnmarshal([]byte(*a.Permissions), &item.Permissions)
+	return item
+}
+
+// OAuthScopes - OAuth2 scope entity list
+type OAuthScopes []*OAuthScope
+
+// ToSchemaOAuthScopes - Convert to OAuth2 scope object list
+func (a OAuthScopes) ToSchemaOAuthScopes() []*schema.OAuthScope {
+	list := make([]*schema.OAuthScope, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaOAuthScope()
+	}
+	return list
+}
+
+// GetOAuthGrantDB - Get OAuth2 grant storage
+func GetOAuthGrantDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, OAuthGrant{})
+}
+
+// SchemaOAuthGrant - OAuth2 grant object
+type SchemaOAuthGrant schema.OAuthGrant
+
+// ToOAuthGrant - Convert to OAuth2 grant entity
+func (a SchemaOAuthGrant) ToOAuthGrant() *OAuthGrant {
+	scopes := util.JSONMarshalToString(a.Scopes)
+	item := &OAuthGrant{
+		UUID:       a.UUID,
+		ClientUUID: &a.ClientUUID,
+		UserUUID:   &a.UserUUID,
+		Scopes:     &scopes,
+	}
+	return item
+}
+
+// OAuthGrant - OAuth2 grant entity
+type OAuthGrant struct {
+	entity.Model
+	UUID       string  `gorm:"column:record_id;size:36;index;"`   // Record internal code
+	ClientUUID *string `gorm:"column:client_uuid;size:36;index;"` // Client
+	UserUUID   *string `gorm:"column:user_uuid;size:36;index;"`   // User
+	Scopes     *string `gorm:"column:scopes;type:text;"`          // Granted scopes (JSON)
+}
+
+func (a OAuthGrant) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a OAuthGrant) TableName() string {
+	return a.Model.TableName("oauth_grant")
+}
+
+// ToSchemaOAuthGrant - Convert to OAuth2 grant object
+func (a OAuthGrant) ToSchemaOAuthGrant() *schema.OAuthGrant {
+	item := &schema.OAuthGrant{
+		UUID:       a.UUID,
+		ClientUUID: *a.ClientUUID,
+		UserUUID:   *a.UserUUID,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+	}
+	_ = util.JSONUnmarshal([]byte(*a.Scopes), &item.Scopes)
+	return item
+}
+
+// OAuthGrants - OAuth2 grant entity list
+type OAuthGrants []*OAuthGrant
+
+// ToSchemaOAuthGrants - Convert to OAuth2 grant object list
+func (a OAuthGrants) ToSchemaOAuthGrants() []*schema.OAuthGrant {
+	list := make([]*schema.OAuthGrant, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaOAuthGrant()
+	}
+	return list
+}
+
+// GetOAuthCodeDB - Get authorization code storage
+func GetOAuthCodeDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, OAuthCode{})
+}
+
+// SchemaOAuthCode - Authorization code object
+type SchemaOAuthCode schema.OAuthCode
+
+// ToOAuthCode - Convert to authorization code entity
+func (a SchemaOAuthCode) ToOAuthCode() *OAuthCode {
+	scopes := util.JSONMarshalToString(a.Scopes)
+	item := &OAuthCode{
+		UUID:          a.UUID,
+		CodeHash:      &a.CodeHash,
+		GrantUUID:     &a.GrantUUID,
+		Scopes:        &scopes,
+		RedirectURI:   &a.RedirectURI,
+		CodeChallenge: &a.CodeChallenge,
+		ExpiresAt:     &a.ExpiresAt,
+	}
+	return item
+}
+
+// OAuthCode - Authorization code entity
+type OAuthCode struct {
+	entity.Model
+	UUID          string     `gorm:"column:record_id;size:36;index;"` // Record internal code
+	CodeHash      *string    `gorm:"column:code_hash;size:64;index;"` // SHA-256 of the code
+	GrantUUID     *string    `gorm:"column:grant_uuid;size:36;"`      // Grant the tokens are issued for
+	Scopes        *string    `gorm:"column:scopes;type:text;"`        // Approved scopes (JSON)
+	RedirectURI   *string    `gorm:"column:redirect_uri;size:1024;"`  // Redirect URI of the authorization request
+	CodeChallenge *string    `gorm:"column:code_challenge;size:128;"` // PKCE code challenge
+	ExpiresAt     *time.Time `gorm:"column:expires_at;index;"`        // Expiration time
+}
+
+func (a OAuthCode) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a OAuthCode) TableName() string {
+	return a.Model.TableName("oauth_code")
+}
+
+// ToSchemaOAuthCode - Convert to authorization code object
+func (a OAuthCode) ToSchemaOAuthCode() *schema.OAuthCode {
+	item := &schema.OAuthCode{
+		UUID:          a.UUID,
+		CodeHash:      *a.CodeHash,
+		GrantUUID:     *a.GrantUUID,
+		RedirectURI:   *a.RedirectURI,
+		CodeChallenge: *a.CodeChallenge,
+		ExpiresAt:     *a.ExpiresAt,
+	}
+	if a.Scopes != nil {
+		_ = util.JSONUnmarshal([]byte(*a.Scopes), &item.Scopes)
+	}
+	return item
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/jinzhu/gorm"
+)
+
+// GetUserIdentityDB - Get external identity storage
+func GetUserIdentityDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, UserIdentity{})
+}
+
+// SchemaUserIdentity - External identity object
+type SchemaUserIdentity schema.UserIdentity
+
+// ToUserIdentity - Convert to external identity entity
+func (a SchemaUserIdentity) ToUserIdentity() *UserIdentity {
+	item := &UserIdentity{
+		UUID:     a.UUID,
+		UserUUID: &a.UserUUID,
+		Provider: &a.Provider,
+		Subject:  &a.Subject,
+	}
+	return item
+}
+
+// UserIdentity - External identity entity
+type UserIdentity struct {
+	entity.Model
+	UUID     string  `gorm:"column:record_id;size:36;index;"` // Record internal code
+	UserUUID *string `gorm:"column:user_uuid;size:36;index;"` // User UUID
+	Provider *string `gorm:"column:provider;size:64;index;"`  // Identity provider name
+	Subject  *string `gorm:"column:subject;size:255;index;"`  // User identifier at the identity provider
+}
+
+func (a UserIdentity) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a UserIdentity) TableName() string {
+	return a.Model.TableName("user_identity")
+}
+
+// ToSchemaUserIdentity - Convert to external identity object
+func (a UserIdentity) ToSchemaUserIdentity() *schema.UserIdentity {
+	item := &schema.UserIdentity{
+		UUID:      a.UUID,
+		UserUUID:  *a.UserUUID,
+		Provider:  *a.Provider,
+		Subject:   *a.Subject,
+		CreatedAt: a.CreatedAt,
+	}
+	return item
+}
+
+// UserIdentities - External identity entity list
+type UserIdentities []*UserIdentity
+
+// ToSchemaUserIdentities - Convert to external identity object list
+func (a UserIdentities) ToSchemaUserIdentities() []*schema.UserIdentity {
+	list := make([]*schema.UserIdentity, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaUserIdentity()
+	}
+	return list
+}
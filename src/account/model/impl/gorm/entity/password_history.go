@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/jinzhu/gorm"
+)
+
+// GetPasswordHistoryDB - Get password history storage
+func GetPasswordHistoryDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, PasswordHistory{})
+}
+
+// PasswordHistory - Former password entity
+type PasswordHistory struct {
+	entity.Model
+	UserUUID string `gorm:"column:user_uuid;size:36;index;"` // User
+	Password string `gorm:"column:password;size:255;"`       // Password hash
+}
+
+func (a PasswordHistory) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a PasswordHistory) TableName() string {
+	return a.Model.TableName("password_history")
+}
@@ -25,11 +25,12 @@ type SchemaRole schema.Role
 // ToRole - Convert to a role entity
 func (a SchemaRole) ToRole() *Role {
 	item := &Role{
-		UUID:     a.UUID,
-		Name:     &a.Name,
-		Sequence: &a.Sequence,
-		Memo:     &a.Memo,
-		Creator:  &a.Creator,
+		UUID:        a.UUID,
+		Name:        &a.Name,
+		Sequence:    &a.Sequence,
+		Memo:        &a.Memo,
+		MFARequired: &a.MFARequired,
+		Creator:     &a.Creator,
 	}
 	return item
 }
@@ -46,11 +47,12 @@ func (a SchemaRole) ToRolePermissions() []*RolePermission {
 // Role - Role entity
 type Role struct {
 	entity.Model
-	UUID     string  `gorm:"column:record_id;size:36;index;"` // Record internal code
-	Name     *string `gorm:"column:name;size:100;index;"`     // Role Name
-	Sequence *int    `gorm:"column:sequence;index;"`          // Sort value
-	Memo     *string `gorm:"column:memo;size:200;"`           // Remarks
-	Creator  *string `gorm:"column:creator;size:36;"`         // Creator
+	UUID        string  `gorm:"column:record_id;size:36;index;"` // Record internal code
+	Name        *string `gorm:"column:name;size:100;index;"`     // Role Name
+	Sequence    *int    `gorm:"column:sequence;index;"`          // Sort value
+	Memo        *string `gorm:"column:memo;size:200;"`           // Remarks
+	MFARequired *bool   `gorm:"column:mfa_required;"`            // Two-factor authentication is mandatory
+	Creator     *string `gorm:"column:creator;size:36;"`         // Creator
 }
 
 func (a Role) String() string {
@@ -72,6 +74,10 @@ func (a Role) ToSchemaRole() *schema.Role {
 		Creator:   *a.Creator,
 		CreatedAt: a.CreatedAt,
 	}
+	// Roles created before the column existed
+	if a.MFARequired != nil {
+		item.MFARequired = *a.MFARequired
+	}
 	return item
 }
 
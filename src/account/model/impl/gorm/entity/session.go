@@ -0,0 +1,144 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/jinzhu/gorm"
+)
+
+// GetUserSessionDB - Get user session storage
+func GetUserSessionDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, UserSession{})
+}
+
+// GetLoginHistoryDB - Get login history storage
+func GetLoginHistoryDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, LoginHistory{})
+}
+
+// SchemaUserSession - User session object
+type SchemaUserSession schema.UserSession
+
+// ToUserSession - Convert to user session entity
+func (a SchemaUserSession) ToUserSession() *UserSession {
+	item := &UserSession{
+		UUID:       a.UUID,
+		UserUUID:   &a.UserUUID,
+		IP:         &a.IP,
+		UserAgent:  &a.UserAgent,
+		LastSeenAt: &a.LastSeenAt,
+		ExpiresAt:  &a.ExpiresAt,
+	}
+	return item
+}
+
+// UserSession - User session entity
+type UserSession struct {
+	entity.Model
+	UUID       string     `gorm:"column:record_id;size:36;index;"` // Session ID (token family)
+	UserUUID   *string    `gorm:"column:user_uuid;size:36;index;"` // User UUID
+	IP         *string    `gorm:"column:ip;size:64;"`              // Client IP
+	UserAgent  *string    `gorm:"column:user_agent;size:512;"`     // Client user agent
+	LastSeenAt *time.Time `gorm:"column:last_seen_at;"`            // Time of the last token refresh
+	ExpiresAt  *time.Time `gorm:"column:expires_at;index;"`        // Expiration time of the last refresh token
+}
+
+func (a UserSession) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a UserSession) TableName() string {
+	return a.Model.TableName("user_session")
+}
+
+// ToSchemaUserSession - Convert to user session object
+func (a UserSession) ToSchemaUserSession() *schema.UserSession {
+	item := &schema.UserSession{
+		UUID:       a.UUID,
+		UserUUID:   *a.UserUUID,
+		IP:         *a.IP,
+		UserAgent:  *a.UserAgent,
+		CreatedAt:  a.CreatedAt,
+		LastSeenAt: *a.LastSeenAt,
+		ExpiresAt:  *a.ExpiresAt,
+	}
+	return item
+}
+
+// UserSessions - User session entity list
+type UserSessions []*UserSession
+
+// ToSchemaUserSessions - Convert to user session object list
+func (a UserSessions) ToSchemaUserSessions() []*schema.UserSession {
+	list := make([]*schema.UserSession, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaUserSession()
+	}
+	return list
+}
+
+// SchemaLoginHistory - Login history object
+type SchemaLoginHistory schema.LoginHistory
+
+// ToLoginHistory - Convert to login history entity
+func (a SchemaLoginHistory) ToLoginHistory() *LoginHistory {
+	item := &LoginHistory{
+		UserUUID:  &a.UserUUID,
+		UserName:  &a.UserName,
+		IP:        &a.IP,
+		UserAgent: &a.UserAgent,
+		Success:   &a.Success,
+		Reason:    &a.Reason,
+	}
+	return item
+}
+
+// LoginHistory - Login history entity
+type LoginHistory struct {
+	entity.Model
+	UserUUID  *string `gorm:"column:user_uuid;size:36;index;"` // User UUID
+	UserName  *string `gorm:"column:user_name;size:64;index;"` // User name used to login
+	IP        *string `gorm:"column:ip;size:64;"`              // Client IP
+	UserAgent *string `gorm:"column:user_agent;size:512;"`     // Client user agent
+	Success   *bool   `gorm:"column:success;index;"`           // A token was issued
+	Reason    *string `gorm:"column:reason;size:255;"`         // Failure reason
+}
+
+func (a LoginHistory) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a LoginHistory) TableName() string {
+	return a.Model.TableName("login_history")
+}
+
+// ToSchemaLoginHistory - Convert to login history object
+func (a LoginHistory) ToSchemaLoginHistory() *schema.LoginHistory {
+	item := &schema.LoginHistory{
+		UserUUID:  *a.UserUUID,
+		UserName:  *a.UserName,
+		IP:        *a.IP,
+		UserAgent: *a.UserAgent,
+		Success:   *a.Success,
+		Reason:    *a.Reason,
+		CreatedAt: a.CreatedAt,
+	}
+	return item
+}
+
+// LoginHistories - Login history entity list
+type LoginHistories []*LoginHistory
+
+// ToSchemaLoginHistories - Convert to login history object list
+func (a LoginHistories) ToSchemaLoginHistories() []*schema.LoginHistory {
+	list := make([]*schema.LoginHistory, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaLoginHistory()
+	}
+	return list
+}
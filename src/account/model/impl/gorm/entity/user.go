@@ -2,6 +2,7 @@ package entity
 
 import (
 	"context"
+	"time"
 
 	"github.com/MayCMF/core/src/account/schema"
 	"github.com/MayCMF/core/src/common/entity"
@@ -24,14 +25,19 @@ type SchemaUser schema.User
 // ToUser - Convert to user entity
 func (a SchemaUser) ToUser() *User {
 	item := &User{
-		UUID: a.UUID,
-		UserName: &a.UserName,
-		RealName: &a.RealName,
-		Password: &a.Password,
-		Status:   &a.Status,
-		Creator:  &a.Creator,
-		Email:    &a.Email,
-		Phone:    &a.Phone,
+		UUID:          a.UUID,
+		UserName:      &a.UserName,
+		RealName:      &a.RealName,
+		Password:      &a.Password,
+		Status:        &a.Status,
+		Creator:       &a.Creator,
+		Email:         &a.Email,
+		EmailVerified: &a.EmailVerified,
+		Phone:         &a.Phone,
+		Avatar:        &a.Avatar,
+	}
+	if !a.PasswordChangedAt.IsZero() {
+		item.PasswordChangedAt = &a.PasswordChangedAt
 	}
 	return item
 }
@@ -51,14 +57,17 @@ func (a SchemaUser) ToUserRoles() []*UserRole {
 // User - User entity
 type User struct {
 	entity.Model
-	UUID string  `gorm:"column:record_id;size:36;index;"` // Record internal code
-	UserName *string `gorm:"column:user_name;size:64;index;"` // UserName
-	RealName *string `gorm:"column:real_name;size:64;index;"` // RealName
-	Password *string `gorm:"column:password;size:40;"`        // Password (sha1 (md5 (plain text)) encryption)
-	Email    *string `gorm:"column:email;not null;unique"`    // Email
-	Phone    *string `gorm:"column:phone;size:20;index;"`     // Phone
-	Status   *int    `gorm:"column:status;index;"`            // Status (1: Enable 2: Disable)
-	Creator  *string `gorm:"column:creator;size:36;"`         // Creator
+	UUID              string     `gorm:"column:record_id;size:36;index;"` // Record internal code
+	UserName          *string    `gorm:"column:user_name;size:64;index;"` // UserName
+	RealName          *string    `gorm:"column:real_name;size:64;index;"` // RealName
+	Password          *string    `gorm:"column:password;size:255;"`       // Password (argon2id or bcrypt hash)
+	Email             *string    `gorm:"column:email;not null;unique"`    // Email
+	EmailVerified     *bool      `gorm:"column:email_verified;"`          // The user confirmed the email address
+	Phone             *string    `gorm:"column:phone;size:20;index;"`     // Phone
+	Avatar            *string    `gorm:"column:avatar;size:36;"`          // Avatar file UUID
+	Status            *int       `gorm:"column:status;index;"`            // Status (1: Enable 2: Disable)
+	Creator           *string    `gorm:"column:creator;size:36;"`         // Creator
+	PasswordChangedAt *time.Time `gorm:"column:password_changed_at;"`     // Time of the last password change
 }
 
 func (a User) String() string {
@@ -73,7 +82,7 @@ func (a User) TableName() string {
 // ToSchemaUser - Convert to user object
 func (a User) ToSchemaUser() *schema.User {
 	item := &schema.User{
-		UUID:  a.UUID,
+		UUID:      a.UUID,
 		UserName:  *a.UserName,
 		RealName:  *a.RealName,
 		Password:  *a.Password,
@@ -83,6 +92,16 @@ func (a User) ToSchemaUser() *schema.User {
 		Phone:     *a.Phone,
 		CreatedAt: a.CreatedAt,
 	}
+	// Users created before avatars were added have none
+	if a.Avatar != nil {
+		item.Avatar = *a.Avatar
+	}
+	if a.EmailVerified != nil {
+		item.EmailVerified = *a.EmailVerified
+	}
+	if a.PasswordChangedAt != nil {
+		item.PasswordChangedAt = *a.PasswordChangedAt
+	}
 	return item
 }
 
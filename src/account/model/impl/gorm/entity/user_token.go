@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/jinzhu/gorm"
+)
+
+// GetUserTokenDB - Get user token storage
+func GetUserTokenDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, UserToken{})
+}
+
+// SchemaUserToken - User token object
+type SchemaUserToken schema.UserToken
+
+// ToUserToken - Convert to user token entity
+func (a SchemaUserToken) ToUserToken() *UserToken {
+	item := &UserToken{
+		UUID:      a.UUID,
+		Kind:      &a.Kind,
+		UserUUID:  &a.UserUUID,
+		Email:     &a.Email,
+		TokenHash: &a.TokenHash,
+		ExpiresAt: &a.ExpiresAt,
+	}
+	return item
+}
+
+// UserToken - User token entity
+type UserToken struct {
+	entity.Model
+	UUID      string     `gorm:"column:record_id;size:36;index;"`  // Record internal code
+	Kind      *string    `gorm:"column:kind;size:32;index;"`       // Kind (password_reset/email_verification)
+	UserUUID  *string    `gorm:"column:user_uuid;size:36;index;"`  // User
+	Email     *string    `gorm:"column:email;size:255;"`           // Email address the token was mailed to
+	TokenHash *string    `gorm:"column:token_hash;size:64;index;"` // SHA-256 of the token
+	ExpiresAt *time.Time `gorm:"column:expires_at;index;"`         // Expiration time
+}
+
+func (a UserToken) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a UserToken) TableName() string {
+	return a.Model.TableName("user_token")
+}
+
+// ToSchemaUserToken - Convert to user token object
+func (a UserToken) ToSchemaUserToken() *schema.UserToken {
+	item := &schema.UserToken{
+		UUID:      a.UUID,
+		Kind:      *a.Kind,
+		UserUUID:  *a.UserUUID,
+		Email:     *a.Email,
+		TokenHash: *a.TokenHash,
+		ExpiresAt: *a.ExpiresAt,
+	}
+	return item
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/jinzhu/gorm"
+)
+
+// NewAccessToken - Create an access token storage instance
+func NewAccessToken(db *gorm.DB) *AccessToken {
+	return &AccessToken{db}
+}
+
+// AccessToken - Access token storage
+type AccessToken struct {
+	db *gorm.DB
+}
+
+// Query - Query data
+func (a *AccessToken) Query(ctx context.Context, params schema.AccessTokenQueryParam, opts ...schema.AccessTokenQueryOptions) (*schema.AccessTokenQueryResult, error) {
+	db := entity.GetAccessTokenDB(ctx, a.db)
+	if v := params.UserUUID; v != "" {
+		db = db.Where("user_uuid=?", v)
+	}
+	if v := params.Kind; v != "" {
+		db = db.Where("kind=?", v)
+	}
+	if v := params.TokenHash; v != "" {
+		db = db.Where("token_hash=?", v)
+	}
+	db = db.Order("id DESC")
+
+	var opt schema.AccessTokenQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	var list entity.AccessTokens
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	qr := &schema.AccessTokenQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaAccessTokens(),
+	}
+	return qr, nil
+}
+
+// Get - Query specified data
+func (a *AccessToken) Get(ctx context.Context, UUID string) (*schema.AccessToken, error) {
+	var item entity.AccessToken
+	ok, err := model.FindOne(ctx, entity.GetAccessTokenDB(ctx, a.db).Where("record_id=?", UUID), &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item.ToSchemaAccessToken(), nil
+}
+
+// Create - Create data
+func (a *AccessToken) Create(ctx context.Context, item schema.AccessToken) error {
+	sitem := entity.SchemaAccessToken(item)
+	result := entity.GetAccessTokenDB(ctx, a.db).Create(sitem.ToAccessToken())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete - Delete data
+func (a *AccessToken) Delete(ctx context.Context, UUID string) error {
+	result := entity.GetAccessTokenDB(ctx, a.db).Where("record_id=?", UUID).Delete(entity.AccessToken{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// UpdateLastUsed - Update the time of the last authenticated request
+func (a *AccessToken) UpdateLastUsed(ctx context.Context, UUID string, lastUsedAt time.Time) error {
+	result := entity.GetAccessTokenDB(ctx, a.db).Where("record_id=?", UUID).Update("last_used_at", lastUsedAt)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/jinzhu/gorm"
+)
+
+// NewInvitation - Create an invitation storage instance
+func NewInvitation(db *gorm.DB) *Invitation {
+	return &Invitation{db}
+}
+
+// Invitation - Invitation storage
+type Invitation struct {
+	db *gorm.DB
+}
+
+// Query - Query data
+func (a *Invitation) Query(ctx context.Context, params schema.InvitationQueryParam, opts ...schema.InvitationQueryOptions) (*schema.InvitationQueryResult, error) {
+	db := entity.GetInvitationDB(ctx, a.db)
+	if v := params.TokenHash; v != "" {
+		db = db.Where("token_hash=?", v)
+	}
+	db = db.Order("id DESC")
+
+	var opt schema.InvitationQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	var list entity.Invitations
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	qr := &schema.InvitationQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaInvitations(),
+	}
+	return qr, nil
+}
+
+// Get - Query specified data
+func (a *Invitation) Get(ctx context.Context, UUID string) (*schema.Invitation, error) {
+	var item entity.Invitation
+	ok, err := model.FindOne(ctx, entity.GetInvitationDB(ctx, a.db).Where("record_id=?", UUID), &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item.ToSchemaInvitation(), nil
+}
+
+// Create - Create data
+func (a *Invitation) Create(ctx context.Context, item schema.Invitation) error {
+	sitem := entity.SchemaInvitation(item)
+	result := entity.GetInvitationDB(ctx, a.db).Create(sitem.ToInvitation())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete - Delete data
+func (a *Invitation) Delete(ctx context.Context, UUID string) error {
+	result := entity.GetInvitationDB(ctx, a.db).Where("record_id=?", UUID).Delete(entity.Invitation{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// IncUses - Count a sign-up unless the invitation expired or reached its
+// limit, concurrent sign-ups never exceed the limit
+func (a *Invitation) IncUses(ctx context.Context, UUID string) (bool, error) {
+	result := entity.GetInvitationDB(ctx, a.db).
+		Where("record_id=? AND expires_at>? AND (max_uses=0 OR uses<max_uses)", UUID, time.Now()).
+		Update("uses", gorm.Expr("uses+1"))
+	if err := result.Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DecUses - Uncount a sign-up that failed
+func (a *Invitation) DecUses(ctx context.Context, UUID string) error {
+	result := entity.GetInvitationDB(ctx, a.db).Where("record_id=? AND uses>0", UUID).Update("uses", gorm.Expr("uses-1"))
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
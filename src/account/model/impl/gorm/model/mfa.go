@@ -0,0 +1,64 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/jinzhu/gorm"
+)
+
+// NewUserMFA - Create a user two-factor authentication storage instance
+func NewUserMFA(db *gorm.DB) *UserMFA {
+	return &UserMFA{db}
+}
+
+// UserMFA - User two-factor authentication storage
+type UserMFA struct {
+	db *gorm.DB
+}
+
+// Get - Query the two-factor authentication of a user
+func (a *UserMFA) Get(ctx context.Context, userUUID string) (*schema.UserMFA, error) {
+	var item entity.UserMFA
+	ok, err := model.FindOne(ctx, entity.GetUserMFADB(ctx, a.db).Where("user_uuid=?", userUUID), &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item.ToSchemaUserMFA(), nil
+}
+
+// Save - Create or replace the two-factor authentication of a user
+func (a *UserMFA) Save(ctx context.Context, item schema.UserMFA) error {
+	return model.ExecTrans(ctx, a.db, func(ctx context.Context) error {
+		var count int
+		result := entity.GetUserMFADB(ctx, a.db).Where("user_uuid=?", item.UserUUID).Count(&count)
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		sitem := entity.SchemaUserMFA(item).ToUserMFA()
+		if count > 0 {
+			result = entity.GetUserMFADB(ctx, a.db).Where("user_uuid=?", item.UserUUID).Omit("user_uuid").Updates(sitem)
+		} else {
+			result = entity.GetUserMFADB(ctx, a.db).Create(sitem)
+		}
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return nil
+	})
+}
+
+// Delete - Delete the two-factor authentication of a user
+func (a *UserMFA) Delete(ctx context.Context, userUUID string) error {
+	result := entity.GetUserMFADB(ctx, a.db).Where("user_uuid=?", userUUID).Delete(entity.UserMFA{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
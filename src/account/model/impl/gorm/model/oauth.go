@@ -0,0 +1,309 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/jinzhu/gorm"
+)
+
+// NewOAuthClient - Create an OAuth2 client storage instance
+func NewOAuthClient(db *gorm.DB) *OAuthClient {
+	return &OAuthClient{db}
+}
+
+// OAuthClient - OAuth2 client storage
+type OAuthClient struct {
+	db *gorm.DB
+}
+
+// Query - Query data
+func (a *OAuthClient) Query(ctx context.Context, params schema.OAuthClientQueryParam, opts ...schema.OAuthClientQueryOptions) (*schema.OAuthClientQueryResult, error) {
+	db := entity.GetOAuthClientDB(ctx, a.db)
+	if v := params.LikeName; v != "" {
+		db = db.Where("name LIKE ?", "%"+v+"%")
+	}
+	if v := params.UserUUID; v != "" {
+		db = db.Where("user_uuid=?", v)
+	}
+	db = db.Order("id DESC")
+
+	var opt schema.OAuthClientQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	var list entity.OAuthClients
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	qr := &schema.OAuthClientQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaOAuthClients(),
+	}
+	return qr, nil
+}
+
+// Get - Query specified data
+func (a *OAuthClient) Get(ctx context.Context, UUID string) (*schema.OAuthClient, error) {
+	var item entity.OAuthClient
+	ok, err := model.FindOne(ctx, entity.GetOAuthClientDB(ctx, a.db).Where("record_id=?", UUID), &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item.ToSchemaOAuthClient(), nil
+}
+
+// Create - Create data
+func (a *OAuthClient) Create(ctx context.Context, item schema.OAuthClient) error {
+	sitem := entity.SchemaOAuthClient(item)
+	result := entity.GetOAuthClientDB(ctx, a.db).Create(sitem.ToOAuthClient())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update - Update data, the secret is kept
+func (a *OAuthClient) Update(ctx context.Context, UUID string, item schema.OAuthClient) error {
+	sitem := entity.SchemaOAuthClient(item)
+	result := entity.GetOAuthClientDB(ctx, a.db).Where("record_id=?", UUID).Omit("record_id", "secret_hash", "creator").Updates(sitem.ToOAuthClient())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// UpdateSecret - Update the secret
+func (a *OAuthClient) UpdateSecret(ctx context.Context, UUID, secretHash string) error {
+	result := entity.GetOAuthClientDB(ctx, a.db).Where("record_id=?", UUID).Update("secret_hash", secretHash)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete - Delete data
+func (a *OAuthClient) Delete(ctx context.Context, UUID string) error {
+	result := entity.GetOAuthClientDB(ctx, a.db).Where("record_id=?", UUID).Delete(entity.OAuthClient{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// NewOAuthScope - Create an OAuth2 scope storage instance
+func NewOAuthScope(db *gorm.DB) *OAuthScope {
+	return &OAuthScope{db}
+}
+
+// OAuthScope - OAuth2 scope storage
+type OAuthScope struct {
+	db *gorm.DB
+}
+
+// Query - Query data
+func (a *OAuthScope) Query(ctx context.Context, params schema.OAuthScopeQueryParam, opts ...schema.OAuthScopeQueryOptions) (*schema.OAuthScopeQueryResult, error) {
+	db := entity.GetOAuthScopeDB(ctx, a.db)
+	if v := params.Name; v != "" {
+		db = db.Where("name=?", v)
+	}
+	if v := params.Names; len(v) > 0 {
+		db = db.Where("name IN(?)", v)
+	}
+	db = db.Order("name")
+
+	var opt schema.OAuthScopeQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	var list entity.OAuthScopes
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	qr := &schema.OAuthScopeQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaOAuthScopes(),
+	}
+	return qr, nil
+}
+
+// Get - Query specified data
+func (a *OAuthScope) Get(ctx context.Context, UUID string) (*schema.OAuthScope, error) {
+	var item entity.OAuthScope
+	ok, err := model.FindOne(ctx, entity.GetOAuthScopeDB(ctx, a.db).Where("record_id=?", UUID), &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item.ToSchemaOAuthScope(), nil
+}
+
+// Create - Create data
+func (a *OAuthScope) Create(ctx context.Context, item schema.OAuthScope) error {
+	sitem := entity.SchemaOAuthScope(item)
+	result := entity.GetOAuthScopeDB(ctx, a.db).Create(sitem.ToOAuthScope())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update - Update data
+func (a *OAuthScope) Update(ctx context.Context, UUID string, item schema.OAuthScope) error {
+	sitem := entity.SchemaOAuthScope(item)
+	result := entity.GetOAuthScopeDB(ctx, a.db).Where("record_id=?", UUID).Omit("record_id", "creator").Updates(sitem.ToOAuthScope())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete - Delete data
+func (a *OAuthScope) Delete(ctx context.Context, UUID string) error {
+	result := entity.GetOAuthScopeDB(ctx, a.db).Where("record_id=?", UUID).Delete(entity.OAuthScope{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// NewOAuthGrant - Create an OAuth2 grant storage instance
+func NewOAuthGrant(db *gorm.DB) *OAuthGrant {
+	return &OAuthGrant{db}
+}
+
+// OAuthGrant - OAuth2 grant storage
+type OAuthGrant struct {
+	db *gorm.DB
+}
+
+// Query - Query data
+func (a *OAuthGrant) Query(ctx context.Context, params schema.OAuthGrantQueryParam) (schema.OAuthGrants, error) {
+	db := entity.GetOAuthGrantDB(ctx, a.db)
+	if v := params.ClientUUID; v != "" {
+		db = db.Where("client_uuid=?", v)
+	}
+	if v := params.UserUUID; v != "" {
+		db = db.Where("user_uuid=?", v)
+	}
+	db = db.Order("id DESC")
+
+	var list entity.OAuthGrants
+	result := db.Find(&list)
+	if err := result.Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return list.ToSchemaOAuthGrants(), nil
+}
+
+// Get - Query specified data
+func (a *OAuthGrant) Get(ctx context.Context, UUID string) (*schema.OAuthGrant, error) {
+	var item entity.OAuthGrant
+	ok, err := model.FindOne(ctx, entity.GetOAuthGrantDB(ctx, a.db).Where("record_id=?", UUID), &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item.ToSchemaOAuthGrant(), nil
+}
+
+// Create - Create data
+func (a *OAuthGrant) Create(ctx context.Context, item schema.OAuthGrant) error {
+	sitem := entity.SchemaOAuthGrant(item)
+	result := entity.GetOAuthGrantDB(ctx, a.db).Create(sitem.ToOAuthGrant())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// UpdateScopes - Update the granted scopes
+func (a *OAuthGrant) UpdateScopes(ctx context.Context, UUID string, scopes []string) error {
+	result := entity.GetOAuthGrantDB(ctx, a.db).Where("record_id=?", UUID).Update("scopes", util.JSONMarshalToString(scopes))
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete - Delete data
+func (a *OAuthGrant) Delete(ctx context.Context, UUID string) error {
+	result := entity.GetOAuthGrantDB(ctx, a.db).Where("record_id=?", UUID).Delete(entity.OAuthGrant{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DeleteByClient - Delete the grants of a client
+func (a *OAuthGrant) DeleteByClient(ctx context.Context, clientUUID string) error {
+	result := entity.GetOAuthGrantDB(ctx, a.db).Where("client_uuid=?", clientUUID).Delete(entity.OAuthGrant{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// NewOAuthCode - Create an authorization code storage instance
+func NewOAuthCode(db *gorm.DB) *OAuthCode {
+	return &OAuthCode{db}
+}
+
+// OAuthCode - Authorization code storage
+type OAuthCode struct {
+	db *gorm.DB
+}
+
+// Create - Create data
+func (a *OAuthCode) Create(ctx context.Context, item schema.OAuthCode) error {
+	sitem := entity.SchemaOAuthCode(item)
+	result := entity.GetOAuthCodeDB(ctx, a.db).Create(sitem.ToOAuthCode())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Take - Take a code out of the storage, only the request deleting the code
+// gets it so that a code is never exchanged twice
+func (a *OAuthCode) Take(ctx context.Context, codeHash string) (*schema.OAuthCode, error) {
+	var item entity.OAuthCode
+	ok, err := model.FindOne(ctx, entity.GetOAuthCodeDB(ctx, a.db).Where("code_hash=?", codeHash), &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	result := entity.GetOAuthCodeDB(ctx, a.db).Unscoped().Where("id=?", item.ID).Delete(entity.OAuthCode{})
+	if err := result.Error; err != nil {
+		return nil, errors.WithStack(err)
+	} else if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return item.ToSchemaOAuthCode(), nil
+}
+
+// DeleteExpired - Delete the expired codes
+func (a *OAuthCode) DeleteExpired(ctx context.Context) error {
+	result := entity.GetOAuthCodeDB(ctx, a.db).Unscoped().Where("expires_at<?", time.Now()).Delete(entity.OAuthCode{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
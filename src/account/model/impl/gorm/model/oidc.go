@@ -0,0 +1,70 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/jinzhu/gorm"
+)
+
+// NewUserIdentity - Create an external identity storage instance
+func NewUserIdentity(db *gorm.DB) *UserIdentity {
+	return &UserIdentity{db}
+}
+
+// UserIdentity - External identity storage
+type UserIdentity struct {
+	db *gorm.DB
+}
+
+// Query - Query data
+func (a *UserIdentity) Query(ctx context.Context, params schema.UserIdentityQueryParam) (schema.UserIdentities, error) {
+	db := entity.GetUserIdentityDB(ctx, a.db)
+	if v := params.UserUUID; v != "" {
+		db = db.Where("user_uuid=?", v)
+	}
+	if v := params.Provider; v != "" {
+		db = db.Where("provider=?", v)
+	}
+	if v := params.Subject; v != "" {
+		db = db.Where("subject=?", v)
+	}
+	db = db.Order("id DESC")
+
+	var list entity.UserIdentities
+	result := db.Find(&list)
+	if err := result.Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return list.ToSchemaUserIdentities(), nil
+}
+
+// Create - Create data
+func (a *UserIdentity) Create(ctx context.Context, item schema.UserIdentity) error {
+	sitem := entity.SchemaUserIdentity(item)
+	result := entity.GetUserIdentityDB(ctx, a.db).Create(sitem.ToUserIdentity())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete - Delete data
+func (a *UserIdentity) Delete(ctx context.Context, UUID string) error {
+	result := entity.GetUserIdentityDB(ctx, a.db).Where("record_id=?", UUID).Delete(entity.UserIdentity{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DeleteByUser - Delete the identities of a user
+func (a *UserIdentity) DeleteByUser(ctx context.Context, userUUID string) error {
+	result := entity.GetUserIdentityDB(ctx, a.db).Where("user_uuid=?", userUUID).Delete(entity.UserIdentity{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
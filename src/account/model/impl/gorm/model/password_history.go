@@ -0,0 +1,67 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/jinzhu/gorm"
+)
+
+// NewPasswordHistory - Create a password history storage instance
+func NewPasswordHistory(db *gorm.DB) *PasswordHistory {
+	return &PasswordHistory{db}
+}
+
+// PasswordHistory - Password history storage
+type PasswordHistory struct {
+	db *gorm.DB
+}
+
+// Create - Add a password hash of a user
+func (a *PasswordHistory) Create(ctx context.Context, userUUID, password string) error {
+	result := entity.GetPasswordHistoryDB(ctx, a.db).Create(&entity.PasswordHistory{
+		UserUUID: userUUID,
+		Password: password,
+	})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Query - Get the last password hashes of a user, newest first
+func (a *PasswordHistory) Query(ctx context.Context, userUUID string, limit int) ([]string, error) {
+	var list []*entity.PasswordHistory
+	result := entity.GetPasswordHistoryDB(ctx, a.db).Where("user_uuid=?", userUUID).Order("id DESC").Limit(limit).Find(&list)
+	if err := result.Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	passwords := make([]string, len(list))
+	for i, item := range list {
+		passwords[i] = item.Password
+	}
+	return passwords, nil
+}
+
+// Prune - Delete all but the last password hashes of a user
+func (a *PasswordHistory) Prune(ctx context.Context, userUUID string, keep int) error {
+	var list []*entity.PasswordHistory
+	result := entity.GetPasswordHistoryDB(ctx, a.db).Select("id").Where("user_uuid=?", userUUID).Order("id DESC").Find(&list)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	} else if len(list) <= keep {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(list)-keep)
+	for _, item := range list[keep:] {
+		ids = append(ids, item.ID)
+	}
+	result = entity.GetPasswordHistoryDB(ctx, a.db).Unscoped().Where("id IN(?)", ids).Delete(entity.PasswordHistory{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
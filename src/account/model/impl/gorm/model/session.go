@@ -0,0 +1,125 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/jinzhu/gorm"
+)
+
+// NewUserSession - Create a user session storage instance
+func NewUserSession(db *gorm.DB) *UserSession {
+	return &UserSession{db}
+}
+
+// UserSession - User session storage
+type UserSession struct {
+	db *gorm.DB
+}
+
+// Query - Query the unexpired sessions of a user, most recently used first
+func (a *UserSession) Query(ctx context.Context, userUUID string) (schema.UserSessions, error) {
+	db := entity.GetUserSessionDB(ctx, a.db).Where("user_uuid=? AND expires_at>?", userUUID, time.Now())
+	db = db.Order("last_seen_at DESC")
+
+	var list entity.UserSessions
+	result := db.Find(&list)
+	if err := result.Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return list.ToSchemaUserSessions(), nil
+}
+
+// Get - Query specified data
+func (a *UserSession) Get(ctx context.Context, UUID string) (*schema.UserSession, error) {
+	var item entity.UserSession
+	ok, err := model.FindOne(ctx, entity.GetUserSessionDB(ctx, a.db).Where("record_id=?", UUID), &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+	return item.ToSchemaUserSession(), nil
+}
+
+// Create - Create data
+func (a *UserSession) Create(ctx context.Context, item schema.UserSession) error {
+	sitem := entity.SchemaUserSession(item)
+	result := entity.GetUserSessionDB(ctx, a.db).Create(sitem.ToUserSession())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update - Update data
+func (a *UserSession) Update(ctx context.Context, UUID string, item schema.UserSession) error {
+	sitem := entity.SchemaUserSession(item)
+	result := entity.GetUserSessionDB(ctx, a.db).Where("record_id=?", UUID).Omit("record_id", "user_uuid").Updates(sitem.ToUserSession())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete - Delete data
+func (a *UserSession) Delete(ctx context.Context, UUID string) error {
+	result := entity.GetUserSessionDB(ctx, a.db).Where("record_id=?", UUID).Delete(entity.UserSession{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// NewLoginHistory - Create a login history storage instance
+func NewLoginHistory(db *gorm.DB) *LoginHistory {
+	return &LoginHistory{db}
+}
+
+// LoginHistory - Login history storage
+type LoginHistory struct {
+	db *gorm.DB
+}
+
+// Query - Query data, most recent first
+func (a *LoginHistory) Query(ctx context.Context, params schema.LoginHistoryQueryParam, opts ...schema.LoginHistoryQueryOptions) (*schema.LoginHistoryQueryResult, error) {
+	db := entity.GetLoginHistoryDB(ctx, a.db)
+	if v := params.UserUUID; v != "" {
+		db = db.Where("user_uuid=?", v)
+	}
+	if v := params.Success; v != nil {
+		db = db.Where("success=?", *v)
+	}
+	db = db.Order("id DESC")
+
+	var opt schema.LoginHistoryQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	var list entity.LoginHistories
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	qr := &schema.LoginHistoryQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaLoginHistories(),
+	}
+	return qr, nil
+}
+
+// Create - Create data
+func (a *LoginHistory) Create(ctx context.Context, item schema.LoginHistory) error {
+	sitem := entity.SchemaLoginHistory(item)
+	result := entity.GetLoginHistoryDB(ctx, a.db).Create(sitem.ToLoginHistory())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"time"
 
 	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
 	"github.com/MayCMF/core/src/account/schema"
@@ -40,6 +41,9 @@ func (a *User) Query(ctx context.Context, params schema.UserQueryParam, opts ...
 	if v := params.LikeRealName; v != "" {
 		db = db.Where("real_name LIKE ?", "%"+v+"%")
 	}
+	if v := params.Email; v != "" {
+		db = db.Where("email=?", v)
+	}
 	if v := params.Status; v > 0 {
 		db = db.Where("status=?", v)
 	}
@@ -224,6 +228,16 @@ func (a *User) Delete(ctx context.Context, UUID string) error {
 			return errors.WithStack(err)
 		}
 
+		result = entity.GetUserMFADB(ctx, a.db).Where("user_uuid=?", UUID).Delete(entity.UserMFA{})
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		result = entity.GetPasswordHistoryDB(ctx, a.db).Unscoped().Where("user_uuid=?", UUID).Delete(entity.PasswordHistory{})
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+
 		return nil
 	})
 }
@@ -239,6 +253,18 @@ func (a *User) UpdateStatus(ctx context.Context, UUID string, status int) error
 
 // UpdatePassword - Update password
 func (a *User) UpdatePassword(ctx context.Context, UUID, password string) error {
+	result := entity.GetUserDB(ctx, a.db).Where("record_id=?", UUID).Updates(map[string]interface{}{
+		"password":            password,
+		"password_changed_at": time.Now(),
+	})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// UpdatePasswordHash - Replace the hash of the same password
+func (a *User) UpdatePasswordHash(ctx context.Context, UUID, password string) error {
 	result := entity.GetUserDB(ctx, a.db).Where("record_id=?", UUID).Update("password", password)
 	if err := result.Error; err != nil {
 		return errors.WithStack(err)
@@ -246,6 +272,15 @@ func (a *User) UpdatePassword(ctx context.Context, UUID, password string) error
 	return nil
 }
 
+// VerifyEmail - Mark the email address as verified if it is still the user's one
+func (a *User) VerifyEmail(ctx context.Context, UUID, email string) (bool, error) {
+	result := entity.GetUserDB(ctx, a.db).Where("record_id=? AND email=?", UUID, email).Update("email_verified", true)
+	if err := result.Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (a *User) queryRoles(ctx context.Context, userUUIDs ...string) (entity.UserRoles, error) {
 	var list entity.UserRoles
 	result := entity.GetUserRoleDB(ctx, a.db).Where("user_uuid IN(?)", userUUIDs).Find(&list)
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/jinzhu/gorm"
+)
+
+// NewUserToken - Create a user token storage instance
+func NewUserToken(db *gorm.DB) *UserToken {
+	return &UserToken{db}
+}
+
+// UserToken - User token storage
+type UserToken struct {
+	db *gorm.DB
+}
+
+// Create - Create data
+func (a *UserToken) Create(ctx context.Context, item schema.UserToken) error {
+	sitem := entity.SchemaUserToken(item)
+	result := entity.GetUserTokenDB(ctx, a.db).Create(sitem.ToUserToken())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Take - Take a token out of the storage, only the request deleting the
+// token gets it so that a token is never used twice
+func (a *UserToken) Take(ctx context.Context, kind, tokenHash string) (*schema.UserToken, error) {
+	var item entity.UserToken
+	db := entity.GetUserTokenDB(ctx, a.db).Where("kind=? AND token_hash=?", kind, tokenHash)
+	ok, err := model.FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	result := entity.GetUserTokenDB(ctx, a.db).Unscoped().Where("id=?", item.ID).Delete(entity.UserToken{})
+	if err := result.Error; err != nil {
+		return nil, errors.WithStack(err)
+	} else if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return item.ToSchemaUserToken(), nil
+}
+
+// DeleteByUser - Delete the tokens of a kind of a user
+func (a *UserToken) DeleteByUser(ctx context.Context, kind, userUUID string) error {
+	result := entity.GetUserTokenDB(ctx, a.db).Unscoped().Where("kind=? AND user_uuid=?", kind, userUUID).Delete(entity.UserToken{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DeleteExpired - Delete the expired tokens
+func (a *UserToken) DeleteExpired(ctx context.Context) error {
+	result := entity.GetUserTokenDB(ctx, a.db).Unscoped().Where("expires_at<?", time.Now()).Delete(entity.UserToken{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IInvitation - Invitation storage interface
+type IInvitation interface {
+	// Query data
+	Query(ctx context.Context, params schema.InvitationQueryParam, opts ...schema.InvitationQueryOptions) (*schema.InvitationQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.Invitation, error)
+	// Create data
+	Create(ctx context.Context, item schema.Invitation) error
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+	// Count a sign-up unless the invitation expired or reached its limit
+	IncUses(ctx context.Context, UUID string) (bool, error)
+	// Uncount a sign-up that failed
+	DecUses(ctx context.Context, UUID string) error
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IUserMFA - User two-factor authentication storage interface
+type IUserMFA interface {
+	// Query the two-factor authentication of a user
+	Get(ctx context.Context, userUUID string) (*schema.UserMFA, error)
+	// Create or replace the two-factor authentication of a user
+	Save(ctx context.Context, item schema.UserMFA) error
+	// Delete the two-factor authentication of a user
+	Delete(ctx context.Context, userUUID string) error
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IOAuthClient - OAuth2 client storage interface
+type IOAuthClient interface {
+	// Query data
+	Query(ctx context.Context, params schema.OAuthClientQueryParam, opts ...schema.OAuthClientQueryOptions) (*schema.OAuthClientQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.OAuthClient, error)
+	// Create data
+	Create(ctx context.Context, item schema.OAuthClient) error
+	// Update data
+	Update(ctx context.Context, UUID string, item schema.OAuthClient) error
+	// Update the secret
+	UpdateSecret(ctx context.Context, UUID, secretHash string) error
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+}
+
+// IOAuthScope - OAuth2 scope storage interface
+type IOAuthScope interface {
+	// Query data
+	Query(ctx context.Context, params schema.OAuthScopeQueryParam, opts ...schema.OAuthScopeQueryOptions) (*schema.OAuthScopeQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.OAuthScope, error)
+	// Create data
+	Create(ctx context.Context, item schema.OAuthScope) error
+	// Update data
+	Update(ctx context.Context, UUID string, item schema.OAuthScope) error
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+}
+
+// IOAuthGrant - OAuth2 grant storage interface
+type IOAuthGrant interface {
+	// Query data
+	Query(ctx context.Context, params schema.OAuthGrantQueryParam) (schema.OAuthGrants, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.OAuthGrant, error)
+	// Create data
+	Create(ctx context.Context, item schema.OAuthGrant) error
+	// Update the granted scopes
+	UpdateScopes(ctx context.Context, UUID string, scopes []string) error
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+	// Delete the grants of a client
+	DeleteByClient(ctx context.Context, clientUUID string) error
+}
+
+// IOAuthCode - Authorization code storage interface
+type IOAuthCode interface {
+	// Create data
+	Create(ctx context.Context, item schema.OAuthCode) error
+	// Take a code out of the storage, a code is only taken once
+	Take(ctx context.Context, codeHash string) (*schema.OAuthCode, error)
+	// Delete the expired codes
+	DeleteExpired(ctx context.Context) error
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IUserIdentity - External identity storage interface
+type IUserIdentity interface {
+	// Query data
+	Query(ctx context.Context, params schema.UserIdentityQueryParam) (schema.UserIdentities, error)
+	// Create data
+	Create(ctx context.Context, item schema.UserIdentity) error
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+	// Delete the identities of a user
+	DeleteByUser(ctx context.Context, userUUID string) error
+}
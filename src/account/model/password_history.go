@@ -0,0 +1,15 @@
+package model
+
+import (
+	"context"
+)
+
+// IPasswordHistory - Password history storage interface
+type IPasswordHistory interface {
+	// Add a password hash of a user
+	Create(ctx context.Context, userUUID, password string) error
+	// Get the last password hashes of a user, newest first
+	Query(ctx context.Context, userUUID string, limit int) ([]string, error)
+	// Delete all but the last password hashes of a user
+	Prune(ctx context.Context, userUUID string, keep int) error
+}
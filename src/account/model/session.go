@@ -0,0 +1,29 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IUserSession - User session storage interface
+type IUserSession interface {
+	// Query the unexpired sessions of a user
+	Query(ctx context.Context, userUUID string) (schema.UserSessions, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.UserSession, error)
+	// Create data
+	Create(ctx context.Context, item schema.UserSession) error
+	// Update data
+	Update(ctx context.Context, UUID string, item schema.UserSession) error
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+}
+
+// ILoginHistory - Login history storage interface
+type ILoginHistory interface {
+	// Query data
+	Query(ctx context.Context, params schema.LoginHistoryQueryParam, opts ...schema.LoginHistoryQueryOptions) (*schema.LoginHistoryQueryResult, error)
+	// Create data
+	Create(ctx context.Context, item schema.LoginHistory) error
+}
@@ -20,6 +20,10 @@ type IUser interface {
 	Delete(ctx context.Context, UUID string) error
 	// Update status
 	UpdateStatus(ctx context.Context, UUID string, status int) error
-	// Update password
+	// Update password, the time of the password change is recorded
 	UpdatePassword(ctx context.Context, UUID, password string) error
+	// Replace the hash of the same password
+	UpdatePasswordHash(ctx context.Context, UUID, password string) error
+	// Mark the email address as verified if it is still the user's one
+	VerifyEmail(ctx context.Context, UUID, email string) (bool, error)
 }
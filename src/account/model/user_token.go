@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/account/schema"
+)
+
+// IUserToken - User token storage interface
+type IUserToken interface {
+	// Create data
+	Create(ctx context.Context, item schema.UserToken) error
+	// Take a token out of the storage, a token is only taken once
+	Take(ctx context.Context, kind, tokenHash string) (*schema.UserToken, error)
+	// Delete the tokens of a kind of a user
+	DeleteByUser(ctx context.Context, kind, userUUID string) error
+	// Delete the expired tokens
+	DeleteExpired(ctx context.Context) error
+}
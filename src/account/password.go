@@ -0,0 +1,48 @@
+package account
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/common/auth/password"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/logger"
+)
+
+// InitPassword - Initialize user password hashing
+func InitPassword() *password.Password {
+	cfg := config.Global().Password
+
+	if cfg.Algorithm == "bcrypt" {
+		return password.New(password.NewBcrypt(cfg.BcryptCost))
+	}
+
+	h := password.NewArgon2id()
+	if cfg.Argon2Memory > 0 {
+		h.Memory = uint32(cfg.Argon2Memory)
+	}
+	if cfg.Argon2Iterations > 0 {
+		h.Iterations = uint32(cfg.Argon2Iterations)
+	}
+	if cfg.Argon2Parallelism > 0 && cfg.Argon2Parallelism < 256 {
+		h.Parallelism = uint8(cfg.Argon2Parallelism)
+	}
+	return password.New(h)
+}
+
+// InitPasswordPolicy - Initialize the password policy, a missing banned
+// password list only leaves the built-in list in place
+func InitPasswordPolicy() *password.Policy {
+	cfg := config.Global().Password
+
+	policy := password.NewPolicy()
+	policy.MinLength = cfg.MinLength
+	policy.MinClasses = cfg.MinClasses
+	policy.MinScore = cfg.MinScore
+
+	if cfg.BannedFile != "" {
+		if err := policy.LoadBanned(cfg.BannedFile); err != nil {
+			logger.Warnf(context.Background(), "Load banned passwords: %s", err.Error())
+		}
+	}
+	return policy
+}
@@ -19,17 +19,34 @@ func RegisterRouter(app *gin.Engine, container *dig.Container) error {
 	return container.Invoke(func(
 		a auth.Auther,
 		e *casbin.SyncedEnforcer,
+		cAccessToken *controllers.AccessToken,
+		cEmailVerification *controllers.EmailVerification,
+		cInvitation *controllers.Invitation,
+		cJWKS *controllers.JWKS,
 		cLogin *controllers.Login,
+		cMFA *controllers.MFA,
+		cOAuth *controllers.OAuth,
+		cOAuthClient *controllers.OAuthClient,
+		cOAuthScope *controllers.OAuthScope,
+		cOIDC *controllers.OIDC,
+		cPasswordPolicy *controllers.PasswordPolicy,
+		cPasswordReset *controllers.PasswordReset,
 		cPermission *controllers.Permission,
+		cRegistration *controllers.Registration,
 		cRole *controllers.Role,
+		cSession *controllers.Session,
 		cUser *controllers.User,
 	) error {
 
+		// [PUBLIC]/.well-known/jwks.json
+		app.GET("/.well-known/jwks.json", cJWKS.Get)
+
 		g := app.Group("/api")
 
 		// User identity authorization
 		g.Use(middleware.UserAuthMiddleware(a,
-			middleware.AllowPathPrefixSkipper("/api/v1/pub/login"),
+			middleware.AllowPathPrefixSkipper("/api/v1/pub/login", "/api/v1/pub/refresh-token", "/api/v1/pub/oauth/token",
+				"/api/v1/pub/password", "/api/v1/pub/email", "/api/v1/pub/register"),
 		))
 
 		// Casbin permission check middleware
@@ -50,22 +67,118 @@ func RegisterRouter(app *gin.Engine, container *dig.Container) error {
 					gLogin.GET("captchaid", cLogin.GetCaptcha)
 					gLogin.GET("captcha", cLogin.ResCaptcha)
 					gLogin.POST("", cLogin.Login)
+					gLogin.POST("mfa", cLogin.LoginMFA)
+					gLogin.POST("mfa/enroll", cLogin.LoginMFAEnroll)
+					gLogin.GET("oidc", cOIDC.Providers)
+					gLogin.GET("oidc/:provider", cOIDC.Authorize)
+					gLogin.GET("oidc/:provider/callback", cOIDC.Callback)
 					gLogin.POST("exit", cLogin.Logout)
 				}
 
 				// [PUBLIC]/api/v1/pub/refresh-token
 				pub.POST("/refresh-token", cLogin.RefreshToken)
 
+				// [PUBLIC]/api/v1/pub/password
+				gPassword := pub.Group("password")
+				{
+					gPassword.POST("forgot", cPasswordReset.Forgot)
+					gPassword.POST("reset", cPasswordReset.Reset)
+					gPassword.POST("check", cPasswordPolicy.Check)
+					gPassword.POST("change", cLogin.ChangePassword)
+				}
+
+				// [PUBLIC]/api/v1/pub/email
+				gEmail := pub.Group("email")
+				{
+					gEmail.POST("verify", cEmailVerification.Verify)
+					gEmail.POST("resend", cEmailVerification.ResendTo)
+				}
+
+				// [PUBLIC]/api/v1/pub/register
+				pub.GET("/register", cRegistration.Info)
+				pub.POST("/register", cRegistration.Register)
+
+				// [PUBLIC]/api/v1/pub/oauth
+				gOAuth := pub.Group("oauth")
+				{
+					gOAuth.GET("authorize", middleware.UnscopedTokenMiddleware(), middleware.UnimpersonatedTokenMiddleware(), cOAuth.Authorization)
+					gOAuth.POST("authorize", middleware.UnscopedTokenMiddleware(), middleware.UnimpersonatedTokenMiddleware(), cOAuth.Authorize)
+					gOAuth.POST("token", cOAuth.Token)
+				}
+
 				// [PUBLIC]/api/v1/pub/current
 				gCurrent := pub.Group("current")
+				// Access tokens are limited to their casbin scopes, which
+				// do not cover managing the account of their user
+				gCurrent.Use(middleware.UnscopedTokenMiddleware(
+					middleware.AllowPathPrefixSkipper("/api/v1/pub/current/user", "/api/v1/pub/current/permission.tree"),
+				))
+				// Impersonators only see the account of the user
+				gCurrent.Use(middleware.UnimpersonatedTokenMiddleware(
+					middleware.AllowPathPrefixSkipper("/api/v1/pub/current/user", "/api/v1/pub/current/permission.tree",
+						"/api/v1/pub/current/impersonation"),
+				))
 				{
 					gCurrent.PUT("password", cLogin.UpdatePassword)
 					gCurrent.GET("user", cLogin.GetUserInfo)
+					gCurrent.POST("email/verification", cEmailVerification.Resend)
 					gCurrent.GET("permission.tree", cLogin.QueryUserPermissionTree)
+					gCurrent.GET("mfa", cMFA.Get)
+					gCurrent.POST("mfa", cMFA.Enroll)
+					gCurrent.POST("mfa/confirm", cMFA.Confirm)
+					gCurrent.POST("mfa/disable", cMFA.Disable)
+					gCurrent.POST("mfa/recovery-codes", cMFA.RegenerateRecoveryCodes)
+					gCurrent.GET("sessions", cSession.Query)
+					gCurrent.DELETE("sessions/:id", cSession.Revoke)
+					gCurrent.GET("login-history", cSession.QueryLoginHistory)
+					gCurrent.GET("tokens", cAccessToken.Query)
+					gCurrent.POST("tokens", cAccessToken.Create)
+					gCurrent.DELETE("tokens/:id", cAccessToken.Delete)
+					gCurrent.GET("oauth-grants", cOAuth.QueryGrants)
+					gCurrent.DELETE("oauth-grants/:id", cOAuth.RevokeGrant)
+					gCurrent.DELETE("impersonation", cLogin.EndImpersonation)
 				}
 
 			}
 
+			// [REGISTERED]/api/v1/api-keys
+			gAPIKey := v1.Group("api-keys")
+			{
+				gAPIKey.GET("", cAccessToken.QueryAPIKey)
+				gAPIKey.POST("", cAccessToken.CreateAPIKey)
+				gAPIKey.DELETE(":id", cAccessToken.DeleteAPIKey)
+			}
+
+			// [REGISTERED]/api/v1/invitations
+			gInvitation := v1.Group("invitations")
+			{
+				gInvitation.GET("", cInvitation.Query)
+				gInvitation.GET(":id", cInvitation.Get)
+				gInvitation.POST("", cInvitation.Create)
+				gInvitation.DELETE(":id", cInvitation.Delete)
+			}
+
+			// [REGISTERED]/api/v1/oauth-clients
+			gOAuthClient := v1.Group("oauth-clients")
+			{
+				gOAuthClient.GET("", cOAuthClient.Query)
+				gOAuthClient.GET(":id", cOAuthClient.Get)
+				gOAuthClient.POST("", cOAuthClient.Create)
+				gOAuthClient.PUT(":id", cOAuthClient.Update)
+				gOAuthClient.DELETE(":id", cOAuthClient.Delete)
+				gOAuthClient.PATCH(":id/secret", cOAuthClient.ResetSecret)
+			}
+
+			// [REGISTERED]/api/v1/oauth-scopes
+			gOAuthScope := v1.Group("oauth-scopes")
+			{
+				gOAuthScope.GET("", cOAuthScope.Query)
+				gOAuthScope.GET(":id", cOAuthScope.Get)
+				gOAuthScope.POST("", cOAuthScope.Create)
+				gOAuthScope.PUT(":id", cOAuthScope.Update)
+				gOAuthScope.DELETE(":id", cOAuthScope.Delete)
+			}
+
 			// [REGISTERED]/api/v1/permissions
 			gPermission := v1.Group("permissions")
 			{
@@ -98,6 +211,13 @@ func RegisterRouter(app *gin.Engine, container *dig.Container) error {
 				gUser.DELETE(":id", cUser.Delete)
 				gUser.PATCH(":id/enable", cUser.Enable)
 				gUser.PATCH(":id/disable", cUser.Disable)
+				gUser.DELETE(":id/mfa", cMFA.Reset)
+				gUser.GET(":id/sessions", cSession.QueryUser)
+				gUser.DELETE(":id/sessions", cSession.RevokeUser)
+				gUser.GET(":id/login-history", cSession.QueryUserLoginHistory)
+				gUser.GET(":id/lockout", cLogin.GetUserLockout)
+				gUser.DELETE(":id/lockout", cLogin.UnlockUser)
+				gUser.POST(":id/impersonate", cLogin.Impersonate)
 			}
 		}
 
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewAccessToken - Create an access token controller
+func NewAccessToken(bAccessToken controllers.IAccessToken) *AccessToken {
+	return &AccessToken{
+		AccessTokenBll: bAccessToken,
+	}
+}
+
+// AccessToken - Manage personal access tokens and API keys
+type AccessToken struct {
+	AccessTokenBll controllers.IAccessToken
+}
+
+// Query - Query the personal access tokens of the current user
+// @Tags Manage Login
+// @Summary Query the personal access tokens of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Param current query int true "Paging index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Success 200 {array} schema.AccessToken "Search result: {list:List data,pagination:{current:Page index,pageSize:Page size,total:Total number}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/tokens [get]
+func (a *AccessToken) Query(c *gin.Context) {
+	a.query(c, schema.AccessTokenQueryParam{
+		UserUUID: ginplus.GetUserUUID(c),
+		Kind:     schema.AccessTokenPersonal,
+	})
+}
+
+// Create - Create a personal access token of the current user
+// @Tags Manage Login
+// @Summary Create a personal access token, the token is only returned once
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.AccessToken true "Name, scopes and expiration time"
+// @Success 200 {object} schema.AccessToken
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/tokens [post]
+func (a *AccessToken) Create(c *gin.Context) {
+	var item schema.AccessToken
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	item.UserUUID = ginplus.GetUserUUID(c)
+	item.Kind = schema.AccessTokenPersonal
+	a.create(c, item)
+}
+
+// Delete - Delete a personal access token of the current user
+// @Tags Manage Login
+// @Summary Delete a personal access token of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/tokens/{id} [delete]
+func (a *AccessToken) Delete(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	err := a.AccessTokenBll.DeletePersonal(ctx, ginplus.GetUserUUID(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Access token"), logger.SetSpanFuncName("Delete")).Infof("Delete access token %s", c.Param("id"))
+	ginplus.ResOK(c)
+}
+
+// QueryAPIKey - Query the API keys
+// @Tags Manage API Keys
+// @Summary Query the API keys
+// @Param Authorization header string false "Bearer User Token"
+// @Param current query int true "Paging index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Param userUUID query string false "Owner"
+// @Success 200 {array} schema.AccessToken "Search result: {list:List data,pagination:{current:Page index,pageSize:Page size,total:Total number}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/api-keys [get]
+func (a *AccessToken) QueryAPIKey(c *gin.Context) {
+	a.query(c, schema.AccessTokenQueryParam{
+		UserUUID: c.Query("userUUID"),
+		Kind:     schema.AccessTokenAPIKey,
+	})
+}
+
+// CreateAPIKey - Create an API key
+// @Tags Manage API Keys
+// @Summary Create an API key acting as its user, the key is only returned once
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.AccessToken true "Owner, name, scopes and expiration time"
+// @Success 200 {object} schema.AccessToken
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/api-keys [post]
+func (a *AccessToken) CreateAPIKey(c *gin.Context) {
+	var item schema.AccessToken
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if item.UserUUID == "" {
+		ginplus.ResError(c, errors.New400Response("User is required"))
+		return
+	}
+
+	item.Kind = schema.AccessTokenAPIKey
+	a.create(c, item)
+}
+
+// DeleteAPIKey - Delete an API key
+// @Tags Manage API Keys
+// @Summary Delete an API key
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/api-keys/{id} [delete]
+func (a *AccessToken) DeleteAPIKey(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	item, err := a.AccessTokenBll.Get(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if item.Kind != schema.AccessTokenAPIKey {
+		ginplus.ResError(c, errors.ErrNotFound)
+		return
+	}
+
+	err = a.AccessTokenBll.Delete(ctx, item.UUID)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Access token"), logger.SetSpanFuncName("DeleteAPIKey")).Infof("Delete API key %s", item.UUID)
+	ginplus.ResOK(c)
+}
+
+func (a *AccessToken) query(c *gin.Context, params schema.AccessTokenQueryParam) {
+	result, err := a.AccessTokenBll.Query(ginplus.NewContext(c), params, schema.AccessTokenQueryOptions{
+		PageParam: ginplus.GetPaginationParam(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+func (a *AccessToken) create(c *gin.Context, item schema.AccessToken) {
+	ctx := ginplus.NewContext(c)
+	nitem, err := a.AccessTokenBll.Create(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Access token"), logger.SetSpanFuncName("Create")).Infof("Create %s %s of %s", nitem.Kind, nitem.UUID, nitem.UserUUID)
+	ginplus.ResSuccess(c, nitem)
+}
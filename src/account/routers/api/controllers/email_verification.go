@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/gin-gonic/gin"
+)
+
+// NewEmailVerification - Create an email verification controller
+func NewEmailVerification(bEmailVerification controllers.IEmailVerification) *EmailVerification {
+	return &EmailVerification{
+		EmailVerificationBll: bEmailVerification,
+	}
+}
+
+// EmailVerification - Email verification by mailed links
+type EmailVerification struct {
+	EmailVerificationBll controllers.IEmailVerification
+}
+
+// Verify - Confirm an email address
+// @Tags Manage Login
+// @Summary Confirm an email address with the token of a verification link
+// @Param body body schema.EmailVerifyParam true "Request parameters"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/email/verify [post]
+func (a *EmailVerification) Verify(c *gin.Context) {
+	var item schema.EmailVerifyParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.EmailVerificationBll.Verify(ginplus.NewContext(c), item.Token)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// ResendTo - Mail a new verification link before login
+// @Tags Manage Login
+// @Summary Mail a new verification link to an unverified address, the response does not tell whether the address belongs to a user
+// @Param Accept-Language header string false "Language of the mail"
+// @Param body body schema.EmailResendParam true "Request parameters"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/email/resend [post]
+func (a *EmailVerification) ResendTo(c *gin.Context) {
+	var item schema.EmailResendParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.EmailVerificationBll.ResendTo(ginplus.NewContext(c), item.Email)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Resend - Mail a new verification link to the current user
+// @Tags Manage Login
+// @Summary Mail a new verification link to the unverified email address of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Param Accept-Language header string false "Language of the mail"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/email/verification [post]
+func (a *EmailVerification) Resend(c *gin.Context) {
+	err := a.EmailVerificationBll.Resend(ginplus.NewContext(c), ginplus.GetUserUUID(c))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
@@ -6,9 +6,22 @@ import (
 
 // Inject - injection ctl
 func Inject(container *dig.Container) error {
+	_ = container.Provide(NewAccessToken)
+	_ = container.Provide(NewEmailVerification)
+	_ = container.Provide(NewInvitation)
+	_ = container.Provide(NewJWKS)
 	_ = container.Provide(NewLogin)
+	_ = container.Provide(NewMFA)
+	_ = container.Provide(NewOAuth)
+	_ = container.Provide(NewOAuthClient)
+	_ = container.Provide(NewOAuthScope)
+	_ = container.Provide(NewOIDC)
+	_ = container.Provide(NewPasswordPolicy)
+	_ = container.Provide(NewPasswordReset)
 	_ = container.Provide(NewPermission)
+	_ = container.Provide(NewRegistration)
 	_ = container.Provide(NewRole)
+	_ = container.Provide(NewSession)
 	_ = container.Provide(NewUser)
 	return nil
 }
\ No newline at end of file
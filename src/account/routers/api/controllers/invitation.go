@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewInvitation - Create an invitation controller
+func NewInvitation(bInvitation controllers.IInvitation) *Invitation {
+	return &Invitation{
+		InvitationBll: bInvitation,
+	}
+}
+
+// Invitation - Manage invitations
+type Invitation struct {
+	InvitationBll controllers.IInvitation
+}
+
+// Query - Query data
+// @Tags Manage Invitations
+// @Summary Query the invitations
+// @Param Authorization header string false "Bearer User Token"
+// @Param current query int true "Paging index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Success 200 {array} schema.Invitation "Search result: {list:List data,pagination:{current:Page index,pageSize:Page size,total:Total number}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/invitations [get]
+func (a *Invitation) Query(c *gin.Context) {
+	result, err := a.InvitationBll.Query(ginplus.NewContext(c), schema.InvitationQueryParam{}, schema.InvitationQueryOptions{
+		PageParam: ginplus.GetPaginationParam(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// Get - Query specified data
+// @Tags Manage Invitations
+// @Summary Query specified data
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.Invitation
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/invitations/{id} [get]
+func (a *Invitation) Get(c *gin.Context) {
+	item, err := a.InvitationBll.Get(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Create - Create an invitation
+// @Tags Manage Invitations
+// @Summary Create an invitation link, the link is only returned once and mailed to the address of the invitation
+// @Param Authorization header string false "Bearer User Token"
+// @Param Accept-Language header string false "Language of the mail"
+// @Param body body schema.Invitation true "Roles, address, usage limit and expiration time"
+// @Success 200 {object} schema.Invitation
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/invitations [post]
+func (a *Invitation) Create(c *gin.Context) {
+	var item schema.Invitation
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	item.Creator = ginplus.GetUserUUID(c)
+	ctx := ginplus.NewContext(c)
+	nitem, err := a.InvitationBll.Create(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Invitation"), logger.SetSpanFuncName("Create")).Infof("Create invitation %s", nitem.UUID)
+	ginplus.ResSuccess(c, nitem)
+}
+
+// Delete - Delete an invitation
+// @Tags Manage Invitations
+// @Summary Delete an invitation, its link stops working
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/invitations/{id} [delete]
+func (a *Invitation) Delete(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	err := a.InvitationBll.Delete(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Invitation"), logger.SetSpanFuncName("Delete")).Infof("Delete invitation %s", c.Param("id"))
+	ginplus.ResOK(c)
+}
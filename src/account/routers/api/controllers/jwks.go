@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/common/auth/jwtauth"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/gin-gonic/gin"
+)
+
+// NewJWKS - Create a key set controller
+func NewJWKS(keySet *jwtauth.KeySet) *JWKS {
+	return &JWKS{
+		KeySet: keySet,
+	}
+}
+
+// JWKS - Publish the public keys verifying the tokens
+type JWKS struct {
+	KeySet *jwtauth.KeySet
+}
+
+// Get - Query the public keys
+// @Tags Manage Login
+// @Summary Query the public keys verifying the tokens (empty with HMAC signature methods)
+// @Success 200 {object} jwtauth.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (a *JWKS) Get(c *gin.Context) {
+	// Verifiers cache the keys, new keys are fetched on an unknown kid
+	c.Header("Cache-Control", "public, max-age=300")
+	ginplus.ResSuccess(c, a.KeySet.JWKS())
+}
@@ -70,9 +70,10 @@ func (a *Login) ResCaptcha(c *gin.Context) {
 
 // Login - User login
 // @Tags Manage Login
-// @Summary User login
+// @Summary User login, users with two-factor authentication get a challenge for /api/v1/pub/login/mfa instead of a token
 // @Param body body schema.LoginParam true "Request parameter"
 // @Success 200 {object} schema.LoginTokenInfo
+// @Success 200 {object} schema.LoginMFAChallenge
 // @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
 // @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
 // @Router /api/v1/pub/login [post]
@@ -94,6 +95,16 @@ func (a *Login) Login(c *gin.Context) {
 		return
 	}
 
+	// The token is only issued after the second step
+	challenge, err := a.LoginBll.CreateMFAChallenge(ginplus.NewContext(c), user)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if challenge != nil {
+		ginplus.ResSuccess(c, challenge)
+		return
+	}
+
 	userID := user.ID
 	// Put user ID into context
 	ginplus.SetUserID(c, int(userID))
@@ -112,6 +123,57 @@ func (a *Login) Login(c *gin.Context) {
 	ginplus.ResSuccess(c, tokenInfo)
 }
 
+// LoginMFA - Finish a login with a two-factor code
+// @Tags Manage Login
+// @Summary Finish a login with a TOTP or recovery code
+// @Param body body schema.LoginMFAParam true "Request parameter"
+// @Success 200 {object} schema.LoginTokenInfo
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid two-factor authentication code}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/login/mfa [post]
+func (a *Login) LoginMFA(c *gin.Context) {
+	var item schema.LoginMFAParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	user, tokenInfo, err := a.LoginBll.VerifyMFA(ginplus.NewContext(c), item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	ginplus.SetUserID(c, int(user.ID))
+	ginplus.SetUserUUID(c, user.UUID)
+
+	logger.StartSpan(ginplus.NewContext(c), logger.SetSpanTitle("User login"), logger.SetSpanFuncName("LoginMFA")).Infof("Login system")
+	ginplus.ResSuccess(c, tokenInfo)
+}
+
+// LoginMFAEnroll - Start the TOTP enrolment required to finish a login
+// @Tags Manage Login
+// @Summary Start the TOTP enrolment required to finish a login
+// @Param body body schema.LoginMFAEnrollParam true "Request parameter"
+// @Success 200 {object} schema.MFAEnrollment
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/login/mfa/enroll [post]
+func (a *Login) LoginMFAEnroll(c *gin.Context) {
+	var item schema.LoginMFAEnrollParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	enrollment, err := a.LoginBll.EnrollMFA(ginplus.NewContext(c), item.MFAToken)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, enrollment)
+}
+
 // Logout - User logout
 // @Tags Manage Login
 // @Summary User logout
@@ -133,14 +195,21 @@ func (a *Login) Logout(c *gin.Context) {
 
 // RefreshToken - Refresh token
 // @Tags Manage Login
-// @Summary Refresh token
-// @Param Authorization header string false "Bearer User Token"
+// @Summary Exchange a refresh token for new tokens
+// @Param body body schema.RefreshTokenParam true "Request parameter"
 // @Success 200 {object} schema.LoginTokenInfo
-// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid user}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Token invalidation}}"
 // @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
 // @Router /api/v1/pub/refresh-token [post]
 func (a *Login) RefreshToken(c *gin.Context) {
-	tokenInfo, err := a.LoginBll.GenerateToken(ginplus.NewContext(c), ginplus.GetUserUUID(c))
+	var item schema.RefreshTokenParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	tokenInfo, err := a.LoginBll.RefreshToken(ginplus.NewContext(c), item.RefreshToken)
 	if err != nil {
 		ginplus.ResError(c, err)
 		return
@@ -206,3 +275,106 @@ func (a *Login) UpdatePassword(c *gin.Context) {
 	}
 	ginplus.ResOK(c)
 }
+
+// ChangePassword - Change an expired password
+// @Tags Manage Login
+// @Summary Change an expired password with the old one before login
+// @Param body body schema.PasswordChangeParam true "Request parameters"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 429 {object} schema.HTTPError "{error:{code:0,message: Too many failed logins}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/password/change [post]
+func (a *Login) ChangePassword(c *gin.Context) {
+	var item schema.PasswordChangeParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.LoginBll.ChangePassword(ginplus.NewContext(c), item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Impersonate - Log in as a user
+// @Summary Get a short-lived token acting as a user without further roles than the impersonator, to reproduce the problems of the user
+// @Summary Get a short-lived token acting as the user, to reproduce the problems of the user
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.LoginTokenInfo
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Users can not impersonate themselves}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/users/{id}/impersonate [post]
+func (a *Login) Impersonate(c *gin.Context) {
+	tokenInfo, err := a.LoginBll.Impersonate(ginplus.NewContext(c), ginplus.GetUserUUID(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, tokenInfo)
+}
+
+// EndImpersonation - End an impersonation
+// @Tags Manage Login
+// @Summary Destroy the impersonation token of the request
+// @Param Authorization header string false "Bearer User Token"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: The token does not impersonate a user}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/impersonation [delete]
+func (a *Login) EndImpersonation(c *gin.Context) {
+	err := a.LoginBll.EndImpersonation(ginplus.NewContext(c), ginplus.GetToken(c))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// GetUserLockout - Get the login lockout of a user
+// @Tags Manage Users
+// @Summary Get the login lockout of a user after too many failed logins
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.LoginLockout
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/users/{id}/lockout [get]
+func (a *Login) GetUserLockout(c *gin.Context) {
+	item, err := a.LoginBll.GetLockout(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// UnlockUser - Lift the login lockout of a user
+// @Tags Manage Users
+// @Summary Lift the login lockout of a user and forget the failed logins
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/users/{id}/lockout [delete]
+func (a *Login) UnlockUser(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	err := a.LoginBll.Unlock(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Login lockout"), logger.SetSpanFuncName("UnlockUser")).Infof("Unlock login of %s", c.Param("id"))
+	ginplus.ResOK(c)
+}
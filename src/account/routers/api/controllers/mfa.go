@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewMFA - Create a two-factor authentication controller
+func NewMFA(bMFA controllers.IMFA) *MFA {
+	return &MFA{
+		MFABll: bMFA,
+	}
+}
+
+// MFA - Manage two-factor authentication
+type MFA struct {
+	MFABll controllers.IMFA
+}
+
+// Get - Get the two-factor authentication state of the current user
+// @Tags Manage Login
+// @Summary Get the two-factor authentication state of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Success 200 {object} schema.MFAStatus
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/mfa [get]
+func (a *MFA) Get(c *gin.Context) {
+	item, err := a.MFABll.Get(ginplus.NewContext(c), ginplus.GetUserUUID(c))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Enroll - Start a TOTP enrolment of the current user
+// @Tags Manage Login
+// @Summary Start a TOTP enrolment of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Success 200 {object} schema.MFAEnrollment
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Two-factor authentication is already enabled}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/mfa [post]
+func (a *MFA) Enroll(c *gin.Context) {
+	item, err := a.MFABll.Enroll(ginplus.NewContext(c), ginplus.GetUserUUID(c))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Confirm - Enable the pending TOTP enrolment of the current user
+// @Tags Manage Login
+// @Summary Enable the pending TOTP enrolment of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.MFACodeParam true "Request parameter"
+// @Success 200 {object} schema.MFARecoveryCodes
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid two-factor authentication code}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/mfa/confirm [post]
+func (a *MFA) Confirm(c *gin.Context) {
+	var item schema.MFACodeParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	ctx := ginplus.NewContext(c)
+	codes, err := a.MFABll.Confirm(ctx, ginplus.GetUserUUID(c), item.Code)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Two-factor authentication"), logger.SetSpanFuncName("Confirm")).Infof("Enable two-factor authentication")
+	ginplus.ResSuccess(c, codes)
+}
+
+// Disable - Disable the two-factor authentication of the current user
+// @Tags Manage Login
+// @Summary Disable the two-factor authentication of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.MFACodeParam true "Request parameter"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid two-factor authentication code}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/mfa/disable [post]
+func (a *MFA) Disable(c *gin.Context) {
+	var item schema.MFACodeParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	ctx := ginplus.NewContext(c)
+	err := a.MFABll.Disable(ctx, ginplus.GetUserUUID(c), item.Code)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Two-factor authentication"), logger.SetSpanFuncName("Disable")).Infof("Disable two-factor authentication")
+	ginplus.ResOK(c)
+}
+
+// RegenerateRecoveryCodes - Replace the recovery codes of the current user
+// @Tags Manage Login
+// @Summary Replace the recovery codes of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.MFACodeParam true "Request parameter"
+// @Success 200 {object} schema.MFARecoveryCodes
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid two-factor authentication code}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/mfa/recovery-codes [post]
+func (a *MFA) RegenerateRecoveryCodes(c *gin.Context) {
+	var item schema.MFACodeParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	codes, err := a.MFABll.RegenerateRecoveryCodes(ginplus.NewContext(c), ginplus.GetUserUUID(c), item.Code)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, codes)
+}
+
+// Reset - Remove the two-factor authentication of a user who lost the device
+// @Tags Manage Users
+// @Summary Remove the two-factor authentication of a user
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/users/{id}/mfa [delete]
+func (a *MFA) Reset(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	err := a.MFABll.Reset(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Two-factor authentication"), logger.SetSpanFuncName("Reset")).Infof("Reset two-factor authentication of %s", c.Param("id"))
+	ginplus.ResOK(c)
+}
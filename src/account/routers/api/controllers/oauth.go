@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewOAuth - Create an OAuth2 authorization server controller
+func NewOAuth(bOAuth controllers.IOAuth) *OAuth {
+	return &OAuth{
+		OAuthBll: bOAuth,
+	}
+}
+
+// OAuth - OAuth2 authorization server for third-party apps
+type OAuth struct {
+	OAuthBll controllers.IOAuth
+}
+
+// Authorization - Describe an authorization request for the consent screen
+// @Tags OAuth
+// @Summary Check the authorization request of a client for the consent screen
+// @Param Authorization header string false "Bearer User Token"
+// @Param response_type query string true "Response type (code)"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string false "Redirect URI"
+// @Param scope query string false "Space separated scopes"
+// @Param state query string false "State returned to the client"
+// @Param code_challenge query string false "PKCE code challenge"
+// @Param code_challenge_method query string false "PKCE code challenge method (S256)"
+// @Success 200 {object} schema.OAuthConsent
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/oauth/authorize [get]
+func (a *OAuth) Authorization(c *gin.Context) {
+	var params schema.OAuthAuthorizeParam
+	if err := c.ShouldBindQuery(&params); err != nil {
+		ginplus.ResError(c, errors.Wrap400Response(err, "Parse request parameter error"))
+		return
+	}
+
+	item, err := a.OAuthBll.Authorization(ginplus.NewContext(c), ginplus.GetUserUUID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Authorize - Answer an authorization request
+// @Tags OAuth
+// @Summary Approve or deny the authorization request of a client, the redirect URI holds the code or the denial
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.OAuthAuthorizeParam true "Authorization request and the decision of the user"
+// @Success 200 {object} schema.OAuthRedirect
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/oauth/authorize [post]
+func (a *OAuth) Authorize(c *gin.Context) {
+	var params schema.OAuthAuthorizeParam
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	ctx := ginplus.NewContext(c)
+	userUUID := ginplus.GetUserUUID(c)
+	item, err := a.OAuthBll.Authorize(ctx, userUUID, params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	if params.Approve {
+		logger.StartSpan(ctx, logger.SetSpanTitle("OAuth"), logger.SetSpanFuncName("Authorize")).Infof("User %s authorized OAuth client %s", userUUID, params.ClientID)
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Token - Issue tokens to a client
+// @Tags OAuth
+// @Summary Token endpoint (authorization code with PKCE, refresh token and client credentials grants)
+// @Accept application/x-www-form-urlencoded
+// @Param grant_type formData string true "Grant type"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Space separated scopes"
+// @Param client_id formData string false "Client ID (without HTTP basic authentication)"
+// @Param client_secret formData string false "Client secret (without HTTP basic authentication)"
+// @Success 200 {object} schema.OAuthTokenInfo
+// @Failure 400 {object} schema.OAuthError "{error:invalid_grant,error_description:Invalid code}"
+// @Failure 401 {object} schema.OAuthError "{error:invalid_client,error_description:Client authentication failed}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/oauth/token [post]
+func (a *OAuth) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var params schema.OAuthTokenParam
+	if err := c.ShouldBind(&params); err != nil {
+		a.resError(c, controllers.NewOAuthError(controllers.OAuthErrInvalidRequest, "Parse request parameter error"))
+		return
+	}
+
+	clientID, clientSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		clientID, clientSecret = params.ClientID, params.ClientSecret
+	}
+
+	item, err := a.OAuthBll.Token(ginplus.NewContext(c), clientID, clientSecret, params)
+	if err != nil {
+		a.resError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Errors of the token endpoint are answered in the format of RFC 6749
+func (a *OAuth) resError(c *gin.Context, err error) {
+	e, ok := err.(*controllers.OAuthError)
+	if !ok {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	if e.Code == controllers.OAuthErrInvalidClient {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	ginplus.ResJSON(c, e.StatusCode, schema.OAuthError{
+		Error:            e.Code,
+		ErrorDescription: e.Description,
+	})
+}
+
+// QueryGrants - Query the clients authorized by the current user
+// @Tags Manage Login
+// @Summary Query the OAuth clients authorized by the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Success 200 {array} schema.OAuthGrant "Query result: {list:List data}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/oauth-grants [get]
+func (a *OAuth) QueryGrants(c *gin.Context) {
+	result, err := a.OAuthBll.QueryGrants(ginplus.NewContext(c), schema.OAuthGrantQueryParam{
+		UserUUID: ginplus.GetUserUUID(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, result)
+}
+
+// RevokeGrant - Revoke the authorization of a client by the current user
+// @Tags Manage Login
+// @Summary Revoke the authorization of a client, its tokens stop working at once
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/oauth-grants/{id} [delete]
+func (a *OAuth) RevokeGrant(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	err := a.OAuthBll.RevokeGrant(ctx, ginplus.GetUserUUID(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("OAuth"), logger.SetSpanFuncName("RevokeGrant")).Infof("Revoke OAuth grant %s", c.Param("id"))
+	ginplus.ResOK(c)
+}
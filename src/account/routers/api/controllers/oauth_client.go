@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewOAuthClient - Create an OAuth2 client management controller
+func NewOAuthClient(bOAuthClient controllers.IOAuthClient) *OAuthClient {
+	return &OAuthClient{
+		OAuthClientBll: bOAuthClient,
+	}
+}
+
+// OAuthClient - Manage OAuth2 clients
+type OAuthClient struct {
+	OAuthClientBll controllers.IOAuthClient
+}
+
+// Query - Query data
+// @Tags Manage OAuth Clients
+// @Summary Query data
+// @Param Authorization header string false "Bearer User Token"
+// @Param current query int true "Paging index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Param name query string false "Client name (fuzzy query)"
+// @Success 200 {array} schema.OAuthClient "Search result: {list:List data,pagination:{current:Page index,pageSize:Page size,total:Total number}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-clients [get]
+func (a *OAuthClient) Query(c *gin.Context) {
+	var params schema.OAuthClientQueryParam
+	params.LikeName = c.Query("name")
+
+	result, err := a.OAuthClientBll.Query(ginplus.NewContext(c), params, schema.OAuthClientQueryOptions{
+		PageParam: ginplus.GetPaginationParam(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// Get - Query specified data
+// @Tags Manage OAuth Clients
+// @Summary Query specified data
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.OAuthClient
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message:Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-clients/{id} [get]
+func (a *OAuthClient) Get(c *gin.Context) {
+	item, err := a.OAuthClientBll.Get(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item.CleanSecure())
+}
+
+// Create - Create data
+// @Tags Manage OAuth Clients
+// @Summary Create a client, the secret of a confidential client is only returned once
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.OAuthClient true "Create data"
+// @Success 200 {object} schema.OAuthClient
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-clients [post]
+func (a *OAuthClient) Create(c *gin.Context) {
+	var item schema.OAuthClient
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	ctx := ginplus.NewContext(c)
+	nitem, err := a.OAuthClientBll.Create(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("OAuth client"), logger.SetSpanFuncName("Create")).Infof("Create OAuth client %s", nitem.UUID)
+	ginplus.ResSuccess(c, nitem)
+}
+
+// Update - Update data
+// @Tags Manage OAuth Clients
+// @Summary Update data, a client becoming confidential gets a secret returned once
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Param body body schema.OAuthClient true "Update data"
+// @Success 200 {object} schema.OAuthClient
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-clients/{id} [put]
+func (a *OAuthClient) Update(c *gin.Context) {
+	var item schema.OAuthClient
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	nitem, err := a.OAuthClientBll.Update(ginplus.NewContext(c), c.Param("id"), item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, nitem)
+}
+
+// ResetSecret - Reset the secret
+// @Tags Manage OAuth Clients
+// @Summary Replace the secret of a confidential client, the new secret is only returned once
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.OAuthClient
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Public clients have no secret}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message:Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-clients/{id}/secret [patch]
+func (a *OAuthClient) ResetSecret(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	item, err := a.OAuthClientBll.ResetSecret(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("OAuth client"), logger.SetSpanFuncName("ResetSecret")).Infof("Reset secret of OAuth client %s", item.UUID)
+	ginplus.ResSuccess(c, item)
+}
+
+// Delete - Delete data
+// @Tags Manage OAuth Clients
+// @Summary Delete a client and its grants
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-clients/{id} [delete]
+func (a *OAuthClient) Delete(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	err := a.OAuthClientBll.Delete(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("OAuth client"), logger.SetSpanFuncName("Delete")).Infof("Delete OAuth client %s", c.Param("id"))
+	ginplus.ResOK(c)
+}
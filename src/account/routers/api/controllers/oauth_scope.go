@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/gin-gonic/gin"
+)
+
+// NewOAuthScope - Create an OAuth2 scope management controller
+func NewOAuthScope(bOAuthScope controllers.IOAuthScope) *OAuthScope {
+	return &OAuthScope{
+		OAuthScopeBll: bOAuthScope,
+	}
+}
+
+// OAuthScope - Manage OAuth2 scopes
+type OAuthScope struct {
+	OAuthScopeBll controllers.IOAuthScope
+}
+
+// Query - Query data
+// @Tags Manage OAuth Scopes
+// @Summary Query data
+// @Param Authorization header string false "Bearer User Token"
+// @Param current query int true "Paging index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Success 200 {array} schema.OAuthScope "Search result: {list:List data,pagination:{current:Page index,pageSize:Page size,total:Total number}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-scopes [get]
+func (a *OAuthScope) Query(c *gin.Context) {
+	result, err := a.OAuthScopeBll.Query(ginplus.NewContext(c), schema.OAuthScopeQueryParam{}, schema.OAuthScopeQueryOptions{
+		PageParam: ginplus.GetPaginationParam(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// Get - Query specified data
+// @Tags Manage OAuth Scopes
+// @Summary Query specified data
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.OAuthScope
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message:Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-scopes/{id} [get]
+func (a *OAuthScope) Get(c *gin.Context) {
+	item, err := a.OAuthScopeBll.Get(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Create - Create data
+// @Tags Manage OAuth Scopes
+// @Summary Create data
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.OAuthScope true "Create data"
+// @Success 200 {object} schema.OAuthScope
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-scopes [post]
+func (a *OAuthScope) Create(c *gin.Context) {
+	var item schema.OAuthScope
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	nitem, err := a.OAuthScopeBll.Create(ginplus.NewContext(c), item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, nitem)
+}
+
+// Update - Update data
+// @Tags Manage OAuth Scopes
+// @Summary Update data, the name is not allowed to change
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Param body body schema.OAuthScope true "Update data"
+// @Success 200 {object} schema.OAuthScope
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-scopes/{id} [put]
+func (a *OAuthScope) Update(c *gin.Context) {
+	var item schema.OAuthScope
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	nitem, err := a.OAuthScopeBll.Update(ginplus.NewContext(c), c.Param("id"), item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, nitem)
+}
+
+// Delete - Delete data
+// @Tags Manage OAuth Scopes
+// @Summary Delete data
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/oauth-scopes/{id} [delete]
+func (a *OAuthScope) Delete(c *gin.Context) {
+	err := a.OAuthScopeBll.Delete(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Cookie keeping the state of a login between the start and the callback
+const (
+	oidcStateCookie     = "maycmf_oidc_state"
+	oidcStateCookiePath = "/api/v1/pub/login/oidc"
+)
+
+// NewOIDC - Create an OpenID Connect login controller
+func NewOIDC(bOIDC controllers.IOIDC, bLogin controllers.ILogin) *OIDC {
+	return &OIDC{
+		OIDCBll:  bOIDC,
+		LoginBll: bLogin,
+	}
+}
+
+// OIDC - Login with external OpenID Connect identity providers
+type OIDC struct {
+	OIDCBll  controllers.IOIDC
+	LoginBll controllers.ILogin
+}
+
+// Providers - Query the identity providers
+// @Tags Manage Login
+// @Summary Query the identity providers offered on the login page
+// @Success 200 {array} schema.OIDCProvider "Query result: {list:List data}"
+// @Router /api/v1/pub/login/oidc [get]
+func (a *OIDC) Providers(c *gin.Context) {
+	ginplus.ResList(c, a.OIDCBll.Providers(ginplus.NewContext(c)))
+}
+
+// Authorize - Start a login at an identity provider
+// @Tags Manage Login
+// @Summary Redirect to the identity provider (authorization code flow with PKCE)
+// @Param provider path string true "Identity provider name"
+// @Success 302 "Redirect to the identity provider"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/login/oidc/{provider} [get]
+func (a *OIDC) Authorize(c *gin.Context) {
+	authURL, state, err := a.OIDCBll.AuthCodeURL(ginplus.NewContext(c), c.Param("provider"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	expired := config.Global().OIDC.StateExpired
+	if expired <= 0 {
+		expired = 600
+	}
+	a.setStateCookie(c, state, expired)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback - Finish a login at an identity provider
+// @Tags Manage Login
+// @Summary Finish a login at an identity provider, the tokens are handed to the success URL of the provider in the URL fragment if it is configured
+// @Param provider path string true "Identity provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} schema.LoginTokenInfo
+// @Success 200 {object} schema.LoginMFAChallenge
+// @Success 302 "Redirect to the success URL"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/login/oidc/{provider}/callback [get]
+func (a *OIDC) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+	cfg, _ := config.Global().OIDC.GetProvider(provider)
+
+	state, _ := c.Cookie(oidcStateCookie)
+	a.setStateCookie(c, "", -1)
+
+	ctx := ginplus.NewContext(c)
+	user, err := a.OIDCBll.Login(ctx, provider, c.Query("code"), c.Query("state"), state)
+	if err != nil {
+		a.resError(c, cfg.SuccessURL, err)
+		return
+	}
+
+	// Two-factor authentication applies to external logins too
+	challenge, err := a.LoginBll.CreateMFAChallenge(ctx, user)
+	if err != nil {
+		a.resError(c, cfg.SuccessURL, err)
+		return
+	} else if challenge != nil {
+		if cfg.SuccessURL != "" {
+			a.redirect(c, cfg.SuccessURL, url.Values{
+				"mfa_token":  {challenge.MFAToken},
+				"enroll":     {strconv.FormatBool(challenge.Enroll)},
+				"expires_at": {strconv.FormatInt(challenge.ExpiresAt, 10)},
+			})
+			return
+		}
+		ginplus.ResSuccess(c, challenge)
+		return
+	}
+
+	ginplus.SetUserID(c, int(user.ID))
+	ginplus.SetUserUUID(c, user.UUID)
+
+	tokenInfo, err := a.LoginBll.GenerateToken(ginplus.NewContext(c), user.UUID)
+	if err != nil {
+		a.resError(c, cfg.SuccessURL, err)
+		return
+	}
+
+	logger.StartSpan(ginplus.NewContext(c), logger.SetSpanTitle("User login"), logger.SetSpanFuncName("OIDC")).Infof("Login system with %s", provider)
+	if cfg.SuccessURL != "" {
+		values := url.Values{
+			"access_token": {tokenInfo.AccessToken},
+			"token_type":   {tokenInfo.TokenType},
+			"expires_at":   {strconv.FormatInt(tokenInfo.ExpiresAt, 10)},
+		}
+		if tokenInfo.RefreshToken != "" {
+			values.Set("refresh_token", tokenInfo.RefreshToken)
+		}
+		a.redirect(c, cfg.SuccessURL, values)
+		return
+	}
+	ginplus.ResSuccess(c, tokenInfo)
+}
+
+// The state is only sent back with the callback, SameSite=Lax lets it
+// through the top level redirect of the identity provider
+func (a *OIDC) setStateCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     oidcStateCookiePath,
+		Secure:   c.Request.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// The result is handed to the frontend in the URL fragment, which is not
+// sent to servers or kept in their logs
+func (a *OIDC) redirect(c *gin.Context, successURL string, values url.Values) {
+	c.Redirect(http.StatusFound, successURL+"#"+values.Encode())
+}
+
+func (a *OIDC) resError(c *gin.Context, successURL string, err error) {
+	if successURL == "" {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	message := errors.ErrInternalServer.Error()
+	if resErr := errors.UnWrapResponse(err); resErr != nil {
+		message = resErr.Message
+	}
+	a.redirect(c, successURL, url.Values{"error": {message}})
+}
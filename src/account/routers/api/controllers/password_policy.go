@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/gin-gonic/gin"
+)
+
+// NewPasswordPolicy - Create a password policy controller
+func NewPasswordPolicy(bPasswordPolicy controllers.IPasswordPolicy) *PasswordPolicy {
+	return &PasswordPolicy{
+		PasswordPolicyBll: bPasswordPolicy,
+	}
+}
+
+// PasswordPolicy - Password policy
+type PasswordPolicy struct {
+	PasswordPolicyBll controllers.IPasswordPolicy
+}
+
+// Check - Check a password against the password policy
+// @Tags Manage Login
+// @Summary Score a password and list the rules of the password policy it breaks, e.g. for a strength meter
+// @Param body body schema.PasswordCheckParam true "Request parameters"
+// @Success 200 {object} schema.PasswordCheckResult
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/password/check [post]
+func (a *PasswordPolicy) Check(c *gin.Context) {
+	var item schema.PasswordCheckParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	ginplus.ResSuccess(c, a.PasswordPolicyBll.Evaluate(ginplus.NewContext(c), item))
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewPasswordReset - Create a password reset controller
+func NewPasswordReset(bPasswordReset controllers.IPasswordReset) *PasswordReset {
+	return &PasswordReset{
+		PasswordResetBll: bPasswordReset,
+	}
+}
+
+// PasswordReset - Password reset by mailed links
+type PasswordReset struct {
+	PasswordResetBll controllers.IPasswordReset
+}
+
+// Forgot - Mail a password reset link
+// @Tags Manage Login
+// @Summary Mail a password reset link, the response does not tell whether the address belongs to a user
+// @Param Accept-Language header string false "Language of the mail"
+// @Param body body schema.PasswordForgotParam true "Request parameters"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/password/forgot [post]
+func (a *PasswordReset) Forgot(c *gin.Context) {
+	var item schema.PasswordForgotParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	err := a.PasswordResetBll.Forgot(ginplus.NewContext(c), item.Email)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
+
+// Reset - Set a new password with a reset link
+// @Tags Manage Login
+// @Summary Set a new password with the token of a reset link, the user is logged out everywhere
+// @Param body body schema.PasswordResetParam true "Request parameters"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/password/reset [post]
+func (a *PasswordReset) Reset(c *gin.Context) {
+	var item schema.PasswordResetParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	ctx := ginplus.NewContext(c)
+	err := a.PasswordResetBll.Reset(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Password reset"), logger.SetSpanFuncName("Reset")).Infof("Reset password by link")
+	ginplus.ResOK(c)
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"github.com/LyricTian/captcha"
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewRegistration - Create a self-registration controller
+func NewRegistration(bRegistration controllers.IRegistration) *Registration {
+	return &Registration{
+		RegistrationBll: bRegistration,
+	}
+}
+
+// Registration - Self-registration
+type Registration struct {
+	RegistrationBll controllers.IRegistration
+}
+
+// Info - Get the settings of the sign-up form
+// @Tags Manage Login
+// @Summary Get the settings of the sign-up form, with the address of an invitation
+// @Param invitation query string false "Token of an invitation link"
+// @Success 200 {object} schema.RegisterInfo
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invitation link is invalid or expired}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/register [get]
+func (a *Registration) Info(c *gin.Context) {
+	info, err := a.RegistrationBll.Info(ginplus.NewContext(c), c.Query("invitation"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, info)
+}
+
+// Register - Sign up
+// @Tags Manage Login
+// @Summary Sign up, a verification link is mailed to the address
+// @Param Accept-Language header string false "Language of the mail"
+// @Param body body schema.RegisterParam true "Request parameters"
+// @Success 200 {object} schema.User
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/register [post]
+func (a *Registration) Register(c *gin.Context) {
+	var item schema.RegisterParam
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	if config.Global().Register.Captcha && !captcha.VerifyString(item.CaptchaID, item.CaptchaCode) {
+		ginplus.ResError(c, errors.New400Response("Invalid verification code"))
+		return
+	}
+
+	ctx := ginplus.NewContext(c)
+	user, err := a.RegistrationBll.Register(ctx, item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Registration"), logger.SetSpanFuncName("Register")).Infof("Sign up user %s as %s", user.UUID, user.Creator)
+	ginplus.ResSuccess(c, user)
+}
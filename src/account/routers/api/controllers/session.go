@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewSession - Create a session controller
+func NewSession(bSession controllers.ISession) *Session {
+	return &Session{
+		SessionBll: bSession,
+	}
+}
+
+// Session - Manage sessions and login history
+type Session struct {
+	SessionBll controllers.ISession
+}
+
+// Query - Query the active sessions of the current user
+// @Tags Manage Login
+// @Summary Query the active sessions of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Success 200 {array} schema.UserSession "Query result: {list:List data}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/sessions [get]
+func (a *Session) Query(c *gin.Context) {
+	list, err := a.SessionBll.Query(ginplus.NewContext(c), ginplus.GetUserUUID(c), ginplus.GetToken(c))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, list)
+}
+
+// Revoke - Revoke a session of the current user
+// @Tags Manage Login
+// @Summary Revoke a session of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Session ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/sessions/{id} [delete]
+func (a *Session) Revoke(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	err := a.SessionBll.Revoke(ctx, ginplus.GetUserUUID(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Session"), logger.SetSpanFuncName("Revoke")).Infof("Revoke session %s", c.Param("id"))
+	ginplus.ResOK(c)
+}
+
+// QueryLoginHistory - Query the login history of the current user
+// @Tags Manage Login
+// @Summary Query the login history of the current user
+// @Param Authorization header string false "Bearer User Token"
+// @Param current query int true "Paging index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Param success query bool false "Only successful (true) or failed (false) attempts"
+// @Success 200 {array} schema.LoginHistory "Search result: {list:List data,pagination:{current:Page index,pageSize:Page size,total:Total number}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/pub/current/login-history [get]
+func (a *Session) QueryLoginHistory(c *gin.Context) {
+	a.queryLoginHistory(c, ginplus.GetUserUUID(c))
+}
+
+// QueryUser - Query the active sessions of a user
+// @Tags Manage Users
+// @Summary Query the active sessions of a user
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {array} schema.UserSession "Query result: {list:List data}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/users/{id}/sessions [get]
+func (a *Session) QueryUser(c *gin.Context) {
+	list, err := a.SessionBll.Query(ginplus.NewContext(c), c.Param("id"), "")
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, list)
+}
+
+// RevokeUser - Logout a user everywhere
+// @Tags Manage Users
+// @Summary Revoke every session of a user
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/users/{id}/sessions [delete]
+func (a *Session) RevokeUser(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	err := a.SessionBll.RevokeAll(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	logger.StartSpan(ctx, logger.SetSpanTitle("Session"), logger.SetSpanFuncName("RevokeUser")).Infof("Revoke sessions of %s", c.Param("id"))
+	ginplus.ResOK(c)
+}
+
+// QueryUserLoginHistory - Query the login history of a user
+// @Tags Manage Users
+// @Summary Query the login history of a user
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Param current query int true "Paging index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Param success query bool false "Only successful (true) or failed (false) attempts"
+// @Success 200 {array} schema.LoginHistory "Search result: {list:List data,pagination:{current:Page index,pageSize:Page size,total:Total number}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/users/{id}/login-history [get]
+func (a *Session) QueryUserLoginHistory(c *gin.Context) {
+	a.queryLoginHistory(c, c.Param("id"))
+}
+
+func (a *Session) queryLoginHistory(c *gin.Context, userUUID string) {
+	params := schema.LoginHistoryQueryParam{
+		UserUUID: userUUID,
+	}
+	if v := c.Query("success"); v != "" {
+		success := v == "true" || v == "1"
+		params.Success = &success
+	}
+
+	result, err := a.SessionBll.QueryLoginHistory(ginplus.NewContext(c), params, schema.LoginHistoryQueryOptions{
+		PageParam: ginplus.GetPaginationParam(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
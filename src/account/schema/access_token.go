@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/common/schema"
+)
+
+// Kinds of access tokens
+const (
+	AccessTokenPersonal = "personal" // Personal access token managed by its user
+	AccessTokenAPIKey   = "api_key"  // API key issued by an administrator
+)
+
+// AccessToken - Long-lived token of a machine client acting as its user,
+// limited to the scopes
+type AccessToken struct {
+	UUID       string          `json:"record_id"`                      // Record ID
+	UserUUID   string          `json:"user_uuid"`                      // Owner (required for API keys)
+	Kind       string          `json:"kind"`                           // Kind (personal/api_key)
+	Name       string          `json:"name" binding:"required"`        // Name
+	Hint       string          `json:"hint"`                           // First characters of the token
+	Scopes     RolePermissions `json:"scopes" binding:"required,gt=0"` // Permission resources the token is limited to
+	ExpiresAt  *time.Time      `json:"expires_at"`                     // Expiration time (empty for no expiration)
+	LastUsedAt *time.Time      `json:"last_used_at"`                   // Time of the last authenticated request
+	Creator    string          `json:"creator"`                        // Creator
+	CreatedAt  time.Time       `json:"created_at"`                     // Creation time
+	Token      string          `json:"token,omitempty"`                // Plain token, only returned on creation
+	TokenHash  string          `json:"-"`                              // SHA-256 of the token
+}
+
+// AccessTokenQueryParam - Query conditions
+type AccessTokenQueryParam struct {
+	UserUUID  string // Owner
+	Kind      string // Kind
+	TokenHash string // SHA-256 of the token
+}
+
+// AccessTokenQueryOptions - Query optional parameter items
+type AccessTokenQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// AccessTokenQueryResult - Search result
+type AccessTokenQueryResult struct {
+	Data       AccessTokens
+	PageResult *schema.PaginationResult
+}
+
+// AccessTokens - Access token list
+type AccessTokens []*AccessToken
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/common/schema"
+)
+
+// Invitation - Link to sign up with preassigned roles
+type Invitation struct {
+	UUID      string    `json:"record_id"`                     // Record ID
+	Note      string    `json:"note"`                          // Note of the administrator
+	Email     string    `json:"email"`                         // Only address allowed to sign up (optional), the link is mailed to it
+	Roles     []string  `json:"roles" binding:"required,gt=0"` // Role IDs of the users signing up
+	MaxUses   int       `json:"max_uses"`                      // Maximum number of sign-ups (0: unlimited)
+	Uses      int       `json:"uses"`                          // Number of sign-ups
+	ExpiresAt time.Time `json:"expires_at"`                    // Expiration time (empty for the configured lifetime)
+	Creator   string    `json:"creator"`                       // Creator
+	CreatedAt time.Time `json:"created_at"`                    // Creation time
+	Token     string    `json:"token,omitempty"`               // Plain token, only returned on creation
+	URL       string    `json:"url,omitempty"`                 // Sign-up link, only returned on creation
+	TokenHash string    `json:"-"`                             // SHA-256 of the token
+}
+
+// Valid - Check whether the invitation still accepts sign-ups
+func (a *Invitation) Valid(now time.Time) bool {
+	return now.Before(a.ExpiresAt) && (a.MaxUses == 0 || a.Uses < a.MaxUses)
+}
+
+// InvitationQueryParam - Query conditions
+type InvitationQueryParam struct {
+	TokenHash string // SHA-256 of the token
+}
+
+// InvitationQueryOptions - Query optional parameter items
+type InvitationQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// InvitationQueryResult - Search result
+type InvitationQueryResult struct {
+	Data       Invitations
+	PageResult *schema.PaginationResult
+}
+
+// Invitations - Invitation list
+type Invitations []*Invitation
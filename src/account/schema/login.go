@@ -1,5 +1,7 @@
 package schema
 
+import "time"
+
 // LoginParam - Login parameter
 type LoginParam struct {
 	UserName    string `json:"user_name" binding:"required"`    // UserName
@@ -10,9 +12,12 @@ type LoginParam struct {
 
 // UserLoginInfo - User login information
 type UserLoginInfo struct {
-	UserName  string   `json:"user_name"`  // UserName
-	RealName  string   `json:"real_name"`  // RealName
-	RoleNames []string `json:"role_names"` // List of role names
+	UserName       string   `json:"user_name"`                 // UserName
+	RealName       string   `json:"real_name"`                 // RealName
+	Email          string   `json:"email"`                     // Email
+	EmailVerified  bool     `json:"email_verified"`            // The user confirmed the email address
+	RoleNames      []string `json:"role_names"`                // List of role names
+	ImpersonatedBy string   `json:"impersonated_by,omitempty"` // UUID of the user impersonating the user
 }
 
 // UpdatePasswordParam - Update password request parameters
@@ -21,6 +26,11 @@ type UpdatePasswordParam struct {
 	NewPassword string `json:"new_password" binding:"required"` // Old password (md5 encryption)
 }
 
+// RefreshTokenParam - Refresh token request parameters
+type RefreshTokenParam struct {
+	RefreshToken string `json:"refresh_token" binding:"required"` // Refresh token
+}
+
 // LoginCaptcha - Login verification code
 type LoginCaptcha struct {
 	CaptchaID string `json:"captcha_id"` // Verification code ID
@@ -28,7 +38,16 @@ type LoginCaptcha struct {
 
 // LoginTokenInfo - Login token information
 type LoginTokenInfo struct {
-	AccessToken string `json:"access_token"` // Access token
-	TokenType   string `json:"token_type"`   // Token type
-	ExpiresAt   int64  `json:"expires_at"`   // Token expiration timestamp
+	AccessToken  string `json:"access_token"`            // Access token
+	TokenType    string `json:"token_type"`              // Token type
+	ExpiresAt    int64  `json:"expires_at"`              // Token expiration timestamp
+	RefreshToken string `json:"refresh_token,omitempty"` // Refresh token, replaced by each refresh
+
+	RecoveryCodes []string `json:"recovery_codes,omitempty"` // Recovery codes of a two-factor enrolment made during the login
+}
+
+// LoginLockout - Login lockout of a user
+type LoginLockout struct {
+	Locked      bool       `json:"locked"`                 // Logins are refused after too many failures
+	LockedUntil *time.Time `json:"locked_until,omitempty"` // End of the lockout
 }
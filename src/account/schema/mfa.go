@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"time"
+)
+
+// UserMFA - TOTP two-factor authentication of a user
+type UserMFA struct {
+	UserUUID      string    `json:"user_uuid"`  // User UUID
+	Secret        string    `json:"-"`          // Base32 TOTP secret
+	Enabled       bool      `json:"enabled"`    // Enrolment confirmed with a valid code
+	LastCounter   int64     `json:"-"`          // Time step of the last accepted code
+	RecoveryCodes []string  `json:"-"`          // Hashes of the unused recovery codes
+	CreatedAt     time.Time `json:"created_at"` // Enrolment time
+}
+
+// MFAStatus - Two-factor authentication state of the current user
+type MFAStatus struct {
+	Enabled       bool `json:"enabled"`        // TOTP is enabled
+	Required      bool `json:"required"`       // A role of the user makes it mandatory
+	RecoveryCodes int  `json:"recovery_codes"` // Number of unused recovery codes
+}
+
+// MFAEnrollment - New TOTP secret to add to an authenticator app
+type MFAEnrollment struct {
+	Secret string `json:"secret"`  // Base32 secret for manual entry
+	URL    string `json:"url"`     // otpauth:// key URI
+	QRCode string `json:"qr_code"` // PNG QR code of the URI as a data URI
+}
+
+// MFACodeParam - Code of an authenticator app or a recovery code
+type MFACodeParam struct {
+	Code string `json:"code" binding:"required"` // TOTP or recovery code
+}
+
+// MFARecoveryCodes - Recovery codes, only shown once
+type MFARecoveryCodes struct {
+	Codes []string `json:"codes"` // Single use recovery codes
+}
+
+// LoginMFAChallenge - Second step required to finish a login
+type LoginMFAChallenge struct {
+	MFAToken  string `json:"mfa_token"`  // Challenge token for the second step
+	Enroll    bool   `json:"enroll"`     // TOTP is mandatory and has to be enrolled first
+	ExpiresAt int64  `json:"expires_at"` // Challenge expiration timestamp
+}
+
+// LoginMFAParam - Second login step parameter
+type LoginMFAParam struct {
+	MFAToken string `json:"mfa_token" binding:"required"` // Challenge token
+	Code     string `json:"code" binding:"required"`      // TOTP or recovery code
+}
+
+// LoginMFAEnrollParam - Enrolment during a login parameter
+type LoginMFAEnrollParam struct {
+	MFAToken string `json:"mfa_token" binding:"required"` // Challenge token
+}
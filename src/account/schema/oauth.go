@@ -0,0 +1,193 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/common/schema"
+)
+
+// OAuth2 grant types
+const (
+	OAuthGrantAuthorizationCode = "authorization_code"
+	OAuthGrantClientCredentials = "client_credentials"
+	OAuthGrantRefreshToken      = "refresh_token"
+)
+
+// OAuthClient - Third-party app accessing the API on behalf of users
+type OAuthClient struct {
+	UUID         string    `json:"record_id"`                             // Record ID (client_id)
+	Name         string    `json:"name" binding:"required"`               // Name shown on the consent screen
+	Public       bool      `json:"public"`                                // Public client without a secret (PKCE is mandatory)
+	RedirectURIs []string  `json:"redirect_uris"`                         // Allowed redirect URIs
+	GrantTypes   []string  `json:"grant_types" binding:"required,gt=0"`   // Allowed grant types
+	Scopes       []string  `json:"scopes" binding:"required,gt=0"`        // Scopes the client may request
+	UserUUID     string    `json:"user_uuid"`                             // User acted as by the client credentials grant
+	Status       int       `json:"status" binding:"required,max=2,min=1"` // Status (1: Enable 2: Disable)
+	Creator      string    `json:"creator"`                               // Creator
+	CreatedAt    time.Time `json:"created_at"`                            // Creation time
+	Secret       string    `json:"secret,omitempty"`                      // Plain secret, only returned on creation and reset
+	SecretHash   string    `json:"-"`                                     // SHA-256 of the secret
+}
+
+// HasGrantType - Check whether the client may use a grant type
+func (a *OAuthClient) HasGrantType(grantType string) bool {
+	for _, v := range a.GrantTypes {
+		if v == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRedirectURI - Check whether a redirect URI is registered for the client
+func (a *OAuthClient) HasRedirectURI(redirectURI string) bool {
+	for _, v := range a.RedirectURIs {
+		if v == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanSecure - Clean up the secret
+func (a *OAuthClient) CleanSecure() *OAuthClient {
+	a.Secret = ""
+	return a
+}
+
+// OAuthClientQueryParam - Query conditions
+type OAuthClientQueryParam struct {
+	LikeName string // Name (fuzzy query)
+	UserUUID string // User acted as by the client credentials grant
+}
+
+// OAuthClientQueryOptions - Query optional parameter items
+type OAuthClientQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// OAuthClientQueryResult - Search result
+type OAuthClientQueryResult struct {
+	Data       OAuthClients
+	PageResult *schema.PaginationResult
+}
+
+// OAuthClients - OAuth2 client list
+type OAuthClients []*OAuthClient
+
+// OAuthScope - Scope a client may request, it grants the permission
+// resources (limited to the permissions of the user)
+type OAuthScope struct {
+	UUID        string          `json:"record_id"`                           // Record ID
+	Name        string          `json:"name" binding:"required"`             // Name requested by the clients
+	Title       string          `json:"title"`                               // Title shown on the consent screen
+	Memo        string          `json:"memo"`                                // Description shown on the consent screen
+	Permissions RolePermissions `json:"permissions" binding:"required,gt=0"` // Granted permission resources
+	Creator     string          `json:"creator"`                             // Creator
+	CreatedAt   time.Time       `json:"created_at"`                          // Creation time
+}
+
+// OAuthScopeQueryParam - Query conditions
+type OAuthScopeQueryParam struct {
+	Name  string   // Name
+	Names []string // Name list
+}
+
+// OAuthScopeQueryOptions - Query optional parameter items
+type OAuthScopeQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// OAuthScopeQueryResult - Search result
+type OAuthScopeQueryResult struct {
+	Data       OAuthScopes
+	PageResult *schema.PaginationResult
+}
+
+// OAuthScopes - OAuth2 scope list
+type OAuthScopes []*OAuthScope
+
+// OAuthGrant - Scopes a user granted to a client, the tokens of the client
+// are limited to them
+type OAuthGrant struct {
+	UUID       string    `json:"record_id"`   // Record ID
+	ClientUUID string    `json:"client_id"`   // Client
+	ClientName string    `json:"client_name"` // Client name
+	UserUUID   string    `json:"user_uuid"`   // User
+	Scopes     []string  `json:"scopes"`      // Granted scopes
+	CreatedAt  time.Time `json:"created_at"`  // Time of the first grant
+	UpdatedAt  time.Time `json:"updated_at"`  // Time of the last grant
+}
+
+// OAuthGrantQueryParam - Query conditions
+type OAuthGrantQueryParam struct {
+	ClientUUID string // Client
+	UserUUID   string // User
+}
+
+// OAuthGrants - OAuth2 grant list
+type OAuthGrants []*OAuthGrant
+
+// OAuthCode - Pending authorization code
+type OAuthCode struct {
+	UUID          string    // Record ID
+	CodeHash      string    // SHA-256 of the code
+	GrantUUID     string    // Grant the tokens are issued for
+	Scopes        []string  // Scopes approved by the authorization request
+	RedirectURI   string    // Redirect URI of the authorization request
+	CodeChallenge string    // PKCE code challenge (S256)
+	ExpiresAt     time.Time // Expiration time
+}
+
+// OAuthAuthorizeParam - Authorization request of a client
+type OAuthAuthorizeParam struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required"` // Response type (code)
+	ClientID            string `form:"client_id" json:"client_id" binding:"required"`         // Client ID
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`                      // Redirect URI (optional with a single registered URI)
+	Scope               string `form:"scope" json:"scope"`                                    // Space separated scopes (default: all scopes of the client)
+	State               string `form:"state" json:"state"`                                    // State returned to the client
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`                  // PKCE code challenge (mandatory for public clients)
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`    // PKCE code challenge method (S256)
+	Approve             bool   `form:"-" json:"approve"`                                      // The user approves the request
+}
+
+// OAuthConsent - Consent screen of an authorization request
+type OAuthConsent struct {
+	ClientID    string      `json:"client_id"`    // Client ID
+	ClientName  string      `json:"client_name"`  // Client name
+	RedirectURI string      `json:"redirect_uri"` // Redirect URI
+	Scopes      OAuthScopes `json:"scopes"`       // Requested scopes
+	Granted     bool        `json:"granted"`      // The scopes are already granted to the client
+}
+
+// OAuthRedirect - Redirection back to the client
+type OAuthRedirect struct {
+	RedirectURI string `json:"redirect_uri"` // Redirect URI holding the code or the error
+}
+
+// OAuthTokenParam - Token request of a client
+type OAuthTokenParam struct {
+	GrantType    string `form:"grant_type"`    // Grant type
+	Code         string `form:"code"`          // Authorization code
+	RedirectURI  string `form:"redirect_uri"`  // Redirect URI of the authorization request
+	CodeVerifier string `form:"code_verifier"` // PKCE code verifier
+	RefreshToken string `form:"refresh_token"` // Refresh token
+	Scope        string `form:"scope"`         // Space separated scopes
+	ClientID     string `form:"client_id"`     // Client ID (without HTTP basic authentication)
+	ClientSecret string `form:"client_secret"` // Client secret (without HTTP basic authentication)
+}
+
+// OAuthTokenInfo - Token response of the token endpoint
+type OAuthTokenInfo struct {
+	AccessToken  string `json:"access_token"`            // Access token
+	TokenType    string `json:"token_type"`              // Token type
+	ExpiresIn    int64  `json:"expires_in"`              // Lifetime of the access token (unit: second)
+	RefreshToken string `json:"refresh_token,omitempty"` // Refresh token
+	Scope        string `json:"scope"`                   // Space separated granted scopes
+}
+
+// OAuthError - Error response of the token endpoint
+type OAuthError struct {
+	Error            string `json:"error"`                       // Error code
+	ErrorDescription string `json:"error_description,omitempty"` // Error description
+}
@@ -0,0 +1,30 @@
+package schema
+
+import (
+	"time"
+)
+
+// OIDCProvider - Identity provider offered on the login page
+type OIDCProvider struct {
+	Name  string `json:"name"`  // Name used in the login URLs
+	Title string `json:"title"` // Name shown on the login page
+}
+
+// UserIdentity - Account of a user at an external identity provider
+type UserIdentity struct {
+	UUID      string    `json:"record_id"`  // Record ID
+	UserUUID  string    `json:"user_uuid"`  // User UUID
+	Provider  string    `json:"provider"`   // Identity provider name
+	Subject   string    `json:"subject"`    // User identifier at the identity provider
+	CreatedAt time.Time `json:"created_at"` // Time of the first login
+}
+
+// UserIdentityQueryParam - Query conditions
+type UserIdentityQueryParam struct {
+	UserUUID string // User UUID
+	Provider string // Identity provider name
+	Subject  string // User identifier at the identity provider
+}
+
+// UserIdentities - External identity list
+type UserIdentities []*UserIdentity
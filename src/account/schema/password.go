@@ -0,0 +1,28 @@
+package schema
+
+// PasswordViolation - Rule of the password policy a password breaks
+type PasswordViolation struct {
+	Code    string `json:"code"`    // Violation code
+	Message string `json:"message"` // Description of the rule
+}
+
+// PasswordCheckParam - Password check request parameters
+type PasswordCheckParam struct {
+	Password string `json:"password" binding:"required"` // Password to check
+	UserName string `json:"user_name"`                   // UserName the password must not contain
+	RealName string `json:"real_name"`                   // RealName the password must not contain
+	Email    string `json:"email"`                       // Email the password must not contain
+}
+
+// PasswordCheckResult - Password check result
+type PasswordCheckResult struct {
+	Score      int                 `json:"score"`      // Strength score (0-4)
+	Violations []PasswordViolation `json:"violations"` // Rules of the password policy the password breaks
+}
+
+// PasswordChangeParam - Change of an expired password before login
+type PasswordChangeParam struct {
+	UserName    string `json:"user_name" binding:"required"`    // UserName
+	OldPassword string `json:"old_password" binding:"required"` // Expired password
+	NewPassword string `json:"new_password" binding:"required"` // New password
+}
@@ -0,0 +1,33 @@
+package schema
+
+// Self-registration modes
+const (
+	RegisterDisabled = "disabled" // Nobody signs up
+	RegisterOpen     = "open"     // Anybody signs up, invitations are accepted
+	RegisterInvite   = "invite"   // Only invited people sign up
+)
+
+// Creators of self-registered users
+const (
+	UserCreatorRegister         = "registration" // Signed up without invitation
+	UserCreatorInvitationPrefix = "invitation:"  // Signed up with the invitation of the suffix
+)
+
+// RegisterParam - Sign-up request parameters
+type RegisterParam struct {
+	UserName    string `json:"user_name" binding:"required"` // UserName
+	RealName    string `json:"real_name" binding:"required"` // RealName
+	Email       string `json:"email" binding:"required"`     // Email
+	Password    string `json:"password" binding:"required"`  // Password
+	Phone       string `json:"phone"`                        // Phone number
+	CaptchaID   string `json:"captcha_id"`                   // Verification code ID
+	CaptchaCode string `json:"captcha_code"`                 // Verification code
+	Invitation  string `json:"invitation"`                   // Token of an invitation link
+}
+
+// RegisterInfo - Settings of the sign-up form
+type RegisterInfo struct {
+	Mode    string `json:"mode"`            // Mode (disabled/open/invite)
+	Captcha bool   `json:"captcha"`         // A captcha has to be solved
+	Email   string `json:"email,omitempty"` // Address the invitation is limited to
+}
@@ -11,6 +11,7 @@ type Role struct {
 	Name        string          `json:"name" binding:"required"`             // Role Name
 	Sequence    int             `json:"sequence"`                            // Sort value
 	Memo        string          `json:"memo"`                                // Remarks
+	MFARequired bool            `json:"mfa_required"`                        // Users of the role must use two-factor authentication
 	Creator     string          `json:"creator"`                             // Creator
 	CreatedAt   time.Time       `json:"created_at"`                          // Creation time
 	Permissions RolePermissions `json:"permissions" binding:"required,gt=0"` // Permission permission
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/common/schema"
+)
+
+// UserSession - Device a user is logged in from, it lasts as long as the
+// refresh tokens rotated from the login
+type UserSession struct {
+	UUID       string    `json:"record_id"`    // Session ID
+	UserUUID   string    `json:"user_uuid"`    // User UUID
+	IP         string    `json:"ip"`           // Client IP of the last token
+	UserAgent  string    `json:"user_agent"`   // Client user agent of the last token
+	CreatedAt  time.Time `json:"created_at"`   // Login time
+	LastSeenAt time.Time `json:"last_seen_at"` // Time of the last token refresh
+	ExpiresAt  time.Time `json:"expires_at"`   // Expiration time of the last refresh token
+	Current    bool      `json:"current"`      // Session of the request token
+}
+
+// UserSessions - User session list
+type UserSessions []*UserSession
+
+// LoginHistory - Login attempt
+type LoginHistory struct {
+	UserUUID  string    `json:"user_uuid"`  // User UUID (empty for an unknown user name)
+	UserName  string    `json:"user_name"`  // User name used to login
+	IP        string    `json:"ip"`         // Client IP
+	UserAgent string    `json:"user_agent"` // Client user agent
+	Success   bool      `json:"success"`    // A token was issued
+	Reason    string    `json:"reason"`     // Failure reason
+	CreatedAt time.Time `json:"created_at"` // Attempt time
+}
+
+// LoginHistoryQueryParam - Login history query conditions
+type LoginHistoryQueryParam struct {
+	UserUUID string // User UUID
+	Success  *bool  // Only successful or failed attempts
+}
+
+// LoginHistoryQueryOptions - Login history query optional parameter items
+type LoginHistoryQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// LoginHistoryQueryResult - Login history query result
+type LoginHistoryQueryResult struct {
+	Data       []*LoginHistory
+	PageResult *schema.PaginationResult
+}
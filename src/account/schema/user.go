@@ -2,22 +2,31 @@ package schema
 
 import (
 	"github.com/MayCMF/core/src/common/schema"
+	"strings"
 	"time"
 )
 
 // User - User object
 type User struct {
-	ID        uint      `json:"id"`                                    // Record ID
-	UUID      string    `json:"record_id"`                             // Record ID
-	UserName  string    `json:"user_name" binding:"required"`          // UserName
-	RealName  string    `json:"real_name" binding:"required"`          // RealName
-	Password  string    `json:"password"`                              // Password
-	Phone     string    `json:"phone"`                                 // Phone number
-	Email     string    `json:"email"`                                 // Email
-	Status    int       `json:"status" binding:"required,max=2,min=1"` // User Status (1: Enable 2: Disable)
-	Creator   string    `json:"creator"`                               // Creator
-	CreatedAt time.Time `json:"created_at"`                            // Creation time
-	Roles     UserRoles `json:"roles" binding:"required,gt=0"`         // Role authorization
+	ID                uint      `json:"id"`                                    // Record ID
+	UUID              string    `json:"record_id"`                             // Record ID
+	UserName          string    `json:"user_name" binding:"required"`          // UserName
+	RealName          string    `json:"real_name" binding:"required"`          // RealName
+	Password          string    `json:"password"`                              // Password
+	Phone             string    `json:"phone"`                                 // Phone number
+	Email             string    `json:"email"`                                 // Email
+	EmailVerified     bool      `json:"email_verified"`                        // The user confirmed the email address
+	Avatar            string    `json:"avatar"`                                // Avatar file UUID
+	Status            int       `json:"status" binding:"required,max=2,min=1"` // User Status (1: Enable 2: Disable)
+	Creator           string    `json:"creator"`                               // Creator
+	PasswordChangedAt time.Time `json:"password_changed_at"`                   // Time of the last password change
+	CreatedAt         time.Time `json:"created_at"`                            // Creation time
+	Roles             UserRoles `json:"roles" binding:"required,gt=0"`         // Role authorization
+}
+
+// SelfRegistered - Check whether the user signed up by itself
+func (a *User) SelfRegistered() bool {
+	return a.Creator == UserCreatorRegister || strings.HasPrefix(a.Creator, UserCreatorInvitationPrefix)
 }
 
 // CleanSecure - Clean up safety data
@@ -36,6 +45,7 @@ type UserQueryParam struct {
 	UserName     string   // UserName
 	LikeUserName string   // Username (fuzzy query)
 	LikeRealName string   // Real name (fuzzy query)
+	Email        string   // Email
 	Status       int      // User Status (1: Enable 2: Disable)
 	RoleIDs      []string // Role ID list
 }
@@ -70,13 +80,15 @@ func (a Users) ToUserShows(mroles map[string]*Role) UserShows {
 
 	for i, item := range a {
 		showItem := &UserShow{
-			UUID:      item.UUID,
-			RealName:  item.RealName,
-			UserName:  item.UserName,
-			Email:     item.Email,
-			Phone:     item.Phone,
-			Status:    item.Status,
-			CreatedAt: item.CreatedAt,
+			UUID:          item.UUID,
+			RealName:      item.RealName,
+			UserName:      item.UserName,
+			Email:         item.Email,
+			EmailVerified: item.EmailVerified,
+			Phone:         item.Phone,
+			Avatar:        item.Avatar,
+			Status:        item.Status,
+			CreatedAt:     item.CreatedAt,
 		}
 
 		var roles Roles
@@ -106,14 +118,16 @@ func (a UserRoles) ToRoleIDs() []string {
 
 // UserShow - User display item
 type UserShow struct {
-	UUID      string    `json:"record_id"`  // Record ID
-	UserName  string    `json:"user_name"`  // UserName
-	RealName  string    `json:"real_name"`  // RealName
-	Phone     string    `json:"phone"`      // Phone
-	Email     string    `json:"email"`      // Email
-	Status    int       `json:"status"`     // User Status (1: Enable 2: Disable)
-	CreatedAt time.Time `json:"created_at"` // Creation time
-	Roles     []*Role   `json:"roles"`      // Roles List
+	UUID          string    `json:"record_id"`      // Record ID
+	UserName      string    `json:"user_name"`      // UserName
+	RealName      string    `json:"real_name"`      // RealName
+	Phone         string    `json:"phone"`          // Phone
+	Email         string    `json:"email"`          // Email
+	EmailVerified bool      `json:"email_verified"` // The user confirmed the email address
+	Avatar        string    `json:"avatar"`         // Avatar file UUID
+	Status        int       `json:"status"`         // User Status (1: Enable 2: Disable)
+	CreatedAt     time.Time `json:"created_at"`     // Creation time
+	Roles         []*Role   `json:"roles"`          // Roles List
 }
 
 // UserShows - User display item list
@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"time"
+)
+
+// Kinds of user tokens
+const (
+	UserTokenPasswordReset     = "password_reset"     // Password reset link
+	UserTokenEmailVerification = "email_verification" // Email verification link
+)
+
+// UserToken - Single-use token mailed to a user
+type UserToken struct {
+	UUID      string    // Record ID
+	Kind      string    // Kind (password_reset/email_verification)
+	UserUUID  string    // User
+	Email     string    // Email address the token was mailed to
+	TokenHash string    // SHA-256 of the token
+	ExpiresAt time.Time // Expiration time
+}
+
+// PasswordForgotParam - Request of a password reset link
+type PasswordForgotParam struct {
+	Email string `json:"email" binding:"required"` // Email of the user
+}
+
+// PasswordResetParam - Password reset request parameters
+type PasswordResetParam struct {
+	Token    string `json:"token" binding:"required"`    // Token of the reset link
+	Password string `json:"password" binding:"required"` // New password
+}
+
+// EmailResendParam - Request of a new verification link before login
+type EmailResendParam struct {
+	Email string `json:"email" binding:"required"` // Email of the user
+}
+
+// EmailVerifyParam - Email verification request parameters
+type EmailVerifyParam struct {
+	Token string `json:"token" binding:"required"` // Token of the verification link
+}
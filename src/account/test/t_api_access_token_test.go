@@ -0,0 +1,234 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAccessToken(t *testing.T) {
+	const router = apiPrefix + "v1/pub/current/tokens"
+	var err error
+
+	// post /permissions
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "query", Name: "query"},
+		},
+		Resources: []*schema.PermissionResource{
+			{Code: "roles", Name: "roles", Method: "GET", Path: "/api/v1/roles.select"},
+			{Code: "tree", Name: "tree", Method: "GET", Path: "/api/v1/permissions.tree"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var permission schema.Permission
+	err = parseReader(w.Body, &permission)
+	assert.Nil(t, err)
+
+	// post /roles
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &schema.Role{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Permissions: []*schema.RolePermission{
+			{PermissionID: permission.UUID, Actions: []string{"query"}, Resources: []string{"roles", "tree"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var role schema.Role
+	err = parseReader(w.Body, &role)
+	assert.Nil(t, err)
+
+	// post /users
+	password := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: util.MustUUID(),
+		RealName: util.MustUUID(),
+		Email:    util.MustUUID() + "@example.com",
+		Password: password,
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: role.UUID}},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var user schema.User
+	err = parseReader(w.Body, &user)
+	assert.Nil(t, err)
+
+	// post /pub/login
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+	jwt := tokenInfo.AccessToken
+
+	// post /pub/current/tokens beyond the permissions of the user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(router, &schema.AccessToken{
+		Name: "ci",
+		Scopes: schema.RolePermissions{
+			{PermissionID: permission.UUID, Actions: []string{"query"}, Resources: []string{"roles", "users"}},
+		},
+	}), jwt))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/current/tokens already expired
+	expired := time.Now().Add(-time.Hour)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(router, &schema.AccessToken{
+		Name:      "ci",
+		ExpiresAt: &expired,
+		Scopes: schema.RolePermissions{
+			{PermissionID: permission.UUID, Resources: []string{"roles"}},
+		},
+	}), jwt))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/current/tokens
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(router, &schema.AccessToken{
+		Name: "ci",
+		Scopes: schema.RolePermissions{
+			{PermissionID: permission.UUID, Actions: []string{"query"}, Resources: []string{"roles"}},
+		},
+	}), jwt))
+	assert.Equal(t, 200, w.Code)
+	var token schema.AccessToken
+	err = parseReader(w.Body, &token)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token.Token)
+	assert.Equal(t, token.Token[:len(token.Hint)], token.Hint)
+	assert.Equal(t, schema.AccessTokenPersonal, token.Kind)
+
+	// get /pub/current/tokens does not show the token again
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, newPageParam()), jwt))
+	assert.Equal(t, 200, w.Code)
+	var pageItems []*schema.AccessToken
+	err = parsePageReader(w.Body, &pageItems)
+	assert.Nil(t, err)
+	if assert.Len(t, pageItems, 1) {
+		assert.Equal(t, token.UUID, pageItems[0].UUID)
+		assert.Empty(t, pageItems[0].Token)
+		assert.Nil(t, pageItems[0].LastUsedAt)
+	}
+
+	// get /roles.select within the scopes
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), token.Token))
+	assert.Equal(t, 200, w.Code)
+
+	// get /permissions.tree granted to the user, but not to the token
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), jwt))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), token.Token))
+	assert.Equal(t, 401, w.Code)
+
+	// the token can not manage the account of its user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, newPageParam()), token.Token))
+	assert.Equal(t, 401, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), token.Token))
+	assert.Equal(t, 200, w.Code)
+
+	// the last use is recorded
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, newPageParam()), jwt))
+	assert.Equal(t, 200, w.Code)
+	pageItems = nil
+	err = parsePageReader(w.Body, &pageItems)
+	assert.Nil(t, err)
+	if assert.Len(t, pageItems, 1) {
+		assert.NotNil(t, pageItems[0].LastUsedAt)
+	}
+
+	// post /api-keys for the user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/api-keys", &schema.AccessToken{
+		UserUUID: user.UUID,
+		Name:     "import",
+		Scopes: schema.RolePermissions{
+			{PermissionID: permission.UUID, Resources: []string{"tree"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var apiKey schema.AccessToken
+	err = parseReader(w.Body, &apiKey)
+	assert.Nil(t, err)
+	assert.Equal(t, schema.AccessTokenAPIKey, apiKey.Kind)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), apiKey.Token))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), apiKey.Token))
+	assert.Equal(t, 401, w.Code)
+
+	// an API key is not a personal token of the user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newDeleteRequest("%s/%s", router, apiKey.UUID), jwt))
+	assert.Equal(t, 404, w.Code)
+
+	// delete /api-keys/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/api-keys", apiKey.UUID))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), apiKey.Token))
+	assert.Equal(t, 401, w.Code)
+
+	// Without casbin the scopes would not limit the tokens, so they are refused
+	cfg := config.Global()
+	cfg.Casbin.Enable = false
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), token.Token))
+	assert.Equal(t, 401, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(router, &schema.AccessToken{
+		Name: "ci",
+		Scopes: schema.RolePermissions{
+			{PermissionID: permission.UUID, Actions: []string{"query"}, Resources: []string{"roles"}},
+		},
+	}), jwt))
+	assert.Equal(t, 400, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), jwt))
+	assert.Equal(t, 200, w.Code)
+	cfg.Casbin.Enable = true
+
+	// delete /pub/current/tokens/:id revokes the token at once
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newDeleteRequest("%s/%s", router, token.UUID), jwt))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), token.Token))
+	assert.Equal(t, 401, w.Code)
+
+	// delete /users/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /roles/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", role.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /permissions/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", permission.UUID))
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"encoding/csv"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MayCMF/core/src/account/schema"
+	aschema "github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAudit(t *testing.T) {
+	const router = apiPrefix + "v1/audit"
+	var err error
+
+	// post /permissions
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "query", Name: "query"},
+		},
+		Resources: []*schema.PermissionResource{
+			{Code: "query", Name: "query", Method: "GET", Path: "/test/v1/audit"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var permission schema.Permission
+	err = parseReader(w.Body, &permission)
+	assert.Nil(t, err)
+	defer engine.ServeHTTP(httptest.NewRecorder(), newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", permission.UUID))
+
+	// post /roles
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &schema.Role{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Permissions: []*schema.RolePermission{
+			{PermissionID: permission.UUID, Actions: []string{"query"}, Resources: []string{"query"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var role schema.Role
+	err = parseReader(w.Body, &role)
+	assert.Nil(t, err)
+
+	// put /roles/:id
+	putItem := role
+	putItem.Sequence = 9999998
+	putItem.Memo = "audited"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPutRequest("%s/%s", putItem, apiPrefix+"v1/roles", role.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// An update without changes is not recorded
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPutRequest("%s/%s", putItem, apiPrefix+"v1/roles", role.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// query /audit, most recent first
+	params := newPageParam(map[string]string{
+		"pageSize":   "10",
+		"entityType": aschema.AuditEntityRole,
+		"entityID":   role.UUID,
+	})
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, params))
+	assert.Equal(t, 200, w.Code)
+	var items []*aschema.Audit
+	err = parsePageReader(w.Body, &items)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(items))
+	if len(items) == 2 {
+		update, create := items[0], items[1]
+		assert.Equal(t, aschema.AuditActionUpdate, update.Action)
+		assert.Equal(t, config.Global().Root.UserName, update.ActorUUID)
+		assert.NotEmpty(t, update.TraceID)
+		assert.Equal(t, 2, len(update.Changes))
+		if len(update.Changes) == 2 {
+			assert.Equal(t, "memo", update.Changes[0].Field)
+			assert.Equal(t, `""`, string(update.Changes[0].Before))
+			assert.Equal(t, `"audited"`, string(update.Changes[0].After))
+			assert.Equal(t, "sequence", update.Changes[1].Field)
+			assert.Equal(t, `9999999`, string(update.Changes[1].Before))
+			assert.Equal(t, `9999998`, string(update.Changes[1].After))
+		}
+		assert.Equal(t, aschema.AuditActionCreate, create.Action)
+		assert.NotEmpty(t, create.Changes)
+	}
+
+	// delete /roles/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", role.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	params["action"] = aschema.AuditActionDelete
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, params))
+	assert.Equal(t, 200, w.Code)
+	items = nil
+	err = parsePageReader(w.Body, &items)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(items))
+	delete(params, "action")
+
+	// get /audit/export
+	delete(params, "current")
+	delete(params, "pageSize")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router+"/export", params))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(records))
+	if len(records) == 4 {
+		assert.Equal(t, "created_at", records[0][0])
+		assert.Equal(t, aschema.AuditActionDelete, records[1][8])
+		assert.Equal(t, aschema.AuditActionCreate, records[3][8])
+	}
+
+	params["format"] = "json"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router+"/export", params))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	items = nil
+	err = parseReader(w.Body, &items)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(items))
+
+	params["format"] = "xml"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router+"/export", params))
+	assert.Equal(t, 400, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, newPageParam(map[string]string{"startTime": "yesterday"})))
+	assert.Equal(t, 400, w.Code)
+}
@@ -0,0 +1,214 @@
+package test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MayCMF/core/src/account/schema"
+	aschema "github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIImpersonation(t *testing.T) {
+	const router = apiPrefix + "v1/users/%s/impersonate"
+	var err error
+
+	// post /permissions
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "impersonate", Name: "impersonate"},
+		},
+		Resources: []*schema.PermissionResource{
+			{Code: "impersonate", Name: "impersonate", Method: "POST", Path: "/api/v1/users/:id/impersonate"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var permission schema.Permission
+	err = parseReader(w.Body, &permission)
+	assert.Nil(t, err)
+
+	// post /roles
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &schema.Role{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Permissions: []*schema.RolePermission{
+			{PermissionID: permission.UUID, Actions: []string{"impersonate"}, Resources: []string{"impersonate"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var role schema.Role
+	err = parseReader(w.Body, &role)
+	assert.Nil(t, err)
+
+	user, password, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	// post /users, the impersonator has the roles of the user
+	supportPassword := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: util.MustUUID(),
+		RealName: util.MustUUID(),
+		Email:    util.MustUUID() + "@example.com",
+		Password: supportPassword,
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: role.UUID}, {RoleID: user.Roles[0].RoleID}},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var support schema.User
+	err = parseReader(w.Body, &support)
+	assert.Nil(t, err)
+
+	other, _, otherCleanup := newLoginUser(t)
+	defer otherCleanup()
+
+	login := func(userName, password string) string {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(userName, password)))
+		assert.Equal(t, 200, w.Code)
+		var tokenInfo schema.LoginTokenInfo
+		err := parseReader(w.Body, &tokenInfo)
+		assert.Nil(t, err)
+		return tokenInfo.AccessToken
+	}
+	supportToken := login(support.UserName, supportPassword)
+	userToken := login(user.UserName, password)
+
+	// Users without the permission can not impersonate
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(fmt.Sprintf(router, support.UUID), nil), userToken))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(fmt.Sprintf(router, support.UUID), nil), supportToken))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(fmt.Sprintf(router, config.Global().Root.UserName), nil), supportToken))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(fmt.Sprintf(router, util.MustUUID()), nil), supportToken))
+	assert.Equal(t, 404, w.Code)
+
+	// Users with roles the impersonator has not can not be impersonated
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(fmt.Sprintf(router, other.UUID), nil), supportToken))
+	assert.Equal(t, 400, w.Code)
+
+	// post /users/:id/impersonate
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(fmt.Sprintf(router, user.UUID), nil), supportToken))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokenInfo.AccessToken)
+	assert.Empty(t, tokenInfo.RefreshToken)
+	token := tokenInfo.AccessToken
+
+	// The token acts as the user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), token))
+	assert.Equal(t, 200, w.Code)
+	var info schema.UserLoginInfo
+	err = parseReader(w.Body, &info)
+	assert.Nil(t, err)
+	assert.Equal(t, user.UserName, info.UserName)
+	assert.Equal(t, support.UUID, info.ImpersonatedBy)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/permission.tree", nil), userToken))
+	assert.Equal(t, 200, w.Code)
+	userTree := w.Body.String()
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/permission.tree", nil), token))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, userTree, w.Body.String())
+
+	// Neither the permissions of the impersonator nor the account management
+	// of the user are available
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(fmt.Sprintf(router, support.UUID), nil), token))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPutRequest(apiPrefix+"v1/pub/current/password", &schema.UpdatePasswordParam{
+		OldPassword: password,
+		NewPassword: util.MustUUID(),
+	}), token))
+	assert.Equal(t, 401, w.Code)
+
+	// Only impersonation tokens end an impersonation
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newDeleteRequest(apiPrefix+"v1/pub/current/impersonation"), userToken))
+	assert.Equal(t, 400, w.Code)
+
+	// delete /pub/current/impersonation
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newDeleteRequest(apiPrefix+"v1/pub/current/impersonation"), token))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), token))
+	assert.Equal(t, 401, w.Code)
+
+	// The impersonation is in the audit log
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/audit", newPageParam(map[string]string{
+		"pageSize":   "10",
+		"entityType": aschema.AuditEntityUser,
+		"entityID":   user.UUID,
+	})))
+	assert.Equal(t, 200, w.Code)
+	var items []*aschema.Audit
+	err = parsePageReader(w.Body, &items)
+	assert.Nil(t, err)
+	assert.True(t, len(items) >= 2)
+	if len(items) >= 2 {
+		end, start := items[0], items[1]
+		assert.Equal(t, aschema.AuditActionImpersonateEnd, end.Action)
+		assert.Equal(t, user.UUID, end.ActorUUID)
+		assert.Equal(t, support.UUID, end.ImpersonatorUUID)
+		assert.Equal(t, aschema.AuditActionImpersonate, start.Action)
+		assert.Equal(t, support.UUID, start.ActorUUID)
+		assert.Empty(t, start.ImpersonatorUUID)
+	}
+
+	// Disabling the impersonator revokes its impersonation tokens
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(fmt.Sprintf(router, user.UUID), nil), supportToken))
+	assert.Equal(t, 200, w.Code)
+	tokenInfo = schema.LoginTokenInfo{}
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPatchRequest("%s/%s/disable", apiPrefix+"v1/users", support.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	// delete /users/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", support.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", role.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", permission.UUID))
+	assert.Equal(t, 200, w.Code)
+}
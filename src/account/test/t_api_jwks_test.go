@@ -0,0 +1,21 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/auth/jwtauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIJWKS(t *testing.T) {
+	// The tests sign with the shared HMAC key, no public key is published
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("/.well-known/jwks.json", nil))
+	assert.Equal(t, 200, w.Code)
+	var set jwtauth.JSONWebKeySet
+	err := parseReader(w.Body, &set)
+	assert.Nil(t, err)
+	assert.NotNil(t, set.Keys)
+	assert.Empty(t, set.Keys)
+}
@@ -0,0 +1,207 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/auth/ldap/ldaptest"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPILDAP(t *testing.T) {
+	const router = apiPrefix + "v1/pub/login"
+	var err error
+
+	// post /permissions
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "query", Name: "query"},
+		},
+		Resources: []*schema.PermissionResource{
+			{Code: "query", Name: "query", Method: "GET", Path: "/test/v1/ldap"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var permission schema.Permission
+	err = parseReader(w.Body, &permission)
+	assert.Nil(t, err)
+
+	// post /roles
+	var roles []schema.Role
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &schema.Role{
+			Name:     util.MustUUID(),
+			Sequence: 9999999,
+			Permissions: []*schema.RolePermission{
+				{PermissionID: permission.UUID, Actions: []string{"query"}, Resources: []string{"query"}},
+			},
+		}))
+		assert.Equal(t, 200, w.Code)
+		var role schema.Role
+		err = parseReader(w.Body, &role)
+		assert.Nil(t, err)
+		roles = append(roles, role)
+	}
+	editor, staff := roles[0], roles[1]
+
+	local, localPassword, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	aliceName := util.MustUUID()
+	aliceDN := "uid=" + aliceName + ",ou=people,dc=example,dc=com"
+	server := ldaptest.NewServer()
+	defer server.Close()
+	server.Add("cn=service,dc=example,dc=com", map[string][]string{
+		"objectClass":  {"person"},
+		"cn":           {"service"},
+		"userPassword": {"service-secret"},
+	})
+	server.Add(aliceDN, map[string][]string{
+		"objectClass":  {"inetOrgPerson"},
+		"uid":          {aliceName},
+		"cn":           {"Alice Liddell"},
+		"mail":         {aliceName + "@example.com"},
+		"userPassword": {"alice-secret"},
+		"memberOf":     {"cn=editors,ou=groups,dc=example,dc=com"},
+	})
+	// A directory entry of the local user is not linked to it
+	server.Add("uid="+local.UserName+",ou=people,dc=example,dc=com", map[string][]string{
+		"objectClass":  {"inetOrgPerson"},
+		"uid":          {local.UserName},
+		"userPassword": {"directory-secret"},
+		"memberOf":     {"cn=editors,ou=groups,dc=example,dc=com"},
+	})
+
+	cfg := config.Global()
+	ldapConfig := cfg.LDAP
+	defer func() { cfg.LDAP = ldapConfig }()
+	cfg.LDAP = config.LDAP{
+		Enable:            true,
+		URL:               server.URL,
+		Timeout:           5,
+		BindDN:            "cn=service,dc=example,dc=com",
+		BindPassword:      "service-secret",
+		BaseDN:            "dc=example,dc=com",
+		UserFilter:        "(&(objectClass=inetOrgPerson)(uid=%s))",
+		UserNameAttribute: "uid",
+		GroupAttribute:    "memberOf",
+		AutoCreate:        true,
+		RoleMapping: map[string]string{
+			"editors":                              editor.Name,
+			"cn=staff,ou=groups,dc=example,dc=com": staff.Name,
+		},
+	}
+
+	// The first login creates the user with the mapped roles
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(aliceName, "alice-secret")))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	// get /pub/current/user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 200, w.Code)
+	var loginInfo schema.UserLoginInfo
+	err = parseReader(w.Body, &loginInfo)
+	assert.Nil(t, err)
+	assert.Equal(t, aliceName, loginInfo.UserName)
+	assert.Equal(t, "Alice Liddell", loginInfo.RealName)
+	assert.Equal(t, []string{editor.Name}, loginInfo.RoleNames)
+
+	// The directory checks the passwords of its users
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(aliceName, "invalid")))
+	assert.Equal(t, 400, w.Code)
+
+	// The mapped roles follow the groups of the entry
+	server.Add(aliceDN, map[string][]string{
+		"objectClass":  {"inetOrgPerson"},
+		"uid":          {aliceName},
+		"cn":           {"Alice Liddell"},
+		"mail":         {aliceName + "@example.com"},
+		"userPassword": {"alice-secret"},
+		"memberOf":     {"cn=staff,ou=groups,dc=example,dc=com"},
+	})
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(aliceName, "alice-secret")))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &loginInfo)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{staff.Name}, loginInfo.RoleNames)
+
+	// Local users login with their local password, also when the directory
+	// has an entry of the same name
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(local.UserName, localPassword)))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(local.UserName, "directory-secret")))
+	assert.Equal(t, 400, w.Code)
+
+	// Local users remain available while the directory is down
+	server.Close()
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(aliceName, "alice-secret")))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(local.UserName, localPassword)))
+	assert.Equal(t, 200, w.Code)
+
+	// query /users by name and delete the created user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/users", map[string]string{
+		"current":  "1",
+		"pageSize": "1",
+		"userName": aliceName,
+	}))
+	assert.Equal(t, 200, w.Code)
+	var userItems []*schema.User
+	err = parsePageReader(w.Body, &userItems)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(userItems)) {
+		// get /users/:id
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newGetRequest("%s/%s", nil, apiPrefix+"v1/users", userItems[0].UUID))
+		assert.Equal(t, 200, w.Code)
+		var alice schema.User
+		err = parseReader(w.Body, &alice)
+		assert.Nil(t, err)
+		assert.Equal(t, "ldap", alice.Creator)
+		assert.True(t, alice.EmailVerified)
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", userItems[0].UUID))
+		assert.Equal(t, 200, w.Code)
+	}
+
+	// delete /roles/:id
+	for _, role := range roles {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", role.UUID))
+		assert.Equal(t, 200, w.Code)
+	}
+
+	// delete /permissions/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", permission.UUID))
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPILoginLockout(t *testing.T) {
+	const router = apiPrefix + "v1/pub/login"
+	var err error
+
+	user, password, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	// post /pub/login with wrong passwords
+	for i := 0; i < 5; i++ {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, "invalid")))
+		assert.Equal(t, 400, w.Code)
+	}
+
+	// The user name is locked, even for the right password
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, password)))
+	assert.Equal(t, 429, w.Code)
+
+	// get /users/:id/lockout
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/lockout", nil, apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+	var lockout schema.LoginLockout
+	err = parseReader(w.Body, &lockout)
+	assert.Nil(t, err)
+	assert.True(t, lockout.Locked)
+	assert.NotNil(t, lockout.LockedUntil)
+
+	// Unknown user names are locked alike
+	for i := 0; i < 6; i++ {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UUID, "invalid")))
+	}
+	assert.Equal(t, 429, w.Code)
+
+	// delete /users/:id/lockout
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s/lockout", apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/lockout", nil, apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+	lockout = schema.LoginLockout{}
+	err = parseReader(w.Body, &lockout)
+	assert.Nil(t, err)
+	assert.False(t, lockout.Locked)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+
+	// A successful login forgets the failures
+	for i := 0; i < 4; i++ {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, "invalid")))
+		assert.Equal(t, 400, w.Code)
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, "invalid")))
+	assert.Equal(t, 400, w.Code)
+}
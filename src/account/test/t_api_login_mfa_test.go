@@ -0,0 +1,335 @@
+package test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LyricTian/captcha"
+	"github.com/LyricTian/captcha/store"
+	"github.com/MayCMF/core/src/account"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+// Captcha store the tests can read the solutions from
+var captchaStore = store.NewMemoryStore(time.Minute, captcha.Expiration)
+
+func init() {
+	captcha.SetCustomStore(captchaStore)
+}
+
+func newLoginParam(userName, password string) *schema.LoginParam {
+	id := captcha.NewLen(4)
+	var code []byte
+	for _, d := range captchaStore.Get(id, false) {
+		code = append(code, '0'+d)
+	}
+	return &schema.LoginParam{
+		UserName:    userName,
+		Password:    password,
+		CaptchaID:   id,
+		CaptchaCode: string(code),
+	}
+}
+
+func withToken(req *http.Request, token string) *http.Request {
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestAPILoginMFA(t *testing.T) {
+	const router = apiPrefix + "v1/pub/login"
+	var err error
+
+	// post /permissions
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "query", Name: "query"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var permission schema.Permission
+	err = parseReader(w.Body, &permission)
+	assert.Nil(t, err)
+
+	// post /roles
+	role := schema.Role{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Permissions: []*schema.RolePermission{
+			{PermissionID: permission.UUID, Actions: []string{"query"}},
+		},
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &role))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &role)
+	assert.Nil(t, err)
+	assert.False(t, role.MFARequired)
+
+	// post /users
+	password := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: util.MustUUID(),
+		RealName: util.MustUUID(),
+		Password: password,
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: role.UUID}},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var user schema.User
+	err = parseReader(w.Body, &user)
+	assert.Nil(t, err)
+
+	// post /pub/login (no two-factor authentication)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokenInfo.AccessToken)
+	token := tokenInfo.AccessToken
+
+	// post /pub/current/mfa
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/pub/current/mfa", nil), token))
+	assert.Equal(t, 200, w.Code)
+	var enrollment schema.MFAEnrollment
+	err = parseReader(w.Body, &enrollment)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.Contains(t, enrollment.URL, "otpauth://totp/")
+	assert.Contains(t, enrollment.QRCode, "data:image/png;base64,")
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/pub/current/mfa/confirm", &schema.MFACodeParam{Code: "000000x"}), token))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/current/mfa/confirm
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	assert.Nil(t, err)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/pub/current/mfa/confirm", &schema.MFACodeParam{Code: code}), token))
+	assert.Equal(t, 200, w.Code)
+	var recovery schema.MFARecoveryCodes
+	err = parseReader(w.Body, &recovery)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, len(recovery.Codes))
+
+	// get /pub/current/mfa
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/mfa", nil), token))
+	assert.Equal(t, 200, w.Code)
+	var status schema.MFAStatus
+	err = parseReader(w.Body, &status)
+	assert.Nil(t, err)
+	assert.True(t, status.Enabled)
+	assert.False(t, status.Required)
+	assert.Equal(t, 10, status.RecoveryCodes)
+
+	// post /pub/login returns a challenge
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	var challenge schema.LoginMFAChallenge
+	err = parseReader(w.Body, &challenge)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, challenge.MFAToken)
+	assert.False(t, challenge.Enroll)
+
+	// The challenge is not an access token
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), challenge.MFAToken))
+	assert.Equal(t, 401, w.Code)
+
+	// post /pub/login/mfa refuses the code already used
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/mfa", &schema.LoginMFAParam{MFAToken: challenge.MFAToken, Code: code}))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/mfa", &schema.LoginMFAParam{MFAToken: "invalid", Code: recovery.Codes[0]}))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/login/mfa with a recovery code
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/mfa", &schema.LoginMFAParam{MFAToken: challenge.MFAToken, Code: recovery.Codes[0]}))
+	assert.Equal(t, 200, w.Code)
+	tokenInfo = schema.LoginTokenInfo{}
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokenInfo.AccessToken)
+	assert.Empty(t, tokenInfo.RecoveryCodes)
+
+	// Successful logins with a code are not counted against the IP
+	for i := 1; i <= 5; i++ {
+		req := newPostRequest(router, newLoginParam(user.UserName, password))
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+		var ipChallenge schema.LoginMFAChallenge
+		err = parseReader(w.Body, &ipChallenge)
+		assert.Nil(t, err)
+
+		req = newPostRequest(router+"/mfa", &schema.LoginMFAParam{MFAToken: ipChallenge.MFAToken, Code: recovery.Codes[i+1]})
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+	}
+
+	// The challenge is used up
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/mfa", &schema.LoginMFAParam{MFAToken: challenge.MFAToken, Code: recovery.Codes[1]}))
+	assert.Equal(t, 400, w.Code)
+
+	// Wrong codes count as failed logins, the password does not forget them
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	challenge = schema.LoginMFAChallenge{}
+	err = parseReader(w.Body, &challenge)
+	assert.Nil(t, err)
+	for i := 0; i < 4; i++ {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newPostRequest(router+"/mfa", &schema.LoginMFAParam{MFAToken: challenge.MFAToken, Code: "000000"}))
+		assert.Equal(t, 400, w.Code)
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/mfa", &schema.LoginMFAParam{MFAToken: challenge.MFAToken, Code: recovery.Codes[1]}))
+	assert.Equal(t, 429, w.Code)
+
+	// post /pub/current/mfa/disable is locked alike
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/pub/current/mfa/disable", &schema.MFACodeParam{Code: recovery.Codes[1]}), tokenInfo.AccessToken))
+	assert.Equal(t, 429, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s/lockout", apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /users/:id/mfa
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s/mfa", apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// put /roles/:id makes two-factor authentication mandatory
+	role.MFARequired = true
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPutRequest("%s/%s", &role, apiPrefix+"v1/roles", role.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// post /pub/login asks for an enrolment
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	challenge = schema.LoginMFAChallenge{}
+	err = parseReader(w.Body, &challenge)
+	assert.Nil(t, err)
+	assert.True(t, challenge.Enroll)
+
+	// post /pub/login/mfa/enroll
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/mfa/enroll", &schema.LoginMFAEnrollParam{MFAToken: challenge.MFAToken}))
+	assert.Equal(t, 200, w.Code)
+	enrollment = schema.MFAEnrollment{}
+	err = parseReader(w.Body, &enrollment)
+	assert.Nil(t, err)
+
+	code, err = totp.GenerateCode(enrollment.Secret, time.Now())
+	assert.Nil(t, err)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/mfa", &schema.LoginMFAParam{MFAToken: challenge.MFAToken, Code: code}))
+	assert.Equal(t, 200, w.Code)
+	tokenInfo = schema.LoginTokenInfo{}
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokenInfo.AccessToken)
+	assert.Equal(t, 10, len(tokenInfo.RecoveryCodes))
+
+	// post /pub/current/mfa/disable is refused while the role requires it
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/pub/current/mfa/disable", &schema.MFACodeParam{Code: tokenInfo.RecoveryCodes[0]}), tokenInfo.AccessToken))
+	assert.Equal(t, 400, w.Code)
+
+	// delete /users/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", role.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", permission.UUID))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestInitSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "maycmf-secret")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldJWTAuth, oldFileManager := cfg.JWTAuth, cfg.FileManager
+	defer func() { cfg.JWTAuth, cfg.FileManager = oldJWTAuth, oldFileManager }()
+
+	// The secret of the running server is not the token signing key
+	assert.NotEmpty(t, cfg.JWTAuth.SecretKey)
+	assert.NotEqual(t, cfg.JWTAuth.SigningKey, cfg.JWTAuth.SecretKey)
+
+	// A generated secret is stored and read again
+	cfg.JWTAuth.SecretKey = ""
+	cfg.JWTAuth.SecretFile = filepath.Join(dir, "secret")
+	assert.Nil(t, account.InitSecret())
+	secret := cfg.JWTAuth.SecretKey
+	assert.Equal(t, 64, len(secret))
+
+	cfg.JWTAuth.SecretKey = ""
+	assert.Nil(t, account.InitSecret())
+	assert.Equal(t, secret, cfg.JWTAuth.SecretKey)
+
+	// The sample keys are refused
+	cfg.JWTAuth.SecretKey = "MAYCMS"
+	assert.NotNil(t, account.InitSecret())
+
+	cfg.JWTAuth.SecretKey = secret
+	cfg.FileManager.SigningKey = "MayCMF-files"
+	assert.NotNil(t, account.InitSecret())
+
+	cfg.FileManager.SigningKey = ""
+	cfg.JWTAuth.SigningKey = "MAYCMS"
+	assert.NotNil(t, account.InitSecret())
+
+	// A token signing key is generated for the HMAC signature methods only
+	cfg.JWTAuth.SigningKey = ""
+	cfg.JWTAuth.SigningFile = filepath.Join(dir, "signing")
+	cfg.JWTAuth.SigningMethod = "RS256"
+	assert.Nil(t, account.InitSecret())
+	assert.Empty(t, cfg.JWTAuth.SigningKey)
+
+	cfg.JWTAuth.SigningMethod = "HS512"
+	assert.Nil(t, account.InitSecret())
+	signingKey := cfg.JWTAuth.SigningKey
+	assert.Equal(t, 64, len(signingKey))
+	assert.NotEqual(t, secret, signingKey)
+
+	cfg.JWTAuth.SigningKey = ""
+	assert.Nil(t, account.InitSecret())
+	assert.Equal(t, signingKey, cfg.JWTAuth.SigningKey)
+}
@@ -0,0 +1,220 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// Create a user with a role, the returned function deletes them
+func newLoginUser(t *testing.T) (*schema.User, string, func()) {
+	var err error
+
+	// post /permissions
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "query", Name: "query"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var permission schema.Permission
+	err = parseReader(w.Body, &permission)
+	assert.Nil(t, err)
+
+	// post /roles
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &schema.Role{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Permissions: []*schema.RolePermission{
+			{PermissionID: permission.UUID, Actions: []string{"query"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var role schema.Role
+	err = parseReader(w.Body, &role)
+	assert.Nil(t, err)
+
+	// post /users
+	password := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: util.MustUUID(),
+		RealName: util.MustUUID(),
+		Email:    util.MustUUID() + "@example.com",
+		Password: password,
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: role.UUID}},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var user schema.User
+	err = parseReader(w.Body, &user)
+	assert.Nil(t, err)
+
+	return &user, password, func() {
+		// delete /users/:id
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", user.UUID))
+		assert.Equal(t, 200, w.Code)
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", role.UUID))
+		assert.Equal(t, 200, w.Code)
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", permission.UUID))
+		assert.Equal(t, 200, w.Code)
+	}
+}
+
+func TestAPIRefreshToken(t *testing.T) {
+	const router = apiPrefix + "v1/pub/refresh-token"
+
+	user, password, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	// post /pub/login
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err := parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, tokenInfo.RefreshToken)
+
+	// post /pub/refresh-token
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.RefreshTokenParam{RefreshToken: tokenInfo.RefreshToken}))
+	assert.Equal(t, 200, w.Code)
+	var next schema.LoginTokenInfo
+	err = parseReader(w.Body, &next)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, next.AccessToken)
+	assert.NotEmpty(t, next.RefreshToken)
+	assert.NotEqual(t, tokenInfo.RefreshToken, next.RefreshToken)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), next.AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	// Replaying the first refresh token revokes the tokens rotated from it
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.RefreshTokenParam{RefreshToken: tokenInfo.RefreshToken}))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.RefreshTokenParam{RefreshToken: next.RefreshToken}))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), next.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	// Disabling a user revokes its refresh tokens
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	tokenInfo = schema.LoginTokenInfo{}
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPatchRequest("%s/%s/disable", apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.RefreshTokenParam{RefreshToken: tokenInfo.RefreshToken}))
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestAPISession(t *testing.T) {
+	const router = apiPrefix + "v1/pub/current/sessions"
+
+	user, password, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	// post /pub/login with a wrong password
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, "invalid")))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/login from two devices
+	tokens := make([]schema.LoginTokenInfo, 2)
+	for i, userAgent := range []string{"desktop", "phone"} {
+		req := newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, password))
+		req.Header.Set("User-Agent", userAgent)
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+		err := parseReader(w.Body, &tokens[i])
+		assert.Nil(t, err)
+	}
+
+	// get /pub/current/sessions
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, nil), tokens[0].AccessToken))
+	assert.Equal(t, 200, w.Code)
+	var sessions []*schema.UserSession
+	err := parsePageReader(w.Body, &sessions)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(sessions))
+	var phone string
+	for _, item := range sessions {
+		assert.Equal(t, user.UUID, item.UserUUID)
+		assert.Equal(t, item.UserAgent == "desktop", item.Current)
+		if item.UserAgent == "phone" {
+			phone = item.UUID
+		}
+	}
+	assert.NotEmpty(t, phone)
+
+	// get /pub/current/login-history
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/login-history", newPageParam(map[string]string{"pageSize": "10"})), tokens[0].AccessToken))
+	assert.Equal(t, 200, w.Code)
+	var history []*schema.LoginHistory
+	err = parsePageReader(w.Body, &history)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(history))
+	assert.True(t, history[0].Success)
+	assert.Equal(t, "phone", history[0].UserAgent)
+	assert.False(t, history[2].Success)
+	assert.Equal(t, "Invalid password", history[2].Reason)
+
+	// delete /pub/current/sessions/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newDeleteRequest("%s/%s", router, phone), tokens[0].AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokens[1].AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newDeleteRequest("%s/%s", router, util.MustUUID()), tokens[0].AccessToken))
+	assert.Equal(t, 404, w.Code)
+
+	// delete /users/:id/sessions
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s/sessions", apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokens[0].AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	// get /users/:id/sessions
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/sessions", nil, apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+	sessions = nil
+	err = parsePageReader(w.Body, &sessions)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(sessions))
+}
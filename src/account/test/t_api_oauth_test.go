@@ -0,0 +1,632 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTokenRequest(clientID, clientSecret string, values url.Values) *http.Request {
+	req, _ := http.NewRequest("POST", apiPrefix+"v1/pub/oauth/token", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if clientSecret != "" {
+		req.SetBasicAuth(clientID, clientSecret)
+	}
+	return req
+}
+
+func TestAPIOAuth(t *testing.T) {
+	const router = apiPrefix + "v1/pub/oauth/authorize"
+	var err error
+
+	// post /permissions
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "query", Name: "query"},
+		},
+		Resources: []*schema.PermissionResource{
+			{Code: "roles", Name: "roles", Method: "GET", Path: "/api/v1/roles.select"},
+			{Code: "tree", Name: "tree", Method: "GET", Path: "/api/v1/permissions.tree"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var permission schema.Permission
+	err = parseReader(w.Body, &permission)
+	assert.Nil(t, err)
+
+	// post /roles
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &schema.Role{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Permissions: []*schema.RolePermission{
+			{PermissionID: permission.UUID, Actions: []string{"query"}, Resources: []string{"roles", "tree"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var role schema.Role
+	err = parseReader(w.Body, &role)
+	assert.Nil(t, err)
+
+	// post /users
+	password := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: util.MustUUID(),
+		RealName: util.MustUUID(),
+		Email:    util.MustUUID() + "@example.com",
+		Password: password,
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: role.UUID}},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var user schema.User
+	err = parseReader(w.Body, &user)
+	assert.Nil(t, err)
+
+	// post /oauth-scopes
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/oauth-scopes", &schema.OAuthScope{
+		Name:  "roles:read",
+		Title: "Read roles",
+		Permissions: schema.RolePermissions{
+			{PermissionID: permission.UUID, Resources: []string{"roles"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var scope schema.OAuthScope
+	err = parseReader(w.Body, &scope)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/oauth-scopes", &schema.OAuthScope{
+		Name: "roles:read",
+		Permissions: schema.RolePermissions{
+			{PermissionID: permission.UUID, Resources: []string{"tree"}},
+		},
+	}))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/oauth-scopes", &schema.OAuthScope{
+		Name:  "permissions:read",
+		Title: "Read permissions",
+		Permissions: schema.RolePermissions{
+			{PermissionID: permission.UUID, Resources: []string{"tree"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var treeScope schema.OAuthScope
+	err = parseReader(w.Body, &treeScope)
+	assert.Nil(t, err)
+
+	// post /oauth-clients requires registered scopes
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/oauth-clients", &schema.OAuthClient{
+		Name:         "app",
+		Public:       true,
+		RedirectURIs: []string{"https://app.example.com/callback"},
+		GrantTypes:   []string{schema.OAuthGrantAuthorizationCode},
+		Scopes:       []string{"unknown"},
+		Status:       1,
+	}))
+	assert.Equal(t, 400, w.Code)
+
+	// post /oauth-clients, a public client without a secret
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/oauth-clients", &schema.OAuthClient{
+		Name:         "app",
+		Public:       true,
+		RedirectURIs: []string{"https://app.example.com/callback"},
+		GrantTypes:   []string{schema.OAuthGrantAuthorizationCode, schema.OAuthGrantRefreshToken},
+		Scopes:       []string{scope.Name},
+		Status:       1,
+	}))
+	assert.Equal(t, 200, w.Code)
+	var client schema.OAuthClient
+	err = parseReader(w.Body, &client)
+	assert.Nil(t, err)
+	assert.Empty(t, client.Secret)
+
+	// post /pub/login
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+	jwt := tokenInfo.AccessToken
+
+	verifier := util.MustUUID() + util.MustUUID()
+	sum := sha256.Sum256([]byte(verifier))
+	params := map[string]string{
+		"response_type":         "code",
+		"client_id":             client.UUID,
+		"scope":                 scope.Name,
+		"state":                 "xyz",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+		"code_challenge_method": "S256",
+	}
+
+	// get /pub/oauth/authorize, public clients must use PKCE
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, map[string]string{
+		"response_type": "code",
+		"client_id":     client.UUID,
+	}), jwt))
+	assert.Equal(t, 400, w.Code)
+
+	// get /pub/oauth/authorize, unregistered redirect URI
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, map[string]string{
+		"response_type":         "code",
+		"client_id":             client.UUID,
+		"redirect_uri":          "https://evil.example.com/callback",
+		"code_challenge":        params["code_challenge"],
+		"code_challenge_method": "S256",
+	}), jwt))
+	assert.Equal(t, 400, w.Code)
+
+	// get /pub/oauth/authorize
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, params), jwt))
+	assert.Equal(t, 200, w.Code)
+	var consent schema.OAuthConsent
+	err = parseReader(w.Body, &consent)
+	assert.Nil(t, err)
+	assert.Equal(t, "app", consent.ClientName)
+	assert.False(t, consent.Granted)
+	if assert.Len(t, consent.Scopes, 1) {
+		assert.Equal(t, "Read roles", consent.Scopes[0].Title)
+	}
+
+	// post /pub/oauth/authorize, denied by the user
+	authorize := schema.OAuthAuthorizeParam{
+		ResponseType:        "code",
+		ClientID:            client.UUID,
+		Scope:               scope.Name,
+		State:               "xyz",
+		CodeChallenge:       params["code_challenge"],
+		CodeChallengeMethod: "S256",
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(router, authorize), jwt))
+	assert.Equal(t, 200, w.Code)
+	var redirect schema.OAuthRedirect
+	err = parseReader(w.Body, &redirect)
+	assert.Nil(t, err)
+	u, err := url.Parse(redirect.RedirectURI)
+	assert.Nil(t, err)
+	assert.Equal(t, "access_denied", u.Query().Get("error"))
+	assert.Equal(t, "xyz", u.Query().Get("state"))
+
+	// post /pub/oauth/authorize, approved by the user
+	authorize.Approve = true
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(router, authorize), jwt))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &redirect)
+	assert.Nil(t, err)
+	u, err = url.Parse(redirect.RedirectURI)
+	assert.Nil(t, err)
+	assert.Equal(t, "app.example.com", u.Host)
+	assert.Equal(t, "xyz", u.Query().Get("state"))
+	code := u.Query().Get("code")
+	assert.NotEmpty(t, code)
+
+	// post /pub/oauth/token with a wrong code verifier burns the code
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest("", "", url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {client.UUID},
+		"code":          {code},
+		"code_verifier": {util.MustUUID() + util.MustUUID()},
+	}))
+	assert.Equal(t, 400, w.Code)
+	var oauthErr schema.OAuthError
+	err = parseReader(w.Body, &oauthErr)
+	assert.Nil(t, err)
+	assert.Equal(t, "invalid_grant", oauthErr.Error)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(router, authorize), jwt))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &redirect)
+	assert.Nil(t, err)
+	u, _ = url.Parse(redirect.RedirectURI)
+	code = u.Query().Get("code")
+
+	// post /pub/oauth/token
+	exchange := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {client.UUID},
+		"code":          {code},
+		"code_verifier": {verifier},
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest("", "", exchange))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var token schema.OAuthTokenInfo
+	err = parseReader(w.Body, &token)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token.AccessToken)
+	assert.NotEmpty(t, token.RefreshToken)
+	assert.Equal(t, scope.Name, token.Scope)
+	assert.True(t, token.ExpiresIn > 0)
+
+	// the code is only exchanged once
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest("", "", exchange))
+	assert.Equal(t, 400, w.Code)
+
+	// get /roles.select within the scope
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), token.AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	// get /permissions.tree granted to the user, but not to the client
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), token.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	// the client can not authorize other clients
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, params), token.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	// get /pub/oauth/authorize again, the scope is already granted
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(router, params), jwt))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &consent)
+	assert.Nil(t, err)
+	assert.True(t, consent.Granted)
+
+	// post /pub/refresh-token does not rotate the tokens of clients
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/refresh-token", &schema.RefreshTokenParam{
+		RefreshToken: token.RefreshToken,
+	}))
+	assert.Equal(t, 401, w.Code)
+
+	// post /pub/oauth/token with the refresh token
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest("", "", url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {client.UUID},
+		"refresh_token": {token.RefreshToken},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var refreshed schema.OAuthTokenInfo
+	err = parseReader(w.Body, &refreshed)
+	assert.Nil(t, err)
+	assert.NotEqual(t, token.RefreshToken, refreshed.RefreshToken)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), refreshed.AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	// get /pub/current/oauth-grants
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/oauth-grants", nil), jwt))
+	assert.Equal(t, 200, w.Code)
+	var grants struct {
+		List []*schema.OAuthGrant `json:"list"`
+	}
+	err = parseReader(w.Body, &grants)
+	assert.Nil(t, err)
+	if assert.Len(t, grants.List, 1) {
+		assert.Equal(t, "app", grants.List[0].ClientName)
+		assert.Equal(t, []string{scope.Name}, grants.List[0].Scopes)
+	}
+
+	// post /oauth-clients, a client requesting several scopes
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/oauth-clients", &schema.OAuthClient{
+		Name:         "wide",
+		Public:       true,
+		RedirectURIs: []string{"https://wide.example.com/callback"},
+		GrantTypes:   []string{schema.OAuthGrantAuthorizationCode},
+		Scopes:       []string{scope.Name, treeScope.Name},
+		Status:       1,
+	}))
+	assert.Equal(t, 200, w.Code)
+	var wide schema.OAuthClient
+	err = parseReader(w.Body, &wide)
+	assert.Nil(t, err)
+
+	authorizeWide := func(scopes string) schema.OAuthTokenInfo {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, withToken(newPostRequest(router, schema.OAuthAuthorizeParam{
+			ResponseType:        "code",
+			ClientID:            wide.UUID,
+			Scope:               scopes,
+			CodeChallenge:       params["code_challenge"],
+			CodeChallengeMethod: "S256",
+			Approve:             true,
+		}), jwt))
+		assert.Equal(t, 200, w.Code)
+		var redirect schema.OAuthRedirect
+		err := parseReader(w.Body, &redirect)
+		assert.Nil(t, err)
+		u, _ := url.Parse(redirect.RedirectURI)
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newTokenRequest("", "", url.Values{
+			"grant_type":    {"authorization_code"},
+			"client_id":     {wide.UUID},
+			"code":          {u.Query().Get("code")},
+			"code_verifier": {verifier},
+		}))
+		assert.Equal(t, 200, w.Code)
+		var token schema.OAuthTokenInfo
+		err = parseReader(w.Body, &token)
+		assert.Nil(t, err)
+		return token
+	}
+
+	wideToken := authorizeWide(scope.Name + " " + treeScope.Name)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), wideToken.AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	// A narrower authorization yields a token with only the approved scopes
+	narrowCodeToken := authorizeWide(scope.Name)
+	assert.Equal(t, scope.Name, narrowCodeToken.Scope)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), narrowCodeToken.AccessToken))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), narrowCodeToken.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/oauth-clients", wide.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// post /oauth-clients, a confidential client acting as the user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/oauth-clients", &schema.OAuthClient{
+		Name:       "service",
+		GrantTypes: []string{schema.OAuthGrantClientCredentials},
+		Scopes:     []string{scope.Name, treeScope.Name},
+		UserUUID:   user.UUID,
+		Status:     1,
+	}))
+	assert.Equal(t, 200, w.Code)
+	var service schema.OAuthClient
+	err = parseReader(w.Body, &service)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, service.Secret)
+
+	// post /oauth-clients by a manager without the roles of the user acted as
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "add", Name: "add"},
+		},
+		Resources: []*schema.PermissionResource{
+			{Code: "add", Name: "add", Method: "POST", Path: "/api/v1/oauth-clients"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var clientPermission schema.Permission
+	err = parseReader(w.Body, &clientPermission)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &schema.Role{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Permissions: []*schema.RolePermission{
+			{PermissionID: clientPermission.UUID, Actions: []string{"add"}, Resources: []string{"add"}},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var managerRole schema.Role
+	err = parseReader(w.Body, &managerRole)
+	assert.Nil(t, err)
+
+	managerPassword := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: util.MustUUID(),
+		RealName: util.MustUUID(),
+		Email:    util.MustUUID() + "@example.com",
+		Password: managerPassword,
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: managerRole.UUID}},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var manager schema.User
+	err = parseReader(w.Body, &manager)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(manager.UserName, managerPassword)))
+	assert.Equal(t, 200, w.Code)
+	tokenInfo = schema.LoginTokenInfo{}
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	escalation := schema.OAuthClient{
+		Name:       "escalation",
+		GrantTypes: []string{schema.OAuthGrantClientCredentials},
+		Scopes:     []string{scope.Name},
+		UserUUID:   user.UUID,
+		Status:     1,
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/oauth-clients", &escalation), tokenInfo.AccessToken))
+	assert.Equal(t, 400, w.Code)
+
+	escalation.UserUUID = manager.UUID
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/oauth-clients", &escalation), tokenInfo.AccessToken))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &escalation)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/oauth-clients", escalation.UUID))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", manager.UUID))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", managerRole.UUID))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", clientPermission.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// post /pub/oauth/token with a wrong secret
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest(service.UUID, "invalid", url.Values{
+		"grant_type": {"client_credentials"},
+	}))
+	assert.Equal(t, 401, w.Code)
+	err = parseReader(w.Body, &oauthErr)
+	assert.Nil(t, err)
+	assert.Equal(t, "invalid_client", oauthErr.Error)
+
+	// post /pub/oauth/token with a grant type of another client
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest(service.UUID, service.Secret, url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {code},
+	}))
+	assert.Equal(t, 400, w.Code)
+	err = parseReader(w.Body, &oauthErr)
+	assert.Nil(t, err)
+	assert.Equal(t, "unauthorized_client", oauthErr.Error)
+
+	// post /pub/oauth/token beyond the scopes of the client
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest(service.UUID, service.Secret, url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"tree"},
+	}))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/oauth/token
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest(service.UUID, service.Secret, url.Values{
+		"grant_type": {"client_credentials"},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var serviceToken schema.OAuthTokenInfo
+	err = parseReader(w.Body, &serviceToken)
+	assert.Nil(t, err)
+	assert.Empty(t, serviceToken.RefreshToken)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), serviceToken.AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), serviceToken.AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	// post /pub/oauth/token with a subset of the scopes of the client
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest(service.UUID, service.Secret, url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {scope.Name},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var narrowToken schema.OAuthTokenInfo
+	err = parseReader(w.Body, &narrowToken)
+	assert.Nil(t, err)
+	assert.Equal(t, scope.Name, narrowToken.Scope)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), narrowToken.AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/permissions.tree", nil), narrowToken.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	// patch /oauth-clients/:id/secret, the old secret stops working
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPatchRequest("%s/%s/secret", apiPrefix+"v1/oauth-clients", service.UUID))
+	assert.Equal(t, 200, w.Code)
+	var reset schema.OAuthClient
+	err = parseReader(w.Body, &reset)
+	assert.Nil(t, err)
+	assert.NotEqual(t, service.Secret, reset.Secret)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest(service.UUID, service.Secret, url.Values{
+		"grant_type": {"client_credentials"},
+	}))
+	assert.Equal(t, 401, w.Code)
+
+	// delete /oauth-clients/:id revokes the tokens of the client at once
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/oauth-clients", service.UUID))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), serviceToken.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	// delete /pub/current/oauth-grants/:id revokes the tokens at once
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newDeleteRequest("%s/%s", apiPrefix+"v1/pub/current/oauth-grants", grants.List[0].UUID), jwt))
+	assert.Equal(t, 200, w.Code)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/roles.select", nil), refreshed.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newTokenRequest("", "", url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {client.UUID},
+		"refresh_token": {refreshed.RefreshToken},
+	}))
+	assert.Equal(t, 400, w.Code)
+
+	// delete /oauth-clients/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/oauth-clients", client.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /oauth-scopes/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/oauth-scopes", scope.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/oauth-scopes", treeScope.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /users/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /roles/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", role.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /permissions/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", permission.UUID))
+	assert.Equal(t, 200, w.Code)
+}
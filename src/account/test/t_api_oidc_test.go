@@ -0,0 +1,253 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/auth/oidc/oidctest"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// Run a login at the mock identity provider up to the callback
+func oidcLogin(t *testing.T, server *oidctest.Server, claims map[string]interface{}) *httptest.ResponseRecorder {
+	const router = apiPrefix + "v1/pub/login/oidc/mock"
+	server.SetClaims(claims)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, nil))
+	if !assert.Equal(t, http.StatusFound, w.Code) {
+		return w
+	}
+	cookies := w.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(w.Header().Get("Location"))
+	if !assert.Nil(t, err) {
+		return w
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	assert.Nil(t, err)
+
+	req := newGetRequest(callback.Path, map[string]string{
+		"code":  callback.Query().Get("code"),
+		"state": callback.Query().Get("state"),
+	})
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIOIDC(t *testing.T) {
+	var err error
+
+	// post /permissions
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/permissions", &schema.Permission{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+		Actions: []*schema.PermissionAction{
+			{Code: "query", Name: "query"},
+		},
+		Resources: []*schema.PermissionResource{
+			{Code: "query", Name: "query", Method: "GET", Path: "/test/v1/oidc"},
+		},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var permission schema.Permission
+	err = parseReader(w.Body, &permission)
+	assert.Nil(t, err)
+
+	// post /roles
+	var roles []schema.Role
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/roles", &schema.Role{
+			Name:     util.MustUUID(),
+			Sequence: 9999999,
+			Permissions: []*schema.RolePermission{
+				{PermissionID: permission.UUID, Actions: []string{"query"}, Resources: []string{"query"}},
+			},
+		}))
+		assert.Equal(t, 200, w.Code)
+		var role schema.Role
+		err = parseReader(w.Body, &role)
+		assert.Nil(t, err)
+		roles = append(roles, role)
+	}
+	editor, viewer := roles[0], roles[1]
+
+	server := oidctest.NewServer("maycmf", "secret")
+	defer server.Close()
+
+	cfg := config.Global()
+	providers := cfg.OIDC.Providers
+	defer func() { cfg.OIDC.Providers = providers }()
+	cfg.OIDC.Providers = []config.OIDCProvider{{
+		Name:         "mock",
+		Title:        "Mock",
+		Issuer:       server.Issuer(),
+		ClientID:     "maycmf",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost" + apiPrefix + "v1/pub/login/oidc/mock/callback",
+		Scopes:       []string{"email", "profile"},
+		AutoCreate:   true,
+		LinkByEmail:  true,
+		RoleClaim:    "groups",
+		RoleMapping: map[string]string{
+			"editors": editor.Name,
+			"viewers": viewer.Name,
+		},
+	}}
+
+	// get /pub/login/oidc
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/pub/login/oidc", nil))
+	assert.Equal(t, 200, w.Code)
+	var providerItems struct {
+		List []*schema.OIDCProvider `json:"list"`
+	}
+	err = parseReader(w.Body, &providerItems)
+	assert.Nil(t, err)
+	assert.Equal(t, []*schema.OIDCProvider{{Name: "mock", Title: "Mock"}}, providerItems.List)
+
+	// get /pub/login/oidc/:provider of an unknown provider
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/pub/login/oidc/other", nil))
+	assert.Equal(t, 404, w.Code)
+
+	// get /pub/login/oidc/:provider/callback without the state cookie
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/pub/login/oidc/mock/callback", map[string]string{
+		"code":  "code",
+		"state": "state",
+	}))
+	assert.Equal(t, 400, w.Code)
+
+	// The first login creates the user with the mapped roles
+	userName := util.MustUUID()
+	w = oidcLogin(t, server, map[string]interface{}{
+		"sub":                util.MustUUID(),
+		"preferred_username": userName,
+		"email":              userName + "@example.com",
+		"email_verified":     true,
+		"groups":             []string{"editors", "unknown"},
+	})
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	// get /pub/current/user
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 200, w.Code)
+	var loginInfo schema.UserLoginInfo
+	err = parseReader(w.Body, &loginInfo)
+	assert.Nil(t, err)
+	assert.Equal(t, userName, loginInfo.UserName)
+	assert.Equal(t, []string{editor.Name}, loginInfo.RoleNames)
+
+	// post /users
+	bobName := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: bobName,
+		RealName: bobName,
+		Email:    bobName + "@example.com",
+		Password: util.MustUUID(),
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: viewer.UUID}},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var bob schema.User
+	err = parseReader(w.Body, &bob)
+	assert.Nil(t, err)
+
+	// An existing user is only linked by a verified email address
+	bobClaims := map[string]interface{}{
+		"sub":            util.MustUUID(),
+		"email":          bob.Email,
+		"email_verified": false,
+		"groups":         []string{"editors"},
+	}
+	w = oidcLogin(t, server, bobClaims)
+	assert.Equal(t, 400, w.Code)
+
+	bobClaims["email_verified"] = true
+	w = oidcLogin(t, server, bobClaims)
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &loginInfo)
+	assert.Nil(t, err)
+	assert.Equal(t, bob.UserName, loginInfo.UserName)
+	assert.Equal(t, []string{editor.Name}, loginInfo.RoleNames)
+
+	// The linked identity logs in even after its email changed, the mapped
+	// roles follow the claims
+	w = oidcLogin(t, server, map[string]interface{}{
+		"sub":    bobClaims["sub"],
+		"email":  util.MustUUID() + "@example.com",
+		"groups": []string{"viewers"},
+	})
+	assert.Equal(t, 200, w.Code)
+
+	// get /users/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s", nil, apiPrefix+"v1/users", bob.UUID))
+	assert.Equal(t, 200, w.Code)
+	var bobItem schema.User
+	err = parseReader(w.Body, &bobItem)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(bobItem.Roles))
+	assert.Equal(t, viewer.UUID, bobItem.Roles[0].RoleID)
+
+	// query /users by name and delete the created users
+	for _, name := range []string{userName, bobName} {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/users", map[string]string{
+			"current":  "1",
+			"pageSize": "1",
+			"userName": name,
+		}))
+		assert.Equal(t, 200, w.Code)
+		var userItems []*schema.User
+		err = parsePageReader(w.Body, &userItems)
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(userItems)) {
+			w = httptest.NewRecorder()
+			engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", userItems[0].UUID))
+			assert.Equal(t, 200, w.Code)
+		}
+	}
+
+	// delete /roles/:id
+	for _, role := range roles {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/roles", role.UUID))
+		assert.Equal(t, 200, w.Code)
+	}
+
+	// delete /permissions/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/permissions", permission.UUID))
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,233 @@
+package test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/auth/password"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+)
+
+type passwordPolicyError struct {
+	Error struct {
+		Message string                     `json:"message"`
+		Details []schema.PasswordViolation `json:"details"`
+	} `json:"error"`
+}
+
+func violationCodes(t *testing.T, w *httptest.ResponseRecorder) []string {
+	var result passwordPolicyError
+	err := parseReader(w.Body, &result)
+	assert.Nil(t, err)
+
+	var codes []string
+	for _, item := range result.Error.Details {
+		codes = append(codes, item.Code)
+	}
+	return codes
+}
+
+func TestAPIPasswordPolicy(t *testing.T) {
+	const router = apiPrefix + "v1/pub/password/check"
+
+	// post /pub/password/check
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.PasswordCheckParam{Password: "qwerty"}))
+	assert.Equal(t, 200, w.Code)
+	var result schema.PasswordCheckResult
+	err := parseReader(w.Body, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Score)
+	assert.NotEmpty(t, result.Violations)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.PasswordCheckParam{Password: util.MustUUID()}))
+	assert.Equal(t, 200, w.Code)
+	result = schema.PasswordCheckResult{}
+	err = parseReader(w.Body, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, result.Score)
+	assert.Empty(t, result.Violations)
+
+	// post /users with a weak password
+	userName := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: userName,
+		RealName: util.MustUUID(),
+		Password: "MayCMF123",
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: util.MustUUID()}},
+	}))
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, violationCodes(t, w), password.ViolationBanned)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/users", &schema.User{
+		UserName: userName,
+		RealName: util.MustUUID(),
+		Password: userName + "!",
+		Status:   1,
+		Roles:    []*schema.UserRole{{RoleID: util.MustUUID()}},
+	}))
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, violationCodes(t, w), password.ViolationUserInput)
+
+	user, oldPassword, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, oldPassword)))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	// put /pub/current/password refuses the current and former passwords
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPutRequest(apiPrefix+"v1/pub/current/password", &schema.UpdatePasswordParam{
+		OldPassword: oldPassword,
+		NewPassword: oldPassword,
+	}), tokenInfo.AccessToken))
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, []string{password.ViolationReused}, violationCodes(t, w))
+
+	newPassword := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPutRequest(apiPrefix+"v1/pub/current/password", &schema.UpdatePasswordParam{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}), tokenInfo.AccessToken))
+	assert.Equal(t, 200, w.Code)
+
+	// put /users/:id refuses a former password as well
+	user.Password = oldPassword
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPutRequest("%s/%s", user, apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, []string{password.ViolationReused}, violationCodes(t, w))
+
+	user.Password = util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPutRequest("%s/%s", user, apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAPIPasswordMaxAge(t *testing.T) {
+	const router = apiPrefix + "v1/pub/password/change"
+
+	cfg := config.Global()
+	cfg.Password.MaxAge = 30
+	defer func() { cfg.Password.MaxAge = 0 }()
+
+	user, oldPassword, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, oldPassword)))
+	assert.Equal(t, 200, w.Code)
+
+	err := container.Invoke(func(db *gorm.DB) error {
+		return entity.GetUserDB(context.Background(), db).Where("record_id=?", user.UUID).
+			Update("password_changed_at", time.Now().AddDate(0, 0, -31)).Error
+	})
+	assert.Nil(t, err)
+
+	// post /pub/login with an expired password
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, oldPassword)))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/password/change
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.PasswordChangeParam{
+		UserName:    user.UserName,
+		OldPassword: "invalid",
+		NewPassword: util.MustUUID(),
+	}))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.PasswordChangeParam{
+		UserName:    user.UserName,
+		OldPassword: oldPassword,
+		NewPassword: oldPassword,
+	}))
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, []string{password.ViolationReused}, violationCodes(t, w))
+
+	newPassword := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.PasswordChangeParam{
+		UserName:    user.UserName,
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, newPassword)))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAPILegacyPassword(t *testing.T) {
+	const router = apiPrefix + "v1/pub/password/change"
+
+	user, oldPassword, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	setLegacy := func() {
+		err := container.Invoke(func(db *gorm.DB) error {
+			return entity.GetUserDB(context.Background(), db).Where("record_id=?", user.UUID).
+				Update("password", util.SHA1HashString(oldPassword)).Error
+		})
+		assert.Nil(t, err)
+	}
+
+	// post /pub/login upgrades a legacy hash
+	setLegacy()
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, oldPassword)))
+	assert.Equal(t, 200, w.Code)
+
+	err := container.Invoke(func(db *gorm.DB) error {
+		var item entity.User
+		err := entity.GetUserDB(context.Background(), db).Where("record_id=?", user.UUID).First(&item).Error
+		if err == nil {
+			assert.False(t, password.IsLegacy(*item.Password))
+		}
+		return err
+	})
+	assert.Nil(t, err)
+
+	// Forced resets refuse the login but allow to change the password
+	cfg := config.Global()
+	cfg.Password.ForceResetLegacy = true
+	defer func() { cfg.Password.ForceResetLegacy = false }()
+
+	setLegacy()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, oldPassword)))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/password/change
+	newPassword := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.PasswordChangeParam{
+		UserName:    user.UserName,
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, newPassword)))
+	assert.Equal(t, 200, w.Code)
+}
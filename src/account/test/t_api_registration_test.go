@@ -0,0 +1,270 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRegisterParam(invitation string) *schema.RegisterParam {
+	return &schema.RegisterParam{
+		UserName:   util.MustUUID(),
+		RealName:   util.MustUUID(),
+		Email:      util.MustUUID() + "@example.com",
+		Password:   util.MustUUID(),
+		Invitation: invitation,
+	}
+}
+
+func TestAPIRegistration(t *testing.T) {
+	const router = apiPrefix + "v1/pub/register"
+	var err error
+
+	cfg := config.Global()
+	register := cfg.Register
+	defer func() { cfg.Register = register }()
+
+	user, _, cleanup := newLoginUser(t)
+	defer cleanup()
+	roleID := user.Roles[0].RoleID
+
+	// get /roles/:id
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s", nil, apiPrefix+"v1/roles", roleID))
+	assert.Equal(t, 200, w.Code)
+	var role schema.Role
+	err = parseReader(w.Body, &role)
+	assert.Nil(t, err)
+
+	cfg.Register.Mode = schema.RegisterDisabled
+	cfg.Register.Captcha = false
+	cfg.Register.VerifyEmail = true
+	cfg.Register.DefaultRoles = []string{role.Name}
+
+	// post /pub/register
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newRegisterParam("")))
+	assert.Equal(t, 400, w.Code)
+
+	cfg.Register.Mode = schema.RegisterOpen
+
+	// get /pub/register
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, nil))
+	assert.Equal(t, 200, w.Code)
+	var info schema.RegisterInfo
+	err = parseReader(w.Body, &info)
+	assert.Nil(t, err)
+	assert.Equal(t, schema.RegisterOpen, info.Mode)
+
+	// post /pub/register
+	param := newRegisterParam("")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, param))
+	assert.Equal(t, 200, w.Code)
+	var nuser schema.User
+	err = parseReader(w.Body, &nuser)
+	assert.Nil(t, err)
+	assert.Equal(t, schema.UserCreatorRegister, nuser.Creator)
+	assert.False(t, nuser.EmailVerified)
+	assert.Empty(t, nuser.Password)
+	if assert.Len(t, nuser.Roles, 1) {
+		assert.Equal(t, roleID, nuser.Roles[0].RoleID)
+	}
+
+	// An address signs up once
+	dup := newRegisterParam("")
+	dup.Email = param.Email
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, dup))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/login is refused until the address is verified
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(param.UserName, param.Password)))
+	assert.Equal(t, 400, w.Code)
+
+	// post /pub/email/resend
+	mailbox.Reset()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/email/resend", &schema.EmailResendParam{Email: param.Email}))
+	assert.Equal(t, 200, w.Code)
+	token := mailToken(mailbox.Last(param.Email))
+	assert.NotEmpty(t, token)
+
+	// post /pub/email/verify
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/email/verify", &schema.EmailVerifyParam{Token: token}))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(param.UserName, param.Password)))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /users/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", nuser.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// Without a granted role nobody signs up
+	cfg.Register.DefaultRoles = nil
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, newRegisterParam("")))
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestAPIInvitation(t *testing.T) {
+	const router = apiPrefix + "v1/invitations"
+	const registerRouter = apiPrefix + "v1/pub/register"
+	var err error
+
+	cfg := config.Global()
+	register := cfg.Register
+	defer func() { cfg.Register = register }()
+
+	cfg.Register.Mode = schema.RegisterInvite
+	cfg.Register.Captcha = false
+	cfg.Register.VerifyEmail = true
+
+	user, _, cleanup := newLoginUser(t)
+	defer cleanup()
+	roleID := user.Roles[0].RoleID
+
+	// An invitation is required
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(registerRouter, newRegisterParam("")))
+	assert.Equal(t, 400, w.Code)
+
+	// post /invitations
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.Invitation{
+		Note:    "Welcome",
+		Roles:   []string{roleID},
+		MaxUses: 1,
+	}))
+	assert.Equal(t, 200, w.Code)
+	var invitation schema.Invitation
+	err = parseReader(w.Body, &invitation)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, invitation.Token)
+	assert.Contains(t, invitation.URL, invitation.Token)
+	assert.True(t, invitation.ExpiresAt.After(time.Now()))
+
+	// Expired invitations are refused
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.Invitation{
+		Roles:     []string{roleID},
+		ExpiresAt: time.Now().Add(-time.Hour),
+	}))
+	assert.Equal(t, 400, w.Code)
+
+	// get /pub/register
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(registerRouter, map[string]string{"invitation": invitation.Token}))
+	assert.Equal(t, 200, w.Code)
+
+	// post /pub/register
+	param := newRegisterParam(invitation.Token)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(registerRouter, param))
+	assert.Equal(t, 200, w.Code)
+	var nuser schema.User
+	err = parseReader(w.Body, &nuser)
+	assert.Nil(t, err)
+	assert.Equal(t, schema.UserCreatorInvitationPrefix+invitation.UUID, nuser.Creator)
+	if assert.Len(t, nuser.Roles, 1) {
+		assert.Equal(t, roleID, nuser.Roles[0].RoleID)
+	}
+
+	// The usage limit is reached
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(registerRouter, newRegisterParam(invitation.Token)))
+	assert.Equal(t, 400, w.Code)
+
+	// get /invitations/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s", nil, router, invitation.UUID))
+	assert.Equal(t, 200, w.Code)
+	var ninvitation schema.Invitation
+	err = parseReader(w.Body, &ninvitation)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, ninvitation.Uses)
+	assert.Empty(t, ninvitation.Token)
+
+	// An invitation mailed to an address verifies it
+	email := util.MustUUID() + "@example.com"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.Invitation{
+		Email: email,
+		Roles: []string{roleID},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var mailed schema.Invitation
+	err = parseReader(w.Body, &mailed)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, mailed.MaxUses)
+	token := mailToken(mailbox.Last(email))
+	assert.Equal(t, mailed.Token, token)
+
+	// Only the invited address signs up
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(registerRouter, newRegisterParam(token)))
+	assert.Equal(t, 400, w.Code)
+
+	mparam := newRegisterParam(token)
+	mparam.Email = email
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(registerRouter, mparam))
+	assert.Equal(t, 200, w.Code)
+	var muser schema.User
+	err = parseReader(w.Body, &muser)
+	assert.Nil(t, err)
+	assert.True(t, muser.EmailVerified)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(mparam.UserName, mparam.Password)))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /invitations/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.Invitation{
+		Roles: []string{roleID},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var deleted schema.Invitation
+	err = parseReader(w.Body, &deleted)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, deleted.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(registerRouter, map[string]string{"invitation": deleted.Token}))
+	assert.Equal(t, 400, w.Code)
+
+	// get /invitations
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, newPageParam()))
+	assert.Equal(t, 200, w.Code)
+	var pageItems []*schema.Invitation
+	err = parsePageReader(w.Body, &pageItems)
+	assert.Nil(t, err)
+	assert.Len(t, pageItems, 1)
+
+	for _, id := range []string{nuser.UUID, muser.UUID} {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", apiPrefix+"v1/users", id))
+		assert.Equal(t, 200, w.Code)
+	}
+	for _, id := range []string{invitation.UUID, mailed.UUID} {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, id))
+		assert.Equal(t, 200, w.Code)
+	}
+}
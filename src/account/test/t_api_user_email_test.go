@@ -0,0 +1,189 @@
+package test
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/MayCMF/core/src/account/schema"
+	"github.com/MayCMF/core/src/common/mailer"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var mailTokenRegexp = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// Token of the link of a mail
+func mailToken(msg *mailer.Message) string {
+	if msg == nil {
+		return ""
+	}
+	if m := mailTokenRegexp.FindStringSubmatch(msg.Text); len(m) == 2 {
+		return m[1]
+	}
+	return ""
+}
+
+func TestAPIEmailVerification(t *testing.T) {
+	const router = apiPrefix + "v1/pub/email/verify"
+	var err error
+
+	user, password, cleanup := newLoginUser(t)
+	defer cleanup()
+	assert.False(t, user.EmailVerified)
+
+	// A new user gets a verification link
+	msg := mailbox.Last(user.Email)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "Confirm your email address", msg.Subject)
+	}
+	token := mailToken(msg)
+	assert.NotEmpty(t, token)
+
+	// post /pub/login
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	// post /pub/email/verify
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.EmailVerifyParam{Token: token}))
+	assert.Equal(t, 200, w.Code)
+
+	// A link is used once
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.EmailVerifyParam{Token: token}))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 200, w.Code)
+	var loginInfo schema.UserLoginInfo
+	err = parseReader(w.Body, &loginInfo)
+	assert.Nil(t, err)
+	assert.True(t, loginInfo.EmailVerified)
+
+	// post /pub/current/email/verification
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/pub/current/email/verification", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 400, w.Code)
+
+	// put /users/:id keeps the verification of an unchanged address
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPutRequest("%s/%s", user, apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+	var nuser schema.User
+	err = parseReader(w.Body, &nuser)
+	assert.Nil(t, err)
+	assert.True(t, nuser.EmailVerified)
+
+	// A new address is verified again, the mail is in the language of the client
+	user.Email = util.MustUUID() + "@example.com"
+	req := newPutRequest("%s/%s", user, apiPrefix+"v1/users", user.UUID)
+	req.Header.Set("Accept-Language", "uk-UA,uk;q=0.9")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	nuser = schema.User{}
+	err = parseReader(w.Body, &nuser)
+	assert.Nil(t, err)
+	assert.False(t, nuser.EmailVerified)
+
+	msg = mailbox.Last(user.Email)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "Підтвердіть адресу електронної пошти", msg.Subject)
+	}
+	first := mailToken(msg)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newPostRequest(apiPrefix+"v1/pub/current/email/verification", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 200, w.Code)
+	second := mailToken(mailbox.Last(user.Email))
+	assert.NotEqual(t, first, second)
+
+	// A new link replaces the former one
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.EmailVerifyParam{Token: first}))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router, &schema.EmailVerifyParam{Token: second}))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAPIPasswordReset(t *testing.T) {
+	const router = apiPrefix + "v1/pub/password"
+	var err error
+
+	user, password, cleanup := newLoginUser(t)
+	defer cleanup()
+
+	// post /pub/login
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, password)))
+	assert.Equal(t, 200, w.Code)
+	var tokenInfo schema.LoginTokenInfo
+	err = parseReader(w.Body, &tokenInfo)
+	assert.Nil(t, err)
+
+	// post /pub/password/forgot does not tell unknown addresses
+	count := len(mailbox.Messages())
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/forgot", &schema.PasswordForgotParam{Email: util.MustUUID() + "@example.com"}))
+	assert.Equal(t, 200, w.Code)
+	assert.Len(t, mailbox.Messages(), count)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/forgot", &schema.PasswordForgotParam{Email: user.Email}))
+	assert.Equal(t, 200, w.Code)
+	msg := mailbox.Last(user.Email)
+	if assert.NotNil(t, msg) {
+		assert.Equal(t, "Reset your password", msg.Subject)
+	}
+	first := mailToken(msg)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/forgot", &schema.PasswordForgotParam{Email: user.Email}))
+	assert.Equal(t, 200, w.Code)
+	second := mailToken(mailbox.Last(user.Email))
+	assert.NotEqual(t, first, second)
+
+	// post /pub/password/reset
+	newPassword := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/reset", &schema.PasswordResetParam{Token: first, Password: newPassword}))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/reset", &schema.PasswordResetParam{Token: second, Password: newPassword}))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/reset", &schema.PasswordResetParam{Token: second, Password: util.MustUUID()}))
+	assert.Equal(t, 400, w.Code)
+
+	// The user is logged out everywhere
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, withToken(newGetRequest(apiPrefix+"v1/pub/current/user", nil), tokenInfo.AccessToken))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, password)))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/pub/login", newLoginParam(user.UserName, newPassword)))
+	assert.Equal(t, 200, w.Code)
+
+	// The reset link proves the address
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s", nil, apiPrefix+"v1/users", user.UUID))
+	assert.Equal(t, 200, w.Code)
+	var nuser schema.User
+	err = parseReader(w.Body, &nuser)
+	assert.Nil(t, err)
+	assert.True(t, nuser.EmailVerified)
+}
@@ -11,16 +11,23 @@ import (
 
 	app "github.com/MayCMF/core/src"
 	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/mailer"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/dig"
 )
 
 const (
 	configFile = "../../../configs/config.toml"
 	modelFile  = "../../../configs/model.conf"
+	mailDir    = "../../../configs/mail"
 	apiPrefix  = "/api/"
 )
 
-var engine *gin.Engine
+var (
+	engine    *gin.Engine
+	container *dig.Container
+	mailbox   *mailer.Memory
+)
 
 func init() {
 	// Initialize configuration file
@@ -35,8 +42,19 @@ func init() {
 	cfg.Casbin.Model = modelFile
 	cfg.Gorm.Debug = false
 	cfg.Gorm.DBType = "sqlite3"
-
-	container, _ := app.BuildContainer()
+	cfg.Mailer.Driver = "memory"
+	cfg.Mailer.TemplateDir = mailDir
+	cfg.Lockout.Enable = true
+	cfg.Lockout.Store = "memory"
+	cfg.Lockout.MaxAttempts = 5
+	cfg.Lockout.MaxIPAttempts = 3
+	cfg.Lockout.Delay = 0
+	cfg.Password.BannedFile = "../../../configs/banned_passwords.txt"
+
+	container, _ = app.BuildContainer()
+	_ = container.Invoke(func(m mailer.Mailer) {
+		mailbox = m.(*mailer.Memory)
+	})
 	engine = app.InitWeb(container)
 }
 
@@ -105,6 +123,11 @@ func newPutRequest(formatRouter string, v interface{}, args ...interface{}) *htt
 	return req
 }
 
+func newPatchRequest(formatRouter string, args ...interface{}) *http.Request {
+	req, _ := http.NewRequest("PATCH", fmt.Sprintf(formatRouter, args...), nil)
+	return req
+}
+
 func newDeleteRequest(formatRouter string, args ...interface{}) *http.Request {
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf(formatRouter, args...), nil)
 	return req
@@ -6,11 +6,14 @@ import (
 	"os"
 
 	"github.com/MayCMF/core/src/account"
+	accountcontrollers "github.com/MayCMF/core/src/account/controllers"
+	"github.com/MayCMF/core/src/audit"
 	"github.com/MayCMF/core/src/filemanager"
 	"github.com/MayCMF/core/src/i18n"
 	"github.com/MayCMF/core/src/primitives"
 
 	"github.com/MayCMF/core/src/common/auth"
+	"github.com/MayCMF/core/src/common/auth/jwtauth"
 	"github.com/MayCMF/core/src/common/boot"
 	"github.com/MayCMF/core/src/common/config"
 	"github.com/MayCMF/core/src/common/logger"
@@ -151,13 +154,32 @@ func BuildContainer() (*dig.Container, func()) {
 	container := dig.New()
 
 	// Injection authentication module
-	auther, err := account.InitAuth()
+	err := account.InitSecret()
 	handleError(err)
 
-	container.Provide(func() auth.Auther {
-		return auther
+	keySet, err := account.InitKeySet()
+	handleError(err)
+
+	container.Provide(func() *jwtauth.KeySet { return keySet })
+
+	auther, err := account.InitAuth(keySet)
+	handleError(err)
+
+	container.Provide(func(bAccessToken accountcontrollers.IAccessToken) auth.Auther {
+		return account.NewTokenAuther(auther, bAccessToken)
 	})
 
+	// Injection password hashing
+	container.Provide(account.InitPassword)
+	container.Provide(account.InitPasswordPolicy)
+
+	// Injection login brute-force protection
+	container.Provide(account.InitLockout)
+
+	// Injection mailer
+	container.Provide(account.InitMailer)
+	container.Provide(account.InitMailTemplates)
+
 	// Inject casbin
 	container.Provide(account.NewCasbinEnforcer)
 
@@ -169,11 +191,10 @@ func BuildContainer() (*dig.Container, func()) {
 	err = transaction.InjectControllers(container)
 	handleError(err)
 
-	err = account.InjectControllers(container)
+	err = audit.InjectControllers(container)
 	handleError(err)
 
-	// Initialize casbin
-	err = account.InitCasbinEnforcer(container)
+	err = account.InjectControllers(container)
 	handleError(err)
 
 	err = i18n.InjectControllers(container)
@@ -185,12 +206,21 @@ func BuildContainer() (*dig.Container, func()) {
 	err = filemanager.InjectControllers(container)
 	handleError(err)
 
+	// Initialize casbin once every module is injected, loading user policies
+	// needs the file usage index of the filemanager module
+	err = account.InitCasbinEnforcer(container)
+	handleError(err)
+
 	// ---------------------------------------------------
 	return container, func() {
 		if auther != nil {
 			_ = auther.Release()
 		}
 
+		if keySet != nil {
+			keySet.Stop()
+		}
+
 		// Release resources
 		account.ReleaseCasbinEnforcer(container)
 
@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"github.com/MayCMF/core/src/audit/controllers"
+	"github.com/MayCMF/core/src/audit/controllers/implement"
+	"github.com/MayCMF/core/src/audit/model"
+	imodel "github.com/MayCMF/core/src/audit/model/impl/gorm/model"
+	"go.uber.org/dig"
+)
+
+// InjectControllers - injection controllers implementation
+func InjectControllers(container *dig.Container) error {
+	_ = container.Provide(implement.NewAudit)
+	_ = container.Provide(func(b *implement.Audit) controllers.IAudit { return b })
+	return nil
+}
+
+// InjectStarage - Injection of gorm
+func InjectStarage(container *dig.Container) error {
+	_ = container.Provide(imodel.NewAudit)
+	_ = container.Provide(func(m *imodel.Audit) model.IAudit { return m })
+	return nil
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/MayCMF/core/src/audit/schema"
+)
+
+// Export formats of the audit log
+const (
+	AuditFormatCSV  = "csv"
+	AuditFormatJSON = "json"
+)
+
+// IAudit - Audit log business logic interface
+type IAudit interface {
+	// Query data
+	Query(ctx context.Context, params schema.AuditQueryParam, opts ...schema.AuditQueryOptions) (*schema.AuditQueryResult, error)
+	// Write every entry matching the conditions in an export format
+	Export(ctx context.Context, w io.Writer, format string, params schema.AuditQueryParam) error
+	// Record an action on an entity by the user of the context, before is nil
+	// for a created entity and after for a deleted one
+	Record(ctx context.Context, entityType, action, entityID string, before, after interface{}) error
+}
+
+// AuditStatusAction - Action of a status update, status 1 enables an entity
+// and any other status disables it
+func AuditStatusAction(status int) string {
+	if status == 1 {
+		return schema.AuditActionEnable
+	}
+	return schema.AuditActionDisable
+}
+
+// Value of a redacted field
+var redacted = schema.AuditValue(`"******"`)
+
+// Fields changed on every save, they are not changes of the entity
+var ignoredFields = []string{"updated_at"}
+
+// Values of unset fields, they are left out of created and deleted entities
+var emptyValues = []string{`null`, `""`, `0`, `false`, `[]`, `{}`, `"0001-01-01T00:00:00Z"`}
+
+// DiffAudit - Compare the JSON fields of two objects, either may be nil.
+// Values of the password and secret fields are redacted.
+func DiffAudit(before, after interface{}) (schema.AuditChanges, error) {
+	bfields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range bfields {
+		names = append(names, name)
+	}
+	for name := range afields {
+		if _, ok := bfields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := schema.AuditChanges{}
+	for _, name := range names {
+		if isIgnoredField(name) {
+			continue
+		}
+
+		bv, av := auditValue(bfields[name]), auditValue(afields[name])
+		if bytes.Equal(bv, av) || (isEmptyValue(bv) && isEmptyValue(av)) {
+			continue
+		}
+
+		if isSecretField(name) {
+			if !isEmptyValue(bv) {
+				bv = redacted
+			}
+			if !isEmptyValue(av) {
+				av = redacted
+			}
+		}
+		changes = append(changes, &schema.AuditChange{Field: name, Before: bv, After: av})
+	}
+	return changes, nil
+}
+
+func auditFields(v interface{}) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if v == nil {
+		return fields, nil
+	}
+
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	} else if bytes.Equal(buf, []byte("null")) {
+		// Typed nil pointer
+		return fields, nil
+	}
+
+	err = json.Unmarshal(buf, &fields)
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func auditValue(v json.RawMessage) schema.AuditValue {
+	if len(v) == 0 {
+		return schema.AuditValue("null")
+	}
+	return schema.AuditValue(v)
+}
+
+func isEmptyValue(v schema.AuditValue) bool {
+	for _, s := range emptyValues {
+		if string(v) == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isIgnoredField(name string) bool {
+	for _, s := range ignoredFields {
+		if name == s {
+			return true
+		}
+	}
+	return false
+}
+
+func isSecretField(name string) bool {
+	name = strings.ToLower(name)
+	return name == "password" || strings.Contains(name, "secret")
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/MayCMF/core/src/audit/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type auditItem struct {
+	Name      string    `json:"name"`
+	Sequence  int       `json:"sequence"`
+	Password  string    `json:"password"`
+	APISecret string    `json:"api_secret"`
+	Roles     []string  `json:"roles"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func TestDiffAudit(t *testing.T) {
+	before := &auditItem{Name: "editor", Sequence: 1, Password: "old", Roles: []string{"a"}}
+	after := &auditItem{Name: "editor", Sequence: 2, Password: "new", APISecret: "key", Roles: []string{"a", "b"}, UpdatedAt: time.Now()}
+
+	changes, err := DiffAudit(before, after)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(changes))
+
+	// Sorted by field, secrets are redacted and updated_at is left out
+	assert.Equal(t, "api_secret", changes[0].Field)
+	assert.Equal(t, `""`, string(changes[0].Before))
+	assert.Equal(t, `"******"`, string(changes[0].After))
+	assert.Equal(t, "password", changes[1].Field)
+	assert.Equal(t, `"******"`, string(changes[1].Before))
+	assert.Equal(t, `"******"`, string(changes[1].After))
+	assert.Equal(t, "roles", changes[2].Field)
+	assert.Equal(t, `["a"]`, string(changes[2].Before))
+	assert.Equal(t, `["a","b"]`, string(changes[2].After))
+	assert.Equal(t, "sequence", changes[3].Field)
+	assert.Equal(t, `1`, string(changes[3].Before))
+	assert.Equal(t, `2`, string(changes[3].After))
+
+	// Nothing changed
+	changes, err = DiffAudit(before, before)
+	assert.Nil(t, err)
+	assert.NotNil(t, changes)
+	assert.Equal(t, 0, len(changes))
+
+	// Created entities only list the fields that are set
+	changes, err = DiffAudit(nil, before)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(changes))
+	assert.Equal(t, "name", changes[0].Field)
+	assert.Equal(t, `null`, string(changes[0].Before))
+	assert.Equal(t, `"editor"`, string(changes[0].After))
+
+	// Deleted entities, a typed nil is no entity
+	var none *auditItem
+	changes, err = DiffAudit(before, none)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(changes))
+	assert.Equal(t, `"******"`, string(changes[1].Before))
+	assert.Equal(t, `null`, string(changes[1].After))
+
+	buf, err := json.Marshal(changes)
+	assert.Nil(t, err)
+	assert.True(t, json.Valid(buf))
+
+	_, err = DiffAudit(make(chan int), nil)
+	assert.NotNil(t, err)
+}
+
+func TestAuditStatusAction(t *testing.T) {
+	assert.Equal(t, schema.AuditActionEnable, AuditStatusAction(1))
+	assert.Equal(t, schema.AuditActionDisable, AuditStatusAction(2))
+	assert.Equal(t, schema.AuditActionDisable, AuditStatusAction(0))
+}
@@ -0,0 +1,148 @@
+package implement
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/MayCMF/core/src/audit/controllers"
+	"github.com/MayCMF/core/src/audit/model"
+	"github.com/MayCMF/core/src/audit/schema"
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	comschema "github.com/MayCMF/core/src/common/schema"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// Size of the client columns
+const (
+	maxTraceID   = 128
+	maxUserAgent = 512
+)
+
+// Number of entries read at once by an export
+const exportPageSize = 500
+
+// Columns of a csv export
+var csvHeader = []string{"created_at", "actor_uuid", "impersonator_uuid", "trace_id", "ip", "user_agent", "entity_type", "entity_id", "action", "changes"}
+
+// NewAudit - Create an audit log instance
+func NewAudit(mAudit model.IAudit) *Audit {
+	return &Audit{
+		AuditModel: mAudit,
+	}
+}
+
+// Audit - Audit log of administrative changes
+type Audit struct {
+	AuditModel model.IAudit
+}
+
+// Query - Query data
+func (a *Audit) Query(ctx context.Context, params schema.AuditQueryParam, opts ...schema.AuditQueryOptions) (*schema.AuditQueryResult, error) {
+	return a.AuditModel.Query(ctx, params, opts...)
+}
+
+// Export - Write every entry matching the conditions, most recent first
+func (a *Audit) Export(ctx context.Context, w io.Writer, format string, params schema.AuditQueryParam) error {
+	var write func(*schema.Audit) error
+	var flush func() error
+	switch format {
+	case controllers.AuditFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return errors.WithStack(err)
+		}
+		write = func(item *schema.Audit) error {
+			changes, err := json.Marshal(item.Changes)
+			if err != nil {
+				return err
+			}
+			return cw.Write([]string{
+				item.CreatedAt.Format(time.RFC3339), item.ActorUUID, item.ImpersonatorUUID, item.TraceID, item.IP, item.UserAgent,
+				item.EntityType, item.EntityID, item.Action, string(changes),
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case controllers.AuditFormatJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return errors.WithStack(err)
+		}
+		sep := ""
+		write = func(item *schema.Audit) error {
+			buf, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, sep+string(buf))
+			sep = ","
+			return err
+		}
+		flush = func() error {
+			_, err := io.WriteString(w, "]")
+			return err
+		}
+	default:
+		return errors.New400Response("Unknown export format")
+	}
+
+	// Entries recorded during the export do not shift the pages
+	if params.EndTime.IsZero() {
+		params.EndTime = time.Now()
+	}
+
+	for page := 1; ; page++ {
+		result, err := a.AuditModel.Query(ctx, params, schema.AuditQueryOptions{
+			PageParam: &comschema.PaginationParam{PageIndex: page, PageSize: exportPageSize},
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, item := range result.Data {
+			if err := write(item); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if len(result.Data) < exportPageSize {
+			break
+		}
+	}
+	return errors.WithStack(flush())
+}
+
+// Record - Record an action on an entity with the changed fields, updates
+// changing nothing are left out
+func (a *Audit) Record(ctx context.Context, entityType, action, entityID string, before, after interface{}) error {
+	changes, err := controllers.DiffAudit(before, after)
+	if err != nil {
+		return errors.WithStack(err)
+	} else if action == schema.AuditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	item := schema.Audit{
+		UUID:       util.MustUUID(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    changes,
+	}
+	item.ActorUUID, _ = icontext.FromUserUUID(ctx)
+	item.ImpersonatorUUID, _ = icontext.FromImpersonator(ctx)
+	item.TraceID, _ = icontext.FromTraceID(ctx)
+	item.IP, _ = icontext.FromClientIP(ctx)
+	item.UserAgent, _ = icontext.FromUserAgent(ctx)
+	if len(item.TraceID) > maxTraceID {
+		item.TraceID = item.TraceID[:maxTraceID]
+	}
+	if len(item.UserAgent) > maxUserAgent {
+		item.UserAgent = item.UserAgent[:maxUserAgent]
+	}
+	return a.AuditModel.Create(ctx, item)
+}
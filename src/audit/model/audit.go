@@ -0,0 +1,15 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/audit/schema"
+)
+
+// IAudit - Audit log storage interface
+type IAudit interface {
+	// Query data
+	Query(ctx context.Context, params schema.AuditQueryParam, opts ...schema.AuditQueryOptions) (*schema.AuditQueryResult, error)
+	// Create data
+	Create(ctx context.Context, item schema.Audit) error
+}
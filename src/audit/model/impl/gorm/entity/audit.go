@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/jinzhu/gorm"
+)
+
+// GetAuditDB - Get audit log storage
+func GetAuditDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, Audit{})
+}
+
+// SchemaAudit - Audit object
+type SchemaAudit schema.Audit
+
+// ToAudit - Convert to audit entity
+func (a SchemaAudit) ToAudit() *Audit {
+	changes := util.JSONMarshalToString(a.Changes)
+	item := &Audit{
+		UUID:             a.UUID,
+		ActorUUID:        &a.ActorUUID,
+		ImpersonatorUUID: &a.ImpersonatorUUID,
+		TraceID:          &a.TraceID,
+		IP:               &a.IP,
+		UserAgent:        &a.UserAgent,
+		EntityType:       &a.EntityType,
+		EntityID:         &a.EntityID,
+		Action:           &a.Action,
+		Changes:          &changes,
+	}
+	return item
+}
+
+// Audit - Audit entity
+type Audit struct {
+	entity.Model
+	UUID             string  `gorm:"column:record_id;size:36;index;"`         // Record internal code
+	ActorUUID        *string `gorm:"column:actor_uuid;size:36;index;"`        // UUID of the user who made the change
+	ImpersonatorUUID *string `gorm:"column:impersonator_uuid;size:36;index;"` // UUID of the user impersonating the actor
+	TraceID          *string `gorm:"column:trace_id;size:128;index;"`         // Tracking ID of the request
+	IP               *string `gorm:"column:ip;size:64;"`                      // Client IP
+	UserAgent        *string `gorm:"column:user_agent;size:512;"`             // Client user agent
+	EntityType       *string `gorm:"column:entity_type;size:32;index;"`       // Type of the entity
+	EntityID         *string `gorm:"column:entity_id;size:64;index;"`         // UUID (language code) of the entity
+	Action           *string `gorm:"column:action;size:32;index;"`            // Action
+	Changes          *string `gorm:"column:changes;type:text;"`               // Changed fields (JSON)
+}
+
+func (a Audit) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a Audit) TableName() string {
+	return a.Model.TableName("audit")
+}
+
+// ToSchemaAudit - Convert to audit object
+func (a Audit) ToSchemaAudit() *schema.Audit {
+	item := &schema.Audit{
+		UUID:       a.UUID,
+		ActorUUID:  *a.ActorUUID,
+		TraceID:    *a.TraceID,
+		IP:         *a.IP,
+		UserAgent:  *a.UserAgent,
+		EntityType: *a.EntityType,
+		EntityID:   *a.EntityID,
+		Action:     *a.Action,
+		CreatedAt:  a.CreatedAt,
+	}
+
+	// Entries recorded before impersonation was audited have no impersonator
+	if a.ImpersonatorUUID != nil {
+		item.ImpersonatorUUID = *a.ImpersonatorUUID
+	}
+	_ = util.JSONUnmarshal([]byte(*a.Changes), &item.Changes)
+	return item
+}
+
+// Audits - Audit entity list
+type Audits []*Audit
+
+// ToSchemaAudits - Convert to audit object list
+func (a Audits) ToSchemaAudits() []*schema.Audit {
+	list := make([]*schema.Audit, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaAudit()
+	}
+	return list
+}
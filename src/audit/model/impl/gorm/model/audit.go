@@ -0,0 +1,82 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/audit/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/jinzhu/gorm"
+)
+
+// NewAudit - Create an audit log storage instance
+func NewAudit(db *gorm.DB) *Audit {
+	return &Audit{db}
+}
+
+// Audit - Audit log storage
+type Audit struct {
+	db *gorm.DB
+}
+
+func (a *Audit) getQueryOption(opts ...schema.AuditQueryOptions) schema.AuditQueryOptions {
+	var opt schema.AuditQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Query - Query data, most recent first
+func (a *Audit) Query(ctx context.Context, params schema.AuditQueryParam, opts ...schema.AuditQueryOptions) (*schema.AuditQueryResult, error) {
+	db := entity.GetAuditDB(ctx, a.db)
+	if v := params.ActorUUID; v != "" {
+		db = db.Where("actor_uuid=?", v)
+	}
+	if v := params.ImpersonatorUUID; v != "" {
+		db = db.Where("impersonator_uuid=?", v)
+	}
+	if v := params.TraceID; v != "" {
+		db = db.Where("trace_id=?", v)
+	}
+	if v := params.EntityType; v != "" {
+		db = db.Where("entity_type=?", v)
+	}
+	if v := params.EntityID; v != "" {
+		db = db.Where("entity_id=?", v)
+	}
+	if v := params.Action; v != "" {
+		db = db.Where("action=?", v)
+	}
+	if v := params.StartTime; !v.IsZero() {
+		db = db.Where("created_at>=?", v)
+	}
+	if v := params.EndTime; !v.IsZero() {
+		db = db.Where("created_at<?", v)
+	}
+	db = db.Order("id DESC")
+
+	opt := a.getQueryOption(opts...)
+	var list entity.Audits
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	qr := &schema.AuditQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaAudits(),
+	}
+	return qr, nil
+}
+
+// Create - Create data
+func (a *Audit) Create(ctx context.Context, item schema.Audit) error {
+	sitem := entity.SchemaAudit(item)
+	result := entity.GetAuditDB(ctx, a.db).Create(sitem.ToAudit())
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"github.com/MayCMF/core/src/audit/routers/api/controllers"
+	"github.com/MayCMF/core/src/common/auth"
+	"github.com/MayCMF/core/src/common/middleware"
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/dig"
+)
+
+// RegisterRouter - Registration /api routing
+func RegisterRouter(app *gin.Engine, container *dig.Container) error {
+	err := controllers.Inject(container)
+	if err != nil {
+		return err
+	}
+
+	return container.Invoke(func(
+		a auth.Auther,
+		e *casbin.SyncedEnforcer,
+		cAudit *controllers.Audit,
+	) error {
+
+		g := app.Group("/api")
+
+		// User identity authorization
+		g.Use(middleware.UserAuthMiddleware(a))
+
+		// Casbin permission check middleware
+		g.Use(middleware.CasbinMiddleware(e))
+
+		// Request frequency limit middleware
+		g.Use(middleware.RateLimiterMiddleware())
+
+		v1 := g.Group("/v1")
+		{
+
+			// [REGISTERED]/api/v1/audit
+			gAudit := v1.Group("audit")
+			{
+				gAudit.GET("", cAudit.Query)
+				gAudit.GET("export", cAudit.Export)
+			}
+		}
+
+		return nil
+	})
+}
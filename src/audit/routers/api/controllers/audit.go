@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/audit/controllers"
+	"github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// NewAudit - Create an audit log controller
+func NewAudit(bAudit controllers.IAudit) *Audit {
+	return &Audit{
+		AuditBll: bAudit,
+	}
+}
+
+// Audit - Audit log of administrative changes
+type Audit struct {
+	AuditBll controllers.IAudit
+}
+
+// Conditions of the query string
+func (a *Audit) getQueryParam(c *gin.Context) (schema.AuditQueryParam, error) {
+	params := schema.AuditQueryParam{
+		ActorUUID:        c.Query("actorUUID"),
+		ImpersonatorUUID: c.Query("impersonatorUUID"),
+		TraceID:          c.Query("traceID"),
+		EntityType:       c.Query("entityType"),
+		EntityID:         c.Query("entityID"),
+		Action:           c.Query("action"),
+	}
+
+	var err error
+	if v := c.Query("startTime"); v != "" {
+		params.StartTime, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return params, errors.New400Response("Invalid start time")
+		}
+	}
+	if v := c.Query("endTime"); v != "" {
+		params.EndTime, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			return params, errors.New400Response("Invalid end time")
+		}
+	}
+	return params, nil
+}
+
+// Query - Query data
+// @Tags Audit Log
+// @Summary Query the audit log, most recent first
+// @Param Authorization header string false "Bearer User Token"
+// @Param current query int true "Paging index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Param actorUUID query string false "UUID of the user who made the changes"
+// @Param impersonatorUUID query string false "UUID of the user impersonating the actor"
+// @Param traceID query string false "Tracking ID of the request"
+// @Param entityType query string false "Entity type (user/role/permission/primitive/node/language/file)"
+// @Param entityID query string false "Entity UUID (language code)"
+// @Param action query string false "Action (create/update/delete/enable/disable/impersonate/impersonate_end)"
+// @Param startTime query string false "Entries recorded at or after (RFC 3339)"
+// @Param endTime query string false "Entries recorded before (RFC 3339)"
+// @Success 200 {array} schema.Audit "Search result: {list:List data,pagination:{current:Page index,pageSize:Page size,total:Total number}}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid start time}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/audit [get]
+func (a *Audit) Query(c *gin.Context) {
+	params, err := a.getQueryParam(c)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	result, err := a.AuditBll.Query(ginplus.NewContext(c), params, schema.AuditQueryOptions{
+		PageParam: ginplus.GetPaginationParam(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// Export - Download every entry matching the conditions
+// @Tags Audit Log
+// @Summary Export the audit log as csv or json
+// @Param Authorization header string false "Bearer User Token"
+// @Param format query string false "Export format (csv/json)" default(csv)
+// @Param actorUUID query string false "UUID of the user who made the changes"
+// @Param impersonatorUUID query string false "UUID of the user impersonating the actor"
+// @Param traceID query string false "Tracking ID of the request"
+// @Param entityType query string false "Entity type (user/role/permission/primitive/node/language/file)"
+// @Param entityID query string false "Entity UUID (language code)"
+// @Param action query string false "Action (create/update/delete/enable/disable/impersonate/impersonate_end)"
+// @Param startTime query string false "Entries recorded at or after (RFC 3339)"
+// @Param endTime query string false "Entries recorded before (RFC 3339)"
+// @Success 200 {file} file "Audit entries"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Unknown export format}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/audit/export [get]
+func (a *Audit) Export(c *gin.Context) {
+	params, err := a.getQueryParam(c)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	format := c.DefaultQuery("format", controllers.AuditFormatCSV)
+	h := c.Writer.Header()
+	switch format {
+	case controllers.AuditFormatCSV:
+		h.Set("Content-Type", "text/csv; charset=utf-8")
+	case controllers.AuditFormatJSON:
+		h.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	h.Set("Content-Disposition", "attachment; filename=audit-"+time.Now().Format("20060102-1504")+"."+format)
+
+	ctx := ginplus.NewContext(c)
+	err = a.AuditBll.Export(ctx, c.Writer, format, params)
+	if err != nil {
+		if !c.Writer.Written() {
+			h.Del("Content-Type")
+			h.Del("Content-Disposition")
+			ginplus.ResError(c, err)
+			return
+		}
+
+		// The response has started, the client gets a truncated export
+		logger.Errorf(ctx, "Export audit log error: %s", err.Error())
+		c.Abort()
+	}
+}
@@ -0,0 +1,10 @@
+package controllers
+
+import (
+	"go.uber.org/dig"
+)
+
+func Inject(container *dig.Container) error {
+	_ = container.Provide(NewAudit)
+	return nil
+}
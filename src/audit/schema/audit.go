@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/common/schema"
+)
+
+// Type of an audited entity
+const (
+	AuditEntityUser       = "user"
+	AuditEntityRole       = "role"
+	AuditEntityPermission = "permission"
+	AuditEntityPrimitive  = "primitive"
+	AuditEntityNode       = "node"
+	AuditEntityLanguage   = "language"
+	AuditEntityFile       = "file"
+)
+
+// Audited action
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionEnable  = "enable"
+	AuditActionDisable = "disable"
+	// A user started or ended impersonating the user entity
+	AuditActionImpersonate    = "impersonate"
+	AuditActionImpersonateEnd = "impersonate_end"
+)
+
+// Audit - Audit entry of an administrative change
+type Audit struct {
+	UUID             string       `json:"record_id"`         // Record ID
+	ActorUUID        string       `json:"actor_uuid"`        // UUID of the user who made the change (empty for anonymous requests)
+	ImpersonatorUUID string       `json:"impersonator_uuid"` // UUID of the user impersonating the actor
+	TraceID          string       `json:"trace_id"`          // Tracking ID of the request
+	IP               string       `json:"ip"`                // Client IP
+	UserAgent        string       `json:"user_agent"`        // Client user agent
+	EntityType       string       `json:"entity_type"`       // Type of the entity (user/role etc)
+	EntityID         string       `json:"entity_id"`         // UUID (language code) of the entity
+	Action           string       `json:"action"`            // Action (create/update/delete/enable/disable)
+	Changes          AuditChanges `json:"changes"`           // Changed fields
+	CreatedAt        time.Time    `json:"created_at"`        // Creation time
+}
+
+// AuditChange - Field of an entity changed by an action
+type AuditChange struct {
+	Field  string     `json:"field"`  // JSON name of the field
+	Before AuditValue `json:"before"` // Value before the change (null when created)
+	After  AuditValue `json:"after"`  // Value after the change (null when deleted)
+}
+
+// AuditValue - Raw JSON value of a field, json.RawMessage numbers are lost
+// by the jsoniter encoder of the responses
+type AuditValue []byte
+
+// MarshalJSON - Encode the raw value, an empty value is null
+func (a AuditValue) MarshalJSON() ([]byte, error) {
+	if len(a) == 0 {
+		return []byte("null"), nil
+	}
+	return a, nil
+}
+
+// UnmarshalJSON - Keep a copy of the raw value
+func (a *AuditValue) UnmarshalJSON(data []byte) error {
+	*a = append((*a)[:0], data...)
+	return nil
+}
+
+// AuditChanges - Changed field list
+type AuditChanges []*AuditChange
+
+// AuditQueryParam - Query conditions
+type AuditQueryParam struct {
+	ActorUUID        string    // Actor UUID
+	ImpersonatorUUID string    // Impersonator UUID
+	TraceID          string    // Tracking ID
+	EntityType       string    // Entity type
+	EntityID         string    // Entity UUID
+	Action           string    // Action
+	StartTime        time.Time // Entries created at or after
+	EndTime          time.Time // Entries created before
+}
+
+// AuditQueryOptions - Audit object query optional parameter item
+type AuditQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// AuditQueryResult - Audit object query result
+type AuditQueryResult struct {
+	Data       Audits
+	PageResult *schema.PaginationResult
+}
+
+// Audits - Audit entry list
+type Audits []*Audit
@@ -3,11 +3,13 @@ package auth
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 // Definition error
 var (
 	ErrInvalidToken = errors.New("invalid token")
+	ErrReusedToken  = errors.New("refresh token reused")
 )
 
 // TokenInfo - Token information
@@ -18,6 +20,10 @@ type TokenInfo interface {
 	GetTokenType() string
 	// Get token expiration timestamp
 	GetExpiresAt() int64
+	// Get refresh token
+	GetRefreshToken() string
+	// Get the session ID shared by the tokens rotated from the same login
+	GetSessionID() string
 	// JSON encoding
 	EncodeToJSON() ([]byte, error)
 }
@@ -27,12 +33,64 @@ type Auther interface {
 	// Generate token
 	GenerateToken(ctx context.Context, userUUID string) (TokenInfo, error)
 
-	// Destroy token
+	// Destroy token, the refresh tokens issued with it are revoked too
 	DestroyToken(ctx context.Context, accessToken string) error
 
+	// Resolve the user ID of a refresh token of a family that is not revoked
+	ParseRefreshToken(ctx context.Context, refreshToken string) (string, error)
+
+	// Exchange a refresh token for new access and refresh tokens
+	RefreshToken(ctx context.Context, refreshToken string) (TokenInfo, error)
+
+	// Resolve the session ID of an access token
+	ParseSessionID(ctx context.Context, accessToken string) (string, error)
+
+	// Revoke every access and refresh token of a session
+	RevokeSession(ctx context.Context, sessionID string) error
+
 	// Resolve user ID
 	ParseUserUUID(ctx context.Context, accessToken string) (string, error)
 
 	// Release resources
 	Release() error
 }
+
+// ScopedAuther - Authentication also accepting tokens limited to a part of
+// the permissions of their user
+type ScopedAuther interface {
+	Auther
+
+	// Resolve user ID and the casbin subject of the token scopes (empty when not limited)
+	ParseScopedUserUUID(ctx context.Context, accessToken string) (userUUID, scope string, err error)
+
+	// Generate a token limited to the casbin subject scope
+	GenerateScopedToken(ctx context.Context, userUUID, scope string) (TokenInfo, error)
+
+	// Resolve user ID and the casbin subject of the scopes of a refresh token
+	ParseScopedRefreshToken(ctx context.Context, refreshToken string) (userUUID, scope string, err error)
+}
+
+// ImpersonatingAuther - Authentication issuing tokens of a user acting as
+// another user, the token subject is the impersonated user
+type ImpersonatingAuther interface {
+	Auther
+
+	// Generate a token of the user for the impersonator, the token expires
+	// after expired seconds and can not be refreshed
+	GenerateImpersonationToken(ctx context.Context, userUUID, impersonatorUUID string, expired int) (TokenInfo, error)
+
+	// Resolve the impersonator of a token, empty when the token was issued to its user
+	ParseImpersonatorUUID(ctx context.Context, accessToken string) (string, error)
+}
+
+// ChallengeAuther - Authentication keeping track of single-use challenges,
+// such as the second step of a login
+type ChallengeAuther interface {
+	Auther
+
+	// Use up a challenge, false when it was used before. The challenge is
+	// remembered for the expiration, which has to outlive the challenge
+	UseChallenge(ctx context.Context, challengeID string, expiration time.Duration) (bool, error)
+	// Check whether a challenge was used
+	ChallengeUsed(ctx context.Context, challengeID string) (bool, error)
+}
@@ -2,6 +2,12 @@ package jwtauth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
@@ -11,10 +17,11 @@ import (
 const defaultKey = "GINADMIN"
 
 var defaultOptions = options{
-	tokenType:     "Bearer",
-	expired:       7200,
-	signingMethod: jwt.SigningMethodHS512,
-	signingKey:    []byte(defaultKey),
+	tokenType:      "Bearer",
+	expired:        7200,
+	refreshExpired: 30 * 24 * 3600,
+	signingMethod:  jwt.SigningMethodHS512,
+	signingKey:     []byte(defaultKey),
 	keyfunc: func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, auth.ErrInvalidToken
@@ -24,11 +31,13 @@ var defaultOptions = options{
 }
 
 type options struct {
-	signingMethod jwt.SigningMethod
-	signingKey    interface{}
-	keyfunc       jwt.Keyfunc
-	expired       int
-	tokenType     string
+	signingMethod  jwt.SigningMethod
+	signingKey     interface{}
+	keyfunc        jwt.Keyfunc
+	keySet         *KeySet
+	expired        int
+	refreshExpired int
+	tokenType      string
 }
 
 // Option - Defining parameter items
@@ -55,6 +64,16 @@ func SetKeyfunc(keyFunc jwt.Keyfunc) Option {
 	}
 }
 
+// SetKeySet - Sign with the asymmetric keys of a key set, the tokens carry
+// the ID of their key in the kid header
+func SetKeySet(keySet *KeySet) Option {
+	return func(o *options) {
+		o.keySet = keySet
+		o.signingMethod = keySet.Method()
+		o.keyfunc = keySet.Keyfunc
+	}
+}
+
 // SetExpired - Set the token expiration time (in seconds, default 7200)
 func SetExpired(expired int) Option {
 	return func(o *options) {
@@ -62,6 +81,13 @@ func SetExpired(expired int) Option {
 	}
 }
 
+// SetRefreshExpired - Set the refresh token expiration time (in seconds, default 30 days)
+func SetRefreshExpired(expired int) Option {
+	return func(o *options) {
+		o.refreshExpired = expired
+	}
+}
+
 // New - Create an authentication instance
 func New(store Storer, opts ...Option) *JWTAuth {
 	o := defaultOptions
@@ -81,41 +107,238 @@ type JWTAuth struct {
 	store Storer
 }
 
-// GenerateToken - Generate token
+// Store keys of the refresh tokens, a family holds the refresh tokens rotated
+// from the same login and the access tokens issued with them (jti claim)
+const (
+	refreshKeyPrefix = "refresh:"
+	usedKeyPrefix    = "refresh_used:"
+	familyKeyPrefix  = "refresh_family:"
+)
+
+// Store key of the used challenges
+const challengeKeyPrefix = "challenge_used:"
+
+var errChallengeNotSupported = errors.New("single-use challenges need a storage")
+
+// Claims of the tokens, the scope is the casbin subject limiting a token
+// issued to a client and the actor is the user impersonating the subject
+type claims struct {
+	jwt.StandardClaims
+	Scope string       `json:"scope,omitempty"`
+	Actor *actorClaims `json:"act,omitempty"`
+}
+
+// Actor claim of RFC 8693
+type actorClaims struct {
+	Subject string `json:"sub"`
+}
+
+// GenerateToken - Generate token, a refresh token starting a new family is
+// issued when a store is set
 func (a *JWTAuth) GenerateToken(ctx context.Context, userUUID string) (auth.TokenInfo, error) {
-	now := time.Now()
-	expiresAt := now.Add(time.Duration(a.opts.expired) * time.Second).Unix()
+	return a.GenerateScopedToken(ctx, userUUID, "")
+}
+
+// GenerateScopedToken - Generate a token limited to the casbin subject scope,
+// the tokens rotated from its refresh token keep the scope
+func (a *JWTAuth) GenerateScopedToken(ctx context.Context, userUUID, scope string) (auth.TokenInfo, error) {
+	var family string
+	if a.store != nil {
+		id, err := randomString(16)
+		if err != nil {
+			return nil, err
+		}
+		family = id
+	}
+	return a.generateToken(ctx, userUUID, scope, family)
+}
+
+// GenerateImpersonationToken - Generate a token of the user carrying the
+// impersonator as actor, its family has no refresh token so it can not be
+// refreshed but it can be revoked as a session
+func (a *JWTAuth) GenerateImpersonationToken(ctx context.Context, userUUID, impersonatorUUID string, expired int) (auth.TokenInfo, error) {
+	var family string
+	if a.store != nil {
+		id, err := randomString(16)
+		if err != nil {
+			return nil, err
+		}
+		family = id
+	}
 
-	token := jwt.NewWithClaims(a.opts.signingMethod, &jwt.StandardClaims{
+	tokenInfo, err := a.signToken(&claims{
+		StandardClaims: newStandardClaims(userUUID, family, expired),
+		Actor:          &actorClaims{Subject: impersonatorUUID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	tokenInfo.SessionID = family
+	return tokenInfo, nil
+}
+
+func newStandardClaims(userUUID, family string, expired int) jwt.StandardClaims {
+	now := time.Now()
+	return jwt.StandardClaims{
+		Id:        family,
 		IssuedAt:  now.Unix(),
-		ExpiresAt: expiresAt,
+		ExpiresAt: now.Add(time.Duration(expired) * time.Second).Unix(),
 		NotBefore: now.Unix(),
 		Subject:   userUUID,
-	})
+	}
+}
+
+func (a *JWTAuth) signToken(c *claims) (*tokenInfo, error) {
+	token := jwt.NewWithClaims(a.opts.signingMethod, c)
+
+	signingKey := a.opts.signingKey
+	if ks := a.opts.keySet; ks != nil {
+		key := ks.SigningKey()
+		token.Header["kid"] = key.ID
+		signingKey = key.PrivateKey
+	}
 
-	tokenString, err := token.SignedString(a.opts.signingKey)
+	tokenString, err := token.SignedString(signingKey)
 	if err != nil {
 		return nil, err
 	}
 
-	tokenInfo := &tokenInfo{
-		ExpiresAt:   expiresAt,
+	return &tokenInfo{
+		ExpiresAt:   c.ExpiresAt,
 		TokenType:   a.opts.tokenType,
 		AccessToken: tokenString,
+	}, nil
+}
+
+func (a *JWTAuth) generateToken(ctx context.Context, userUUID, scope, family string) (auth.TokenInfo, error) {
+	tokenInfo, err := a.signToken(&claims{
+		StandardClaims: newStandardClaims(userUUID, family, a.opts.expired),
+		Scope:          scope,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if family != "" {
+		refreshToken, err := randomString(32)
+		if err != nil {
+			return nil, err
+		}
+
+		value := family + ":" + userUUID
+		if scope != "" {
+			value += ":" + scope
+		}
+		err = a.store.SetValue(ctx, refreshKeyPrefix+hashToken(refreshToken), value, a.refreshExpiration())
+		if err != nil {
+			return nil, err
+		}
+		tokenInfo.RefreshToken = refreshToken
+		tokenInfo.SessionID = family
 	}
 	return tokenInfo, nil
 }
 
+func (a *JWTAuth) refreshExpiration() time.Duration {
+	return time.Duration(a.opts.refreshExpired) * time.Second
+}
+
+// Refresh tokens are only kept as hashes
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Get the family, the user and the scope of a refresh token that is neither
+// used nor revoked
+func (a *JWTAuth) getRefreshToken(ctx context.Context, refreshToken string) (family, userUUID, scope string, err error) {
+	if a.store == nil || refreshToken == "" {
+		return "", "", "", auth.ErrInvalidToken
+	}
+
+	value, err := a.store.GetValue(ctx, refreshKeyPrefix+hashToken(refreshToken))
+	if err != nil {
+		return "", "", "", err
+	}
+
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) < 2 {
+		return "", "", "", auth.ErrInvalidToken
+	}
+	family, userUUID = parts[0], parts[1]
+	if len(parts) == 3 {
+		scope = parts[2]
+	}
+
+	if revoked, err := a.store.Check(ctx, familyKeyPrefix+family); err != nil {
+		return "", "", "", err
+	} else if revoked {
+		return "", "", "", auth.ErrInvalidToken
+	}
+	return family, userUUID, scope, nil
+}
+
+// ParseRefreshToken - Resolve the user UUID of a refresh token, an already
+// used token is still resolved so that RefreshToken can detect its reuse
+func (a *JWTAuth) ParseRefreshToken(ctx context.Context, refreshToken string) (string, error) {
+	_, userUUID, _, err := a.getRefreshToken(ctx, refreshToken)
+	return userUUID, err
+}
+
+// ParseScopedRefreshToken - Resolve the user UUID and the scope of a refresh token
+func (a *JWTAuth) ParseScopedRefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	_, userUUID, scope, err := a.getRefreshToken(ctx, refreshToken)
+	return userUUID, scope, err
+}
+
+// RefreshToken - Rotate a refresh token, a refresh token used twice means it
+// was stolen so its whole family is revoked
+func (a *JWTAuth) RefreshToken(ctx context.Context, refreshToken string) (auth.TokenInfo, error) {
+	family, userUUID, scope, err := a.getRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := a.store.Swap(ctx, usedKeyPrefix+hashToken(refreshToken), "1", a.refreshExpiration())
+	if err != nil {
+		return nil, err
+	} else if used != "" {
+		if err := a.revokeFamily(ctx, family); err != nil {
+			return nil, err
+		}
+		return nil, auth.ErrReusedToken
+	}
+
+	return a.generateToken(ctx, userUUID, scope, family)
+}
+
+func (a *JWTAuth) revokeFamily(ctx context.Context, family string) error {
+	// Outlives every refresh and access token of the family
+	expiration := a.refreshExpiration()
+	if d := time.Duration(a.opts.expired) * time.Second; d > expiration {
+		expiration = d
+	}
+	return a.store.Set(ctx, familyKeyPrefix+family, expiration)
+}
+
 // Parsing token
-func (a *JWTAuth) parseToken(tokenString string) (*jwt.StandardClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.StandardClaims{}, a.opts.keyfunc)
+func (a *JWTAuth) parseToken(tokenString string) (*claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &claims{}, a.opts.keyfunc)
 	if err != nil {
 		return nil, err
 	} else if !token.Valid {
 		return nil, auth.ErrInvalidToken
 	}
 
-	return token.Claims.(*jwt.StandardClaims), nil
+	return token.Claims.(*claims), nil
 }
 
 func (a *JWTAuth) callStore(fn func(Storer) error) error {
@@ -135,15 +358,47 @@ func (a *JWTAuth) DestroyToken(ctx context.Context, tokenString string) error {
 	// If the storage is set, the unexpired token is placed
 	return a.callStore(func(store Storer) error {
 		expired := time.Unix(claims.ExpiresAt, 0).Sub(time.Now())
-		return store.Set(ctx, tokenString, expired)
+		if err := store.Set(ctx, tokenString, expired); err != nil {
+			return err
+		}
+
+		if family := claims.Id; family != "" {
+			return a.revokeFamily(ctx, family)
+		}
+		return nil
+	})
+}
+
+// ParseSessionID - Resolve the session ID (token family) of an access token
+func (a *JWTAuth) ParseSessionID(ctx context.Context, tokenString string) (string, error) {
+	claims, err := a.parseToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.Id, nil
+}
+
+// RevokeSession - Revoke the access and refresh tokens of a session
+func (a *JWTAuth) RevokeSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return a.callStore(func(store Storer) error {
+		return a.revokeFamily(ctx, sessionID)
 	})
 }
 
 // ParseUserUUID - Resolve user UUID
 func (a *JWTAuth) ParseUserUUID(ctx context.Context, tokenString string) (string, error) {
+	userUUID, _, err := a.ParseScopedUserUUID(ctx, tokenString)
+	return userUUID, err
+}
+
+// ParseScopedUserUUID - Resolve user UUID and the scope of the token
+func (a *JWTAuth) ParseScopedUserUUID(ctx context.Context, tokenString string) (string, string, error) {
 	claims, err := a.parseToken(tokenString)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	err = a.callStore(func(store Storer) error {
@@ -153,13 +408,60 @@ func (a *JWTAuth) ParseUserUUID(ctx context.Context, tokenString string) (string
 		} else if exists {
 			return auth.ErrInvalidToken
 		}
+
+		if family := claims.Id; family != "" {
+			revoked, err := store.Check(ctx, familyKeyPrefix+family)
+			if err != nil {
+				return err
+			} else if revoked {
+				return auth.ErrInvalidToken
+			}
+		}
 		return nil
 	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return claims.Subject, claims.Scope, nil
+}
+
+// ParseImpersonatorUUID - Resolve the actor of a token impersonating its subject
+func (a *JWTAuth) ParseImpersonatorUUID(ctx context.Context, tokenString string) (string, error) {
+	claims, err := a.parseToken(tokenString)
 	if err != nil {
 		return "", err
+	} else if claims.Actor == nil {
+		return "", nil
+	}
+	return claims.Actor.Subject, nil
+}
+
+// UseChallenge - Use up a challenge, without a storage challenges are refused
+// as their use can not be tracked
+func (a *JWTAuth) UseChallenge(ctx context.Context, challengeID string, expiration time.Duration) (bool, error) {
+	if a.store == nil {
+		return false, errChallengeNotSupported
 	}
 
-	return claims.Subject, nil
+	used, err := a.store.Swap(ctx, challengeKeyPrefix+challengeID, "1", expiration)
+	if err != nil {
+		return false, err
+	}
+	return used == "", nil
+}
+
+// ChallengeUsed - Check whether a challenge was used
+func (a *JWTAuth) ChallengeUsed(ctx context.Context, challengeID string) (bool, error) {
+	if a.store == nil {
+		return false, errChallengeNotSupported
+	}
+
+	used, err := a.store.GetValue(ctx, challengeKeyPrefix+challengeID)
+	if err != nil {
+		return false, err
+	}
+	return used != "", nil
 }
 
 // Release - Release resources
@@ -3,6 +3,7 @@ package jwtauth
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/MayCMF/core/src/common/auth/jwtauth/store/buntdb"
 	"github.com/stretchr/testify/assert"
@@ -34,3 +35,183 @@ func TestAuth(t *testing.T) {
 	assert.EqualError(t, err, "invalid token")
 	assert.Empty(t, id)
 }
+
+func TestRefreshToken(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	userUUID := "test"
+	token, err := jwtAuth.GenerateToken(ctx, userUUID)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token.GetRefreshToken())
+
+	id, err := jwtAuth.ParseRefreshToken(ctx, token.GetRefreshToken())
+	assert.Nil(t, err)
+	assert.Equal(t, userUUID, id)
+
+	// Every use rotates the refresh token
+	next, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+	assert.Nil(t, err)
+	assert.NotEmpty(t, next.GetRefreshToken())
+	assert.NotEqual(t, token.GetRefreshToken(), next.GetRefreshToken())
+
+	id, err = jwtAuth.ParseUserUUID(ctx, next.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, userUUID, id)
+
+	// Replaying a used refresh token revokes the whole family
+	_, err = jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+	assert.EqualError(t, err, "refresh token reused")
+
+	_, err = jwtAuth.RefreshToken(ctx, next.GetRefreshToken())
+	assert.EqualError(t, err, "invalid token")
+
+	_, err = jwtAuth.ParseRefreshToken(ctx, next.GetRefreshToken())
+	assert.EqualError(t, err, "invalid token")
+
+	_, err = jwtAuth.ParseUserUUID(ctx, next.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+
+	_, err = jwtAuth.RefreshToken(ctx, "invalid")
+	assert.EqualError(t, err, "invalid token")
+
+	// Logging out revokes the refresh tokens too
+	token, err = jwtAuth.GenerateToken(ctx, userUUID)
+	assert.Nil(t, err)
+
+	err = jwtAuth.DestroyToken(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+
+	_, err = jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+	assert.EqualError(t, err, "invalid token")
+}
+
+func TestRevokeSession(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	token, err := jwtAuth.GenerateToken(ctx, "test")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token.GetSessionID())
+
+	sessionID, err := jwtAuth.ParseSessionID(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, token.GetSessionID(), sessionID)
+
+	// Rotated tokens stay in the session
+	next, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+	assert.Nil(t, err)
+	assert.Equal(t, sessionID, next.GetSessionID())
+
+	err = jwtAuth.RevokeSession(ctx, sessionID)
+	assert.Nil(t, err)
+
+	_, err = jwtAuth.ParseUserUUID(ctx, next.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+
+	_, err = jwtAuth.RefreshToken(ctx, next.GetRefreshToken())
+	assert.EqualError(t, err, "invalid token")
+}
+
+func TestScopedToken(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	token, err := jwtAuth.GenerateScopedToken(ctx, "test", "oauth_grant:1")
+	assert.Nil(t, err)
+
+	id, scope, err := jwtAuth.ParseScopedUserUUID(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "test", id)
+	assert.Equal(t, "oauth_grant:1", scope)
+
+	// Rotated tokens keep the scope of the refresh token
+	id, scope, err = jwtAuth.ParseScopedRefreshToken(ctx, token.GetRefreshToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "test", id)
+	assert.Equal(t, "oauth_grant:1", scope)
+
+	next, err := jwtAuth.RefreshToken(ctx, token.GetRefreshToken())
+	assert.Nil(t, err)
+
+	_, scope, err = jwtAuth.ParseScopedUserUUID(ctx, next.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "oauth_grant:1", scope)
+
+	// Unscoped tokens stay unscoped
+	token, err = jwtAuth.GenerateToken(ctx, "test")
+	assert.Nil(t, err)
+
+	_, scope, err = jwtAuth.ParseScopedRefreshToken(ctx, token.GetRefreshToken())
+	assert.Nil(t, err)
+	assert.Empty(t, scope)
+}
+
+func TestImpersonationToken(t *testing.T) {
+	store, err := buntdb.NewStore(":memory:")
+	assert.Nil(t, err)
+
+	jwtAuth := New(store)
+
+	defer jwtAuth.Release()
+
+	ctx := context.Background()
+	start := time.Now()
+	token, err := jwtAuth.GenerateImpersonationToken(ctx, "test", "support", 600)
+	assert.Nil(t, err)
+	assert.Empty(t, token.GetRefreshToken())
+	assert.NotEmpty(t, token.GetSessionID())
+	assert.True(t, token.GetExpiresAt() <= start.Add(601*time.Second).Unix())
+
+	// The subject is the impersonated user
+	id, err := jwtAuth.ParseUserUUID(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "test", id)
+
+	actor, err := jwtAuth.ParseImpersonatorUUID(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, "support", actor)
+
+	// The token is revoked with its session
+	sessionID, err := jwtAuth.ParseSessionID(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Equal(t, token.GetSessionID(), sessionID)
+
+	err = jwtAuth.RevokeSession(ctx, sessionID)
+	assert.Nil(t, err)
+
+	_, err = jwtAuth.ParseUserUUID(ctx, token.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+
+	token, err = jwtAuth.GenerateImpersonationToken(ctx, "test", "support", 600)
+	assert.Nil(t, err)
+
+	err = jwtAuth.DestroyToken(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+
+	_, err = jwtAuth.ParseUserUUID(ctx, token.GetAccessToken())
+	assert.EqualError(t, err, "invalid token")
+
+	// Tokens issued to their user have no actor
+	token, err = jwtAuth.GenerateToken(ctx, "test")
+	assert.Nil(t, err)
+
+	actor, err = jwtAuth.ParseImpersonatorUUID(ctx, token.GetAccessToken())
+	assert.Nil(t, err)
+	assert.Empty(t, actor)
+}
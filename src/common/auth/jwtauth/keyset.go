@@ -0,0 +1,472 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MayCMF/core/src/common/auth"
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// Key - Asymmetric key signing tokens, the ID is the kid header of its tokens
+type Key struct {
+	ID         string        // JWK thumbprint of the public key (RFC 7638)
+	PrivateKey crypto.Signer // Private key
+	CreatedAt  time.Time     // Modification time of the key file
+	RetiredAt  time.Time     // Creation time of the next key, zero for the signing key
+	path       string
+}
+
+// JSONWebKey - Public key of the key set (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet - Public keys verifying the tokens
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type keySetOptions struct {
+	rotation time.Duration
+	overlap  time.Duration
+}
+
+// KeySetOption - Key set parameter items
+type KeySetOption func(*keySetOptions)
+
+// SetKeyRotation - Set the age of the signing key replaced by a new key (default 0: no rotation)
+func SetKeyRotation(rotation time.Duration) KeySetOption {
+	return func(o *keySetOptions) {
+		o.rotation = rotation
+	}
+}
+
+// SetKeyOverlap - Set the time a replaced key still verifies tokens and is
+// published, at least the token expiration time (default 7200 seconds)
+func SetKeyOverlap(overlap time.Duration) KeySetOption {
+	return func(o *keySetOptions) {
+		o.overlap = overlap
+	}
+}
+
+// Reloading the key directory on an unknown key ID is limited to once per interval
+const keyReloadInterval = 10 * time.Second
+
+// NewKeySet - Load the PEM private keys of a directory, a key is generated
+// when no key matches the signature method
+func NewKeySet(dir string, method jwt.SigningMethod, opts ...KeySetOption) (*KeySet, error) {
+	switch method.Alg() {
+	case "RS256", "ES256", "EdDSA":
+	default:
+		return nil, fmt.Errorf("unsupported signing method %s", method.Alg())
+	}
+
+	o := keySetOptions{
+		overlap: time.Duration(defaultOptions.expired) * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &KeySet{
+		dir:    dir,
+		method: method,
+		opts:   o,
+		stop:   make(chan struct{}),
+	}
+
+	err = s.refresh(time.Now(), true)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// KeySet - Asymmetric signing keys stored as PEM files, the newest key signs
+// and the keys replaced within the overlap still verify
+type KeySet struct {
+	dir      string
+	method   jwt.SigningMethod
+	opts     keySetOptions
+	lock     sync.RWMutex
+	keys     []*Key
+	loadedAt time.Time
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// Method - Signature method of the keys
+func (s *KeySet) Method() jwt.SigningMethod {
+	return s.method
+}
+
+// SigningKey - Key signing new tokens
+func (s *KeySet) SigningKey() *Key {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.keys[0]
+}
+
+// Keys - Keys verifying tokens, newest first
+func (s *KeySet) Keys() []*Key {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]*Key(nil), s.keys...)
+}
+
+// Keyfunc - Verification key of a token, tokens signed by keys of other
+// instances sharing the key directory are accepted after a reload
+func (s *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	if t.Method.Alg() != s.method.Alg() {
+		return nil, auth.ErrInvalidToken
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	now := time.Now()
+	if key := s.lookup(kid, now); key != nil {
+		return key.PrivateKey.Public(), nil
+	}
+
+	s.lock.RLock()
+	reload := now.Sub(s.loadedAt) >= keyReloadInterval
+	s.lock.RUnlock()
+	if reload && s.refresh(now, false) == nil {
+		if key := s.lookup(kid, now); key != nil {
+			return key.PrivateKey.Public(), nil
+		}
+	}
+	return nil, auth.ErrInvalidToken
+}
+
+func (s *KeySet) lookup(kid string, now time.Time) *Key {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for _, key := range s.keys {
+		if key.ID == kid && !s.expired(key, now) {
+			return key
+		}
+	}
+	return nil
+}
+
+func (s *KeySet) expired(key *Key, now time.Time) bool {
+	return !key.RetiredAt.IsZero() && !now.Before(key.RetiredAt.Add(s.opts.overlap))
+}
+
+// JWKS - Public keys of the key set, empty for a nil key set
+func (s *KeySet) JWKS() *JSONWebKeySet {
+	set := &JSONWebKeySet{Keys: []JSONWebKey{}}
+	if s == nil {
+		return set
+	}
+
+	for _, key := range s.Keys() {
+		set.Keys = append(set.Keys, newJSONWebKey(key.ID, s.method.Alg(), key.PrivateKey.Public()))
+	}
+	return set
+}
+
+// Rotate - Reload the key directory, generate a key when the signing key is
+// older than the rotation and remove the keys replaced before the overlap
+func (s *KeySet) Rotate() error {
+	return s.refresh(time.Now(), true)
+}
+
+// Start - Rotate the keys in the background, errors are given to onError
+func (s *KeySet) Start(onError func(error)) {
+	if s.opts.rotation <= 0 {
+		return
+	}
+
+	interval := s.opts.rotation
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Rotate(); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop - Stop the background rotation
+func (s *KeySet) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+func (s *KeySet) refresh(now time.Time, generate bool) error {
+	keys, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if generate && (len(keys) == 0 || (s.opts.rotation > 0 && now.Sub(keys[0].CreatedAt) >= s.opts.rotation)) {
+		key, err := s.generate(now)
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			keys[0].RetiredAt = key.CreatedAt
+		}
+		keys = append([]*Key{key}, keys...)
+	}
+
+	var valid []*Key
+	for _, key := range keys {
+		if !s.expired(key, now) {
+			valid = append(valid, key)
+		} else if s.opts.rotation > 0 {
+			// Keys are only removed when they are rotated by the key set
+			_ = os.Remove(key.path)
+		}
+	}
+
+	if len(valid) == 0 {
+		return errors.New("no valid signing key")
+	}
+
+	s.lock.Lock()
+	s.keys = valid
+	s.loadedAt = now
+	s.lock.Unlock()
+	return nil
+}
+
+// Load the keys matching the signature method, newest first
+func (s *KeySet) load() ([]*Key, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	var keys []*Key
+	for _, fi := range files {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".pem" {
+			continue
+		}
+
+		path := filepath.Join(s.dir, fi.Name())
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, err := parsePrivateKey(buf)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err.Error())
+		} else if !s.matches(signer) {
+			continue
+		}
+
+		id := thumbprint(signer.Public())
+		if ids[id] {
+			continue
+		}
+		ids[id] = true
+
+		keys = append(keys, &Key{
+			ID:         id,
+			PrivateKey: signer,
+			CreatedAt:  fi.ModTime(),
+			path:       path,
+		})
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	for i := 1; i < len(keys); i++ {
+		keys[i].RetiredAt = keys[i-1].CreatedAt
+	}
+	return keys, nil
+}
+
+// A key signs with the signature method: RSA of at least 2048 bits, P-256 or Ed25519
+func (s *KeySet) matches(signer crypto.Signer) bool {
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		return s.method.Alg() == "RS256" && key.N.BitLen() >= 2048
+	case *ecdsa.PrivateKey:
+		return s.method.Alg() == "ES256" && key.Curve == elliptic.P256()
+	case ed25519.PrivateKey:
+		return s.method.Alg() == "EdDSA"
+	}
+	return false
+}
+
+func (s *KeySet) generate(now time.Time) (*Key, error) {
+	var signer crypto.Signer
+	var err error
+	switch s.method.Alg() {
+	case "RS256":
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write the key under a temporary name, other instances sharing the
+	// directory only see complete key files
+	id := thumbprint(signer.Public())
+	path := filepath.Join(s.dir, id+".pem")
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Chtimes(tmp, now, now)
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+
+	return &Key{
+		ID:         id,
+		PrivateKey: signer,
+		CreatedAt:  now,
+		path:       path,
+	}, nil
+}
+
+// Parse the first private key of a PEM file (PKCS #8, PKCS #1 or SEC 1)
+func parsePrivateKey(buf []byte) (crypto.Signer, error) {
+	for {
+		block, rest := pem.Decode(buf)
+		if block == nil {
+			return nil, errors.New("no private key found")
+		}
+		buf = rest
+
+		var key interface{}
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key")
+		}
+		return signer, nil
+	}
+}
+
+func encodeInt(b []byte, size int) string {
+	if len(b) < size {
+		b = append(make([]byte, size-len(b)), b...)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newJSONWebKey(kid, alg string, publicKey crypto.PublicKey) JSONWebKey {
+	item := JSONWebKey{
+		Kid: kid,
+		Use: "sig",
+		Alg: alg,
+	}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		item.Kty = "RSA"
+		item.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		item.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		item.Kty = "EC"
+		item.Crv = key.Curve.Params().Name
+		item.X = encodeInt(key.X.Bytes(), size)
+		item.Y = encodeInt(key.Y.Bytes(), size)
+	case ed25519.PublicKey:
+		item.Kty = "OKP"
+		item.Crv = "Ed25519"
+		item.X = base64.RawURLEncoding.EncodeToString(key)
+	}
+	return item
+}
+
+// JWK thumbprint (RFC 7638): hash of the required members in lexicographic order
+func thumbprint(publicKey crypto.PublicKey) string {
+	k := newJSONWebKey("", "", publicKey)
+
+	var members string
+	switch k.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, k.E, k.N)
+	case "EC":
+		members = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, k.Crv, k.X, k.Y)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, k.Crv, k.X)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MayCMF/core/src/common/auth/jwtauth/store/buntdb"
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySetSigningMethods(t *testing.T) {
+	for _, method := range []jwt.SigningMethod{jwt.SigningMethodRS256, jwt.SigningMethodES256, SigningMethodEdDSA} {
+		dir, err := ioutil.TempDir("", "jwt_keys")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		keySet, err := NewKeySet(dir, method)
+		assert.Nil(t, err)
+
+		store, err := buntdb.NewStore(":memory:")
+		assert.Nil(t, err)
+		jwtAuth := New(store, SetKeySet(keySet))
+
+		ctx := context.Background()
+		token, err := jwtAuth.GenerateToken(ctx, "test")
+		assert.Nil(t, err)
+
+		parsed, _ := jwt.Parse(token.GetAccessToken(), nil)
+		assert.Equal(t, method.Alg(), parsed.Header["alg"])
+		assert.Equal(t, keySet.SigningKey().ID, parsed.Header["kid"])
+
+		id, err := jwtAuth.ParseUserUUID(ctx, token.GetAccessToken())
+		assert.Nil(t, err)
+		assert.Equal(t, "test", id)
+
+		set := keySet.JWKS()
+		if assert.Len(t, set.Keys, 1) {
+			assert.Equal(t, keySet.SigningKey().ID, set.Keys[0].Kid)
+			assert.Equal(t, method.Alg(), set.Keys[0].Alg)
+		}
+
+		// The generated key is stored and loaded again
+		reloaded, err := NewKeySet(dir, method)
+		assert.Nil(t, err)
+		assert.Equal(t, keySet.SigningKey().ID, reloaded.SigningKey().ID)
+
+		_ = jwtAuth.Release()
+	}
+}
+
+func TestKeySetPEMFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt_keys")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	buf := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	err = ioutil.WriteFile(filepath.Join(dir, "signing.pem"), buf, 0600)
+	assert.Nil(t, err)
+
+	// Keys of other signature methods are ignored
+	keySet, err := NewKeySet(dir, jwt.SigningMethodRS256)
+	assert.Nil(t, err)
+	assert.Equal(t, thumbprint(&key.PublicKey), keySet.SigningKey().ID)
+
+	keySet, err = NewKeySet(dir, jwt.SigningMethodES256)
+	assert.Nil(t, err)
+	assert.NotEqual(t, thumbprint(&key.PublicKey), keySet.SigningKey().ID)
+	assert.Len(t, keySet.Keys(), 1)
+
+	// Tokens of the shared signing key (HMAC) are refused
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.StandardClaims{Subject: "test"})
+	tokenString, err := token.SignedString([]byte(defaultKey))
+	assert.Nil(t, err)
+	_, err = jwt.Parse(tokenString, keySet.Keyfunc)
+	assert.NotNil(t, err)
+}
+
+func TestKeySetRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jwt_keys")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	keySet, err := NewKeySet(dir, jwt.SigningMethodES256,
+		SetKeyRotation(time.Hour),
+		SetKeyOverlap(2*time.Hour),
+	)
+	assert.Nil(t, err)
+	first := keySet.SigningKey()
+
+	token := jwt.New(jwt.SigningMethodES256)
+	token.Header["kid"] = first.ID
+	tokenString, err := token.SignedString(first.PrivateKey)
+	assert.Nil(t, err)
+
+	// The signing key is not due yet
+	now := time.Now()
+	err = keySet.refresh(now.Add(30*time.Minute), true)
+	assert.Nil(t, err)
+	assert.Equal(t, first.ID, keySet.SigningKey().ID)
+
+	// A new key signs, the replaced key still verifies and is published
+	err = keySet.refresh(now.Add(time.Hour), true)
+	assert.Nil(t, err)
+	second := keySet.SigningKey()
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Len(t, keySet.JWKS().Keys, 2)
+
+	_, err = jwt.Parse(tokenString, keySet.Keyfunc)
+	assert.Nil(t, err)
+
+	// The replaced key is removed after the overlap
+	err = keySet.refresh(now.Add(3*time.Hour+time.Minute), false)
+	assert.Nil(t, err)
+	assert.Equal(t, second.ID, keySet.SigningKey().ID)
+	assert.Len(t, keySet.Keys(), 1)
+
+	_, err = os.Stat(filepath.Join(dir, first.ID+".pem"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -11,6 +11,12 @@ type Storer interface {
 	Set(ctx context.Context, tokenString string, expiration time.Duration) error
 	// Check if the token exists
 	Check(ctx context.Context, tokenString string) (bool, error)
+	// Store a value under a key and specify expiration time
+	SetValue(ctx context.Context, key, value string, expiration time.Duration) error
+	// Get the value of a key, empty if the key does not exist
+	GetValue(ctx context.Context, key string) (string, error)
+	// Replace the value of a key and return the previous one in one step
+	Swap(ctx context.Context, key, value string, expiration time.Duration) (string, error)
 	// Close storage
 	Close() error
 }
@@ -30,16 +30,49 @@ type Store struct {
 	db *buntdb.DB
 }
 
+func setOptions(expiration time.Duration) *buntdb.SetOptions {
+	if expiration > 0 {
+		return &buntdb.SetOptions{Expires: true, TTL: expiration}
+	}
+	return nil
+}
+
 // Set ...
 func (a *Store) Set(ctx context.Context, tokenString string, expiration time.Duration) error {
+	return a.SetValue(ctx, tokenString, "1", expiration)
+}
+
+// SetValue - Store a value under a key
+func (a *Store) SetValue(ctx context.Context, key, value string, expiration time.Duration) error {
 	return a.db.Update(func(tx *buntdb.Tx) error {
-		var opts *buntdb.SetOptions
-		if expiration > 0 {
-			opts = &buntdb.SetOptions{Expires: true, TTL: expiration}
+		_, _, err := tx.Set(key, value, setOptions(expiration))
+		return err
+	})
+}
+
+// GetValue - Get the value of a key
+func (a *Store) GetValue(ctx context.Context, key string) (string, error) {
+	var value string
+	err := a.db.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(key)
+		if err != nil && err != buntdb.ErrNotFound {
+			return err
 		}
-		_, _, err := tx.Set(tokenString, "1", opts)
+		value = val
+		return nil
+	})
+	return value, err
+}
+
+// Swap - Replace the value of a key and return the previous one
+func (a *Store) Swap(ctx context.Context, key, value string, expiration time.Duration) (string, error) {
+	var previous string
+	err := a.db.Update(func(tx *buntdb.Tx) error {
+		val, _, err := tx.Set(key, value, setOptions(expiration))
+		previous = val
 		return err
 	})
+	return previous, err
 }
 
 // Delete - Delete key
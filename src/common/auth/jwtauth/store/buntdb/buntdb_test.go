@@ -25,3 +25,31 @@ func TestStore(t *testing.T) {
 	err = store.Delete(ctx, key)
 	assert.Nil(t, err)
 }
+
+func TestStoreValue(t *testing.T) {
+	store, err := NewStore(":memory:")
+	assert.Nil(t, err)
+
+	defer store.Close()
+
+	key := "test"
+	ctx := context.Background()
+	v, err := store.GetValue(ctx, key)
+	assert.Nil(t, err)
+	assert.Empty(t, v)
+
+	err = store.SetValue(ctx, key, "foo", 0)
+	assert.Nil(t, err)
+
+	v, err = store.GetValue(ctx, key)
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", v)
+
+	v, err = store.Swap(ctx, key, "bar", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", v)
+
+	v, err = store.Swap(ctx, key, "baz", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "bar", v)
+}
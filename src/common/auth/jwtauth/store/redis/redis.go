@@ -73,7 +73,7 @@ func (s *Store) Set(ctx context.Context, tokenString string, expiration time.Dur
 
 // Delete ...
 func (s *Store) Delete(ctx context.Context, tokenString string) error {
-	cmd := s.cli.Del(tokenString)
+	cmd := s.cli.Del(s.wrapperKey(tokenString))
 	if err := cmd.Err(); err != nil {
 		return err
 	}
@@ -89,6 +89,42 @@ func (s *Store) Check(ctx context.Context, tokenString string) (bool, error) {
 	return cmd.Val() > 0, nil
 }
 
+// SetValue - Store a value under a key
+func (s *Store) SetValue(ctx context.Context, key, value string, expiration time.Duration) error {
+	cmd := s.cli.Set(s.wrapperKey(key), value, expiration)
+	return cmd.Err()
+}
+
+// GetValue - Get the value of a key
+func (s *Store) GetValue(ctx context.Context, key string) (string, error) {
+	cmd := s.cli.Get(s.wrapperKey(key))
+	if err := cmd.Err(); err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return cmd.Val(), nil
+}
+
+// Swap - Replace the value of a key and return the previous one
+func (s *Store) Swap(ctx context.Context, key, value string, expiration time.Duration) (string, error) {
+	pipe := s.cli.TxPipeline()
+	cmd := pipe.GetSet(s.wrapperKey(key), value)
+	if expiration > 0 {
+		pipe.Expire(s.wrapperKey(key), expiration)
+	}
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		return "", err
+	}
+
+	if err := cmd.Err(); err == redis.Nil {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return cmd.Val(), nil
+}
+
 // Close ...
 func (s *Store) Close() error {
 	return s.cli.Close()
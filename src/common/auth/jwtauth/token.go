@@ -9,6 +9,9 @@ type tokenInfo struct {
 	AccessToken string `json:"access_token"` // Access token
 	TokenType   string `json:"token_type"`   // Token type
 	ExpiresAt   int64  `json:"expires_at"`   // Token expiration time
+
+	RefreshToken string `json:"refresh_token,omitempty"` // Refresh token
+	SessionID    string `json:"-"`                       // Token family
 }
 
 func (t *tokenInfo) GetAccessToken() string {
@@ -23,6 +26,14 @@ func (t *tokenInfo) GetExpiresAt() int64 {
 	return t.ExpiresAt
 }
 
+func (t *tokenInfo) GetRefreshToken() string {
+	return t.RefreshToken
+}
+
+func (t *tokenInfo) GetSessionID() string {
+	return t.SessionID
+}
+
 func (t *tokenInfo) EncodeToJSON() ([]byte, error) {
 	return json.Marshal(t)
 }
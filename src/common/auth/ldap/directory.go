@@ -0,0 +1,233 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Definition error
+var (
+	ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+	ErrUserNotFound       = errors.New("ldap: user not found")
+	ErrAmbiguousUser      = errors.New("ldap: user name matches several entries")
+)
+
+// Config - Directory of the users of logins
+type Config struct {
+	URL               string        // ldap:// or ldaps:// URL of the server
+	StartTLS          bool          // Encrypt ldap:// connections with StartTLS
+	TLSConfig         *tls.Config   // TLS settings of ldaps:// and StartTLS
+	Timeout           time.Duration // Time limit of each operation
+	BindDN            string        // Service account searching the users, anonymous when empty
+	BindPassword      string        // Password of the service account
+	BaseDN            string        // Base of the user search
+	UserFilter        string        // Filter of a user, %s is the escaped user name, e.g. (uid=%s)
+	UserNameAttribute string        // Attribute of the user name (default: the login name)
+	RealNameAttribute string        // Attribute of the real name (default: cn)
+	EmailAttribute    string        // Attribute of the email address (default: mail)
+	GroupAttribute    string        // Attribute of a user listing its group DNs, e.g. memberOf
+	GroupBaseDN       string        // Base of the group search (default: BaseDN)
+	GroupFilter       string        // Filter of the groups of a user, %s is the escaped user DN, e.g. (member=%s)
+}
+
+// Identity - User of a directory
+type Identity struct {
+	DN       string   // DN of the user entry
+	UserName string   // User name
+	RealName string   // Real name
+	Email    string   // Email address
+	Groups   []string // DNs of the groups of the user
+}
+
+// NewDirectory - Create the client of a directory
+func NewDirectory(cfg Config) *Directory {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(uid=%s)"
+	}
+	if cfg.RealNameAttribute == "" {
+		cfg.RealNameAttribute = "cn"
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.GroupBaseDN == "" {
+		cfg.GroupBaseDN = cfg.BaseDN
+	}
+	return &Directory{cfg: cfg}
+}
+
+// Directory - Checks the passwords of users with a bind as their entry
+type Directory struct {
+	cfg Config
+}
+
+// Authenticate - Find the entry of a user name and bind as it with the
+// password, the groups are searched as the service account
+func (a *Directory) Authenticate(ctx context.Context, userName, password string) (*Identity, error) {
+	if userName == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := a.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	attributes := []string{a.cfg.RealNameAttribute, a.cfg.EmailAttribute}
+	if a.cfg.UserNameAttribute != "" {
+		attributes = append(attributes, a.cfg.UserNameAttribute)
+	}
+	if a.cfg.GroupAttribute != "" {
+		attributes = append(attributes, a.cfg.GroupAttribute)
+	}
+
+	entries, err := conn.Search(SearchRequest{
+		BaseDN:     a.cfg.BaseDN,
+		Scope:      ScopeWholeSubtree,
+		Filter:     fmt.Sprintf(a.cfg.UserFilter, EscapeFilter(userName)),
+		Attributes: attributes,
+		SizeLimit:  2,
+	})
+	if IsResult(err, ResultSizeLimitExceeded) || len(entries) > 1 {
+		return nil, ErrAmbiguousUser
+	} else if err != nil {
+		return nil, err
+	} else if len(entries) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	entry := entries[0]
+	err = conn.Bind(entry.DN, password)
+	if IsResult(err, ResultInvalidCredentials) {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, err
+	}
+
+	item := &Identity{
+		DN:       entry.DN,
+		UserName: userName,
+		RealName: entry.Value(a.cfg.RealNameAttribute),
+		Email:    entry.Value(a.cfg.EmailAttribute),
+	}
+	if a.cfg.UserNameAttribute != "" {
+		if v := entry.Value(a.cfg.UserNameAttribute); v != "" {
+			item.UserName = v
+		}
+	}
+
+	if a.cfg.GroupFilter != "" {
+		item.Groups, err = a.searchGroups(conn, entry.DN)
+		if err != nil {
+			return nil, err
+		}
+	} else if a.cfg.GroupAttribute != "" {
+		item.Groups = entry.Values(a.cfg.GroupAttribute)
+	}
+	return item, nil
+}
+
+func (a *Directory) dial(ctx context.Context) (*Conn, error) {
+	conn, err := Dial(ctx, a.cfg.URL, a.cfg.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+	conn.Timeout = a.cfg.Timeout
+
+	if a.cfg.StartTLS && strings.HasPrefix(a.cfg.URL, "ldap://") {
+		err = conn.StartTLS(a.cfg.TLSConfig)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	err = a.bindService(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (a *Directory) bindService(conn *Conn) error {
+	if a.cfg.BindDN == "" {
+		return conn.UnauthenticatedBind()
+	}
+	return conn.Bind(a.cfg.BindDN, a.cfg.BindPassword)
+}
+
+func (a *Directory) searchGroups(conn *Conn, userDN string) ([]string, error) {
+	err := a.bindService(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := conn.Search(SearchRequest{
+		BaseDN:     a.cfg.GroupBaseDN,
+		Scope:      ScopeWholeSubtree,
+		Filter:     fmt.Sprintf(a.cfg.GroupFilter, EscapeFilter(userDN)),
+		Attributes: []string{"1.1"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]string, len(entries))
+	for i, item := range entries {
+		groups[i] = item.DN
+	}
+	return groups, nil
+}
+
+// RDNValue - Get the value of the first attribute of a DN, e.g. the common
+// name of a group
+func RDNValue(dn string) string {
+	rdn := dn
+	for i := 0; i < len(dn); i++ {
+		if dn[i] == '\\' {
+			i++
+		} else if dn[i] == ',' || dn[i] == '+' {
+			rdn = dn[:i]
+			break
+		}
+	}
+
+	i := strings.IndexByte(rdn, '=')
+	if i < 0 {
+		return ""
+	}
+	return unescapeDN(strings.TrimSpace(rdn[i+1:]))
+}
+
+// Unescape the special characters of a DN value (RFC 4514)
+func unescapeDN(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		if i+2 < len(s) {
+			if c, err := hex.DecodeString(s[i+1 : i+3]); err == nil {
+				b.Write(c)
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i+1])
+		i++
+	}
+	return b.String()
+}
@@ -0,0 +1,181 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/MayCMF/core/src/common/auth/ldap/internal/ber"
+)
+
+// Filter choices
+const (
+	filterAnd        = ber.ClassContext | ber.Constructed | 0
+	filterOr         = ber.ClassContext | ber.Constructed | 1
+	filterNot        = ber.ClassContext | ber.Constructed | 2
+	filterEquality   = ber.ClassContext | ber.Constructed | 3
+	filterSubstrings = ber.ClassContext | ber.Constructed | 4
+	filterPresent    = ber.ClassContext | 7
+)
+
+// Deepest nesting of filters accepted
+const maxFilterDepth = 16
+
+// ErrInvalidFilter - Definition error
+var ErrInvalidFilter = errors.New("ldap: invalid or unsupported filter")
+
+// EscapeFilter - Escape a value for a filter (RFC 4515), e.g. a user name
+func EscapeFilter(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '*', '(', ')', 0:
+			b.WriteByte('\\')
+			b.WriteString(hex.EncodeToString([]byte{c}))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Compile a filter, the and, or, not, equality, presence and substring
+// filters are supported
+func compileFilter(filter string) (*ber.Packet, error) {
+	p, rest, err := parseFilter(filter, 0)
+	if err != nil {
+		return nil, err
+	} else if rest != "" {
+		return nil, ErrInvalidFilter
+	}
+	return p, nil
+}
+
+func parseFilter(s string, depth int) (*ber.Packet, string, error) {
+	if depth > maxFilterDepth || len(s) < 2 || s[0] != '(' {
+		return nil, "", ErrInvalidFilter
+	}
+	s = s[1:]
+
+	var p *ber.Packet
+	switch s[0] {
+	case '&', '|':
+		tag := byte(filterAnd)
+		if s[0] == '|' {
+			tag = filterOr
+		}
+		p = ber.NewSequence(tag)
+		s = s[1:]
+		for strings.HasPrefix(s, "(") {
+			var child *ber.Packet
+			var err error
+			child, s, err = parseFilter(s, depth+1)
+			if err != nil {
+				return nil, "", err
+			}
+			p.Append(child)
+		}
+		if len(p.Children) == 0 {
+			return nil, "", ErrInvalidFilter
+		}
+	case '!':
+		child, rest, err := parseFilter(s[1:], depth+1)
+		if err != nil {
+			return nil, "", err
+		}
+		p, s = ber.NewSequence(filterNot, child), rest
+	default:
+		// Values escape their parentheses, the first one ends the item
+		i := strings.IndexByte(s, ')')
+		if i < 0 {
+			return nil, "", ErrInvalidFilter
+		}
+
+		var err error
+		p, err = parseItem(s[:i])
+		if err != nil {
+			return nil, "", err
+		}
+		s = s[i:]
+	}
+
+	if !strings.HasPrefix(s, ")") {
+		return nil, "", ErrInvalidFilter
+	}
+	return p, s[1:], nil
+}
+
+func parseItem(item string) (*ber.Packet, error) {
+	i := strings.IndexByte(item, '=')
+	if i <= 0 {
+		return nil, ErrInvalidFilter
+	}
+
+	attr, value := item[:i], item[i+1:]
+	if strings.ContainsAny(attr, "<>~:*\\") {
+		return nil, ErrInvalidFilter
+	} else if value == "*" {
+		return ber.NewString(filterPresent, attr), nil
+	} else if !strings.Contains(value, "*") {
+		v, err := unescapeFilter(value)
+		if err != nil {
+			return nil, err
+		}
+		return ber.NewSequence(filterEquality,
+			ber.NewString(ber.TagOctetString, attr),
+			ber.NewString(ber.TagOctetString, v),
+		), nil
+	}
+
+	parts := strings.Split(value, "*")
+	substrings := ber.NewSequence(ber.TagSequence)
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		v, err := unescapeFilter(part)
+		if err != nil {
+			return nil, err
+		}
+
+		tag := byte(ber.ClassContext | 1)
+		if i == 0 {
+			tag = ber.ClassContext | 0
+		} else if i == len(parts)-1 {
+			tag = ber.ClassContext | 2
+		}
+		substrings.Append(ber.NewString(tag, v))
+	}
+	return ber.NewSequence(filterSubstrings,
+		ber.NewString(ber.TagOctetString, attr),
+		substrings,
+	), nil
+}
+
+func unescapeFilter(s string) (string, error) {
+	if !strings.ContainsAny(s, "\\(") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			return "", ErrInvalidFilter
+		case '\\':
+			if i+3 > len(s) {
+				return "", ErrInvalidFilter
+			}
+			c, err := hex.DecodeString(s[i+1 : i+3])
+			if err != nil {
+				return "", ErrInvalidFilter
+			}
+			b.Write(c)
+			i += 2
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
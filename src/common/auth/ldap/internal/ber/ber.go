@@ -0,0 +1,259 @@
+// Package ber encodes and decodes the subset of the ASN.1 basic encoding
+// rules used by LDAP: single byte tags and definite lengths.
+package ber
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Classes and the constructed bit of a tag
+const (
+	ClassUniversal   = 0x00
+	ClassApplication = 0x40
+	ClassContext     = 0x80
+	Constructed      = 0x20
+)
+
+// Universal tags
+const (
+	TagBoolean     = 0x01
+	TagInteger     = 0x02
+	TagOctetString = 0x04
+	TagNull        = 0x05
+	TagEnumerated  = 0x0a
+	TagSequence    = 0x30
+	TagSet         = 0x31
+)
+
+// Largest packet accepted, directories answer a search with one packet per entry
+const maxLength = 16 << 20
+
+// Deepest nesting of constructed values accepted
+const maxDepth = 32
+
+// Definition error
+var (
+	ErrTooLarge  = errors.New("ber: packet too large")
+	ErrMalformed = errors.New("ber: malformed packet")
+)
+
+// Packet - Encoded value, a constructed value holds its children
+type Packet struct {
+	Tag      byte      // Identifier octet (class, constructed bit and tag number)
+	Value    []byte    // Contents of a primitive value
+	Children []*Packet // Values of a constructed value
+}
+
+// NewSequence - Create a constructed value
+func NewSequence(tag byte, children ...*Packet) *Packet {
+	return &Packet{Tag: tag | Constructed, Children: children}
+}
+
+// NewString - Create a primitive value of a string
+func NewString(tag byte, s string) *Packet {
+	return &Packet{Tag: tag, Value: []byte(s)}
+}
+
+// NewInteger - Create a primitive value of an integer
+func NewInteger(tag byte, v int64) *Packet {
+	var b []byte
+	for {
+		b = append([]byte{byte(v)}, b...)
+		v >>= 8
+		if (v == 0 && b[0]&0x80 == 0) || (v == -1 && b[0]&0x80 != 0) {
+			break
+		}
+	}
+	return &Packet{Tag: tag, Value: b}
+}
+
+// NewBoolean - Create a primitive value of a boolean
+func NewBoolean(tag byte, v bool) *Packet {
+	if v {
+		return &Packet{Tag: tag, Value: []byte{0xff}}
+	}
+	return &Packet{Tag: tag, Value: []byte{0x00}}
+}
+
+// IsConstructed - Check whether the value holds children
+func (p *Packet) IsConstructed() bool {
+	return p.Tag&Constructed != 0
+}
+
+// Append - Add children to a constructed value
+func (p *Packet) Append(children ...*Packet) *Packet {
+	p.Children = append(p.Children, children...)
+	return p
+}
+
+// Child - Get a child, nil when there are fewer children
+func (p *Packet) Child(i int) *Packet {
+	if i < 0 || i >= len(p.Children) {
+		return nil
+	}
+	return p.Children[i]
+}
+
+// String - Contents as string
+func (p *Packet) String() string {
+	if p == nil {
+		return ""
+	}
+	return string(p.Value)
+}
+
+// Int - Contents as integer
+func (p *Packet) Int() (int64, error) {
+	if p == nil || len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, ErrMalformed
+	}
+
+	v := int64(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// Bool - Contents as boolean
+func (p *Packet) Bool() bool {
+	return p != nil && len(p.Value) == 1 && p.Value[0] != 0
+}
+
+// Bytes - Encode the value
+func (p *Packet) Bytes() []byte {
+	contents := p.Value
+	if p.IsConstructed() {
+		contents = nil
+		for _, c := range p.Children {
+			contents = append(contents, c.Bytes()...)
+		}
+	}
+
+	b := append([]byte{p.Tag}, encodeLength(len(contents))...)
+	return append(b, contents...)
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	return append([]byte{0x80 | byte(len(b))}, b...)
+}
+
+// Read - Read the next value of a stream
+func Read(r *bufio.Reader) (*Packet, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if tag&0x1f == 0x1f {
+		return nil, fmt.Errorf("ber: unsupported tag %#x", tag)
+	}
+
+	n, err := r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	length := int(n)
+	if n&0x80 != 0 {
+		size := int(n & 0x7f)
+		if size == 0 || size > 4 {
+			return nil, ErrMalformed
+		}
+		length = 0
+		for i := 0; i < size; i++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxLength {
+		return nil, ErrTooLarge
+	}
+
+	contents := make([]byte, length)
+	if _, err := io.ReadFull(r, contents); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return decode(tag, contents, 0)
+}
+
+// Parse - Decode a single value
+func Parse(b []byte) (*Packet, error) {
+	p, rest, err := parse(b, 0)
+	if err != nil {
+		return nil, err
+	} else if len(rest) > 0 {
+		return nil, ErrMalformed
+	}
+	return p, nil
+}
+
+func parse(b []byte, depth int) (*Packet, []byte, error) {
+	if len(b) < 2 || b[0]&0x1f == 0x1f {
+		return nil, nil, ErrMalformed
+	}
+
+	tag, n := b[0], b[1]
+	b = b[2:]
+	length := int(n)
+	if n&0x80 != 0 {
+		size := int(n & 0x7f)
+		if size == 0 || size > 4 || len(b) < size {
+			return nil, nil, ErrMalformed
+		}
+		length = 0
+		for _, c := range b[:size] {
+			length = length<<8 | int(c)
+		}
+		b = b[size:]
+	}
+	if length < 0 || length > len(b) {
+		return nil, nil, ErrMalformed
+	}
+
+	p, err := decode(tag, b[:length], depth)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, b[length:], nil
+}
+
+func decode(tag byte, contents []byte, depth int) (*Packet, error) {
+	p := &Packet{Tag: tag}
+	if !p.IsConstructed() {
+		p.Value = contents
+		return p, nil
+	} else if depth >= maxDepth {
+		return nil, ErrMalformed
+	}
+
+	for len(contents) > 0 {
+		child, rest, err := parse(contents, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		p.Children = append(p.Children, child)
+		contents = rest
+	}
+	return p, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
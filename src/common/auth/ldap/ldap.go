@@ -0,0 +1,385 @@
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MayCMF/core/src/common/auth/ldap/internal/ber"
+)
+
+// Result codes
+const (
+	ResultSuccess                  = 0
+	ResultProtocolError            = 2
+	ResultSizeLimitExceeded        = 4
+	ResultInvalidCredentials       = 49
+	ResultInsufficientAccessRights = 50
+	ResultUnavailable              = 52
+)
+
+// Search scopes
+const (
+	ScopeBaseObject   = 0
+	ScopeSingleLevel  = 1
+	ScopeWholeSubtree = 2
+)
+
+// Protocol operations
+const (
+	opBindRequest      = ber.ClassApplication | ber.Constructed | 0
+	opBindResponse     = ber.ClassApplication | ber.Constructed | 1
+	opUnbindRequest    = ber.ClassApplication | 2
+	opSearchRequest    = ber.ClassApplication | ber.Constructed | 3
+	opSearchEntry      = ber.ClassApplication | ber.Constructed | 4
+	opSearchDone       = ber.ClassApplication | ber.Constructed | 5
+	opSearchReference  = ber.ClassApplication | ber.Constructed | 19
+	opExtendedRequest  = ber.ClassApplication | ber.Constructed | 23
+	opExtendedResponse = ber.ClassApplication | ber.Constructed | 24
+)
+
+// StartTLSOID - Name of the StartTLS extended operation
+const StartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// Definition error
+var (
+	ErrEmptyPassword  = errors.New("ldap: empty password")
+	ErrUnexpectedOp   = errors.New("ldap: unexpected response")
+	ErrTLSStarted     = errors.New("ldap: connection is already encrypted")
+	ErrInvalidURL     = errors.New("ldap: url must start with ldap:// or ldaps://")
+	ErrConnectionDone = errors.New("ldap: connection closed by the server")
+)
+
+// Error - Result code of a failed operation
+type Error struct {
+	Code    int    // Result code
+	Message string // Diagnostic message of the server
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.Code)
+	}
+	return fmt.Sprintf("ldap: result code %d: %s", e.Code, e.Message)
+}
+
+// IsResult - Check whether an error is the result code of a failed operation
+func IsResult(err error, code int) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == code
+}
+
+// Entry - Directory entry, the attribute names are lowercase
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Values - Get the values of an attribute
+func (e *Entry) Values(name string) []string {
+	return e.Attributes[strings.ToLower(name)]
+}
+
+// Value - Get the first value of an attribute
+func (e *Entry) Value(name string) string {
+	if values := e.Values(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// SearchRequest - Search parameters
+type SearchRequest struct {
+	BaseDN     string   // Base of the search
+	Scope      int      // Scope of the search below the base
+	Filter     string   // Filter (RFC 4515)
+	Attributes []string // Attributes of the entries to get, all when empty
+	SizeLimit  int      // Maximum number of entries (0: unlimited)
+}
+
+// Dial - Connect to a directory, ldaps:// URLs are encrypted at once
+func Dial(ctx context.Context, rawURL string, tlsConfig *tls.Config) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var port string
+	switch u.Scheme {
+	case "ldap":
+		port = "389"
+	case "ldaps":
+		port = "636"
+	default:
+		return nil, ErrInvalidURL
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{conn: conn, host: u.Hostname()}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.deadline = deadline
+	}
+	if u.Scheme == "ldaps" {
+		err = c.startTLS(tlsConfig)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	c.r = bufio.NewReader(c.conn)
+	return c, nil
+}
+
+// Conn - Connection to a directory, operations run one after another
+type Conn struct {
+	Timeout time.Duration // Time limit of each operation (0: none)
+
+	lock     sync.Mutex
+	conn     net.Conn
+	r        *bufio.Reader
+	host     string
+	deadline time.Time
+	msgID    int64
+	tls      bool
+}
+
+// StartTLS - Encrypt the connection with the StartTLS extended operation
+func (c *Conn) StartTLS(tlsConfig *tls.Config) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.tls {
+		return ErrTLSStarted
+	}
+
+	id, err := c.send(ber.NewSequence(opExtendedRequest,
+		ber.NewString(ber.ClassContext|0, StartTLSOID),
+	))
+	if err != nil {
+		return err
+	}
+
+	op, err := c.receive(id)
+	if err != nil {
+		return err
+	} else if op.Tag != opExtendedResponse {
+		return ErrUnexpectedOp
+	} else if err := result(op); err != nil {
+		return err
+	}
+
+	err = c.startTLS(tlsConfig)
+	if err != nil {
+		return err
+	}
+	c.r = bufio.NewReader(c.conn)
+	return nil
+}
+
+func (c *Conn) startTLS(tlsConfig *tls.Config) error {
+	if tlsConfig == nil {
+		tlsConfig = new(tls.Config)
+	}
+	if tlsConfig.ServerName == "" && !tlsConfig.InsecureSkipVerify {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = c.host
+	}
+
+	conn := tls.Client(c.conn, tlsConfig)
+	c.setDeadline()
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	c.conn = conn
+	c.tls = true
+	return nil
+}
+
+// Bind - Authenticate with a DN and password, empty passwords are refused
+// as directories accept them as unauthenticated binds
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		return ErrEmptyPassword
+	}
+	return c.bind(dn, password)
+}
+
+// UnauthenticatedBind - Bind anonymously
+func (c *Conn) UnauthenticatedBind() error {
+	return c.bind("", "")
+}
+
+func (c *Conn) bind(dn, password string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	id, err := c.send(ber.NewSequence(opBindRequest,
+		ber.NewInteger(ber.TagInteger, 3),
+		ber.NewString(ber.TagOctetString, dn),
+		ber.NewString(ber.ClassContext|0, password),
+	))
+	if err != nil {
+		return err
+	}
+
+	op, err := c.receive(id)
+	if err != nil {
+		return err
+	} else if op.Tag != opBindResponse {
+		return ErrUnexpectedOp
+	}
+	return result(op)
+}
+
+// Search - Search entries, the entries found before a size limit error are
+// returned with it
+func (c *Conn) Search(req SearchRequest) ([]*Entry, error) {
+	filter, err := compileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := ber.NewSequence(ber.TagSequence)
+	for _, name := range req.Attributes {
+		attributes.Append(ber.NewString(ber.TagOctetString, name))
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	id, err := c.send(ber.NewSequence(opSearchRequest,
+		ber.NewString(ber.TagOctetString, req.BaseDN),
+		ber.NewInteger(ber.TagEnumerated, int64(req.Scope)),
+		ber.NewInteger(ber.TagEnumerated, 0),
+		ber.NewInteger(ber.TagInteger, int64(req.SizeLimit)),
+		ber.NewInteger(ber.TagInteger, 0),
+		ber.NewBoolean(ber.TagBoolean, false),
+		filter,
+		attributes,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	for {
+		op, err := c.receive(id)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Tag {
+		case opSearchEntry:
+			entries = append(entries, parseEntry(op))
+		case opSearchReference:
+			// Referrals to other directories are not followed
+		case opSearchDone:
+			return entries, result(op)
+		default:
+			return nil, ErrUnexpectedOp
+		}
+	}
+}
+
+func parseEntry(op *ber.Packet) *Entry {
+	item := &Entry{
+		DN:         op.Child(0).String(),
+		Attributes: make(map[string][]string),
+	}
+
+	if attributes := op.Child(1); attributes != nil {
+		for _, attr := range attributes.Children {
+			name := strings.ToLower(attr.Child(0).String())
+			if values := attr.Child(1); values != nil {
+				for _, v := range values.Children {
+					item.Attributes[name] = append(item.Attributes[name], v.String())
+				}
+			}
+		}
+	}
+	return item
+}
+
+// Close - Unbind and close the connection
+func (c *Conn) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, _ = c.send(&ber.Packet{Tag: opUnbindRequest})
+	return c.conn.Close()
+}
+
+func (c *Conn) setDeadline() {
+	deadline := c.deadline
+	if c.Timeout > 0 {
+		if t := time.Now().Add(c.Timeout); deadline.IsZero() || t.Before(deadline) {
+			deadline = t
+		}
+	}
+	_ = c.conn.SetDeadline(deadline)
+}
+
+func (c *Conn) send(op *ber.Packet) (int64, error) {
+	c.msgID++
+	msg := ber.NewSequence(ber.TagSequence,
+		ber.NewInteger(ber.TagInteger, c.msgID),
+		op,
+	)
+
+	c.setDeadline()
+	_, err := c.conn.Write(msg.Bytes())
+	return c.msgID, err
+}
+
+// Receive the response of a message, unsolicited notifications end the
+// connection
+func (c *Conn) receive(id int64) (*ber.Packet, error) {
+	for {
+		msg, err := ber.Read(c.r)
+		if err != nil {
+			return nil, err
+		} else if len(msg.Children) < 2 {
+			return nil, ber.ErrMalformed
+		}
+
+		msgID, err := msg.Children[0].Int()
+		if err != nil {
+			return nil, err
+		} else if msgID == 0 {
+			if err := result(msg.Children[1]); err != nil {
+				return nil, err
+			}
+			return nil, ErrConnectionDone
+		} else if msgID == id {
+			return msg.Children[1], nil
+		}
+	}
+}
+
+// Error of the result of an operation
+func result(op *ber.Packet) error {
+	code, err := op.Child(0).Int()
+	if err != nil {
+		return err
+	} else if code != ResultSuccess {
+		return &Error{Code: int(code), Message: op.Child(2).String()}
+	}
+	return nil
+}
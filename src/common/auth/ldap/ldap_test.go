@@ -0,0 +1,201 @@
+package ldap_test
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/MayCMF/core/src/common/auth/ldap"
+	"github.com/MayCMF/core/src/common/auth/ldap/ldaptest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	baseDN    = "dc=example,dc=com"
+	serviceDN = "cn=service,dc=example,dc=com"
+	aliceDN   = "uid=alice,ou=people,dc=example,dc=com"
+)
+
+func newServer() *ldaptest.Server {
+	server := ldaptest.NewServer()
+	server.Add(serviceDN, map[string][]string{
+		"objectClass":  {"person"},
+		"cn":           {"service"},
+		"userPassword": {"service-secret"},
+	})
+	server.Add(aliceDN, map[string][]string{
+		"objectClass":  {"inetOrgPerson"},
+		"uid":          {"alice"},
+		"cn":           {"Alice Liddell"},
+		"mail":         {"alice@example.com"},
+		"userPassword": {"alice-secret"},
+		"memberOf":     {"cn=editors,ou=groups,dc=example,dc=com"},
+	})
+	server.Add("cn=editors,ou=groups,dc=example,dc=com", map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {"editors"},
+		"member":      {aliceDN},
+	})
+	server.Add("cn=staff,ou=groups,dc=example,dc=com", map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {"staff"},
+		"member":      {aliceDN, "uid=bob,ou=people,dc=example,dc=com"},
+	})
+	return server
+}
+
+func newConfig(server *ldaptest.Server) ldap.Config {
+	return ldap.Config{
+		URL:            server.URL,
+		Timeout:        5 * time.Second,
+		BindDN:         serviceDN,
+		BindPassword:   "service-secret",
+		BaseDN:         baseDN,
+		UserFilter:     "(&(objectClass=inetOrgPerson)(uid=%s))",
+		GroupAttribute: "memberOf",
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	d := ldap.NewDirectory(newConfig(server))
+
+	identity, err := d.Authenticate(ctx, "alice", "alice-secret")
+	assert.Nil(t, err)
+	assert.Equal(t, aliceDN, identity.DN)
+	assert.Equal(t, "alice", identity.UserName)
+	assert.Equal(t, "Alice Liddell", identity.RealName)
+	assert.Equal(t, "alice@example.com", identity.Email)
+	assert.Equal(t, []string{"cn=editors,ou=groups,dc=example,dc=com"}, identity.Groups)
+	assert.Equal(t, []string{serviceDN, aliceDN}, server.Binds())
+
+	_, err = d.Authenticate(ctx, "alice", "invalid")
+	assert.Equal(t, ldap.ErrInvalidCredentials, err)
+
+	_, err = d.Authenticate(ctx, "alice", "")
+	assert.Equal(t, ldap.ErrInvalidCredentials, err)
+
+	_, err = d.Authenticate(ctx, "bob", "bob-secret")
+	assert.Equal(t, ldap.ErrUserNotFound, err)
+
+	// User names cannot change the filter
+	_, err = d.Authenticate(ctx, "*", "alice-secret")
+	assert.Equal(t, ldap.ErrUserNotFound, err)
+	_, err = d.Authenticate(ctx, "alice)(uid=*", "alice-secret")
+	assert.Equal(t, ldap.ErrUserNotFound, err)
+
+	// A wrong service password fails every login
+	cfg := newConfig(server)
+	cfg.BindPassword = "invalid"
+	_, err = ldap.NewDirectory(cfg).Authenticate(ctx, "alice", "alice-secret")
+	assert.True(t, ldap.IsResult(err, ldap.ResultInvalidCredentials))
+}
+
+func TestDirectoryGroupSearch(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	cfg := newConfig(server)
+	cfg.GroupAttribute = ""
+	cfg.GroupBaseDN = "ou=groups," + baseDN
+	cfg.GroupFilter = "(&(objectClass=groupOfNames)(member=%s))"
+
+	identity, err := ldap.NewDirectory(cfg).Authenticate(context.Background(), "alice", "alice-secret")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"cn=editors,ou=groups,dc=example,dc=com",
+		"cn=staff,ou=groups,dc=example,dc=com",
+	}, identity.Groups)
+}
+
+func TestDirectoryAmbiguousUser(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+	server.Add("uid=alice,ou=guests,dc=example,dc=com", map[string][]string{
+		"objectClass":  {"inetOrgPerson"},
+		"uid":          {"alice"},
+		"userPassword": {"alice-secret"},
+	})
+
+	_, err := ldap.NewDirectory(newConfig(server)).Authenticate(context.Background(), "alice", "alice-secret")
+	assert.Equal(t, ldap.ErrAmbiguousUser, err)
+}
+
+func TestDirectoryStartTLS(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+	server.RequireTLS = true
+
+	ctx := context.Background()
+	cfg := newConfig(server)
+	_, err := ldap.NewDirectory(cfg).Authenticate(ctx, "alice", "alice-secret")
+	assert.True(t, ldap.IsResult(err, 13))
+
+	cfg.StartTLS = true
+	cfg.TLSConfig = &tls.Config{RootCAs: server.CertPool()}
+	identity, err := ldap.NewDirectory(cfg).Authenticate(ctx, "alice", "alice-secret")
+	assert.Nil(t, err)
+	assert.Equal(t, aliceDN, identity.DN)
+
+	// The certificate of the server is verified
+	cfg.TLSConfig = nil
+	_, err = ldap.NewDirectory(cfg).Authenticate(ctx, "alice", "alice-secret")
+	assert.NotNil(t, err)
+}
+
+func TestSearch(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	conn, err := ldap.Dial(context.Background(), server.URL, nil)
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	// Anonymous searches are refused by the server
+	_, err = conn.Search(ldap.SearchRequest{BaseDN: baseDN, Scope: ldap.ScopeWholeSubtree, Filter: "(cn=*)"})
+	assert.True(t, ldap.IsResult(err, ldap.ResultInsufficientAccessRights))
+
+	err = conn.Bind(serviceDN, "service-secret")
+	assert.Nil(t, err)
+
+	entries, err := conn.Search(ldap.SearchRequest{
+		BaseDN:     "ou=groups," + baseDN,
+		Scope:      ldap.ScopeSingleLevel,
+		Filter:     "(|(cn=edit*)(&(cn=*af*)(!(cn=staff))))",
+		Attributes: []string{"cn"},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "editors", entries[0].Value("CN"))
+	assert.Empty(t, entries[0].Values("member"))
+
+	entries, err = conn.Search(ldap.SearchRequest{
+		BaseDN:    baseDN,
+		Scope:     ldap.ScopeWholeSubtree,
+		Filter:    "(objectClass=groupOfNames)",
+		SizeLimit: 1,
+	})
+	assert.True(t, ldap.IsResult(err, ldap.ResultSizeLimitExceeded))
+	assert.Len(t, entries, 1)
+
+	_, err = conn.Search(ldap.SearchRequest{BaseDN: baseDN, Filter: "(cn>=a)"})
+	assert.Equal(t, ldap.ErrInvalidFilter, err)
+	_, err = conn.Search(ldap.SearchRequest{BaseDN: baseDN, Filter: "(cn=a"})
+	assert.Equal(t, ldap.ErrInvalidFilter, err)
+}
+
+func TestEscapeFilter(t *testing.T) {
+	assert.Equal(t, "alice", ldap.EscapeFilter("alice"))
+	assert.Equal(t, `\2a\28uid=\5c\29\00`, ldap.EscapeFilter("*(uid=\\)\x00"))
+}
+
+func TestRDNValue(t *testing.T) {
+	assert.Equal(t, "editors", ldap.RDNValue("cn=editors,ou=groups,dc=example,dc=com"))
+	assert.Equal(t, "Sales, EMEA", ldap.RDNValue(`CN=Sales\, EMEA,OU=Groups,DC=example,DC=com`))
+	assert.Equal(t, "a+b", ldap.RDNValue(`cn=a\2bb`))
+	assert.Equal(t, "", ldap.RDNValue("editors"))
+}
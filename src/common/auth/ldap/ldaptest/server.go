@@ -0,0 +1,462 @@
+// Package ldaptest provides an in-process LDAP directory for tests, the
+// entries are kept in memory and userPassword values are compared in clear
+// text. Searches need a bind and StartTLS uses a self-signed certificate.
+package ldaptest
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MayCMF/core/src/common/auth/ldap"
+	"github.com/MayCMF/core/src/common/auth/ldap/internal/ber"
+)
+
+// Protocol operations
+const (
+	opBindRequest      = ber.ClassApplication | ber.Constructed | 0
+	opBindResponse     = ber.ClassApplication | ber.Constructed | 1
+	opSearchRequest    = ber.ClassApplication | ber.Constructed | 3
+	opSearchEntry      = ber.ClassApplication | ber.Constructed | 4
+	opSearchDone       = ber.ClassApplication | ber.Constructed | 5
+	opExtendedRequest  = ber.ClassApplication | ber.Constructed | 23
+	opExtendedResponse = ber.ClassApplication | ber.Constructed | 24
+)
+
+// Filter choices
+const (
+	filterAnd        = ber.ClassContext | ber.Constructed | 0
+	filterOr         = ber.ClassContext | ber.Constructed | 1
+	filterNot        = ber.ClassContext | ber.Constructed | 2
+	filterEquality   = ber.ClassContext | ber.Constructed | 3
+	filterSubstrings = ber.ClassContext | ber.Constructed | 4
+	filterPresent    = ber.ClassContext | 7
+)
+
+// Result codes the server answers with besides those of the ldap package
+const (
+	resultOperationsError         = 1
+	resultAuthMethodNotSupported  = 7
+	resultConfidentialityRequired = 13
+)
+
+// Directory entry
+type entry struct {
+	dn         string
+	attributes map[string][]string
+}
+
+func (e *entry) values(name string) []string {
+	return e.attributes[strings.ToLower(name)]
+}
+
+// NewServer - Start a directory on a local port
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		URL:      "ldap://" + listener.Addr().String(),
+		listener: listener,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.newCertificate()
+
+	s.wg.Add(1)
+	go s.accept()
+	return s
+}
+
+// Server - Directory for tests
+type Server struct {
+	URL        string // ldap:// URL of the server
+	RequireTLS bool   // Refuse binds before StartTLS
+
+	listener  net.Listener
+	tlsConfig *tls.Config
+	certPEM   []byte
+	wg        sync.WaitGroup
+
+	lock    sync.RWMutex
+	entries []*entry
+	conns   map[net.Conn]struct{}
+	binds   []string
+}
+
+func (s *Server) newCertificate() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ldaptest"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+
+	s.certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	s.tlsConfig = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+}
+
+// CertPEM - PEM encoded certificate of StartTLS
+func (s *Server) CertPEM() []byte {
+	return s.certPEM
+}
+
+// CertPool - Pool trusting the certificate of StartTLS
+func (s *Server) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(s.certPEM)
+	return pool
+}
+
+// Add - Add or replace an entry
+func (s *Server) Add(dn string, attributes map[string][]string) {
+	item := &entry{dn: dn, attributes: make(map[string][]string)}
+	for name, values := range attributes {
+		item.attributes[strings.ToLower(name)] = values
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, e := range s.entries {
+		if strings.EqualFold(e.dn, dn) {
+			s.entries[i] = item
+			return
+		}
+	}
+	s.entries = append(s.entries, item)
+}
+
+// Remove - Remove an entry
+func (s *Server) Remove(dn string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, e := range s.entries {
+		if strings.EqualFold(e.dn, dn) {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Binds - DNs of the successful binds so far
+func (s *Server) Binds() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return append([]string(nil), s.binds...)
+}
+
+// Close - Stop the server and close its connections
+func (s *Server) Close() {
+	_ = s.listener.Close()
+
+	s.lock.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.lock.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.lock.Lock()
+		s.conns[conn] = struct{}{}
+		s.lock.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(conn)
+
+			s.lock.Lock()
+			delete(s.conns, conn)
+			s.lock.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+// State of a connection
+type session struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	tls     bool
+	boundDN string
+}
+
+func (s *session) write(msgID int64, op *ber.Packet) error {
+	msg := ber.NewSequence(ber.TagSequence, ber.NewInteger(ber.TagInteger, msgID), op)
+	_, err := s.conn.Write(msg.Bytes())
+	return err
+}
+
+func (s *session) result(msgID int64, tag byte, code int, message string) error {
+	return s.write(msgID, ber.NewSequence(tag,
+		ber.NewInteger(ber.TagEnumerated, int64(code)),
+		ber.NewString(ber.TagOctetString, ""),
+		ber.NewString(ber.TagOctetString, message),
+	))
+}
+
+func (s *Server) serve(conn net.Conn) {
+	sess := &session{conn: conn, r: bufio.NewReader(conn)}
+	for {
+		msg, err := ber.Read(sess.r)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+
+		msgID, err := msg.Child(0).Int()
+		if err != nil {
+			return
+		}
+
+		op := msg.Child(1)
+		switch op.Tag {
+		case opBindRequest:
+			err = s.bind(sess, msgID, op)
+		case opSearchRequest:
+			err = s.search(sess, msgID, op)
+		case opExtendedRequest:
+			err = s.extended(sess, msgID, op)
+		default:
+			// Unbind and unknown operations end the connection
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) bind(sess *session, msgID int64, op *ber.Packet) error {
+	dn, auth := op.Child(1).String(), op.Child(2)
+	sess.boundDN = ""
+
+	if auth == nil || auth.Tag != ber.ClassContext|0 {
+		return sess.result(msgID, opBindResponse, resultAuthMethodNotSupported, "only simple binds are supported")
+	}
+
+	password := auth.String()
+	if dn == "" && password == "" {
+		return sess.result(msgID, opBindResponse, ldap.ResultSuccess, "")
+	} else if s.RequireTLS && !sess.tls {
+		return sess.result(msgID, opBindResponse, resultConfidentialityRequired, "StartTLS is required")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, e := range s.entries {
+		if !strings.EqualFold(e.dn, dn) {
+			continue
+		}
+		for _, v := range e.values("userPassword") {
+			if password != "" && v == password {
+				sess.boundDN = e.dn
+				s.binds = append(s.binds, e.dn)
+				return sess.result(msgID, opBindResponse, ldap.ResultSuccess, "")
+			}
+		}
+	}
+	return sess.result(msgID, opBindResponse, ldap.ResultInvalidCredentials, "invalid credentials")
+}
+
+func (s *Server) extended(sess *session, msgID int64, op *ber.Packet) error {
+	if op.Child(0).String() != ldap.StartTLSOID {
+		return sess.result(msgID, opExtendedResponse, ldap.ResultProtocolError, "unsupported extended operation")
+	} else if sess.tls {
+		return sess.result(msgID, opExtendedResponse, resultOperationsError, "TLS is already started")
+	}
+
+	err := sess.result(msgID, opExtendedResponse, ldap.ResultSuccess, "")
+	if err != nil {
+		return err
+	}
+
+	conn := tls.Server(sess.conn, s.tlsConfig)
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	sess.conn, sess.r, sess.tls = conn, bufio.NewReader(conn), true
+	return nil
+}
+
+func (s *Server) search(sess *session, msgID int64, op *ber.Packet) error {
+	if sess.boundDN == "" {
+		return sess.result(msgID, opSearchDone, ldap.ResultInsufficientAccessRights, "anonymous searches are refused")
+	}
+
+	base := strings.ToLower(op.Child(0).String())
+	scope, _ := op.Child(1).Int()
+	sizeLimit, _ := op.Child(3).Int()
+	filter := op.Child(6)
+	if filter == nil {
+		return sess.result(msgID, opSearchDone, ldap.ResultProtocolError, "missing filter")
+	}
+
+	var attributes []string
+	if list := op.Child(7); list != nil {
+		for _, item := range list.Children {
+			attributes = append(attributes, strings.ToLower(item.String()))
+		}
+	}
+
+	s.lock.RLock()
+	var found []*entry
+	for _, e := range s.entries {
+		if inScope(strings.ToLower(e.dn), base, scope) && match(filter, e) {
+			found = append(found, e)
+		}
+	}
+	s.lock.RUnlock()
+
+	code := ldap.ResultSuccess
+	if sizeLimit > 0 && int64(len(found)) > sizeLimit {
+		found, code = found[:sizeLimit], ldap.ResultSizeLimitExceeded
+	}
+
+	for _, e := range found {
+		err := sess.write(msgID, searchEntry(e, attributes))
+		if err != nil {
+			return err
+		}
+	}
+	return sess.result(msgID, opSearchDone, code, "")
+}
+
+func inScope(dn, base string, scope int64) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == base
+	case ldap.ScopeSingleLevel:
+		i := strings.IndexByte(dn, ',')
+		return i >= 0 && dn[i+1:] == base
+	default:
+		return base == "" || dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+// Entry with the requested attributes, passwords are never returned
+func searchEntry(e *entry, attributes []string) *ber.Packet {
+	all := len(attributes) == 0
+	wanted := make(map[string]bool)
+	for _, name := range attributes {
+		wanted[name] = true
+	}
+
+	list := ber.NewSequence(ber.TagSequence)
+	for name, values := range e.attributes {
+		if name == "userpassword" || (!all && !wanted[name]) {
+			continue
+		}
+
+		set := ber.NewSequence(ber.TagSet)
+		for _, v := range values {
+			set.Append(ber.NewString(ber.TagOctetString, v))
+		}
+		list.Append(ber.NewSequence(ber.TagSequence, ber.NewString(ber.TagOctetString, name), set))
+	}
+	return ber.NewSequence(opSearchEntry, ber.NewString(ber.TagOctetString, e.dn), list)
+}
+
+// Evaluate a filter, values are compared case-insensitively
+func match(f *ber.Packet, e *entry) bool {
+	switch f.Tag {
+	case filterAnd:
+		for _, c := range f.Children {
+			if !match(c, e) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, c := range f.Children {
+			if match(c, e) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return f.Child(0) != nil && !match(f.Child(0), e)
+	case filterEquality:
+		for _, v := range e.values(f.Child(0).String()) {
+			if strings.EqualFold(v, f.Child(1).String()) {
+				return true
+			}
+		}
+		return false
+	case filterSubstrings:
+		for _, v := range e.values(f.Child(0).String()) {
+			if matchSubstrings(strings.ToLower(v), f.Child(1)) {
+				return true
+			}
+		}
+		return false
+	case filterPresent:
+		return len(e.values(f.String())) > 0
+	}
+	return false
+}
+
+func matchSubstrings(v string, parts *ber.Packet) bool {
+	if parts == nil {
+		return false
+	}
+
+	for _, part := range parts.Children {
+		s := strings.ToLower(part.String())
+		switch part.Tag {
+		case ber.ClassContext | 0:
+			if !strings.HasPrefix(v, s) {
+				return false
+			}
+			v = v[len(s):]
+		case ber.ClassContext | 1:
+			i := strings.Index(v, s)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(s):]
+		case ber.ClassContext | 2:
+			if !strings.HasSuffix(v, s) {
+				return false
+			}
+			v = ""
+		}
+	}
+	return true
+}
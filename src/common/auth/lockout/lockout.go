@@ -0,0 +1,303 @@
+package lockout
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Store - Storage of expiring counters
+type Store interface {
+	// Add delta to the counter of a key and return the new value, a new key
+	// expires after the expiration
+	Incr(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error)
+
+	// Set the counter of a key
+	Set(ctx context.Context, key string, value int64, expiration time.Duration) error
+
+	// Remaining lifetime of a key, 0 if it does not exist
+	TTL(ctx context.Context, key string) (time.Duration, error)
+
+	// Delete keys
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Event kinds
+const (
+	EventDelayed  = "delayed"  // A failed login delays the next attempt
+	EventLocked   = "locked"   // Too many failed logins lock the user name or IP
+	EventRefused  = "refused"  // An attempt is refused during a delay or lockout
+	EventUnlocked = "unlocked" // A lockout is lifted by an administrator
+)
+
+// Event - Lockout event, emitted for auditing
+type Event struct {
+	Kind     string        // Kind of the event
+	UserName string        // User name of the attempt
+	IP       string        // Client IP of the attempt
+	Failures int64         // Failed attempts counted
+	Duration time.Duration // Duration of the delay or lockout
+}
+
+var defaultOptions = options{
+	maxAttempts:   5,
+	maxIPAttempts: 50,
+	window:        15 * time.Minute,
+	lockDuration:  15 * time.Minute,
+	delay:         time.Second,
+	maxDelay:      30 * time.Second,
+}
+
+type options struct {
+	maxAttempts   int64
+	maxIPAttempts int64
+	window        time.Duration
+	lockDuration  time.Duration
+	delay         time.Duration
+	maxDelay      time.Duration
+	onEvent       func(context.Context, Event)
+}
+
+// Option - Defining parameter items
+type Option func(*options)
+
+// SetMaxAttempts - Set the failed logins of a user name before its lockout (default 5)
+func SetMaxAttempts(n int64) Option {
+	return func(o *options) {
+		o.maxAttempts = n
+	}
+}
+
+// SetMaxIPAttempts - Set the failed logins from an IP before its lockout (default 50)
+func SetMaxIPAttempts(n int64) Option {
+	return func(o *options) {
+		o.maxIPAttempts = n
+	}
+}
+
+// SetWindow - Set the period failed logins are counted in (default 15 minutes)
+func SetWindow(window time.Duration) Option {
+	return func(o *options) {
+		o.window = window
+	}
+}
+
+// SetLockDuration - Set the duration of a lockout (default 15 minutes)
+func SetLockDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.lockDuration = d
+	}
+}
+
+// SetDelay - Set the delay after the first failed login of a user name, it
+// doubles with every further failure up to the maximum delay (default 1
+// second and 30 seconds, 0 disables delays)
+func SetDelay(delay, maxDelay time.Duration) Option {
+	return func(o *options) {
+		o.delay = delay
+		o.maxDelay = maxDelay
+	}
+}
+
+// SetEventHandler - Set the handler of the lockout events
+func SetEventHandler(fn func(context.Context, Event)) Option {
+	return func(o *options) {
+		o.onEvent = fn
+	}
+}
+
+// New - Create a guard against brute-force logins, failed logins are
+// counted per user name and per client IP
+func New(store Store, opts ...Option) *Guard {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxDelay < o.delay {
+		o.maxDelay = o.delay
+	}
+	return &Guard{
+		store: store,
+		opts:  o,
+	}
+}
+
+// Guard - Login attempt limits
+type Guard struct {
+	store Store
+	opts  options
+}
+
+func userKey(kind, userName string) string {
+	return kind + ":user:" + userName
+}
+
+func ipKey(kind, ip string) string {
+	return kind + ":ip:" + ip
+}
+
+func (a *Guard) emit(ctx context.Context, e Event) {
+	if a.opts.onEvent != nil {
+		a.opts.onEvent(ctx, e)
+	}
+}
+
+// Begin - Start a login attempt, it returns the time to wait before the next
+// attempt when the user name or IP is delayed or locked. The attempt is
+// counted before the password is checked, so that parallel attempts cannot
+// exceed the limits.
+func (a *Guard) Begin(ctx context.Context, userName, ip string) (time.Duration, error) {
+	keys := []string{
+		userKey("lock", userName),
+		userKey("delay", userName),
+	}
+	if ip != "" {
+		keys = append(keys, ipKey("lock", ip))
+	}
+	for _, key := range keys {
+		ttl, err := a.store.TTL(ctx, key)
+		if err != nil {
+			return 0, err
+		} else if ttl > 0 {
+			a.emit(ctx, Event{Kind: EventRefused, UserName: userName, IP: ip, Duration: ttl})
+			return ttl, nil
+		}
+	}
+
+	n, err := a.store.Incr(ctx, userKey("fail", userName), 1, a.opts.window)
+	if err != nil {
+		return 0, err
+	} else if a.opts.maxAttempts > 0 && n > a.opts.maxAttempts {
+		return a.lock(ctx, userKey, userName, Event{UserName: userName, IP: ip, Failures: n - 1})
+	}
+
+	if ip != "" {
+		n, err = a.store.Incr(ctx, ipKey("fail", ip), 1, a.opts.window)
+		if err != nil {
+			return 0, err
+		} else if a.opts.maxIPAttempts > 0 && n > a.opts.maxIPAttempts {
+			return a.lock(ctx, ipKey, ip, Event{UserName: userName, IP: ip, Failures: n - 1})
+		}
+	}
+	return 0, nil
+}
+
+// Lock a user name or IP and forget its failures
+func (a *Guard) lock(ctx context.Context, key func(string, string) string, value string, e Event) (time.Duration, error) {
+	err := a.store.Set(ctx, key("lock", value), 1, a.opts.lockDuration)
+	if err != nil {
+		return 0, err
+	}
+
+	err = a.store.Delete(ctx, key("fail", value), key("delay", value))
+	if err != nil {
+		return 0, err
+	}
+
+	e.Kind = EventLocked
+	e.Duration = a.opts.lockDuration
+	a.emit(ctx, e)
+	return a.opts.lockDuration, nil
+}
+
+// Fail - Finish a failed login attempt, the next attempt of the user name is
+// delayed progressively
+func (a *Guard) Fail(ctx context.Context, userName, ip string) error {
+	if a.opts.delay <= 0 {
+		return nil
+	}
+
+	// The attempt was counted when it began
+	n, err := a.store.Incr(ctx, userKey("fail", userName), 0, a.opts.window)
+	if err != nil || n <= 0 {
+		return err
+	}
+
+	delay := a.opts.delay
+	for i := int64(1); i < n && delay < a.opts.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > a.opts.maxDelay {
+		delay = a.opts.maxDelay
+	}
+
+	err = a.store.Set(ctx, userKey("delay", userName), 1, delay)
+	if err != nil {
+		return err
+	}
+
+	a.emit(ctx, Event{Kind: EventDelayed, UserName: userName, IP: ip, Failures: n, Duration: delay})
+	return nil
+}
+
+// Succeed - Finish a successful login attempt, the failures of the user name
+// are forgotten and the attempt is not counted for the IP
+func (a *Guard) Succeed(ctx context.Context, userName, ip string) error {
+	err := a.store.Delete(ctx, userKey("fail", userName), userKey("delay", userName))
+	if err != nil {
+		return err
+	}
+
+	if ip != "" {
+		_, err = a.store.Incr(ctx, ipKey("fail", ip), -1, a.opts.window)
+	}
+	return err
+}
+
+// Pass - Finish the first step of a login attempt that needs a second one,
+// such as a two-factor code. The attempt stays counted for the user name
+// until the second step succeeds, but no longer for the IP as the second
+// step is counted for it again.
+func (a *Guard) Pass(ctx context.Context, userName, ip string) error {
+	if ip == "" {
+		return nil
+	}
+
+	_, err := a.store.Incr(ctx, ipKey("fail", ip), -1, a.opts.window)
+	return err
+}
+
+// Locked - Get the remaining lockout of a user name
+func (a *Guard) Locked(ctx context.Context, userName string) (time.Duration, error) {
+	return a.store.TTL(ctx, userKey("lock", userName))
+}
+
+// Unlock - Lift the lockout and forget the failures of a user name
+func (a *Guard) Unlock(ctx context.Context, userName string) error {
+	err := a.store.Delete(ctx, userKey("lock", userName), userKey("fail", userName), userKey("delay", userName))
+	if err != nil {
+		return err
+	}
+
+	a.emit(ctx, Event{Kind: EventUnlocked, UserName: userName})
+	return nil
+}
+
+// UnlockIP - Lift the lockout and forget the failures of a client IP
+func (a *Guard) UnlockIP(ctx context.Context, ip string) error {
+	err := a.store.Delete(ctx, ipKey("lock", ip), ipKey("fail", ip))
+	if err != nil {
+		return err
+	}
+
+	a.emit(ctx, Event{Kind: EventUnlocked, IP: ip})
+	return nil
+}
+
+// String - Describe an event for logs
+func (e Event) String() string {
+	s := e.Kind
+	if e.UserName != "" {
+		s += " user " + e.UserName
+	}
+	if e.IP != "" {
+		s += " ip " + e.IP
+	}
+	if e.Failures > 0 {
+		s += " after " + strconv.FormatInt(e.Failures, 10) + " failures"
+	}
+	if e.Duration > 0 {
+		s += " for " + e.Duration.Round(time.Second).String()
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGuard(opts ...Option) (*Guard, *MemoryStore, *time.Time) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	return New(store, opts...), store, &now
+}
+
+func TestGuardLockout(t *testing.T) {
+	var events []Event
+	guard, _, now := newTestGuard(
+		SetMaxAttempts(3),
+		SetDelay(0, 0),
+		SetEventHandler(func(ctx context.Context, e Event) { events = append(events, e) }),
+	)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		wait, err := guard.Begin(ctx, "tom", "10.0.0.1")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Duration(0), wait)
+		assert.Nil(t, guard.Fail(ctx, "tom", "10.0.0.1"))
+	}
+
+	// The attempt after the limit locks the user name
+	wait, err := guard.Begin(ctx, "tom", "10.0.0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, 15*time.Minute, wait)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, EventLocked, events[0].Kind)
+		assert.Equal(t, int64(3), events[0].Failures)
+	}
+
+	wait, err = guard.Begin(ctx, "tom", "10.0.0.2")
+	assert.Nil(t, err)
+	assert.True(t, wait > 0)
+	assert.Equal(t, EventRefused, events[len(events)-1].Kind)
+
+	// Other user names are not locked
+	wait, err = guard.Begin(ctx, "jerry", "10.0.0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), wait)
+
+	// The lockout expires
+	*now = now.Add(16 * time.Minute)
+	wait, err = guard.Begin(ctx, "tom", "10.0.0.1")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), wait)
+}
+
+func TestGuardUnlock(t *testing.T) {
+	guard, _, _ := newTestGuard(SetMaxAttempts(1), SetDelay(0, 0))
+	ctx := context.Background()
+
+	_, _ = guard.Begin(ctx, "tom", "")
+	_ = guard.Fail(ctx, "tom", "")
+	wait, _ := guard.Begin(ctx, "tom", "")
+	assert.True(t, wait > 0)
+
+	locked, err := guard.Locked(ctx, "tom")
+	assert.Nil(t, err)
+	assert.True(t, locked > 0)
+
+	assert.Nil(t, guard.Unlock(ctx, "tom"))
+	wait, err = guard.Begin(ctx, "tom", "")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), wait)
+}
+
+func TestGuardIP(t *testing.T) {
+	guard, _, _ := newTestGuard(SetMaxIPAttempts(2), SetDelay(0, 0))
+	ctx := context.Background()
+
+	// Successful logins are not counted for the IP
+	for i := 0; i < 3; i++ {
+		wait, _ := guard.Begin(ctx, "tom", "10.0.0.1")
+		assert.Equal(t, time.Duration(0), wait)
+		assert.Nil(t, guard.Succeed(ctx, "tom", "10.0.0.1"))
+	}
+
+	// Neither are logins of two steps
+	for i := 0; i < 3; i++ {
+		wait, _ := guard.Begin(ctx, "tom", "10.0.0.1")
+		assert.Equal(t, time.Duration(0), wait)
+		assert.Nil(t, guard.Pass(ctx, "tom", "10.0.0.1"))
+		wait, _ = guard.Begin(ctx, "tom", "10.0.0.1")
+		assert.Equal(t, time.Duration(0), wait)
+		assert.Nil(t, guard.Succeed(ctx, "tom", "10.0.0.1"))
+	}
+
+	for _, name := range []string{"a", "b"} {
+		wait, _ := guard.Begin(ctx, name, "10.0.0.1")
+		assert.Equal(t, time.Duration(0), wait)
+		assert.Nil(t, guard.Fail(ctx, name, "10.0.0.1"))
+	}
+
+	// Failures of many user names lock the IP
+	wait, _ := guard.Begin(ctx, "c", "10.0.0.1")
+	assert.Equal(t, 15*time.Minute, wait)
+	wait, _ = guard.Begin(ctx, "tom", "10.0.0.1")
+	assert.True(t, wait > 0)
+	wait, _ = guard.Begin(ctx, "tom", "10.0.0.2")
+	assert.Equal(t, time.Duration(0), wait)
+
+	assert.Nil(t, guard.UnlockIP(ctx, "10.0.0.1"))
+	wait, _ = guard.Begin(ctx, "d", "10.0.0.1")
+	assert.Equal(t, time.Duration(0), wait)
+}
+
+func TestGuardDelay(t *testing.T) {
+	guard, _, now := newTestGuard(SetMaxAttempts(10), SetDelay(time.Second, 3*time.Second))
+	ctx := context.Background()
+
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		wait, err := guard.Begin(ctx, "tom", "")
+		assert.Nil(t, err)
+		assert.Equal(t, time.Duration(0), wait)
+		assert.Nil(t, guard.Fail(ctx, "tom", ""))
+
+		// Attempts during the delay are refused
+		wait, _ = guard.Begin(ctx, "tom", "")
+		assert.Equal(t, delay, wait)
+		*now = now.Add(delay)
+	}
+
+	// A successful login forgets the failures
+	_, _ = guard.Begin(ctx, "tom", "")
+	assert.Nil(t, guard.Succeed(ctx, "tom", ""))
+	_, _ = guard.Begin(ctx, "tom", "")
+	assert.Nil(t, guard.Fail(ctx, "tom", ""))
+	wait, _ := guard.Begin(ctx, "tom", "")
+	assert.Equal(t, time.Second, wait)
+}
@@ -0,0 +1,101 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Expired keys are removed at most once per sweep interval
+const sweepInterval = time.Minute
+
+type memoryItem struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewMemoryStore - Create a store keeping the counters in memory, the
+// counters are not shared between processes
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]*memoryItem),
+		now:   time.Now,
+	}
+}
+
+// MemoryStore - In-memory storage
+type MemoryStore struct {
+	lock      sync.Mutex
+	items     map[string]*memoryItem
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// Get an item that has not expired, expired items are removed from time to time
+func (a *MemoryStore) get(key string, now time.Time) *memoryItem {
+	if now.Sub(a.lastSweep) >= sweepInterval {
+		for k, item := range a.items {
+			if !now.Before(item.expiresAt) {
+				delete(a.items, k)
+			}
+		}
+		a.lastSweep = now
+	}
+
+	item, ok := a.items[key]
+	if !ok || !now.Before(item.expiresAt) {
+		return nil
+	}
+	return item
+}
+
+// Incr - Add delta to the counter of a key
+func (a *MemoryStore) Incr(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	now := a.now()
+	item := a.get(key, now)
+	if item == nil {
+		item = &memoryItem{expiresAt: now.Add(expiration)}
+		a.items[key] = item
+	}
+	item.value += delta
+	return item.value, nil
+}
+
+// Set - Set the counter of a key
+func (a *MemoryStore) Set(ctx context.Context, key string, value int64, expiration time.Duration) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.items[key] = &memoryItem{
+		value:     value,
+		expiresAt: a.now().Add(expiration),
+	}
+	return nil
+}
+
+// TTL - Remaining lifetime of a key
+func (a *MemoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	now := a.now()
+	item := a.get(key, now)
+	if item == nil {
+		return 0, nil
+	}
+	return item.expiresAt.Sub(now), nil
+}
+
+// Delete - Delete keys
+func (a *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for _, key := range keys {
+		delete(a.items, key)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// RedisConfig - Redis configuration parameter
+type RedisConfig struct {
+	Addr      string // Address (IP: Port)
+	DB        int    // Database
+	Password  string // Password
+	KeyPrefix string // Store the prefix of the key
+}
+
+// NewRedisStore - Create a redis-based storage, the counters are shared
+// between the processes using the database
+func NewRedisStore(cfg *RedisConfig) *RedisStore {
+	cli := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		DB:       cfg.DB,
+		Password: cfg.Password,
+	})
+	return &RedisStore{
+		cli:    cli,
+		prefix: cfg.KeyPrefix,
+	}
+}
+
+// RedisStore - Redis storage
+type RedisStore struct {
+	cli    *redis.Client
+	prefix string
+}
+
+func (s *RedisStore) wrapperKey(key string) string {
+	return fmt.Sprintf("%s%s", s.prefix, key)
+}
+
+// Incr - Add delta to the counter of a key
+func (s *RedisStore) Incr(ctx context.Context, key string, delta int64, expiration time.Duration) (int64, error) {
+	key = s.wrapperKey(key)
+	n, err := s.cli.IncrBy(key, delta).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// A key without expiration was created by the increase
+	ttl, err := s.cli.PTTL(key).Result()
+	if err != nil {
+		return 0, err
+	} else if ttl == -1 {
+		err = s.cli.PExpire(key, expiration).Err()
+	}
+	return n, err
+}
+
+// Set - Set the counter of a key
+func (s *RedisStore) Set(ctx context.Context, key string, value int64, expiration time.Duration) error {
+	return s.cli.Set(s.wrapperKey(key), value, expiration).Err()
+}
+
+// TTL - Remaining lifetime of a key
+func (s *RedisStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.cli.PTTL(s.wrapperKey(key)).Result()
+	if err != nil {
+		return 0, err
+	} else if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Delete - Delete keys
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	wkeys := make([]string, len(keys))
+	for i, key := range keys {
+		wkeys[i] = s.wrapperKey(key)
+	}
+	return s.cli.Del(wkeys...).Err()
+}
+
+// Close - Close the connection
+func (s *RedisStore) Close() error {
+	return s.cli.Close()
+}
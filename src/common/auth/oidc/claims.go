@@ -0,0 +1,69 @@
+package oidc
+
+import (
+	"strings"
+	"time"
+)
+
+// Claims - Claims of a verified ID token
+type Claims map[string]interface{}
+
+// Subject - Identifier of the user at the identity provider
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+// String - Value of a string claim
+func (c Claims) String(name string) string {
+	v, _ := c[name].(string)
+	return v
+}
+
+// Bool - Value of a boolean claim, some providers send "true" as string
+func (c Claims) Bool(name string) bool {
+	switch v := c[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// Strings - Values of a claim holding a list (or a single string)
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func (c Claims) hasAudience(aud string) bool {
+	for _, v := range c.Strings("aud") {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Claims) time(name string) (time.Time, bool) {
+	switch v := c[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,225 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/oauth2"
+)
+
+// Definition error
+var (
+	ErrInvalidIssuer  = errors.New("issuer does not match the discovery document")
+	ErrInvalidIDToken = errors.New("invalid id token")
+	ErrNoIDToken      = errors.New("no id token in the token response")
+)
+
+// Time tolerated between the clocks of the identity provider and ours
+const clockSkew = time.Minute
+
+// Interval between two key set downloads for unknown keys
+const keysRefreshInterval = 10 * time.Second
+
+// Config - Registration of a client at an identity provider
+type Config struct {
+	Issuer       string       // Issuer URL, the discovery document is below it
+	ClientID     string       // Client ID
+	ClientSecret string       // Client secret
+	RedirectURL  string       // Callback URL registered at the identity provider
+	Scopes       []string     // Requested scopes ("openid" is always requested)
+	HTTPClient   *http.Client // Client of the requests to the identity provider
+}
+
+// Endpoints of the discovery document
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewProvider - Create the client of an identity provider from its discovery document
+func NewProvider(ctx context.Context, cfg Config) (*Provider, error) {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	var doc discovery
+	err := getJSON(ctx, cfg.HTTPClient, strings.TrimSuffix(cfg.Issuer, "/")+"/.well-known/openid-configuration", &doc)
+	if err != nil {
+		return nil, err
+	} else if doc.Issuer != cfg.Issuer {
+		return nil, ErrInvalidIssuer
+	}
+
+	scopes := []string{"openid"}
+	for _, s := range cfg.Scopes {
+		if s != "openid" {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return &Provider{
+		cfg:     cfg,
+		jwksURI: doc.JWKSURI,
+		oauth2: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			},
+		},
+	}, nil
+}
+
+// Provider - Client of an identity provider (authorization code flow with PKCE)
+type Provider struct {
+	cfg     Config
+	jwksURI string
+	oauth2  oauth2.Config
+
+	lock      sync.RWMutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// NewRandom - Generate a random value for the state and the nonce of a login
+func NewRandom() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// NewVerifier - Generate a PKCE code verifier
+func NewVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// AuthCodeURL - URL of the identity provider starting a login
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.oauth2.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+}
+
+// Exchange - Exchange an authorization code for the raw ID token
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (string, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.cfg.HTTPClient)
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return "", err
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return "", ErrNoIDToken
+	}
+	return rawIDToken, nil
+}
+
+// Verify - Check the signature, issuer, audience, lifetime and nonce of an
+// ID token and return its claims
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (Claims, error) {
+	parser := &jwt.Parser{
+		ValidMethods:         []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"},
+		SkipClaimsValidation: true,
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	c := Claims(claims)
+	now := time.Now()
+	if c.String("iss") != p.cfg.Issuer || c.Subject() == "" {
+		return nil, ErrInvalidIDToken
+	} else if !c.hasAudience(p.cfg.ClientID) {
+		return nil, ErrInvalidIDToken
+	} else if azp := c.String("azp"); azp != "" && azp != p.cfg.ClientID {
+		return nil, ErrInvalidIDToken
+	} else if exp, ok := c.time("exp"); !ok || now.After(exp.Add(clockSkew)) {
+		return nil, ErrInvalidIDToken
+	} else if iat, ok := c.time("iat"); ok && now.Add(clockSkew).Before(iat) {
+		return nil, ErrInvalidIDToken
+	} else if c.String("nonce") != nonce {
+		return nil, ErrInvalidIDToken
+	}
+	return c, nil
+}
+
+// Get a verification key, the key set is downloaded again for an unknown key
+// (key rotation) at most once per interval
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.lock.RLock()
+	key, ok := p.lookup(kid)
+	fetchedAt := p.fetchedAt
+	p.lock.RUnlock()
+	if ok {
+		return key, nil
+	} else if time.Since(fetchedAt) < keysRefreshInterval {
+		return nil, ErrInvalidIDToken
+	}
+
+	keys, err := fetchKeys(ctx, p.cfg.HTTPClient, p.jwksURI)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.keys = keys
+	p.fetchedAt = time.Now()
+	if key, ok := p.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrInvalidIDToken
+}
+
+// A token without key ID can only be verified by a single key
+func (p *Provider) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: unexpected status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
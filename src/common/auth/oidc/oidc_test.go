@@ -0,0 +1,144 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/MayCMF/core/src/common/auth/oidc"
+	"github.com/MayCMF/core/src/common/auth/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost/callback"
+
+// Follow the authorization request up to the redirect with the code
+func authorize(t *testing.T, authURL string) url.Values {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(authURL)
+	if !assert.Nil(t, err) {
+		return nil
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	assert.Nil(t, err)
+	return location.Query()
+}
+
+func TestProvider(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+	server.SetClaims(map[string]interface{}{
+		"sub":    "alice",
+		"email":  "alice@example.com",
+		"groups": []string{"editors", "staff"},
+	})
+
+	ctx := context.Background()
+	p, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       server.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"email"},
+	})
+	assert.Nil(t, err)
+
+	state, nonce, verifier := oidc.NewRandom(), oidc.NewRandom(), oidc.NewVerifier()
+	query := authorize(t, p.AuthCodeURL(state, nonce, verifier))
+	assert.Equal(t, state, query.Get("state"))
+
+	// The code is bound to the verifier
+	_, err = p.Exchange(ctx, query.Get("code"), oidc.NewVerifier())
+	assert.NotNil(t, err)
+
+	query = authorize(t, p.AuthCodeURL(state, nonce, verifier))
+	rawIDToken, err := p.Exchange(ctx, query.Get("code"), verifier)
+	assert.Nil(t, err)
+
+	_, err = p.Verify(ctx, rawIDToken, "other")
+	assert.Equal(t, oidc.ErrInvalidIDToken, err)
+
+	claims, err := p.Verify(ctx, rawIDToken, nonce)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", claims.Subject())
+	assert.Equal(t, "alice@example.com", claims.String("email"))
+	assert.Equal(t, []string{"editors", "staff"}, claims.Strings("groups"))
+
+	// A code is only exchanged once
+	_, err = p.Exchange(ctx, query.Get("code"), verifier)
+	assert.NotNil(t, err)
+}
+
+func TestProviderVerify(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+
+	ctx := context.Background()
+	p, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:      server.Issuer(),
+		ClientID:    "client",
+		RedirectURL: redirectURL,
+	})
+	assert.Nil(t, err)
+
+	now := time.Now()
+	newClaims := func(extra map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{
+			"iss":   server.Issuer(),
+			"aud":   "client",
+			"sub":   "alice",
+			"nonce": "nonce",
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		}
+		for k, v := range extra {
+			claims[k] = v
+		}
+		return claims
+	}
+
+	_, err = p.Verify(ctx, server.SignIDToken(newClaims(nil)), "nonce")
+	assert.Nil(t, err)
+
+	_, err = p.Verify(ctx, server.SignIDToken(newClaims(map[string]interface{}{
+		"aud": []string{"client", "other"},
+	})), "nonce")
+	assert.Nil(t, err)
+
+	for _, extra := range []map[string]interface{}{
+		{"iss": "http://other"},
+		{"aud": "other"},
+		{"azp": "other"},
+		{"sub": ""},
+		{"exp": now.Add(-time.Hour).Unix()},
+		{"iat": now.Add(time.Hour).Unix()},
+	} {
+		_, err = p.Verify(ctx, server.SignIDToken(newClaims(extra)), "nonce")
+		assert.Equal(t, oidc.ErrInvalidIDToken, err)
+	}
+
+	// Tokens signed by another key are refused
+	other := oidctest.NewServer("client", "secret")
+	defer other.Close()
+	_, err = p.Verify(ctx, other.SignIDToken(newClaims(nil)), "nonce")
+	assert.Equal(t, oidc.ErrInvalidIDToken, err)
+}
+
+func TestNewProvider(t *testing.T) {
+	server := oidctest.NewServer("client", "secret")
+	defer server.Close()
+
+	_, err := oidc.NewProvider(context.Background(), oidc.Config{
+		Issuer: server.Issuer() + "/",
+	})
+	assert.Equal(t, oidc.ErrInvalidIssuer, err)
+}
@@ -0,0 +1,190 @@
+// Package oidctest provides a local OpenID Connect identity provider for tests,
+// every authorization request is granted at once for the configured claims.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/MayCMF/core/src/common/auth/oidc"
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// Key ID of the signing key
+const keyID = "oidctest"
+
+// Pending authorization code
+type authorization struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+	claims      map[string]interface{}
+}
+
+// NewServer - Start an identity provider for a client
+func NewServer(clientID, clientSecret string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        make(map[string]*authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Server - Identity provider for tests
+type Server struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	key    *rsa.PrivateKey
+	lock   sync.Mutex
+	claims map[string]interface{}
+	codes  map[string]*authorization
+}
+
+// Issuer - Issuer URL of the identity provider
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// SetClaims - Set the claims of the user granted by the next authorizations
+func (s *Server) SetClaims(claims map[string]interface{}) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.claims = claims
+}
+
+// SignIDToken - Sign an ID token with the key of the identity provider
+func (s *Server) SignIDToken(claims map[string]interface{}) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims(claims))
+	token.Header["kid"] = keyID
+	raw, err := token.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.Issuer(),
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != s.ClientID ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "invalid redirect uri", http.StatusBadRequest)
+		return
+	}
+
+	code := oidc.NewRandom()
+	s.lock.Lock()
+	s.codes[code] = &authorization{
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		claims:      s.claims,
+	}
+	s.lock.Unlock()
+
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", q.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != s.ClientID || clientSecret != s.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostFormValue("code")
+	s.lock.Lock()
+	item, ok := s.codes[code]
+	delete(s.codes, code)
+	s.lock.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" ||
+		r.PostFormValue("redirect_uri") != item.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != item.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss": s.Issuer(),
+		"aud": s.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	if item.nonce != "" {
+		claims["nonce"] = item.nonce
+	}
+	for k, v := range item.claims {
+		claims[k] = v
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": oidc.NewRandom(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     s.SignIDToken(claims),
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.JSONWebKeySet{
+		Keys: []oidc.JSONWebKey{oidc.NewRSAKey(keyID, &s.key.PublicKey)},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,127 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idID = "argon2id"
+
+// Argon2id defaults (RFC 9106 second recommended option)
+const (
+	DefaultArgon2Memory      = 64 * 1024
+	DefaultArgon2Iterations  = 3
+	DefaultArgon2Parallelism = 4
+)
+
+// NewArgon2id - Create an argon2id hasher with the default parameters
+func NewArgon2id() *Argon2id {
+	return &Argon2id{
+		Memory:      DefaultArgon2Memory,
+		Iterations:  DefaultArgon2Iterations,
+		Parallelism: DefaultArgon2Parallelism,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Argon2id - argon2id hashes in PHC format:
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+type Argon2id struct {
+	Memory      uint32 // Memory in KiB
+	Iterations  uint32 // Number of passes
+	Parallelism uint8  // Number of lanes
+	SaltLength  uint32 // Salt length in bytes
+	KeyLength   uint32 // Hash length in bytes
+}
+
+type argon2Hash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// ID - Algorithm identifier
+func (a *Argon2id) ID() string {
+	return argon2idID
+}
+
+// Hash - Hash a password with a random salt
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, a.KeyLength)
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2idID, argon2.Version,
+		a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Match - Check for an argon2id PHC string
+func (a *Argon2id) Match(encoded string) bool {
+	return strings.HasPrefix(encoded, "$"+argon2idID+"$")
+}
+
+// Verify - Hash the password with the salt and parameters of the encoded hash
+func (a *Argon2id) Verify(encoded, password string) (bool, error) {
+	h, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	key := argon2.IDKey([]byte(password), h.salt, h.iterations, h.memory, h.parallelism, uint32(len(h.key)))
+	return subtle.ConstantTimeCompare(key, h.key) == 1, nil
+}
+
+// NeedsRehash - Check whether the hash parameters differ from the current ones
+func (a *Argon2id) NeedsRehash(encoded string) bool {
+	h, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return h.memory != a.Memory ||
+		h.iterations != a.Iterations ||
+		h.parallelism != a.Parallelism ||
+		uint32(len(h.salt)) != a.SaltLength ||
+		uint32(len(h.key)) != a.KeyLength
+}
+
+func decodeArgon2id(encoded string) (*argon2Hash, error) {
+	fields := splitPHC(encoded)
+	if len(fields) != 5 || fields[0] != argon2idID {
+		return nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(fields[1], "v=%d", &version); err != nil {
+		return nil, ErrInvalidHash
+	} else if version != argon2.Version {
+		return nil, ErrInvalidHash
+	}
+
+	h := new(argon2Hash)
+	_, err := fmt.Sscanf(fields[2], "m=%d,t=%d,p=%d", &h.memory, &h.iterations, &h.parallelism)
+	if err != nil || h.iterations == 0 || h.parallelism == 0 {
+		return nil, ErrInvalidHash
+	}
+
+	h.salt, err = base64.RawStdEncoding.DecodeString(fields[3])
+	if err != nil {
+		return nil, ErrInvalidHash
+	}
+	h.key, err = base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil || len(h.key) == 0 {
+		return nil, ErrInvalidHash
+	}
+	return h, nil
+}
@@ -0,0 +1,64 @@
+package password
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const bcryptID = "bcrypt"
+
+// NewBcrypt - Create a bcrypt hasher, a cost out of range uses the default cost
+func NewBcrypt(cost int) *Bcrypt {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &Bcrypt{Cost: cost}
+}
+
+// Bcrypt - bcrypt hashes in modular crypt format: $2a$10$<salt+hash>
+type Bcrypt struct {
+	Cost int
+}
+
+// ID - Algorithm identifier
+func (a *Bcrypt) ID() string {
+	return bcryptID
+}
+
+// Hash - Hash a password with a random salt, bcrypt only uses the first 72
+// bytes of a password
+func (a *Bcrypt) Hash(password string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), a.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Match - Check for a bcrypt hash prefix
+func (a *Bcrypt) Match(encoded string) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(encoded, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify - Compare a password with a bcrypt hash
+func (a *Bcrypt) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	} else if err != nil {
+		return false, ErrInvalidHash
+	}
+	return true, nil
+}
+
+// NeedsRehash - Check whether the hash cost differs from the current one
+func (a *Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != a.Cost
+}
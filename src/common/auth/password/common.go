@@ -0,0 +1,32 @@
+package password
+
+// Most common passwords by frequency, they are always banned
+var commonPasswords = []string{
+	"123456", "password", "123456789", "12345678", "12345", "qwerty", "1234567",
+	"111111", "1234567890", "123123", "abc123", "1234", "password1", "iloveyou",
+	"1q2w3e4r", "000000", "qwerty123", "zaq12wsx", "dragon", "sunshine",
+	"princess", "letmein", "654321", "monkey", "1qaz2wsx", "123321",
+	"qwertyuiop", "superman", "asdfghjkl", "football", "baseball", "welcome",
+	"admin", "login", "master", "hello", "freedom", "whatever", "qazwsx",
+	"trustno1", "shadow", "michael", "jennifer", "ashley", "bailey", "passw0rd",
+	"mustang", "access", "flower", "555555", "lovely", "7777777", "888888",
+	"666666", "121212", "starwars", "charlie", "donald", "batman", "jordan",
+	"hunter", "pokemon", "computer", "secret", "summer", "winter", "hockey",
+	"killer", "soccer", "ranger", "buster", "thomas", "robert", "daniel",
+	"hannah", "harley", "tigger", "cheese", "pepper", "ginger", "joshua",
+	"maggie", "matthew", "andrew", "george", "nicole", "jessica", "chelsea",
+	"biteme", "matrix", "yankees", "696969", "q1w2e3r4", "123qwe", "1q2w3e",
+	"zxcvbnm", "asdf", "qwer", "test", "test123", "changeme", "default", "root",
+	"toor", "administrator", "passwd", "pass", "guest", "love", "iloveu",
+	"loveme", "money", "friends", "samsung", "internet", "google", "apple",
+	"orange", "banana", "chocolate", "cookie", "butterfly", "purple", "diamond",
+	"silver", "golden", "angel", "angels", "family", "forever", "blink182",
+	"liverpool", "arsenal", "chelsea1", "barcelona", "madrid", "england",
+	"london", "paris", "qwe123", "asd123", "zxc123", "abc1234", "abcd1234",
+	"aa123456", "a123456", "123456a", "password123", "admin123", "root123",
+	"welcome1", "letmein1", "monkey1", "dragon1", "qwerty1", "1234qwer",
+	"12qwaszx", "11111111", "00000000", "12341234", "987654321", "147258369",
+	"159753", "112233", "123654", "999999", "azerty", "qwertz", "abcdef",
+	"abcdefg", "abcdefgh", "1111", "0000", "2000", "2020", "2021", "2022",
+	"2023", "2024",
+}
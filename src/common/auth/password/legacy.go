@@ -0,0 +1,41 @@
+package password
+
+import (
+	"crypto/subtle"
+
+	"github.com/MayCMF/core/src/common/util"
+)
+
+const legacyID = "sha1"
+
+// legacy - Unsalted hex SHA-1 hashes of the first releases, they can only be
+// verified so that they are replaced on the next login
+type legacy struct{}
+
+func (legacy) ID() string {
+	return legacyID
+}
+
+func (legacy) Hash(password string) (string, error) {
+	return "", ErrLegacyHash
+}
+
+func (legacy) Match(encoded string) bool {
+	if len(encoded) != 40 {
+		return false
+	}
+	for _, c := range encoded {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (legacy) Verify(encoded, password string) (bool, error) {
+	return subtle.ConstantTimeCompare([]byte(encoded), []byte(util.SHA1HashString(password))) == 1, nil
+}
+
+func (legacy) NeedsRehash(encoded string) bool {
+	return true
+}
@@ -0,0 +1,85 @@
+package password
+
+import (
+	"errors"
+	"strings"
+)
+
+// Definition error
+var (
+	ErrInvalidHash   = errors.New("invalid password hash")
+	ErrUnknownHash   = errors.New("unknown password hash algorithm")
+	ErrLegacyHash    = errors.New("legacy password hash")
+	ErrEmptyPassword = errors.New("empty password")
+)
+
+// Hasher - Password hashing algorithm interface
+type Hasher interface {
+	// Algorithm identifier of the hashes
+	ID() string
+
+	// Hash a password into an encoded string holding the salt and parameters
+	Hash(password string) (string, error)
+
+	// Check whether an encoded hash was made by this algorithm
+	Match(encoded string) bool
+
+	// Compare a password with an encoded hash
+	Verify(encoded, password string) (bool, error)
+
+	// Check whether an encoded hash uses other parameters than the current ones
+	NeedsRehash(encoded string) bool
+}
+
+// New - Create a password manager, new passwords are hashed by h and the
+// hashes of every known algorithm can be verified
+func New(h Hasher) *Password {
+	return &Password{
+		hasher:  h,
+		hashers: []Hasher{h, NewArgon2id(), NewBcrypt(0), legacy{}},
+	}
+}
+
+// Password - Hashes and verifies passwords
+type Password struct {
+	hasher  Hasher
+	hashers []Hasher
+}
+
+// Hash - Hash a new password with the current algorithm
+func (a *Password) Hash(password string) (string, error) {
+	if password == "" {
+		return "", ErrEmptyPassword
+	}
+	return a.hasher.Hash(password)
+}
+
+// Verify - Compare a password with an encoded hash of any known algorithm,
+// rehash reports that the hash should be replaced by a new one
+func (a *Password) Verify(encoded, password string) (ok bool, rehash bool, err error) {
+	for _, h := range a.hashers {
+		if !h.Match(encoded) {
+			continue
+		}
+
+		ok, err = h.Verify(encoded, password)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, h.ID() != a.hasher.ID() || h.NeedsRehash(encoded), nil
+	}
+	return false, false, ErrUnknownHash
+}
+
+// IsLegacy - Check whether an encoded hash is an unsalted SHA-1 hash
+func IsLegacy(encoded string) bool {
+	return legacy{}.Match(encoded)
+}
+
+// Split a PHC string ($id$v=19$params$salt$hash) into its fields
+func splitPHC(encoded string) []string {
+	if !strings.HasPrefix(encoded, "$") {
+		return nil
+	}
+	return strings.Split(encoded[1:], "$")
+}
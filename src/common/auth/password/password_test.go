@@ -0,0 +1,92 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestArgon2id() *Argon2id {
+	h := NewArgon2id()
+	h.Memory = 1024
+	h.Iterations = 1
+	return h
+}
+
+func TestArgon2id(t *testing.T) {
+	p := New(newTestArgon2id())
+
+	encoded, err := p.Hash("abc-123")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=4$"))
+
+	other, err := p.Hash("abc-123")
+	assert.Nil(t, err)
+	assert.NotEqual(t, encoded, other)
+
+	ok, rehash, err := p.Verify(encoded, "abc-123")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, _, err = p.Verify(encoded, "abc-124")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// Stronger parameters upgrade the hash
+	h := newTestArgon2id()
+	h.Iterations = 2
+	ok, rehash, err = New(h).Verify(encoded, "abc-123")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+
+	_, _, err = p.Verify("$argon2id$v=19$m=1024,t=1,p=4$!!$!!", "abc-123")
+	assert.Equal(t, ErrInvalidHash, err)
+}
+
+func TestBcrypt(t *testing.T) {
+	p := New(NewBcrypt(4))
+
+	encoded, err := p.Hash("abc-123")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$2a$04$"))
+
+	ok, rehash, err := p.Verify(encoded, "abc-123")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, _, err = p.Verify(encoded, "abc-124")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// A bcrypt hash is still valid after switching to argon2id
+	ok, rehash, err = New(newTestArgon2id()).Verify(encoded, "abc-123")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+}
+
+func TestLegacy(t *testing.T) {
+	p := New(newTestArgon2id())
+	encoded := util.SHA1HashString("abc-123")
+	assert.True(t, IsLegacy(encoded))
+
+	ok, rehash, err := p.Verify(encoded, "abc-123")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+
+	ok, _, err = p.Verify(encoded, "abc-124")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	_, _, err = p.Verify("plain", "plain")
+	assert.Equal(t, ErrUnknownHash, err)
+
+	_, err = p.Hash("")
+	assert.Equal(t, ErrEmptyPassword, err)
+}
@@ -0,0 +1,167 @@
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Violation codes
+const (
+	ViolationMinLength = "min_length"        // Too short
+	ViolationClasses   = "character_classes" // Too few character classes
+	ViolationBanned    = "banned"            // Common or banned password
+	ViolationUserInput = "user_input"        // Contains the user name, real name or email address
+	ViolationStrength  = "strength"          // Too easy to guess
+	ViolationReused    = "reused"            // One of the last passwords of the user
+)
+
+// Violation - Rule of a password policy a password breaks
+type Violation struct {
+	Code    string // Violation code
+	Message string // Description
+}
+
+// NewPolicy - Create a password policy, the common passwords are banned
+func NewPolicy() *Policy {
+	a := &Policy{
+		dictionary: make(map[string]int),
+	}
+	a.Ban(commonPasswords...)
+	return a
+}
+
+// Policy - Rules of new passwords
+type Policy struct {
+	MinLength  int // Minimum number of characters
+	MinClasses int // Minimum number of character classes (lowercase, uppercase, digits, symbols)
+	MinScore   int // Minimum strength score (0-4)
+
+	// Banned passwords with their rank, they are the dictionary of the
+	// strength estimation
+	dictionary map[string]int
+}
+
+// Ban - Ban passwords, they rank after the passwords banned before
+func (a *Policy) Ban(words ...string) {
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		} else if _, ok := a.dictionary[word]; !ok {
+			a.dictionary[word] = len(a.dictionary) + 1
+		}
+	}
+}
+
+// LoadBanned - Ban the passwords of a file, one per line, lines starting
+// with # are ignored
+func (a *Policy) LoadBanned(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	a.Ban(words...)
+	return nil
+}
+
+// Check - Check a new password, user inputs are the user name, real name and
+// email address of its user that must not be part of the password
+func (a *Policy) Check(password string, userInputs ...string) []Violation {
+	var violations []Violation
+
+	if n := len([]rune(password)); n < a.MinLength {
+		violations = append(violations, Violation{
+			Code:    ViolationMinLength,
+			Message: fmt.Sprintf("Password must be at least %d characters long", a.MinLength),
+		})
+	}
+
+	if classes(password) < a.MinClasses {
+		violations = append(violations, Violation{
+			Code:    ViolationClasses,
+			Message: fmt.Sprintf("Password must contain %d of lowercase letters, uppercase letters, digits and symbols", a.MinClasses),
+		})
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := a.dictionary[lower]; ok {
+		violations = append(violations, Violation{
+			Code:    ViolationBanned,
+			Message: "Password is too common",
+		})
+	} else if _, ok := a.dictionary[unleet(lower)]; ok {
+		violations = append(violations, Violation{
+			Code:    ViolationBanned,
+			Message: "Password is too common",
+		})
+	}
+
+	for _, input := range splitUserInputs(userInputs) {
+		if strings.Contains(lower, input) {
+			violations = append(violations, Violation{
+				Code:    ViolationUserInput,
+				Message: "Password must not contain the user name, real name or email address",
+			})
+			break
+		}
+	}
+
+	if a.Strength(password, userInputs...) < a.MinScore {
+		violations = append(violations, Violation{
+			Code:    ViolationStrength,
+			Message: "Password is too easy to guess",
+		})
+	}
+	return violations
+}
+
+// Count the character classes of a password
+func classes(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// Lowercase user inputs with the local part of email addresses, short ones
+// are skipped
+func splitUserInputs(userInputs []string) []string {
+	var inputs []string
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if i := strings.LastIndex(input, "@"); i > 0 {
+			inputs = append(inputs, input)
+			input = input[:i]
+		}
+		if len([]rune(input)) >= 3 {
+			inputs = append(inputs, input)
+		}
+	}
+	return inputs
+}
@@ -0,0 +1,76 @@
+package password
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func violationCodes(violations []Violation) []string {
+	codes := make([]string, len(violations))
+	for i, v := range violations {
+		codes[i] = v.Code
+	}
+	return codes
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := NewPolicy()
+	policy.MinLength = 10
+	policy.MinClasses = 3
+	policy.MinScore = 3
+
+	assert.Empty(t, policy.Check("correct-Horse-7battery"))
+
+	codes := violationCodes(policy.Check("abc"))
+	assert.Contains(t, codes, ViolationMinLength)
+	assert.Contains(t, codes, ViolationClasses)
+	assert.Contains(t, codes, ViolationStrength)
+
+	codes = violationCodes(policy.Check("P@ssw0rd"))
+	assert.Contains(t, codes, ViolationBanned)
+
+	codes = violationCodes(policy.Check("Tom.Jones-1987x", "tom.jones", "Tom Jones", "tom.jones@example.com"))
+	assert.Equal(t, []string{ViolationUserInput, ViolationStrength}, codes)
+}
+
+func TestPolicyLoadBanned(t *testing.T) {
+	dir, err := ioutil.TempDir("", "policy")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "banned.txt")
+	assert.Nil(t, ioutil.WriteFile(fpath, []byte("# Company words\nMayCMF2020\n\nkyiv\n"), 0600))
+
+	policy := NewPolicy()
+	assert.Nil(t, policy.LoadBanned(fpath))
+	assert.Equal(t, []string{ViolationBanned}, violationCodes(policy.Check("maycmf2020")))
+	assert.Empty(t, policy.Check("# Company words"))
+	assert.NotNil(t, policy.LoadBanned(filepath.Join(dir, "missing.txt")))
+}
+
+func TestPolicyStrength(t *testing.T) {
+	policy := NewPolicy()
+
+	for password, score := range map[string]int{
+		"":                     0,
+		"password1":            0,
+		"aaaaaaaaaaaa":         0,
+		"qwerty123":            0,
+		"abcdefghijklmnop":     0,
+		"Monkey!Dragon":        1,
+		"k8#Lq":                2,
+		"xK9!mQ2#":             4,
+		"correct-Horse-7bat":   4,
+		"zxcvbnm,./1234567890": 1,
+	} {
+		assert.Equal(t, score, policy.Strength(password), password)
+	}
+
+	// User inputs are guessed first
+	assert.Equal(t, 4, policy.Strength("jonathan.smithers"))
+	assert.Equal(t, 0, policy.Strength("jonathan.smithers", "jonathan.smithers@example.com"))
+}
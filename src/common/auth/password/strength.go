@@ -0,0 +1,208 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Replacements of l33t speak
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+}
+
+// Keyboard rows, runs along them are easy to guess
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"qwertzuiopü",
+	"azertyuiop",
+	"qsdfghjklm",
+	"wxcvbn",
+}
+
+// Longest dictionary word that is matched
+const maxWordLength = 32
+
+func unleet(s string) string {
+	return strings.Map(func(r rune) rune {
+		if v, ok := leet[r]; ok {
+			return v
+		}
+		return r
+	}, s)
+}
+
+// Number of candidates of a single character guessed by brute force
+func cardinality(r rune) float64 {
+	switch {
+	case unicode.IsDigit(r):
+		return 10
+	case unicode.IsLower(r), unicode.IsUpper(r):
+		return 26
+	}
+	return 33
+}
+
+// Strength - Estimate the strength of a password on a scale from 0 (too
+// guessable) to 4 (very unguessable) in the manner of zxcvbn: the password
+// is split into the patterns needing the fewest guesses, which are banned
+// words, user inputs, repeats, sequences, keyboard runs and single
+// characters guessed by brute force
+func (a *Policy) Strength(password string, userInputs ...string) int {
+	dictionary := a.dictionary
+	if inputs := splitUserInputs(userInputs); len(inputs) > 0 {
+		dictionary = make(map[string]int, len(a.dictionary)+len(inputs))
+		for k, v := range a.dictionary {
+			dictionary[k] = v
+		}
+		for _, input := range inputs {
+			dictionary[input] = 1
+		}
+	}
+
+	guesses := estimateGuesses([]rune(password), dictionary)
+	switch {
+	case guesses < 3:
+		return 0
+	case guesses < 6:
+		return 1
+	case guesses < 8:
+		return 2
+	case guesses < 10:
+		return 3
+	}
+	return 4
+}
+
+// Estimate the log10 of the guesses needed to find a password
+func estimateGuesses(runes []rune, dictionary map[string]int) float64 {
+	n := len(runes)
+	lower := []rune(strings.ToLower(string(runes)))
+	if len(lower) != n {
+		lower = runes
+	}
+
+	// best[i] is the fewest log10 guesses of the first i characters
+	best := make([]float64, n+1)
+	for j := 1; j <= n; j++ {
+		best[j] = best[j-1] + math.Log10(cardinality(runes[j-1]))
+
+		for i := j - 3; i >= 0 && j-i <= maxWordLength; i-- {
+			if g := matchGuesses(runes[i:j], lower[i:j], dictionary); g > 0 {
+				if v := best[i] + math.Log10(g); v < best[j] {
+					best[j] = v
+				}
+			}
+		}
+	}
+	return best[n]
+}
+
+// Guesses of a part of a password matching a pattern, 0 if it matches none
+func matchGuesses(part, lower []rune, dictionary map[string]int) float64 {
+	var guesses float64
+
+	word := string(lower)
+	if rank, ok := dictionary[word]; ok {
+		guesses = float64(rank) * caseVariations(part)
+	} else if rank, ok := dictionary[unleet(word)]; ok {
+		guesses = float64(rank) * caseVariations(part) * 2
+	}
+
+	if g := repeatGuesses(lower); g > 0 && (guesses == 0 || g < guesses) {
+		guesses = g
+	}
+	if g := sequenceGuesses(lower); g > 0 && (guesses == 0 || g < guesses) {
+		guesses = g
+	}
+	if g := keyboardGuesses(word); g > 0 && (guesses == 0 || g < guesses) {
+		guesses = g
+	}
+	return guesses
+}
+
+// Guesses of the capitalization of a dictionary word
+func caseVariations(part []rune) float64 {
+	var upper int
+	for _, r := range part {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+
+	switch {
+	case upper == 0:
+		return 1
+	case upper == len(part), upper == 1 && unicode.IsUpper(part[0]):
+		return 2
+	}
+	return math.Pow(2, float64(upper))
+}
+
+// Guesses of a character repeated
+func repeatGuesses(part []rune) float64 {
+	for _, r := range part[1:] {
+		if r != part[0] {
+			return 0
+		}
+	}
+	return cardinality(part[0]) * float64(len(part))
+}
+
+// Guesses of characters ascending or descending by one
+func sequenceGuesses(part []rune) float64 {
+	delta := part[1] - part[0]
+	if delta != 1 && delta != -1 {
+		return 0
+	}
+	for i := 2; i < len(part); i++ {
+		if part[i]-part[i-1] != delta {
+			return 0
+		}
+	}
+
+	base := cardinality(part[0])
+	switch part[0] {
+	case 'a', 'z', '0', '1', '9':
+		base = 4
+	}
+	if delta < 0 {
+		base *= 2
+	}
+	return base * float64(len(part))
+}
+
+// Guesses of a run along a keyboard row, at least 4 keys long
+func keyboardGuesses(word string) float64 {
+	if len(word) < 4 {
+		return 0
+	}
+
+	for _, row := range keyboardRows {
+		if strings.Contains(row, word) {
+			return 40 * float64(len(word))
+		} else if strings.Contains(reverse(row), word) {
+			return 80 * float64(len(word))
+		}
+	}
+	return 0
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
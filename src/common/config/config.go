@@ -52,6 +52,15 @@ type Config struct {
 	LogGormHook LogGormHook `toml:"log_gorm_hook"`
 	Root        Root        `toml:"root"`
 	JWTAuth     JWTAuth     `toml:"jwt_auth"`
+	Password    Password    `toml:"password"`
+	MFA         MFA         `toml:"mfa"`
+	OIDC        OIDC        `toml:"oidc"`
+	LDAP        LDAP        `toml:"ldap"`
+	OAuth       OAuth       `toml:"oauth"`
+	Mailer      Mailer      `toml:"mailer"`
+	UserEmail   UserEmail   `toml:"user_email"`
+	Register    Register    `toml:"registration"`
+	Lockout     Lockout     `toml:"login_lockout"`
 	Monitor     Monitor     `toml:"monitor"`
 	Captcha     Captcha     `toml:"captcha"`
 	RateLimiter RateLimiter `toml:"rate_limiter"`
@@ -124,11 +133,150 @@ type Root struct {
 
 // JWTAuth User Authentication
 type JWTAuth struct {
-	SigningMethod string `toml:"signing_method"`
-	SigningKey    string `toml:"signing_key"`
-	Expired       int    `toml:"expired"`
+	SigningMethod        string `toml:"signing_method"`
+	SigningKey           string `toml:"signing_key"`
+	SigningFile          string `toml:"signing_file"`
+	SecretKey            string `toml:"secret_key"`
+	SecretFile           string `toml:"secret_file"`
+	KeyDir               string `toml:"key_dir"`
+	KeyRotation          int    `toml:"key_rotation"`
+	KeyOverlap           int    `toml:"key_overlap"`
+	Expired              int    `toml:"expired"`
+	RefreshExpired       int    `toml:"refresh_expired"`
+	ImpersonationExpired int    `toml:"impersonation_expired"`
+	Store                string `toml:"store"`
+	FilePath             string `toml:"file_path"`
+	RedisDB              int    `toml:"redis_db"`
+	RedisPrefix          string `toml:"redis_prefix"`
+}
+
+// LDAP - LDAP / Active Directory login configuration parameters
+type LDAP struct {
+	Enable             bool              `toml:"enable"`
+	URL                string            `toml:"url"`
+	StartTLS           bool              `toml:"start_tls"`
+	CACert             string            `toml:"ca_cert"`
+	InsecureSkipVerify bool              `toml:"insecure_skip_verify"`
+	Timeout            int               `toml:"timeout"`
+	BindDN             string            `toml:"bind_dn"`
+	BindPassword       string            `toml:"bind_password"`
+	BaseDN             string            `toml:"base_dn"`
+	UserFilter         string            `toml:"user_filter"`
+	UserNameAttribute  string            `toml:"user_name_attribute"`
+	RealNameAttribute  string            `toml:"real_name_attribute"`
+	EmailAttribute     string            `toml:"email_attribute"`
+	GroupAttribute     string            `toml:"group_attribute"`
+	GroupBaseDN        string            `toml:"group_base_dn"`
+	GroupFilter        string            `toml:"group_filter"`
+	AutoCreate         bool              `toml:"auto_create"`
+	LinkByUserName     bool              `toml:"link_by_user_name"`
+	RoleMapping        map[string]string `toml:"role_mapping"`
+	DefaultRoles       []string          `toml:"default_roles"`
+}
+
+// Password - User password hashing configuration parameters
+type Password struct {
+	Algorithm         string `toml:"algorithm"`
+	Argon2Memory      int    `toml:"argon2_memory"`
+	Argon2Iterations  int    `toml:"argon2_iterations"`
+	Argon2Parallelism int    `toml:"argon2_parallelism"`
+	BcryptCost        int    `toml:"bcrypt_cost"`
+	ForceResetLegacy  bool   `toml:"force_reset_legacy"`
+	MinLength         int    `toml:"min_length"`
+	MinClasses        int    `toml:"min_classes"`
+	MinScore          int    `toml:"min_score"`
+	BannedFile        string `toml:"banned_file"`
+	History           int    `toml:"history"`
+	MaxAge            int    `toml:"max_age"`
+}
+
+// MFA - Two-factor authentication configuration parameters
+type MFA struct {
+	Issuer           string `toml:"issuer"`
+	ChallengeExpired int    `toml:"challenge_expired"`
+	RecoveryCodes    int    `toml:"recovery_codes"`
+}
+
+// OIDC - OpenID Connect login configuration parameters
+type OIDC struct {
+	StateExpired int            `toml:"state_expired"`
+	Providers    []OIDCProvider `toml:"providers"`
+}
+
+// GetProvider - Get an identity provider by name
+func (a OIDC) GetProvider(name string) (OIDCProvider, bool) {
+	for _, item := range a.Providers {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return OIDCProvider{}, false
+}
+
+// OIDCProvider - OpenID Connect identity provider
+type OIDCProvider struct {
+	Name          string            `toml:"name"`
+	Title         string            `toml:"title"`
+	Issuer        string            `toml:"issuer"`
+	ClientID      string            `toml:"client_id"`
+	ClientSecret  string            `toml:"client_secret"`
+	RedirectURL   string            `toml:"redirect_url"`
+	Scopes        []string          `toml:"scopes"`
+	SuccessURL    string            `toml:"success_url"`
+	AutoCreate    bool              `toml:"auto_create"`
+	LinkByEmail   bool              `toml:"link_by_email"`
+	UserNameClaim string            `toml:"user_name_claim"`
+	RoleClaim     string            `toml:"role_claim"`
+	RoleMapping   map[string]string `toml:"role_mapping"`
+	DefaultRoles  []string          `toml:"default_roles"`
+}
+
+// OAuth - OAuth2 authorization server configuration parameters
+type OAuth struct {
+	CodeExpired int `toml:"code_expired"`
+}
+
+// Mailer - Email sending configuration parameters
+type Mailer struct {
+	Driver       string `toml:"driver"`
+	From         string `toml:"from"`
+	TemplateDir  string `toml:"template_dir"`
+	FileDir      string `toml:"file_dir"`
+	SMTPHost     string `toml:"smtp_host"`
+	SMTPPort     int    `toml:"smtp_port"`
+	SMTPUserName string `toml:"smtp_user_name"`
+	SMTPPassword string `toml:"smtp_password"`
+	SMTPTLS      string `toml:"smtp_tls"`
+}
+
+// UserEmail - Password reset and email verification configuration parameters
+type UserEmail struct {
+	ResetExpired  int    `toml:"reset_expired"`
+	ResetURL      string `toml:"reset_url"`
+	VerifyExpired int    `toml:"verify_expired"`
+	VerifyURL     string `toml:"verify_url"`
+}
+
+// Register - Self-registration configuration parameters
+type Register struct {
+	Mode              string   `toml:"mode"`
+	Captcha           bool     `toml:"captcha"`
+	VerifyEmail       bool     `toml:"verify_email"`
+	DefaultRoles      []string `toml:"default_roles"`
+	InvitationExpired int      `toml:"invitation_expired"`
+	InvitationURL     string   `toml:"invitation_url"`
+}
+
+// Lockout - Login brute-force protection configuration parameters
+type Lockout struct {
+	Enable        bool   `toml:"enable"`
 	Store         string `toml:"store"`
-	FilePath      string `toml:"file_path"`
+	MaxAttempts   int64  `toml:"max_attempts"`
+	MaxIPAttempts int64  `toml:"max_ip_attempts"`
+	Window        int    `toml:"window"`
+	LockDuration  int    `toml:"lock_duration"`
+	Delay         int    `toml:"delay"`
+	MaxDelay      int    `toml:"max_delay"`
 	RedisDB       int    `toml:"redis_db"`
 	RedisPrefix   string `toml:"redis_prefix"`
 }
@@ -193,12 +341,32 @@ type Gorm struct {
 
 // Postgres Configuration parameter
 type FileManager struct {
-	Dir         string   `toml:"dir"`
-	MaxSize     int64    `toml:"maxsize"`
-	ImagesDir   string   `toml:"images_dir"`
-	AllowImages []string `toml:"allow_images"`
-	FilesDir    string   `toml:"files_dir"`
-	AllowFiles  []string `toml:"allow_files"`
+	Dir           string   `toml:"dir"`
+	MaxSize       int64    `toml:"maxsize"`
+	ImagesDir     string   `toml:"images_dir"`
+	AllowImages   []string `toml:"allow_images"`
+	FilesDir      string   `toml:"files_dir"`
+	AllowFiles    []string `toml:"allow_files"`
+	PrivateDir    string   `toml:"private_dir"`
+	SigningKey    string   `toml:"signing_key"`
+	URLExpired    int      `toml:"url_expired"`
+	ExifStrip     bool     `toml:"exif_strip"`
+	AutoRotate    bool     `toml:"auto_rotate"`
+	QuarantineDir string   `toml:"quarantine_dir"`
+	JobDir        string   `toml:"job_dir"`
+	JobExpired    int      `toml:"job_expired"`
+	ArchiveSize   int64    `toml:"archive_size"`
+	ArchiveFiles  int      `toml:"archive_files"`
+	TextSize      int      `toml:"text_size"`
+	Clamd         Clamd    `toml:"clamd"`
+}
+
+// Clamd - ClamAV daemon configuration parameters
+type Clamd struct {
+	Enable  bool   `toml:"enable"`
+	Network string `toml:"network"`
+	Address string `toml:"address"`
+	Timeout int    `toml:"timeout"`
 }
 
 // MySQL configuration parameters
@@ -6,10 +6,14 @@ import (
 
 // Define keys in the global context
 type (
-	transCtx     struct{}
-	transLockCtx struct{}
-	userUUIDCtx  struct{}
-	traceIDCtx   struct{}
+	transCtx        struct{}
+	transLockCtx    struct{}
+	userUUIDCtx     struct{}
+	impersonatorCtx struct{}
+	traceIDCtx      struct{}
+	clientIPCtx     struct{}
+	userAgentCtx    struct{}
+	languageCtx     struct{}
 )
 
 // NewTrans - Create the context of the transaction
@@ -50,6 +54,22 @@ func FromUserUUID(ctx context.Context) (string, bool) {
 	return "", false
 }
 
+// NewImpersonator - Create a context for the user impersonating the user
+func NewImpersonator(ctx context.Context, impersonatorUUID string) context.Context {
+	return context.WithValue(ctx, impersonatorCtx{}, impersonatorUUID)
+}
+
+// FromImpersonator - Get the user impersonating the user from the context
+func FromImpersonator(ctx context.Context) (string, bool) {
+	v := ctx.Value(impersonatorCtx{})
+	if v != nil {
+		if s, ok := v.(string); ok {
+			return s, s != ""
+		}
+	}
+	return "", false
+}
+
 // NewTraceID - Create a context for tracking IDs
 func NewTraceID(ctx context.Context, traceID string) context.Context {
 	return context.WithValue(ctx, traceIDCtx{}, traceID)
@@ -65,3 +85,51 @@ func FromTraceID(ctx context.Context) (string, bool) {
 	}
 	return "", false
 }
+
+// NewClientIP - Create a context for the client IP
+func NewClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPCtx{}, ip)
+}
+
+// FromClientIP - Get the client IP from the context
+func FromClientIP(ctx context.Context) (string, bool) {
+	v := ctx.Value(clientIPCtx{})
+	if v != nil {
+		if s, ok := v.(string); ok {
+			return s, s != ""
+		}
+	}
+	return "", false
+}
+
+// NewUserAgent - Create a context for the client user agent
+func NewUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, userAgentCtx{}, userAgent)
+}
+
+// FromUserAgent - Get the client user agent from the context
+func FromUserAgent(ctx context.Context) (string, bool) {
+	v := ctx.Value(userAgentCtx{})
+	if v != nil {
+		if s, ok := v.(string); ok {
+			return s, s != ""
+		}
+	}
+	return "", false
+}
+
+// NewLanguage - Create a context for the language of the client
+func NewLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageCtx{}, language)
+}
+
+// FromLanguage - Get the language of the client from the context
+func FromLanguage(ctx context.Context) (string, bool) {
+	v := ctx.Value(languageCtx{})
+	if v != nil {
+		if s, ok := v.(string); ok {
+			return s, s != ""
+		}
+	}
+	return "", false
+}
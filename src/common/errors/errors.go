@@ -24,6 +24,14 @@ var (
 	ErrInvalidPassword         = New400Response("Invalid password")
 	ErrInvalidUser             = New400Response("Invalid user")
 	ErrUserDisable             = New400Response("User is disabled, please contact administrator")
+	ErrPasswordExpired         = New400Response("Password must be reset, please change it or request a password reset link")
+	ErrInvalidMFACode          = New400Response("Invalid two-factor authentication code")
+	ErrInvalidMFAToken         = New400Response("Two-factor authentication expired, please login again")
+	ErrInvalidResetToken       = New400Response("Password reset link is invalid or expired")
+	ErrInvalidVerifyToken      = New400Response("Email verification link is invalid or expired")
+	ErrInvalidInvitation       = New400Response("Invitation link is invalid or expired")
+	ErrEmailNotVerified        = New400Response("Email address is not verified, please open the verification link")
+	ErrPasswordChangeRequired  = New400Response("Password has expired, please change it")
 
 	ErrNoPerm          = NewResponse(401, "No access", 401)
 	ErrInvalidToken    = NewResponse(9999, "Token invalidation", 401)
@@ -2,10 +2,11 @@ package errors
 
 // ResponseError - Define response error
 type ResponseError struct {
-	Code       int    // error code
-	Message    string // wrong information
-	StatusCode int    // Response status code
-	ERR        error  // Response error
+	Code       int         // error code
+	Message    string      // wrong information
+	Details    interface{} // Structured details (optional)
+	StatusCode int         // Response status code
+	ERR        error       // Response error
 }
 
 func (r *ResponseError) Error() string {
@@ -71,6 +72,17 @@ func New400Response(msg string) error {
 	return NewResponse(400, msg, 400)
 }
 
+// New400DetailResponse - Create a response error with error code 400 and
+// structured details
+func New400DetailResponse(msg string, details interface{}) error {
+	return &ResponseError{
+		Code:       400,
+		Message:    msg,
+		Details:    details,
+		StatusCode: 400,
+	}
+}
+
 // New500Response - Create a response error with error code 500
 func New500Response(msg string) error {
 	return NewResponse(500, msg, 500)
@@ -21,6 +21,10 @@ const (
 	UserIDKey = prefix + "/user-id"
 	// UserUUIDKey - Key in the storage context (user UUID)
 	UserUUIDKey = prefix + "/user-uuid"
+	// ImpersonatorKey - Key in the storage context (UUID of the user impersonating the user)
+	ImpersonatorKey = prefix + "/impersonator"
+	// TokenScopeKey - Key in the storage context (casbin subject of the token scopes)
+	TokenScopeKey = prefix + "/token-scope"
 	// TraceIDKey - Key in storage context (tracking ID)
 	TraceIDKey = prefix + "/trace-id"
 	// ResBodyKey - The key in the storage context (response to the Body data)
@@ -41,6 +45,23 @@ func NewContext(c *gin.Context) context.Context {
 		parent = logger.NewUserUUIDContext(parent, v)
 	}
 
+	if v := GetImpersonator(c); v != "" {
+		parent = icontext.NewImpersonator(parent, v)
+		parent = logger.NewImpersonatorContext(parent, v)
+	}
+
+	if v := c.ClientIP(); v != "" {
+		parent = icontext.NewClientIP(parent, v)
+	}
+
+	if v := c.Request.UserAgent(); v != "" {
+		parent = icontext.NewUserAgent(parent, v)
+	}
+
+	if v := GetLanguage(c); v != "" {
+		parent = icontext.NewLanguage(parent, v)
+	}
+
 	return parent
 }
 
@@ -55,6 +76,18 @@ func GetToken(c *gin.Context) string {
 	return token
 }
 
+// GetLanguage - Get the preferred language of the Accept-Language header
+func GetLanguage(c *gin.Context) string {
+	v := c.GetHeader("Accept-Language")
+	if i := strings.IndexAny(v, ",;"); i >= 0 {
+		v = v[:i]
+	}
+	if v = strings.TrimSpace(v); v == "*" {
+		return ""
+	}
+	return v
+}
+
 // GetPageIndex - Get paged page index
 func GetPageIndex(c *gin.Context) int {
 	defaultVal := 1
@@ -113,6 +146,18 @@ func SetUserUUID(c *gin.Context, userUUID string) {
 	c.Set(UserUUIDKey, userUUID)
 }
 
+// GetImpersonator - Get the UUID of the user impersonating the user, empty
+// when the request token was issued to its user
+func GetImpersonator(c *gin.Context) string {
+	return c.GetString(ImpersonatorKey)
+}
+
+// GetTokenScope - Get the casbin subject of the token scopes, empty when the
+// request token has the full permissions of its user
+func GetTokenScope(c *gin.Context) string {
+	return c.GetString(TokenScopeKey)
+}
+
 // ParseJSON - Parse request JSON
 func ParseJSON(c *gin.Context, obj interface{}) error {
 	if err := c.ShouldBindJSON(obj); err != nil {
@@ -193,6 +238,7 @@ func ResError(c *gin.Context, err error, status ...int) {
 	eitem := schema.HTTPErrorItem{
 		Code:    res.Code,
 		Message: res.Message,
+		Details: res.Details,
 	}
 	ResJSON(c, res.StatusCode, schema.HTTPError{Error: eitem})
 }
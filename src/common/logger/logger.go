@@ -12,6 +12,7 @@ const (
 	TraceIDKey      = "trace_id"
 	UserUUIDKey     = "user_uuid"
 	UserIDKey       = "user_id"
+	ImpersonatorKey = "impersonated_by"
 	SpanTitleKey    = "span_title"
 	SpanFunctionKey = "span_function"
 	VersionKey      = "version"
@@ -79,9 +80,10 @@ func getTraceID() string {
 }
 
 type (
-	traceIDContextKey  struct{}
-	spanIDContextKey   struct{}
-	userUUIDContextKey struct{}
+	traceIDContextKey      struct{}
+	spanIDContextKey       struct{}
+	userUUIDContextKey     struct{}
+	impersonatorContextKey struct{}
 )
 
 // NewTraceIDContext - Create a tracking ID context
@@ -116,6 +118,22 @@ func FromUserUUIDContext(ctx context.Context) string {
 	return ""
 }
 
+// NewImpersonatorContext - Create a context of the user impersonating the user
+func NewImpersonatorContext(ctx context.Context, impersonatorUUID string) context.Context {
+	return context.WithValue(ctx, impersonatorContextKey{}, impersonatorUUID)
+}
+
+// FromImpersonatorContext - Get the user impersonating the user from the context
+func FromImpersonatorContext(ctx context.Context) string {
+	v := ctx.Value(impersonatorContextKey{})
+	if v != nil {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
 type spanOptions struct {
 	Title    string
 	FuncName string
@@ -154,6 +172,9 @@ func StartSpan(ctx context.Context, opts ...SpanOption) *Entry {
 		TraceIDKey:  FromTraceIDContext(ctx),
 		VersionKey:  version,
 	}
+	if v := FromImpersonatorContext(ctx); v != "" {
+		fields[ImpersonatorKey] = v
+	}
 	if v := o.Title; v != "" {
 		fields[SpanTitleKey] = v
 	}
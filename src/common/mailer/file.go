@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// NewFile - Create a mailer writing the messages as .eml files into a directory
+func NewFile(dir, from string) *File {
+	return &File{
+		dir:  dir,
+		from: from,
+	}
+}
+
+// File - Mailer writing the messages into a directory instead of sending them
+type File struct {
+	dir  string
+	from string
+}
+
+// Send - Write a message to a new file
+func (a *File) Send(ctx context.Context, msg *Message) error {
+	msg = withSender(msg, a.from)
+
+	err := os.MkdirAll(a.dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), util.MustUUID()[:8])
+	return ioutil.WriteFile(filepath.Join(a.dir, name), msg.Bytes(), 0600)
+}
+
+// NewLog - Create a mailer writing the messages to the log
+func NewLog(from string) *Log {
+	return &Log{from: from}
+}
+
+// Log - Mailer writing the messages to the log, for development only as the
+// messages hold secret links
+type Log struct {
+	from string
+}
+
+// Send - Log a message
+func (a *Log) Send(ctx context.Context, msg *Message) error {
+	msg = withSender(msg, a.from)
+	logger.StartSpan(ctx, logger.SetSpanTitle("Mailer"), logger.SetSpanFuncName("Send")).
+		Infof("Mail from %s to %s: %s\n%s", msg.From, strings.Join(msg.To, ", "), msg.Subject, msg.Text)
+	return nil
+}
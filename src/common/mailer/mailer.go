@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Mailer - Email sending interface
+type Mailer interface {
+	// Send a message, the sender of the mailer is used when it has none
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message - Email message
+type Message struct {
+	From    string   // Sender address
+	To      []string // Recipient addresses
+	Subject string   // Subject
+	Text    string   // Plain text body
+	HTML    string   // HTML body (optional)
+}
+
+// Bytes - Encode the message (RFC 5322), a message with an HTML body is sent
+// as multipart/alternative
+func (m *Message) Bytes() []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "From: %s\r\n", headerValue(m.From))
+	fmt.Fprintf(buf, "To: %s\r\n", headerValue(strings.Join(m.To, ", ")))
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if m.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writeQuotedPrintable(buf, m.Text)
+		return buf.Bytes()
+	}
+
+	w := multipart.NewWriter(buf)
+	fmt.Fprintf(buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", w.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		pw, _ := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		writeQuotedPrintable(pw, part.body)
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+// Line breaks of addresses would inject headers
+func headerValue(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+func writeQuotedPrintable(w io.Writer, s string) {
+	qw := quotedprintable.NewWriter(w)
+	_, _ = qw.Write([]byte(s))
+	_ = qw.Close()
+}
+
+// Set the sender of the mailer on a copy of a message without one
+func withSender(msg *Message, from string) *Message {
+	item := *msg
+	if item.From == "" {
+		item.From = from
+	}
+	return &item
+}
@@ -0,0 +1,87 @@
+package mailer
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mail")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"en/welcome.txt":  `{{define "subject"}}Welcome {{.Name}}{{end}}Hello {{.Name}}`,
+		"en/welcome.html": `<p>Hello {{.Name}}</p>`,
+		"uk/welcome.txt":  `{{define "subject"}}Вітаємо {{.Name}}{{end}}Привіт {{.Name}}`,
+	}
+	for name, content := range files {
+		fpath := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(fpath), 0700))
+		assert.Nil(t, ioutil.WriteFile(fpath, []byte(content), 0600))
+	}
+
+	templates, err := NewTemplates(dir, "en")
+	assert.Nil(t, err)
+
+	data := map[string]string{"Name": "<Tom>"}
+	msg, err := templates.Render("uk-UA", "welcome", data)
+	assert.Nil(t, err)
+	assert.Equal(t, "Вітаємо <Tom>", msg.Subject)
+	assert.Equal(t, "Привіт <Tom>\n", msg.Text)
+	assert.Equal(t, "", msg.HTML)
+
+	// Unknown languages fall back to the default language
+	msg, err = templates.Render("de", "welcome", data)
+	assert.Nil(t, err)
+	assert.Equal(t, "Welcome <Tom>", msg.Subject)
+	assert.Equal(t, "<p>Hello &lt;Tom&gt;</p>", msg.HTML)
+
+	_, err = templates.Render("en", "unknown", data)
+	assert.NotNil(t, err)
+
+	// A missing directory has no templates
+	templates, err = NewTemplates(filepath.Join(dir, "missing"), "en")
+	assert.Nil(t, err)
+	_, err = templates.Render("en", "welcome", data)
+	assert.NotNil(t, err)
+}
+
+func TestMessageBytes(t *testing.T) {
+	msg := &Message{
+		From:    "noreply@example.com",
+		To:      []string{"tom@example.com\r\nBcc: eve@example.com"},
+		Subject: "Вітаємо",
+		Text:    "Hello",
+		HTML:    "<p>Hello</p>",
+	}
+
+	s := string(msg.Bytes())
+	assert.True(t, strings.Contains(s, "To: tom@example.comBcc: eve@example.com\r\n"))
+	assert.True(t, strings.Contains(s, "Subject: =?utf-8?q?"))
+	assert.True(t, strings.Contains(s, "multipart/alternative"))
+	assert.True(t, strings.Contains(s, "<p>Hello</p>"))
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory("noreply@example.com")
+	ctx := context.Background()
+
+	assert.Nil(t, m.Send(ctx, &Message{To: []string{"a@example.com"}, Subject: "1"}))
+	assert.Nil(t, m.Send(ctx, &Message{To: []string{"b@example.com"}, Subject: "2"}))
+	assert.Nil(t, m.Send(ctx, &Message{To: []string{"a@example.com"}, Subject: "3"}))
+
+	assert.Len(t, m.Messages(), 3)
+	assert.Equal(t, "3", m.Last("a@example.com").Subject)
+	assert.Equal(t, "noreply@example.com", m.Last("b@example.com").From)
+	assert.Nil(t, m.Last("c@example.com"))
+
+	m.Reset()
+	assert.Len(t, m.Messages(), 0)
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// NewMemory - Create a mailer keeping the messages in memory
+func NewMemory(from string) *Memory {
+	return &Memory{from: from}
+}
+
+// Memory - Mailer keeping the messages in memory, used by tests
+type Memory struct {
+	from     string
+	lock     sync.Mutex
+	messages []*Message
+}
+
+// Send - Keep a message
+func (a *Memory) Send(ctx context.Context, msg *Message) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.messages = append(a.messages, withSender(msg, a.from))
+	return nil
+}
+
+// Messages - Get the sent messages, oldest first
+func (a *Memory) Messages() []*Message {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return append([]*Message(nil), a.messages...)
+}
+
+// Last - Get the last message sent to an address
+func (a *Memory) Last(to string) *Message {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for i := len(a.messages) - 1; i >= 0; i-- {
+		for _, v := range a.messages[i].To {
+			if v == to {
+				return a.messages[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Reset - Forget the sent messages
+func (a *Memory) Reset() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.messages = nil
+}
@@ -0,0 +1,150 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// TLS modes of the SMTP connection
+const (
+	SMTPStartTLS = "starttls" // Upgrade the connection with STARTTLS, refused when the server does not support it
+	SMTPTLS      = "tls"      // Implicit TLS (usually port 465)
+	SMTPNoTLS    = "none"     // Plain connection, only for local relays
+)
+
+type smtpOptions struct {
+	userName string
+	password string
+	tlsMode  string
+	timeout  time.Duration
+}
+
+// SMTPOption - SMTP mailer option
+type SMTPOption func(*smtpOptions)
+
+// SetSMTPAuth - Set the credentials of the PLAIN authentication
+func SetSMTPAuth(userName, password string) SMTPOption {
+	return func(o *smtpOptions) {
+		o.userName = userName
+		o.password = password
+	}
+}
+
+// SetSMTPTLS - Set the TLS mode (starttls/tls/none, default starttls)
+func SetSMTPTLS(mode string) SMTPOption {
+	return func(o *smtpOptions) {
+		if mode != "" {
+			o.tlsMode = mode
+		}
+	}
+}
+
+// SetSMTPTimeout - Set the timeout of connecting to the server
+func SetSMTPTimeout(timeout time.Duration) SMTPOption {
+	return func(o *smtpOptions) {
+		o.timeout = timeout
+	}
+}
+
+// NewSMTP - Create a mailer sending through an SMTP server
+func NewSMTP(host string, port int, from string, opts ...SMTPOption) *SMTP {
+	o := smtpOptions{
+		tlsMode: SMTPStartTLS,
+		timeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &SMTP{
+		host: host,
+		addr: net.JoinHostPort(host, fmt.Sprint(port)),
+		from: from,
+		opts: o,
+	}
+}
+
+// SMTP - Mailer sending through an SMTP server, a connection is opened per message
+type SMTP struct {
+	host string
+	addr string
+	from string
+	opts smtpOptions
+}
+
+// Send - Send a message
+func (a *SMTP) Send(ctx context.Context, msg *Message) error {
+	msg = withSender(msg, a.from)
+
+	client, err := a.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if a.opts.userName != "" {
+		err = client.Auth(smtp.PlainAuth("", a.opts.userName, a.opts.password, a.host))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(a.from); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err = client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (a *SMTP) dial(ctx context.Context) (*smtp.Client, error) {
+	dialer := &net.Dialer{Timeout: a.opts.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", a.addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	tlsConfig := &tls.Config{ServerName: a.host}
+	if a.opts.tlsMode == SMTPTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, a.host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if a.opts.tlsMode == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("smtp server %s does not support STARTTLS", a.addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
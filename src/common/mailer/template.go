@@ -0,0 +1,133 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+// NewTemplates - Load the mail templates of a directory, it holds a
+// subdirectory per language code with a <name>.txt template for each mail
+// (defining the "subject" template) and optionally a <name>.html template.
+// A missing directory has no templates.
+func NewTemplates(dir, defaultLanguage string) (*Templates, error) {
+	a := &Templates{
+		defaultLanguage: strings.ToLower(defaultLanguage),
+		text:            make(map[string]*texttemplate.Template),
+		html:            make(map[string]*htmltemplate.Template),
+	}
+
+	langs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return a, nil
+		}
+		return nil, err
+	}
+
+	for _, lang := range langs {
+		if !lang.IsDir() {
+			continue
+		}
+		err := a.load(filepath.Join(dir, lang.Name()), strings.ToLower(lang.Name()))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Templates - Localized mail templates
+type Templates struct {
+	defaultLanguage string
+	text            map[string]*texttemplate.Template
+	html            map[string]*htmltemplate.Template
+}
+
+func (a *Templates) load(dir, lang string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		fpath := filepath.Join(dir, file.Name())
+		ext := filepath.Ext(file.Name())
+		key := lang + "/" + strings.TrimSuffix(file.Name(), ext)
+
+		switch ext {
+		case ".txt":
+			t, err := texttemplate.ParseFiles(fpath)
+			if err != nil {
+				return err
+			} else if t.Lookup("subject") == nil {
+				return fmt.Errorf("mail template %s does not define the subject", fpath)
+			}
+			a.text[key] = t
+		case ".html":
+			t, err := htmltemplate.ParseFiles(fpath)
+			if err != nil {
+				return err
+			}
+			a.html[key] = t
+		}
+	}
+	return nil
+}
+
+// Render - Render a mail for a language, falling back to its primary
+// language and then to the default language
+func (a *Templates) Render(language, name string, data interface{}) (*Message, error) {
+	key, ok := a.lookup(language, name)
+	if !ok {
+		return nil, fmt.Errorf("mail template %s not found", name)
+	}
+
+	t := a.text[key]
+	msg := new(Message)
+
+	buf := new(bytes.Buffer)
+	if err := t.ExecuteTemplate(buf, "subject", data); err != nil {
+		return nil, err
+	}
+	msg.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := t.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	msg.Text = strings.TrimSpace(buf.String()) + "\n"
+
+	if h, ok := a.html[key]; ok {
+		buf.Reset()
+		if err := h.Execute(buf, data); err != nil {
+			return nil, err
+		}
+		msg.HTML = buf.String()
+	}
+	return msg, nil
+}
+
+func (a *Templates) lookup(language, name string) (string, bool) {
+	language = strings.ToLower(language)
+	candidates := []string{language}
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		candidates = append(candidates, language[:i])
+	}
+	candidates = append(candidates, a.defaultLanguage)
+
+	for _, lang := range candidates {
+		if lang == "" {
+			continue
+		}
+		if _, ok := a.text[lang+"/"+name]; ok {
+			return lang + "/" + name, true
+		}
+	}
+	return "", false
+}
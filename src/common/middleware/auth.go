@@ -8,11 +8,41 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Resolve the user of a token, scoped tokens set the casbin subject of their
+// scopes and impersonation tokens the user impersonating their user
+func parseUserUUID(c *gin.Context, a auth.Auther, t string) (string, error) {
+	id, err := parseScopedUserUUID(c, a, t)
+	if err != nil || id == "" {
+		return id, err
+	}
+
+	if ia, ok := a.(auth.ImpersonatingAuther); ok {
+		impersonator, err := ia.ParseImpersonatorUUID(ginplus.NewContext(c), t)
+		if err != nil {
+			return "", err
+		} else if impersonator != "" {
+			c.Set(ginplus.ImpersonatorKey, impersonator)
+		}
+	}
+	return id, nil
+}
+
+func parseScopedUserUUID(c *gin.Context, a auth.Auther, t string) (string, error) {
+	if sa, ok := a.(auth.ScopedAuther); ok {
+		id, scope, err := sa.ParseScopedUserUUID(ginplus.NewContext(c), t)
+		if err == nil && scope != "" {
+			c.Set(ginplus.TokenScopeKey, scope)
+		}
+		return id, err
+	}
+	return a.ParseUserUUID(ginplus.NewContext(c), t)
+}
+
 // UserAuthMiddleware - User authorization middleware
 func UserAuthMiddleware(a auth.Auther, skippers ...SkipperFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if t := ginplus.GetToken(c); t != "" {
-			id, err := a.ParseUserUUID(ginplus.NewContext(c), t)
+			id, err := parseUserUUID(c, a, t)
 			if err != nil {
 				if err == auth.ErrInvalidToken {
 					ginplus.ResError(c, errors.ErrInvalidToken)
@@ -43,3 +73,75 @@ func UserAuthMiddleware(a auth.Auther, skippers ...SkipperFunc) gin.HandlerFunc
 		ginplus.ResError(c, errors.ErrInvalidToken)
 	}
 }
+
+// OptionalUserAuthMiddleware - Identify the user of a valid token on routes
+// open to anonymous users, the request goes on anonymously with any other token
+func OptionalUserAuthMiddleware(a auth.Auther) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if t := ginplus.GetToken(c); t != "" {
+			if id, err := parseUserUUID(c, a, t); err == nil && id != "" {
+				c.Set(ginplus.UserUUIDKey, id)
+			}
+		}
+		c.Next()
+	}
+}
+
+// UserBasicAuthMiddleware - User authorization middleware for clients that only
+// speak basic auth (WebDAV), the token is also accepted as basic auth password
+func UserBasicAuthMiddleware(a auth.Auther, realm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t := ginplus.GetToken(c)
+		if _, password, ok := c.Request.BasicAuth(); ok && t == "" {
+			t = password
+		}
+
+		if t != "" {
+			id, err := parseUserUUID(c, a, t)
+			if err != nil && err != auth.ErrInvalidToken {
+				e := errors.UnWrapResponse(errors.ErrInvalidToken)
+				ginplus.ResError(c, errors.WrapResponse(err, e.Code, e.Message, e.StatusCode))
+				return
+			} else if err == nil && id != "" {
+				c.Set(ginplus.UserUUIDKey, id)
+				c.Next()
+				return
+			}
+		}
+
+		cfg := config.Global()
+		if t == "" && cfg.IsDebugMode() {
+			c.Set(ginplus.UserUUIDKey, cfg.Root.UserName)
+			c.Next()
+			return
+		}
+
+		// Ask the client for credentials
+		c.Header("WWW-Authenticate", `Basic realm="`+realm+`"`)
+		ginplus.ResError(c, errors.ErrInvalidToken)
+	}
+}
+
+// UnscopedTokenMiddleware - Refuse requests of scoped tokens (access tokens),
+// for routes that are not covered by the casbin permissions
+func UnscopedTokenMiddleware(skippers ...SkipperFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if SkipHandler(c, skippers...) || ginplus.GetTokenScope(c) == "" {
+			c.Next()
+			return
+		}
+		ginplus.ResError(c, errors.ErrNoPerm)
+	}
+}
+
+// UnimpersonatedTokenMiddleware - Refuse requests of impersonation tokens, for
+// routes managing the account of the user that the impersonator may not use
+func UnimpersonatedTokenMiddleware(skippers ...SkipperFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if SkipHandler(c, skippers...) || ginplus.GetImpersonator(c) == "" {
+			c.Next()
+			return
+		}
+		ginplus.ResError(c, errors.ErrNoPerm)
+	}
+}
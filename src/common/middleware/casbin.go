@@ -30,6 +30,17 @@ func CasbinMiddleware(enforcer *casbin.SyncedEnforcer, skippers ...SkipperFunc)
 			ginplus.ResError(c, errors.ErrNoPerm)
 			return
 		}
+
+		// A scoped token is also limited to the permissions of its scopes
+		if scope := ginplus.GetTokenScope(c); scope != "" {
+			if b, err := enforcer.Enforce(scope, p, m); err != nil {
+				ginplus.ResError(c, errors.WithStack(err))
+				return
+			} else if !b {
+				ginplus.ResError(c, errors.ErrNoPerm)
+				return
+			}
+		}
 		c.Next()
 	}
 }
@@ -63,6 +63,9 @@ func LoggerMiddleware(skippers ...SkipperFunc) gin.HandlerFunc {
 		}
 
 		fields[logger.UserUUIDKey] = ginplus.GetUserUUID(c)
+		if v := ginplus.GetImpersonator(c); v != "" {
+			fields[logger.ImpersonatorKey] = v
+		}
 		span.WithFields(fields).Infof("[http] %s-%s-%s-%d(%dms)",
 			p, c.Request.Method, c.ClientIP(), c.Writer.Status(), timeConsuming)
 	}
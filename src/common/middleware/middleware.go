@@ -71,6 +71,20 @@ func AllowMethodAndPathPrefixSkipper(prefixes ...string) SkipperFunc {
 	}
 }
 
+// AllowMethodAndRouteSkipper - Check if the request method and matched route
+// (e.g. GET/api/v1/file/:id/download) are one of the specified, skip if it is included
+func AllowMethodAndRouteSkipper(routes ...string) SkipperFunc {
+	return func(c *gin.Context) bool {
+		route := JoinRouter(c.Request.Method, c.FullPath())
+		for _, r := range routes {
+			if route == r {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // JoinRouter - Splicing route
 func JoinRouter(method, path string) string {
 	if len(path) > 0 && path[0] != '/' {
@@ -38,14 +38,14 @@ func RateLimiterMiddleware(skippers ...SkipperFunc) gin.HandlerFunc {
 			return
 		}
 
-		userUUID := ginplus.GetUserUUID(c)
-		if userUUID == "" {
-			c.Next()
-			return
+		// Requests without a user are limited per client IP
+		key := ginplus.GetUserUUID(c)
+		if key == "" {
+			key = "ip:" + c.ClientIP()
 		}
 
 		limit := cfg.Count
-		rate, delay, allowed := limiter.AllowMinute(userUUID, limit)
+		rate, delay, allowed := limiter.AllowMinute(key, limit)
 		if !allowed {
 			h := c.Writer.Header()
 			h.Set("X-RateLimit-Limit", strconv.FormatInt(limit, 10))
@@ -19,8 +19,9 @@ type HTTPError struct {
 
 // HTTPErrorItem HTTP response error item
 type HTTPErrorItem struct {
-	Code    int    `json:"code"`    // Error code
-	Message string `json:"message"` // Error message
+	Code    int         `json:"code"`              // Error code
+	Message string      `json:"message"`           // Error message
+	Details interface{} `json:"details,omitempty"` // Structured details
 }
 
 // HTTPStatus - HTTP response status
@@ -1,8 +1,10 @@
 package util
 
 import (
+	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
 	"fmt"
 )
 
@@ -29,3 +31,27 @@ func SHA1Hash(b []byte) string {
 func SHA1HashString(s string) string {
 	return SHA1Hash([]byte(s))
 }
+
+// SHA256Hash - SHA256 hash value
+func SHA256Hash(b []byte) string {
+	h := sha256.New()
+	h.Write(b)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// SHA256HashString - SHA256 hash value
+func SHA256HashString(s string) string {
+	return SHA256Hash([]byte(s))
+}
+
+// HMACSHA256Hash - HMAC-SHA256 hash value
+func HMACSHA256Hash(key, b []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write(b)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// HMACSHA256HashString - HMAC-SHA256 hash value
+func HMACSHA256HashString(key, s string) string {
+	return HMACSHA256Hash([]byte(key), []byte(s))
+}
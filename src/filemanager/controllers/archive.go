@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"context"
+	"io"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IArchive - Bulk archive business logic interface
+type IArchive interface {
+	// Get specified job of the current user
+	GetJob(ctx context.Context, UUID string) (*schema.FileJob, error)
+	// Remove the archive built by a job of the current user once it is downloaded
+	RemoveResult(ctx context.Context, UUID string) error
+	// Extract an uploaded zip archive into the library in the background
+	Extract(ctx context.Context, creator string, params schema.FileExtractParam, zipPath string) (*schema.FileJob, error)
+	// Stream a zip archive of the selected files
+	Write(ctx context.Context, w io.Writer, params schema.FileArchiveParam) error
+	// Build a zip archive of the selected files in the background
+	Build(ctx context.Context, creator string, params schema.FileArchiveParam) (*schema.FileJob, error)
+}
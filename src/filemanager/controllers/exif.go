@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // Register gif decoder for dimensions
+	"image/jpeg"
+	_ "image/png" // Register png decoder for dimensions
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// ImageMeta - Metadata read from an uploaded image
+type ImageMeta struct {
+	Format      string     // Image format (jpeg, png, gif)
+	Width       int        // Width in pixels, as displayed
+	Height      int        // Height in pixels, as displayed
+	Orientation int        // EXIF orientation (1-8)
+	CameraMake  string     // Camera manufacturer
+	CameraModel string     // Camera model
+	TakenAt     *time.Time // Capture time
+	Latitude    *float64   // GPS latitude
+	Longitude   *float64   // GPS longitude
+}
+
+// ReadImageMeta - Read dimensions and EXIF metadata, returns nil if data is not a known image
+func ReadImageMeta(data []byte) *ImageMeta {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	meta := &ImageMeta{
+		Format:      format,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Orientation: 1,
+	}
+
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return meta
+	}
+
+	if tag, err := x.Get(exif.Orientation); err == nil {
+		if v, err := tag.Int(0); err == nil && v >= 1 && v <= 8 {
+			meta.Orientation = v
+		}
+	}
+	// Orientations 5-8 are displayed rotated by 90 degrees
+	if meta.Orientation >= 5 {
+		meta.Width, meta.Height = meta.Height, meta.Width
+	}
+
+	if tag, err := x.Get(exif.Make); err == nil {
+		if v, err := tag.StringVal(); err == nil {
+			meta.CameraMake = strings.TrimSpace(v)
+		}
+	}
+	if tag, err := x.Get(exif.Model); err == nil {
+		if v, err := tag.StringVal(); err == nil {
+			meta.CameraModel = strings.TrimSpace(v)
+		}
+	}
+	if v, err := x.DateTime(); err == nil {
+		meta.TakenAt = &v
+	}
+	if lat, long, err := x.LatLong(); err == nil {
+		meta.Latitude = &lat
+		meta.Longitude = &long
+	}
+	return meta
+}
+
+// PrepareImage - Copy image metadata into the item and rotate or strip the image as requested
+func PrepareImage(data []byte, item *schema.File, strip, rotate bool) ([]byte, error) {
+	meta := ReadImageMeta(data)
+	if meta == nil {
+		return data, nil
+	}
+	item.Width = meta.Width
+	item.Height = meta.Height
+	item.Orientation = meta.Orientation
+	item.CameraMake = meta.CameraMake
+	item.CameraModel = meta.CameraModel
+	item.TakenAt = meta.TakenAt
+	item.Latitude = meta.Latitude
+	item.Longitude = meta.Longitude
+
+	data, err := ProcessJPEG(data, meta, strip, rotate)
+	if err != nil {
+		return nil, err
+	}
+	item.Filesize = int64(len(data))
+	return data, nil
+}
+
+// ProcessJPEG - Rotate a jpeg by its EXIF orientation and/or strip its metadata
+// segments (EXIF including GPS, XMP, IPTC and comments). Stripping a rotated
+// image also rotates it, as the orientation tag is lost with the EXIF segment.
+func ProcessJPEG(data []byte, meta *ImageMeta, strip, rotate bool) ([]byte, error) {
+	if meta == nil || meta.Format != "jpeg" {
+		return data, nil
+	}
+
+	if (rotate || strip) && meta.Orientation > 1 {
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		// Re-encoding drops every metadata segment
+		buf := new(bytes.Buffer)
+		err = jpeg.Encode(buf, orientImage(img, meta.Orientation), &jpeg.Options{Quality: 90})
+		if err != nil {
+			return nil, err
+		}
+		meta.Orientation = 1
+		return buf.Bytes(), nil
+	}
+
+	if strip {
+		return stripJPEGMeta(data)
+	}
+	return data, nil
+}
+
+// Remove APP1 (EXIF/XMP), APP13 (IPTC) and COM segments from a jpeg
+func stripJPEGMeta(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errors.New("invalid jpeg data")
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+	buf.Write(data[:2])
+
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return nil, errors.New("invalid jpeg marker")
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Fill byte
+			i++
+			continue
+		}
+		if marker == 0xDA {
+			// Start of scan, the rest is entropy coded data
+			break
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + size
+		if size < 2 || end > len(data) {
+			return nil, errors.New("invalid jpeg segment")
+		}
+		if marker != 0xE1 && marker != 0xED && marker != 0xFE {
+			buf.Write(data[i:end])
+		}
+		i = end
+	}
+
+	buf.Write(data[i:])
+	return buf.Bytes(), nil
+}
+
+// Transform an image so that it is displayed upright for the given EXIF orientation
+func orientImage(img image.Image, orientation int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			default:
+				dx, dy = x, y
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Build a w x h jpeg carrying an EXIF segment with the given orientation
+func newOrientedJPEG(t *testing.T, w, h int, orientation uint16) []byte {
+	buf := new(bytes.Buffer)
+	err := jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil)
+	assert.Nil(t, err)
+
+	// Little endian TIFF header with a single IFD0 orientation entry
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0, 1, 0}
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(payload)+2))
+	app1 = append(app1, payload...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func TestReadImageMeta(t *testing.T) {
+	data := newOrientedJPEG(t, 4, 2, 6)
+
+	meta := ReadImageMeta(data)
+	assert.NotNil(t, meta)
+	assert.Equal(t, "jpeg", meta.Format)
+	assert.Equal(t, 6, meta.Orientation)
+	assert.Equal(t, 2, meta.Width)
+	assert.Equal(t, 4, meta.Height)
+	assert.Nil(t, meta.Latitude)
+
+	assert.Nil(t, ReadImageMeta([]byte("not an image")))
+}
+
+func TestProcessJPEG(t *testing.T) {
+	data := newOrientedJPEG(t, 4, 2, 1)
+
+	// Strip only removes the metadata segments
+	stripped, err := ProcessJPEG(data, ReadImageMeta(data), true, false)
+	assert.Nil(t, err)
+	assert.False(t, bytes.Contains(stripped, []byte("Exif")))
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(stripped))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, cfg.Width)
+
+	// Rotate re-encodes the image upright
+	data = newOrientedJPEG(t, 4, 2, 6)
+	meta := ReadImageMeta(data)
+	rotated, err := ProcessJPEG(data, meta, false, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, meta.Orientation)
+	cfg, err = jpeg.DecodeConfig(bytes.NewReader(rotated))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, cfg.Width)
+	assert.Equal(t, 4, cfg.Height)
+
+	// Without options the data is kept as is
+	kept, err := ProcessJPEG(data, ReadImageMeta(data), false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, data, kept)
+}
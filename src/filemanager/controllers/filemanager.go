@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"io"
 
 	"github.com/MayCMF/core/src/filemanager/schema"
 )
@@ -17,7 +18,15 @@ type IFile interface {
 	// Update data
 	Update(ctx context.Context, UUID string, item schema.File) (*schema.File, error)
 	// Delete data
-	Delete(ctx context.Context, UUID string) error
-	// Upload File
-	Upload(ctx context.Context, item schema.File) (*schema.File, error)
+	Delete(ctx context.Context, UUID string, force bool) error
+	// Upload File, content is stored once the scanners accept it
+	Upload(ctx context.Context, item schema.File, content io.Reader) (*schema.File, error)
+	// Upload several files, the batch is stored completely or not at all
+	UploadBatch(ctx context.Context, items schema.Files, contents []io.Reader) (schema.Files, error)
+	// Replace the content of a file
+	Replace(ctx context.Context, UUID string, item schema.File, content io.Reader) (*schema.File, error)
+	// Scan a quarantined file again
+	Scan(ctx context.Context, UUID string) (*schema.File, error)
+	// Increase the download counter
+	IncreaseDownloads(ctx context.Context, UUID string) error
 }
@@ -6,6 +6,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
 )
 
 // Check if path is exist
@@ -35,6 +41,54 @@ func CheckDir(dir string) (err error) {
 	return
 }
 
+// Build the storage path of a new upload below the public or the private directory,
+// the subdirectory can not leave the storage directory
+func StoragePath(private bool, dir, filename string) string {
+	cfg := config.Global().FileManager
+	root := cfg.Dir
+	if private {
+		root = cfg.PrivateDir
+	}
+	return path.Join(root, path.Clean("/"+dir), StorageName(filename))
+}
+
+// Build the stored name of an uploaded file, prefixed with the upload time and
+// a unique ID so that uploads of the same name never share their content
+func StorageName(filename string) string {
+	return time.Now().Format("20060102-1504") + "_" + util.MustUUID() + "_" + path.Base("/"+filename)
+}
+
+// Check that a path lies inside the public, private or quarantine directory
+func InStorage(p string) bool {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return false
+	}
+
+	cfg := config.Global().FileManager
+	for _, root := range []string{cfg.Dir, cfg.PrivateDir, cfg.QuarantineDir} {
+		if root == "" {
+			continue
+		}
+		rabs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(abs, rabs+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Open stored content, paths outside the storage directories are refused
+func OpenContent(p string) (*os.File, error) {
+	if !InStorage(p) {
+		return nil, &os.PathError{Op: "open", Path: p, Err: os.ErrPermission}
+	}
+	return os.Open(p)
+}
+
 // Read Directory and get file list
 func ReadDir(dir string) (files []string, err error) {
 	var (
@@ -268,3 +322,41 @@ func Stat(path string) (info os.FileInfo, err error) {
 func LStat(path string) (info os.FileInfo, err error) {
 	return os.Lstat(path)
 }
+
+// Save a stream to a file, creating its directory
+func SaveFile(filepath string, r io.Reader) (err error) {
+	if err = CheckDir(path.Dir(filepath)); err != nil {
+		return
+	}
+
+	file, err := os.Create(filepath)
+	if err != nil {
+		return
+	}
+	if _, err = io.Copy(file, r); err != nil {
+		file.Close()
+		return
+	}
+	return file.Close()
+}
+
+// Move a file, falling back to copy and remove across file systems
+func MoveFile(src, dst string) (err error) {
+	if err = CheckDir(path.Dir(dst)); err != nil {
+		return
+	}
+	if err = os.Rename(src, dst); err == nil {
+		return
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	err = SaveFile(dst, file)
+	file.Close()
+	if err != nil {
+		return
+	}
+	return os.Remove(src)
+}
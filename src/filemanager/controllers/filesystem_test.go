@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoragePath(t *testing.T) {
+	err := config.LoadGlobal("../../../configs/config.toml")
+	assert.Nil(t, err)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = "static"
+	cfg.FileManager.PrivateDir = "data/private"
+	cfg.FileManager.QuarantineDir = "data/quarantine"
+
+	p := StoragePath(false, "/images", "cat.jpg")
+	assert.True(t, strings.HasPrefix(p, "static/images/"))
+	assert.True(t, strings.HasSuffix(p, "_cat.jpg"))
+	assert.True(t, InStorage(p))
+
+	// Uploads of the same name are stored apart
+	assert.NotEqual(t, p, StoragePath(false, "/images", "cat.jpg"))
+
+	// Neither the subdirectory nor the file name leave the storage directory
+	p = StoragePath(true, "../../etc", "../passwd")
+	assert.True(t, strings.HasPrefix(p, "data/private/etc/"))
+	assert.True(t, strings.HasSuffix(p, "_passwd"))
+	assert.True(t, InStorage(p))
+
+	assert.True(t, InStorage("data/quarantine/3f2504e0"))
+	assert.False(t, InStorage("/etc/passwd"))
+	assert.False(t, InStorage("static/../configs/config.toml"))
+	assert.False(t, InStorage("static"))
+	assert.False(t, InStorage("static-old/cat.jpg"))
+
+	_, err = OpenContent("/etc/passwd")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IFolder - Folder business logic interface
+type IFolder interface {
+	// Query data
+	Query(ctx context.Context, params schema.FolderQueryParam, opts ...schema.FolderQueryOptions) (*schema.FolderQueryResult, error)
+	// Get specified data
+	Get(ctx context.Context, UUID string, opts ...schema.FolderQueryOptions) (*schema.Folder, error)
+	// Create data
+	Create(ctx context.Context, item schema.Folder) (*schema.Folder, error)
+	// Update data (changing the parent moves the folder with its subtree)
+	Update(ctx context.Context, UUID string, item schema.Folder) (*schema.Folder, error)
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+}
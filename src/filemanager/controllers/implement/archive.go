@@ -0,0 +1,415 @@
+package implement
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/model"
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// NewArchive - Create a bulk archive management instance
+func NewArchive(
+	bFile controllers.IFile,
+	mFile model.IFile,
+	mFolder model.IFolder,
+	mFileJob model.IFileJob,
+) *Archive {
+	return &Archive{
+		FileBll:      bFile,
+		FileModel:    mFile,
+		FolderModel:  mFolder,
+		FileJobModel: mFileJob,
+	}
+}
+
+// Archive - Bulk zip upload and download of files
+type Archive struct {
+	FileBll      controllers.IFile
+	FileModel    model.IFile
+	FolderModel  model.IFolder
+	FileJobModel model.IFileJob
+}
+
+// Entry of a zip download
+type archiveEntry struct {
+	name string
+	file *schema.File
+}
+
+// GetJob - Get specified job of the current user
+func (a *Archive) GetJob(ctx context.Context, UUID string) (*schema.FileJob, error) {
+	return getOwnFileJob(ctx, a.FileJobModel, UUID)
+}
+
+// RemoveResult - Remove the archive built by a job of the current user once it is downloaded
+func (a *Archive) RemoveResult(ctx context.Context, UUID string) error {
+	job, err := getOwnFileJob(ctx, a.FileJobModel, UUID)
+	if err != nil {
+		return err
+	} else if job.Result == "" {
+		return nil
+	}
+
+	if err := os.Remove(job.Result); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	job.Result = ""
+	updateFileJob(ctx, a.FileJobModel, job)
+	return nil
+}
+
+// Directory of the archives built by jobs
+func archiveDir() string {
+	return path.Join(config.Global().FileManager.JobDir, "archives")
+}
+
+// Remove built archives older than the job lifetime, they are only kept until
+// they are downloaded
+func sweepArchives(ctx context.Context) {
+	expired := config.Global().FileManager.JobExpired
+	if expired <= 0 {
+		return
+	}
+
+	dir := archiveDir()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, fi := range files {
+		if fi.IsDir() || time.Since(fi.ModTime()) < time.Duration(expired)*time.Second {
+			continue
+		}
+		if err := os.Remove(path.Join(dir, fi.Name())); err != nil && !os.IsNotExist(err) {
+			logger.Errorf(ctx, "Remove expired archive %s error: %s", fi.Name(), err.Error())
+		}
+	}
+}
+
+// Check a zip entry name and return it cleaned, rejecting absolute paths
+// and paths escaping the archive root (zip slip)
+func safeEntryName(name string) (string, error) {
+	name = strings.Replace(name, "\\", "/", -1)
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") || strings.Contains(clean, ":") {
+		return "", errors.New400Response("Unsafe archive entry path " + name)
+	}
+	return clean, nil
+}
+
+// Extract - Extract an uploaded zip archive into the library in the background,
+// every entry goes through the same checks as a single upload
+func (a *Archive) Extract(ctx context.Context, creator string, params schema.FileExtractParam, zipPath string) (*schema.FileJob, error) {
+	cfg := config.Global().FileManager
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		_ = os.Remove(zipPath)
+		return nil, errors.New400Response("Invalid zip archive")
+	}
+
+	total := 0
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			total++
+		}
+	}
+	zr.Close()
+
+	if cfg.ArchiveFiles > 0 && total > cfg.ArchiveFiles {
+		_ = os.Remove(zipPath)
+		return nil, errors.New400Response("Too many files in the archive, the limit is " + strconv.Itoa(cfg.ArchiveFiles))
+	}
+
+	job, err := createFileJob(ctx, a.FileJobModel, schema.FileJobTypeExtract, creator, total)
+	if err != nil {
+		_ = os.Remove(zipPath)
+		return nil, err
+	}
+
+	go a.runExtract(jobContext(ctx), *job, params, zipPath)
+	return job, nil
+}
+
+func (a *Archive) runExtract(ctx context.Context, job schema.FileJob, params schema.FileExtractParam, zipPath string) {
+	defer os.Remove(zipPath)
+
+	job.Status = schema.FileJobStatusRunning
+	updateFileJob(ctx, a.FileJobModel, &job)
+
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		job.Status = schema.FileJobStatusFailed
+		job.Errors = append(job.Errors, err.Error())
+		updateFileJob(ctx, a.FileJobModel, &job)
+		return
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		if err := a.extractEntry(ctx, f, params); err != nil {
+			job.Failed++
+			job.Errors = append(job.Errors, fmt.Sprintf("%s: %s", f.Name, err.Error()))
+		}
+		job.Processed++
+		updateFileJob(ctx, a.FileJobModel, &job)
+	}
+
+	job.Status = schema.FileJobStatusDone
+	updateFileJob(ctx, a.FileJobModel, &job)
+}
+
+func (a *Archive) extractEntry(ctx context.Context, f *zip.File, params schema.FileExtractParam) error {
+	name, err := safeEntryName(f.Name)
+	if err != nil {
+		return err
+	}
+
+	// The declared size can not be trusted, reading is capped as well
+	maxSize := config.Global().FileManager.MaxSize
+	if int64(f.UncompressedSize64) > maxSize {
+		return errors.New400Response("Upload file too large, The max upload limit is " + strconv.FormatInt(maxSize, 10))
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(rc, maxSize+1))
+	rc.Close()
+	if err != nil {
+		return err
+	} else if int64(len(data)) > maxSize {
+		return errors.New400Response("Upload file too large, The max upload limit is " + strconv.FormatInt(maxSize, 10))
+	}
+
+	filename := path.Base(name)
+	item := schema.File{
+		UID:      params.UID,
+		FolderID: params.FolderID,
+		Tags:     params.Tags,
+		Private:  params.Private,
+		Filename: filename,
+		FileExt:  path.Ext(filename),
+		Filesize: int64(len(data)),
+		Filemime: controllers.DetectMime(filename, data),
+		Uri:      controllers.StoragePath(params.Private, "", filename),
+	}
+
+	if strings.HasPrefix(item.Filemime, "image/") {
+		data, err = controllers.PrepareImage(data, &item, params.StripExif, params.AutoRotate)
+		if err != nil {
+			return err
+		}
+	} else {
+		controllers.PrepareMedia(bytes.NewReader(data), int64(len(data)), &item)
+	}
+
+	_, err = a.FileBll.Upload(ctx, item, bytes.NewReader(data))
+	return err
+}
+
+// Collect the files of a selection with their names inside the archive
+func (a *Archive) collect(ctx context.Context, params schema.FileArchiveParam) ([]*archiveEntry, error) {
+	var entries []*archiveEntry
+	names := make(map[string]int)
+	add := func(dir string, file *schema.File) {
+		// Private files are only served through their own download endpoint
+		if file.Private {
+			return
+		}
+
+		name := path.Join(dir, file.Filename)
+		if n := names[name]; n > 0 {
+			ext := path.Ext(name)
+			names[name]++
+			name = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), n, ext)
+		} else {
+			names[name] = 1
+		}
+		entries = append(entries, &archiveEntry{name: name, file: file})
+	}
+
+	if len(params.UUIDs) > 0 {
+		result, err := a.FileModel.Query(ctx, schema.FileQueryParam{
+			UUIDs: params.UUIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range result.Data {
+			add("", file)
+		}
+	}
+
+	if params.FolderID != "" {
+		folder, err := a.FolderModel.Get(ctx, params.FolderID)
+		if err != nil {
+			return nil, err
+		} else if folder == nil {
+			return nil, errors.New400Response("Folder does not exist")
+		}
+
+		ppath := folder.UUID
+		if folder.ParentPath != "" {
+			ppath = folder.ParentPath + "/" + folder.UUID
+		}
+		result, err := a.FolderModel.Query(ctx, schema.FolderQueryParam{
+			PrefixParentPath: ppath,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		byUUID := make(map[string]*schema.Folder)
+		for _, item := range result.Data {
+			byUUID[item.UUID] = item
+		}
+
+		// Subfolders become directories of the archive
+		dirs := map[string]string{folder.UUID: ""}
+		var dirOf func(UUID string) string
+		dirOf = func(UUID string) string {
+			if dir, ok := dirs[UUID]; ok {
+				return dir
+			}
+			item, ok := byUUID[UUID]
+			if !ok {
+				return ""
+			}
+			dirs[UUID] = path.Join(dirOf(item.ParentID), item.Name)
+			return dirs[UUID]
+		}
+
+		for _, item := range append([]*schema.Folder{folder}, result.Data...) {
+			fileResult, err := a.FileModel.Query(ctx, schema.FileQueryParam{
+				FolderID: &item.UUID,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range fileResult.Data {
+				add(dirOf(item.UUID), file)
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New400Response("No files selected")
+	}
+	return entries, nil
+}
+
+func (a *Archive) write(w io.Writer, entries []*archiveEntry, progress func(err error)) error {
+	zw := zip.NewWriter(w)
+	for _, entry := range entries {
+		err := a.writeEntry(zw, entry)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if progress != nil {
+			progress(err)
+		}
+	}
+	return zw.Close()
+}
+
+func (a *Archive) writeEntry(zw *zip.Writer, entry *archiveEntry) error {
+	f, err := controllers.OpenContent(entry.file.Uri)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fh := &zip.FileHeader{
+		Name:     entry.name,
+		Method:   zip.Deflate,
+		Modified: entry.file.CreatedAt,
+	}
+	w, err := zw.CreateHeader(fh)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// Write - Stream a zip archive of the selected files
+func (a *Archive) Write(ctx context.Context, w io.Writer, params schema.FileArchiveParam) error {
+	entries, err := a.collect(ctx, params)
+	if err != nil {
+		return err
+	}
+	return a.write(w, entries, nil)
+}
+
+// Build - Build a zip archive of the selected files in the background
+func (a *Archive) Build(ctx context.Context, creator string, params schema.FileArchiveParam) (*schema.FileJob, error) {
+	entries, err := a.collect(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := createFileJob(ctx, a.FileJobModel, schema.FileJobTypeArchive, creator, len(entries))
+	if err != nil {
+		return nil, err
+	}
+
+	sweepArchives(ctx)
+	go a.runBuild(jobContext(ctx), *job, entries)
+	return job, nil
+}
+
+func (a *Archive) runBuild(ctx context.Context, job schema.FileJob, entries []*archiveEntry) {
+	job.Status = schema.FileJobStatusRunning
+	updateFileJob(ctx, a.FileJobModel, &job)
+
+	zipPath := path.Join(archiveDir(), job.UUID+".zip")
+	err := controllers.CheckDir(path.Dir(zipPath))
+	if err == nil {
+		var f *os.File
+		f, err = os.Create(zipPath)
+		if err == nil {
+			err = a.write(f, entries, func(err error) {
+				job.Processed++
+				if err != nil {
+					// The stored file has gone missing, it is left out
+					job.Failed++
+					job.Errors = append(job.Errors, err.Error())
+				}
+				updateFileJob(ctx, a.FileJobModel, &job)
+			})
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}
+
+	if err != nil {
+		_ = os.Remove(zipPath)
+		job.Status = schema.FileJobStatusFailed
+		job.Errors = append(job.Errors, err.Error())
+	} else {
+		job.Status = schema.FileJobStatusDone
+		job.Result = zipPath
+	}
+	updateFileJob(ctx, a.FileJobModel, &job)
+}
@@ -2,26 +2,62 @@ package implement
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
 	"strconv"
+	"strings"
 
+	acontrollers "github.com/MayCMF/core/src/audit/controllers"
+	aschema "github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common"
 	"github.com/MayCMF/core/src/common/config"
 	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
 	commonschema "github.com/MayCMF/core/src/common/schema"
 	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/controllers"
 	"github.com/MayCMF/core/src/filemanager/model"
+	"github.com/MayCMF/core/src/filemanager/scanner"
 	"github.com/MayCMF/core/src/filemanager/schema"
+	i18n "github.com/MayCMF/core/src/i18n/model"
+	transaction "github.com/MayCMF/core/src/transaction/model"
 )
 
 // NewFile - Create a File
-func NewFile(mFile model.IFile) *File {
+func NewFile(
+	trans transaction.ITrans,
+	mFile model.IFile,
+	mFolder model.IFolder,
+	mFileUsage model.IFileUsage,
+	mFileText model.IFileText,
+	mLanguage i18n.ILanguage,
+	sChain *scanner.Chain,
+	bAudit acontrollers.IAudit,
+) *File {
 	return &File{
-		FileModel: mFile,
+		TransModel:     trans,
+		FileModel:      mFile,
+		FolderModel:    mFolder,
+		FileUsageModel: mFileUsage,
+		FileTextModel:  mFileText,
+		LanguageModel:  mLanguage,
+		Scanner:        sChain,
+		AuditBll:       bAudit,
 	}
 }
 
 // File - Sample program
 type File struct {
-	FileModel model.IFile
+	TransModel     transaction.ITrans
+	FileModel      model.IFile
+	FolderModel    model.IFolder
+	FileUsageModel model.IFileUsage
+	FileTextModel  model.IFileText
+	LanguageModel  i18n.ILanguage
+	Scanner        *scanner.Chain
+	AuditBll       acontrollers.IAudit
 }
 
 // Query - Query data
@@ -78,8 +114,70 @@ func (a *File) checkFileSize(ctx context.Context, Filesize int64) error {
 	return nil
 }
 
+func (a *File) checkFolder(ctx context.Context, folderID string) error {
+	if folderID == "" {
+		return nil
+	}
+
+	folder, err := a.FolderModel.Get(ctx, folderID)
+	if err != nil {
+		return err
+	} else if folder == nil {
+		return errors.New400Response("Folder does not exist")
+	}
+	return nil
+}
+
+func (a *File) checkTranslations(ctx context.Context, translations schema.FileTranslations) error {
+	for lang := range translations.ToMap() {
+		language, err := a.LanguageModel.Get(ctx, lang)
+		if err != nil {
+			return err
+		} else if language == nil {
+			return errors.New400Response("Unknown language " + lang)
+		}
+	}
+	return nil
+}
+
+func (a *File) checkLibrary(ctx context.Context, item schema.File) error {
+	if err := a.checkFolder(ctx, item.FolderID); err != nil {
+		return err
+	}
+	return a.checkTranslations(ctx, item.Translations)
+}
+
+// Get a file with its tags and translations for the audit log
+func (a *File) getAuditItem(ctx context.Context, UUID string) (*schema.File, error) {
+	return a.FileModel.Get(ctx, UUID, schema.FileQueryOptions{
+		IncludeTags:         true,
+		IncludeTranslations: true,
+	})
+}
+
+// Record an action on a file, the file is read again unless it is deleted
+func (a *File) recordAudit(ctx context.Context, action, UUID string, oldItem *schema.File) (*schema.File, error) {
+	var nitem *schema.File
+	if action != aschema.AuditActionDelete {
+		var err error
+		nitem, err = a.getUpdate(ctx, UUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := a.AuditBll.Record(ctx, aschema.AuditEntityFile, action, UUID, oldItem, nitem)
+	if err != nil {
+		return nil, err
+	}
+	return nitem, nil
+}
+
 func (a *File) getUpdate(ctx context.Context, UUID string) (*schema.File, error) {
-	return a.Get(ctx, UUID)
+	return a.Get(ctx, UUID, schema.FileQueryOptions{
+		IncludeTags:         true,
+		IncludeTranslations: true,
+	})
 }
 
 // Create - Create File data
@@ -89,16 +187,36 @@ func (a *File) Create(ctx context.Context, item schema.File) (*schema.File, erro
 		return nil, err
 	}
 
+	err = a.checkLibrary(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	// The storage location and mime type are never taken from the request, and
+	// a record without uploaded content stays in quarantine as no scanner has seen it
 	item.UUID = util.MustUUID()
-	err = a.FileModel.Create(ctx, item)
+	item.Uri = controllers.StoragePath(item.Private, "", item.Filename)
+	item.Filemime = controllers.DetectMime(item.Filename, nil)
+	item.ScanStatus = schema.ScanStatusQuarantine
+	item.ScanThreat = ""
+	var nitem *schema.File
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.FileModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.recordAudit(ctx, aschema.AuditActionCreate, item.UUID, nil)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, item.UUID)
+	return nitem, nil
 }
 
-// Upload - Upload File
-func (a *File) Upload(ctx context.Context, item schema.File) (*schema.File, error) {
+// Upload - Upload File, the content is quarantined until the scanners accept it
+func (a *File) Upload(ctx context.Context, item schema.File, content io.Reader) (*schema.File, error) {
 
 	err := a.checkFileExt(ctx, item.FileExt)
 	if err != nil {
@@ -110,19 +228,265 @@ func (a *File) Upload(ctx context.Context, item schema.File) (*schema.File, erro
 		return nil, err
 	}
 
+	err = a.checkLibrary(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
 	// item.UID = getUserID(item.UserUUID)
 	item.UUID = util.MustUUID()
-	err = a.FileModel.Create(ctx, item)
+	item.ScanStatus = schema.ScanStatusQuarantine
+	qpath := quarantinePath(item.UUID)
+	if err := controllers.SaveFile(qpath, content); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The record is only kept when the upload gets through the scanners, or
+	// as the record of an infected upload
+	var nitem *schema.File
+	var rejected error
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.FileModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		err = a.scan(ctx, &item)
+		if err != nil {
+			if item.ScanStatus == schema.ScanStatusInfected {
+				rejected = err
+				return nil
+			}
+			return err
+		}
+
+		nitem, err = a.recordAudit(ctx, aschema.AuditActionCreate, item.UUID, nil)
+		return err
+	})
+	if err != nil {
+		_ = os.Remove(contentPath(&item))
+		return nil, err
+	} else if rejected != nil {
+		return nil, rejected
+	}
+	return nitem, nil
+}
+
+// UploadBatch - Upload several files, the batch is stored completely or not at all
+func (a *File) UploadBatch(ctx context.Context, items schema.Files, contents []io.Reader) (schema.Files, error) {
+	var nitems schema.Files
+	err := common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		for i, item := range items {
+			nitem, err := a.Upload(ctx, *item, contents[i])
+			if err != nil {
+				return err
+			}
+			nitems = append(nitems, nitem)
+		}
+		return nil
+	})
 	if err != nil {
+		// The records are rolled back, so the content stored so far goes as well
+		for _, item := range nitems {
+			_ = os.Remove(contentPath(item))
+		}
 		return nil, err
 	}
+	return nitems, nil
+}
+
+func quarantinePath(UUID string) string {
+	return path.Join(config.Global().FileManager.QuarantineDir, UUID)
+}
+
+// Location of the stored content of a file, quarantined content is kept under the file UUID
+func contentPath(item *schema.File) string {
+	if item.ScanStatus == schema.ScanStatusQuarantine {
+		return quarantinePath(item.UUID)
+	}
+	return item.Uri
+}
+
+// Set stored content aside under a temporary name, so that it can be put back
+// when the records can not be changed. Returns an empty path without content
+// or with a location outside the storage directories.
+func setAside(filepath string) (string, error) {
+	if filepath == "" || !controllers.InStorage(filepath) || !controllers.PathExists(filepath) {
+		return "", nil
+	}
+	aside := filepath + ".aside"
+	if err := os.Rename(filepath, aside); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return aside, nil
+}
+
+// Put content set aside back to its location
+func putBack(aside, filepath string) {
+	if aside != "" {
+		_ = os.Rename(aside, filepath)
+	}
+}
+
+// Run the scanner chain on a quarantined file, release it when clean and
+// remove its content when infected. On scanner failure the file stays in
+// quarantine so it can be scanned again.
+func (a *File) scan(ctx context.Context, item *schema.File) error {
+	qpath := quarantinePath(item.UUID)
+	result, err := a.Scanner.Scan(ctx, item, qpath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if result.Infected {
+		_ = os.Remove(qpath)
+		logger.StartSpan(ctx, logger.SetSpanTitle("File scan"), logger.SetSpanFuncName("scan")).
+			Warnf("Rejected upload %s (%s): %s reported by %s", item.UUID, item.Filename, result.Threat, result.Scanner)
+
+		err = a.FileModel.UpdateScanStatus(ctx, item.UUID, schema.ScanStatusInfected, result.Threat, "")
+		if err != nil {
+			return err
+		}
+		item.ScanStatus = schema.ScanStatusInfected
+		return errors.New400Response("File rejected by " + result.Scanner + ": " + result.Threat)
+	}
+
+	if err := controllers.MoveFile(qpath, item.Uri); err != nil {
+		return errors.WithStack(err)
+	}
+	err = a.FileModel.UpdateScanStatus(ctx, item.UUID, schema.ScanStatusClean, "", item.Uri)
+	if err != nil {
+		// The record still points to quarantine, so the content goes back there
+		_ = controllers.MoveFile(item.Uri, qpath)
+		return err
+	}
+	item.ScanStatus = schema.ScanStatusClean
 
-	return a.getUpdate(ctx, item.UUID)
+	indexText(ctx, a.FileTextModel, item)
+	return nil
+}
+
+// Scan - Scan a file left in quarantine again
+func (a *File) Scan(ctx context.Context, UUID string) (*schema.File, error) {
+	item, err := a.FileModel.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	} else if item.ScanStatus != schema.ScanStatusQuarantine {
+		return nil, errors.New400Response("File is not in quarantine")
+	} else if !controllers.PathExists(quarantinePath(UUID)) {
+		return nil, errors.New400Response("File has no uploaded content")
+	}
+
+	err = a.scan(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	return a.getUpdate(ctx, UUID)
+}
+
+// Replace - Replace the content of a file, the current content is kept until
+// the new one has passed the scanners and is stored
+func (a *File) Replace(ctx context.Context, UUID string, item schema.File, content io.Reader) (*schema.File, error) {
+	oldItem, err := a.getAuditItem(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if oldItem == nil {
+		return nil, errors.ErrNotFound
+	} else if oldItem.ScanStatus == schema.ScanStatusQuarantine {
+		return nil, errors.New400Response("File is in quarantine")
+	}
+
+	err = a.checkFileExt(ctx, item.FileExt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.checkFileSize(ctx, item.Filesize)
+	if err != nil {
+		return nil, err
+	}
+
+	item.UUID = UUID
+	tpath := quarantinePath(UUID) + ".replace"
+	if err := controllers.SaveFile(tpath, content); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.Remove(tpath)
+
+	result, err := a.Scanner.Scan(ctx, &item, tpath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if result.Infected {
+		logger.StartSpan(ctx, logger.SetSpanTitle("File scan"), logger.SetSpanFuncName("Replace")).
+			Warnf("Rejected replacement of %s (%s): %s reported by %s", UUID, item.Filename, result.Threat, result.Scanner)
+		return nil, errors.New400Response("File rejected by " + result.Scanner + ": " + result.Threat)
+	}
+
+	// The new content is stored next to the current one
+	if controllers.InStorage(oldItem.Uri) {
+		item.Uri = path.Join(path.Dir(oldItem.Uri), controllers.StorageName(item.Filename))
+	} else {
+		item.Uri = controllers.StoragePath(oldItem.Private, "", item.Filename)
+	}
+	item.ScanStatus = schema.ScanStatusClean
+	item.ScanThreat = ""
+
+	aside, err := setAside(oldItem.Uri)
+	if err != nil {
+		return nil, err
+	}
+	if err := controllers.MoveFile(tpath, item.Uri); err != nil {
+		putBack(aside, oldItem.Uri)
+		return nil, errors.WithStack(err)
+	}
+
+	var nitem *schema.File
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.FileModel.UpdateContent(ctx, UUID, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.recordAudit(ctx, aschema.AuditActionUpdate, UUID, oldItem)
+		return err
+	})
+	if err != nil {
+		_ = os.Remove(item.Uri)
+		putBack(aside, oldItem.Uri)
+		return nil, err
+	}
+	if aside != "" {
+		_ = os.Remove(aside)
+	}
+
+	indexText(ctx, a.FileTextModel, &item)
+	return nitem, nil
+}
+
+// Move a stored file between the public and the private directory, so that
+// a file made private can no longer be fetched from the public directory
+func (a *File) moveStorage(uri string, private bool) (string, error) {
+	cfg := config.Global().FileManager
+	from, to := cfg.Dir, cfg.PrivateDir
+	if !private {
+		from, to = to, from
+	}
+	if !strings.HasPrefix(uri, from+"/") || !controllers.InStorage(uri) || !controllers.PathExists(uri) {
+		return uri, nil
+	}
+
+	nuri := to + strings.TrimPrefix(uri, from)
+	if err := controllers.MoveFile(uri, nuri); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return nuri, nil
 }
 
 // Update - Update File data
 func (a *File) Update(ctx context.Context, UUID string, item schema.File) (*schema.File, error) {
-	oldItem, err := a.FileModel.Get(ctx, UUID)
+	oldItem, err := a.getAuditItem(ctx, UUID)
 	if err != nil {
 		return nil, err
 	} else if oldItem == nil {
@@ -134,21 +498,100 @@ func (a *File) Update(ctx context.Context, UUID string, item schema.File) (*sche
 		}
 	}
 
-	err = a.FileModel.Update(ctx, UUID, item)
+	err = a.checkLibrary(ctx, item)
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, UUID)
+
+	// The storage location only changes along with the private flag, and the
+	// mime type only along with the content
+	item.Uri = oldItem.Uri
+	item.Filemime = oldItem.Filemime
+	if oldItem.Private != item.Private && oldItem.ScanStatus != schema.ScanStatusQuarantine {
+		uri, err := a.moveStorage(oldItem.Uri, item.Private)
+		if err != nil {
+			return nil, err
+		}
+		item.Uri = uri
+	}
+
+	var nitem *schema.File
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.FileModel.Update(ctx, UUID, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.recordAudit(ctx, aschema.AuditActionUpdate, UUID, oldItem)
+		return err
+	})
+	if err != nil {
+		// The stored file goes back where the record still points to
+		if item.Uri != oldItem.Uri {
+			_ = controllers.MoveFile(item.Uri, oldItem.Uri)
+		}
+		return nil, err
+	}
+	return nitem, nil
 }
 
-// Delete - Delete data
-func (a *File) Delete(ctx context.Context, UUID string) error {
-	oldItem, err := a.FileModel.Get(ctx, UUID)
+// IncreaseDownloads - Increase the download counter
+func (a *File) IncreaseDownloads(ctx context.Context, UUID string) error {
+	return a.FileModel.IncreaseDownloads(ctx, UUID)
+}
+
+// Delete - Delete data, files still in use are only deleted when forced
+func (a *File) Delete(ctx context.Context, UUID string, force bool) error {
+	oldItem, err := a.getAuditItem(ctx, UUID)
 	if err != nil {
 		return err
 	} else if oldItem == nil {
 		return errors.ErrNotFound
 	}
 
-	return a.FileModel.Delete(ctx, UUID)
+	if !force {
+		result, err := a.FileUsageModel.Query(ctx, schema.FileUsageQueryParam{
+			FileID: UUID,
+		}, schema.FileUsageQueryOptions{
+			PageParam: &commonschema.PaginationParam{PageSize: -1},
+		})
+		if err != nil {
+			return err
+		} else if result.PageResult.Total > 0 {
+			return errors.NewResponse(409, fmt.Sprintf("File is in use (%d usages), delete it with force to remove it anyway", result.PageResult.Total), 409)
+		}
+	}
+
+	// The content is removed once the records are gone
+	cpath := contentPath(oldItem)
+	aside, err := setAside(cpath)
+	if err != nil {
+		return err
+	}
+
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.FileUsageModel.DeleteByFile(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		err = a.FileTextModel.Delete(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		err = a.FileModel.Delete(ctx, UUID)
+		if err != nil {
+			return err
+		}
+
+		_, err = a.recordAudit(ctx, aschema.AuditActionDelete, UUID, oldItem)
+		return err
+	})
+	if err != nil {
+		putBack(aside, cpath)
+		return err
+	}
+	if aside != "" {
+		_ = os.Remove(aside)
+	}
+	return nil
 }
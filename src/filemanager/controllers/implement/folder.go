@@ -0,0 +1,201 @@
+package implement
+
+import (
+	"context"
+	"strings"
+
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/errors"
+	commonschema "github.com/MayCMF/core/src/common/schema"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/model"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	transaction "github.com/MayCMF/core/src/transaction/model"
+)
+
+// NewFolder - Create a Folder management instance
+func NewFolder(
+	trans transaction.ITrans,
+	mFolder model.IFolder,
+	mFile model.IFile,
+) *Folder {
+	return &Folder{
+		TransModel:  trans,
+		FolderModel: mFolder,
+		FileModel:   mFile,
+	}
+}
+
+// Folder - Manage media library folders
+type Folder struct {
+	TransModel  transaction.ITrans
+	FolderModel model.IFolder
+	FileModel   model.IFile
+}
+
+// Query - Query data
+func (a *Folder) Query(ctx context.Context, params schema.FolderQueryParam, opts ...schema.FolderQueryOptions) (*schema.FolderQueryResult, error) {
+	return a.FolderModel.Query(ctx, params, opts...)
+}
+
+// Get - Get specified data
+func (a *Folder) Get(ctx context.Context, UUID string, opts ...schema.FolderQueryOptions) (*schema.Folder, error) {
+	item, err := a.FolderModel.Get(ctx, UUID, opts...)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+func (a *Folder) getSep() string {
+	return "/"
+}
+
+func (a *Folder) joinParentPath(ppath, code string) string {
+	if ppath != "" {
+		ppath += a.getSep()
+	}
+	return ppath + code
+}
+
+// Get the parent path
+func (a *Folder) getParentPath(ctx context.Context, parentID string) (string, error) {
+	if parentID == "" {
+		return "", nil
+	}
+
+	pitem, err := a.FolderModel.Get(ctx, parentID)
+	if err != nil {
+		return "", err
+	} else if pitem == nil {
+		return "", errors.ErrInvalidParent
+	}
+
+	return a.joinParentPath(pitem.ParentPath, pitem.UUID), nil
+}
+
+func (a *Folder) checkName(ctx context.Context, item schema.Folder) error {
+	result, err := a.FolderModel.Query(ctx, schema.FolderQueryParam{
+		ParentID: &item.ParentID,
+		Name:     item.Name,
+	}, schema.FolderQueryOptions{
+		PageParam: &commonschema.PaginationParam{PageSize: -1},
+	})
+	if err != nil {
+		return err
+	} else if result.PageResult.Total > 0 {
+		return errors.New400Response("Folder name is already exists")
+	}
+	return nil
+}
+
+// Create - Create Folder
+func (a *Folder) Create(ctx context.Context, item schema.Folder) (*schema.Folder, error) {
+	if err := a.checkName(ctx, item); err != nil {
+		return nil, err
+	}
+
+	parentPath, err := a.getParentPath(ctx, item.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	item.ParentPath = parentPath
+	item.UUID = util.MustUUID()
+	err = a.FolderModel.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Get(ctx, item.UUID)
+}
+
+// Update - Update Folder
+func (a *Folder) Update(ctx context.Context, UUID string, item schema.Folder) (*schema.Folder, error) {
+	if UUID == item.ParentID {
+		return nil, errors.ErrInvalidParent
+	}
+
+	oldItem, err := a.FolderModel.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if oldItem == nil {
+		return nil, errors.ErrNotFound
+	} else if oldItem.Name != item.Name || oldItem.ParentID != item.ParentID {
+		if err := a.checkName(ctx, item); err != nil {
+			return nil, err
+		}
+	}
+	item.ParentPath = oldItem.ParentPath
+
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		// If the parent is updated, you need to update the current node and the parent path below the node.
+		if item.ParentID != oldItem.ParentID {
+			parentPath, err := a.getParentPath(ctx, item.ParentID)
+			if err != nil {
+				return err
+			}
+
+			// A folder cannot be moved into its own subtree
+			opath := a.joinParentPath(oldItem.ParentPath, oldItem.UUID)
+			if strings.HasPrefix(parentPath+a.getSep(), opath+a.getSep()) {
+				return errors.ErrInvalidParent
+			}
+			item.ParentPath = parentPath
+
+			result, err := a.FolderModel.Query(ctx, schema.FolderQueryParam{
+				PrefixParentPath: opath,
+			})
+			if err != nil {
+				return err
+			}
+
+			npath := a.joinParentPath(item.ParentPath, UUID)
+			for _, folder := range result.Data {
+				npath2 := npath + folder.ParentPath[len(opath):]
+				err = a.FolderModel.UpdateParentPath(ctx, folder.UUID, npath2)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return a.FolderModel.Update(ctx, UUID, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.Get(ctx, UUID)
+}
+
+// Delete - Delete Folder, only empty folders can be deleted
+func (a *Folder) Delete(ctx context.Context, UUID string) error {
+	oldItem, err := a.FolderModel.Get(ctx, UUID)
+	if err != nil {
+		return err
+	} else if oldItem == nil {
+		return errors.ErrNotFound
+	}
+
+	result, err := a.FolderModel.Query(ctx, schema.FolderQueryParam{
+		ParentID: &UUID,
+	}, schema.FolderQueryOptions{PageParam: &commonschema.PaginationParam{PageSize: -1}})
+	if err != nil {
+		return err
+	} else if result.PageResult.Total > 0 {
+		return errors.ErrNotAllowDeleteWithChild
+	}
+
+	fileResult, err := a.FileModel.Query(ctx, schema.FileQueryParam{
+		FolderID: &UUID,
+	}, schema.FileQueryOptions{PageParam: &commonschema.PaginationParam{PageSize: -1}})
+	if err != nil {
+		return err
+	} else if fileResult.PageResult.Total > 0 {
+		return errors.ErrNotAllowDeleteWithChild
+	}
+
+	return a.FolderModel.Delete(ctx, UUID)
+}
@@ -0,0 +1,81 @@
+package implement
+
+import (
+	"context"
+
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/model"
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+func getFileJob(ctx context.Context, mFileJob model.IFileJob, UUID string) (*schema.FileJob, error) {
+	item, err := mFileJob.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+// Get a job of the current user, jobs of other users do not exist for them
+func getOwnFileJob(ctx context.Context, mFileJob model.IFileJob, UUID string) (*schema.FileJob, error) {
+	item, err := getFileJob(ctx, mFileJob, UUID)
+	if err != nil {
+		return nil, err
+	} else if userUUID, _ := icontext.FromUserUUID(ctx); item.Creator != userUUID {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+// Context of a background job, it outlives the request but keeps its user
+// and trace for the audit log and the logs
+func jobContext(ctx context.Context) context.Context {
+	jctx := context.Background()
+	if v, ok := icontext.FromTraceID(ctx); ok {
+		jctx = icontext.NewTraceID(jctx, v)
+		jctx = logger.NewTraceIDContext(jctx, v)
+	}
+	if v, ok := icontext.FromUserUUID(ctx); ok {
+		jctx = icontext.NewUserUUID(jctx, v)
+		jctx = logger.NewUserUUIDContext(jctx, v)
+	}
+	if v, ok := icontext.FromImpersonator(ctx); ok {
+		jctx = icontext.NewImpersonator(jctx, v)
+		jctx = logger.NewImpersonatorContext(jctx, v)
+	}
+	if v, ok := icontext.FromClientIP(ctx); ok {
+		jctx = icontext.NewClientIP(jctx, v)
+	}
+	if v, ok := icontext.FromUserAgent(ctx); ok {
+		jctx = icontext.NewUserAgent(jctx, v)
+	}
+	return jctx
+}
+
+// Create a pending background job
+func createFileJob(ctx context.Context, mFileJob model.IFileJob, jobType, creator string, total int) (*schema.FileJob, error) {
+	item := schema.FileJob{
+		UUID:    util.MustUUID(),
+		Type:    jobType,
+		Status:  schema.FileJobStatusPending,
+		Total:   total,
+		Creator: creator,
+	}
+	err := mFileJob.Create(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+	return getFileJob(ctx, mFileJob, item.UUID)
+}
+
+// Store the progress of a job, failures are only logged so the job goes on
+func updateFileJob(ctx context.Context, mFileJob model.IFileJob, job *schema.FileJob) {
+	if err := mFileJob.Update(ctx, job.UUID, *job); err != nil {
+		logger.Errorf(ctx, "Update file job %s error: %s", job.UUID, err.Error())
+	}
+}
@@ -0,0 +1,101 @@
+package implement
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/model"
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// Content changed more recently may belong to an upload in progress
+const orphanGrace = time.Hour
+
+// NewReconcile - Create a storage reconciliation instance
+func NewReconcile(bFile controllers.IFile, mFile model.IFile) *Reconcile {
+	return &Reconcile{
+		FileBll:   bFile,
+		FileModel: mFile,
+	}
+}
+
+// Reconcile - Compare the file records with the storage
+type Reconcile struct {
+	FileBll   controllers.IFile
+	FileModel model.IFile
+}
+
+// Reconcile - Report and optionally repair the mismatches
+func (a *Reconcile) Reconcile(ctx context.Context, repair bool) (*schema.ReconcileReport, error) {
+	report := &schema.ReconcileReport{
+		Missing:  schema.Files{},
+		Orphans:  []string{},
+		Repaired: repair,
+	}
+
+	// Infected files have no content, every other record must have one
+	known := make(map[string]bool)
+	for _, status := range []string{"", schema.ScanStatusQuarantine} {
+		result, err := a.FileModel.Query(ctx, schema.FileQueryParam{
+			ScanStatus: status,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Data {
+			report.Checked++
+			cpath := contentPath(item)
+			known[filepath.Clean(cpath)] = true
+			if cpath == "" || !controllers.PathExists(cpath) {
+				report.Missing = append(report.Missing, item)
+			}
+		}
+	}
+
+	cfg := config.Global().FileManager
+	for _, root := range []string{cfg.Dir, cfg.PrivateDir, cfg.QuarantineDir} {
+		if root == "" || !controllers.PathExists(root) {
+			continue
+		}
+
+		err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			} else if info.IsDir() || known[filepath.Clean(p)] {
+				return nil
+			} else if time.Since(info.ModTime()) < orphanGrace {
+				return nil
+			}
+			report.Orphans = append(report.Orphans, p)
+			return nil
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if !repair {
+		return report, nil
+	}
+
+	for _, item := range report.Missing {
+		if err := a.FileBll.Delete(ctx, item.UUID, true); err != nil {
+			return nil, err
+		}
+		logger.Printf(ctx, "Removed file record %s without content (%s)", item.UUID, item.Uri)
+	}
+	for _, p := range report.Orphans {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return nil, errors.WithStack(err)
+		}
+		logger.Printf(ctx, "Removed orphaned content %s", p)
+	}
+	return report, nil
+}
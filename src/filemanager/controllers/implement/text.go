@@ -0,0 +1,196 @@
+package implement
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/extract"
+	"github.com/MayCMF/core/src/filemanager/model"
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// Length of a search result snippet in characters
+const snippetWidth = 160
+
+// NewFileText - Create a document text management instance
+func NewFileText(
+	mFile model.IFile,
+	mFileText model.IFileText,
+	mFileJob model.IFileJob,
+) *FileText {
+	return &FileText{
+		FileModel:     mFile,
+		FileTextModel: mFileText,
+		FileJobModel:  mFileJob,
+	}
+}
+
+// FileText - Text extraction and full text search of documents
+type FileText struct {
+	FileModel     model.IFile
+	FileTextModel model.IFileText
+	FileJobModel  model.IFileJob
+}
+
+// Extract the text of a stored document and save it with the file, the text
+// of a file replaced by another format is removed
+func extractText(ctx context.Context, mFileText model.IFileText, item *schema.File) error {
+	size := config.Global().FileManager.TextSize
+	ext := path.Ext(item.Filename)
+	if size <= 0 || !extract.Supported(ext) {
+		return mFileText.Delete(ctx, item.UUID)
+	}
+
+	f, err := controllers.OpenContent(contentPath(item))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	text, err := extract.Text(ext, f, fi.Size())
+	if err != nil {
+		return err
+	}
+
+	return mFileText.Save(ctx, schema.FileText{
+		FileID:  item.UUID,
+		Content: controllers.TruncateText(text, size),
+	})
+}
+
+// Index the text of a stored file, a document that can not be read is still
+// a valid upload so failures are only logged
+func indexText(ctx context.Context, mFileText model.IFileText, item *schema.File) {
+	if err := extractText(ctx, mFileText, item); err != nil {
+		logger.StartSpan(ctx, logger.SetSpanTitle("File text"), logger.SetSpanFuncName("indexText")).
+			Warnf("Extract text of %s (%s) error: %s", item.UUID, item.Filename, err.Error())
+	}
+}
+
+// Get - Get the text extracted from a file
+func (a *FileText) Get(ctx context.Context, fileID string) (*schema.FileText, error) {
+	item, err := a.FileTextModel.Get(ctx, fileID)
+	if err != nil {
+		return nil, err
+	} else if item == nil {
+		return nil, errors.ErrNotFound
+	}
+	return item, nil
+}
+
+// Search - Search files containing all words of the query, the files come
+// with a snippet of their text around the first match
+func (a *FileText) Search(ctx context.Context, params schema.FileSearchParam, opts ...schema.FileTextQueryOptions) (*schema.FileSearchResult, error) {
+	words := controllers.SearchWords(params.Query)
+	if len(words) == 0 {
+		return nil, errors.New400Response("Missing search query")
+	}
+
+	result, err := a.FileTextModel.Query(ctx, schema.FileTextQueryParam{
+		Words: words,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileIDs []string
+	for _, item := range result.Data {
+		fileIDs = append(fileIDs, item.FileID)
+	}
+
+	files := make(map[string]*schema.File)
+	if len(fileIDs) > 0 {
+		fileResult, err := a.FileModel.Query(ctx, schema.FileQueryParam{
+			UUIDs: fileIDs,
+		}, schema.FileQueryOptions{
+			IncludeTags:         true,
+			IncludeTranslations: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range fileResult.Data {
+			files[item.UUID] = item
+		}
+	}
+
+	hits := make([]*schema.FileSearchHit, 0, len(result.Data))
+	for _, item := range result.Data {
+		file, ok := files[item.FileID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, &schema.FileSearchHit{
+			File:    file,
+			Snippet: controllers.Snippet(item.Content, words, snippetWidth),
+		})
+	}
+
+	return &schema.FileSearchResult{
+		Data:       hits,
+		PageResult: result.PageResult,
+	}, nil
+}
+
+// Index - Extract the text of the stored documents in the background, only
+// documents without a text unless all of them are requested
+func (a *FileText) Index(ctx context.Context, creator string, params schema.FileIndexParam) (*schema.FileJob, error) {
+	result, err := a.FileModel.Query(ctx, schema.FileQueryParam{})
+	if err != nil {
+		return nil, err
+	}
+
+	var indexed map[string]bool
+	if !params.All {
+		textResult, err := a.FileTextModel.Query(ctx, schema.FileTextQueryParam{})
+		if err != nil {
+			return nil, err
+		}
+		indexed = make(map[string]bool)
+		for _, item := range textResult.Data {
+			indexed[item.FileID] = true
+		}
+	}
+
+	var files schema.Files
+	for _, item := range result.Data {
+		if extract.Supported(path.Ext(item.Filename)) && !indexed[item.UUID] {
+			files = append(files, item)
+		}
+	}
+
+	job, err := createFileJob(ctx, a.FileJobModel, schema.FileJobTypeIndex, creator, len(files))
+	if err != nil {
+		return nil, err
+	}
+
+	go a.runIndex(jobContext(ctx), *job, files)
+	return job, nil
+}
+
+func (a *FileText) runIndex(ctx context.Context, job schema.FileJob, files schema.Files) {
+	job.Status = schema.FileJobStatusRunning
+	updateFileJob(ctx, a.FileJobModel, &job)
+
+	for _, item := range files {
+		if err := extractText(ctx, a.FileTextModel, item); err != nil {
+			job.Failed++
+			job.Errors = append(job.Errors, fmt.Sprintf("%s: %s", item.Filename, err.Error()))
+		}
+		job.Processed++
+		updateFileJob(ctx, a.FileJobModel, &job)
+	}
+
+	job.Status = schema.FileJobStatusDone
+	updateFileJob(ctx, a.FileJobModel, &job)
+}
@@ -0,0 +1,121 @@
+package implement
+
+import (
+	"context"
+	"sort"
+
+	"github.com/MayCMF/core/src/common"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/model"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	transaction "github.com/MayCMF/core/src/transaction/model"
+)
+
+// NewFileUsage - Create a File usage management instance
+func NewFileUsage(
+	trans transaction.ITrans,
+	mFile model.IFile,
+	mFileUsage model.IFileUsage,
+) *FileUsage {
+	return &FileUsage{
+		TransModel:     trans,
+		FileModel:      mFile,
+		FileUsageModel: mFileUsage,
+	}
+}
+
+// FileUsage - Index of the places where files are used
+type FileUsage struct {
+	TransModel     transaction.ITrans
+	FileModel      model.IFile
+	FileUsageModel model.IFileUsage
+}
+
+// Query - Query the places where a file is used
+func (a *FileUsage) Query(ctx context.Context, fileID string) (schema.FileUsages, error) {
+	result, err := a.FileUsageModel.Query(ctx, schema.FileUsageQueryParam{
+		FileID: fileID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
+
+// Resolve the references of a text to the UUIDs of existing files
+func (a *FileUsage) resolve(ctx context.Context, text string) ([]string, error) {
+	UUIDs, uris := controllers.FindFileRefs(text, config.Global().FileManager.Dir)
+
+	var fileIDs []string
+	if len(UUIDs) > 0 {
+		result, err := a.FileModel.Query(ctx, schema.FileQueryParam{
+			UUIDs: UUIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		fileIDs = append(fileIDs, result.Data.ToUUIDs()...)
+	}
+	if len(uris) > 0 {
+		result, err := a.FileModel.Query(ctx, schema.FileQueryParam{
+			Uris: uris,
+		})
+		if err != nil {
+			return nil, err
+		}
+		fileIDs = append(fileIDs, result.Data.ToUUIDs()...)
+	}
+	return fileIDs, nil
+}
+
+// Track - Track the files referenced by the fields of an entity
+func (a *FileUsage) Track(ctx context.Context, entityType, entityID string, fields map[string]string) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var usages []schema.FileUsage
+	for _, name := range names {
+		fileIDs, err := a.resolve(ctx, fields[name])
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool)
+		for _, fileID := range fileIDs {
+			if seen[fileID] {
+				continue
+			}
+			seen[fileID] = true
+			usages = append(usages, schema.FileUsage{
+				FileID:     fileID,
+				EntityType: entityType,
+				EntityID:   entityID,
+				Field:      name,
+			})
+		}
+	}
+
+	return common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.FileUsageModel.DeleteByEntity(ctx, entityType, entityID)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range usages {
+			err := a.FileUsageModel.Create(ctx, item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Untrack - Untrack all files of an entity
+func (a *FileUsage) Untrack(ctx context.Context, entityType, entityID string) error {
+	return a.FileUsageModel.DeleteByEntity(ctx, entityType, entityID)
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// Nesting limit of the boxes read from an mp4 file
+const maxBoxDepth = 8
+
+// MediaMeta - Metadata read from an uploaded audio or video file
+type MediaMeta struct {
+	Format   string // Container format (wav, mp4)
+	Duration int    // Duration in seconds
+	Width    int    // Width in pixels of the video track
+	Height   int    // Height in pixels of the video track
+}
+
+// ReadMediaMeta - Read the duration of wav and mp4 (mov, m4a) files and the
+// dimensions of their video, returns nil if r is not a known media file
+func ReadMediaMeta(r io.ReaderAt, size int64) *MediaMeta {
+	head := make([]byte, 12)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return nil
+	}
+
+	switch {
+	case bytes.Equal(head[0:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE")):
+		return readWAV(r, size)
+	case bytes.Equal(head[4:8], []byte("ftyp")):
+		return readMP4(r, size)
+	}
+	return nil
+}
+
+// PrepareMedia - Fill the duration and video dimensions of an audio or video file
+func PrepareMedia(r io.ReaderAt, size int64, item *schema.File) {
+	meta := ReadMediaMeta(r, size)
+	if meta == nil {
+		return
+	}
+	item.Duration = meta.Duration
+	if meta.Width > 0 && meta.Height > 0 {
+		item.Width = meta.Width
+		item.Height = meta.Height
+	}
+}
+
+// Divide rounding to the nearest integer
+func divRound(n, d uint64) int {
+	if d == 0 {
+		return 0
+	}
+	return int((n + d/2) / d)
+}
+
+// The duration of a wav file follows from the byte rate and the data size
+func readWAV(r io.ReaderAt, size int64) *MediaMeta {
+	var byteRate, dataSize uint64
+	header := make([]byte, 8)
+	for off := int64(12); off+8 <= size; {
+		if _, err := r.ReadAt(header, off); err != nil {
+			return nil
+		}
+		chunkSize := int64(binary.LittleEndian.Uint32(header[4:8]))
+		off += 8
+
+		switch string(header[0:4]) {
+		case "fmt ":
+			format := make([]byte, 12)
+			if _, err := r.ReadAt(format, off); err != nil {
+				return nil
+			}
+			byteRate = uint64(binary.LittleEndian.Uint32(format[8:12]))
+		case "data":
+			// Streamed files leave the size open
+			if off+chunkSize > size {
+				chunkSize = size - off
+			}
+			dataSize = uint64(chunkSize)
+		}
+		if byteRate > 0 && dataSize > 0 {
+			return &MediaMeta{Format: "wav", Duration: divRound(dataSize, byteRate)}
+		}
+		off += chunkSize + chunkSize%2
+	}
+	return nil
+}
+
+// The duration of an mp4 file is in the movie header, the dimensions in
+// the header of its video track
+func readMP4(r io.ReaderAt, size int64) *MediaMeta {
+	meta := &MediaMeta{Format: "mp4"}
+	found := false
+	err := walkBoxes(r, 0, size, 0, func(typ string, off, n int64) error {
+		switch typ {
+		case "mvhd":
+			b := make([]byte, 32)
+			if _, err := r.ReadAt(b[:min64(n, 32)], off); err != nil && err != io.EOF {
+				return err
+			}
+			var timescale, duration uint64
+			if b[0] == 1 {
+				timescale = uint64(binary.BigEndian.Uint32(b[20:24]))
+				duration = binary.BigEndian.Uint64(b[24:32])
+			} else {
+				timescale = uint64(binary.BigEndian.Uint32(b[12:16]))
+				duration = uint64(binary.BigEndian.Uint32(b[16:20]))
+			}
+			meta.Duration = divRound(duration, timescale)
+			found = true
+		case "tkhd":
+			b := make([]byte, 96)
+			if _, err := r.ReadAt(b[:min64(n, 96)], off); err != nil && err != io.EOF {
+				return err
+			}
+			dim := b[76:84]
+			if b[0] == 1 {
+				dim = b[88:96]
+			}
+			// Audio tracks have no dimensions, 16.16 fixed point
+			w, h := int(binary.BigEndian.Uint32(dim[0:4])>>16), int(binary.BigEndian.Uint32(dim[4:8])>>16)
+			if w*h > meta.Width*meta.Height {
+				meta.Width, meta.Height = w, h
+			}
+		}
+		return nil
+	})
+	if err != nil || !found {
+		return nil
+	}
+	return meta
+}
+
+// Call fn with the type, payload offset and payload size of the boxes
+// between off and end, descending into the boxes that hold the headers
+func walkBoxes(r io.ReaderAt, off, end int64, depth int, fn func(typ string, off, n int64) error) error {
+	if depth > maxBoxDepth {
+		return nil
+	}
+
+	header := make([]byte, 16)
+	for off+8 <= end {
+		if _, err := r.ReadAt(header[:8], off); err != nil {
+			return err
+		}
+		boxSize, hlen := int64(binary.BigEndian.Uint32(header[0:4])), int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = end - off
+		case 1:
+			if _, err := r.ReadAt(header[8:16], off+8); err != nil {
+				return err
+			}
+			boxSize, hlen = int64(binary.BigEndian.Uint64(header[8:16])), 16
+		}
+		if boxSize < hlen || off+boxSize > end {
+			return nil
+		}
+
+		typ := string(header[4:8])
+		switch typ {
+		case "moov", "trak":
+			if err := walkBoxes(r, off+hlen, off+boxSize, depth+1, fn); err != nil {
+				return err
+			}
+		default:
+			if err := fn(typ, off+hlen, boxSize-hlen); err != nil {
+				return err
+			}
+		}
+		off += boxSize
+	}
+	return nil
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
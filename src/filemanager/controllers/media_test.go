@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// Build a wav of 8 kHz mono 8 bit samples
+func newWAV(seconds int) []byte {
+	const byteRate = 8000
+	data := make([]byte, seconds*byteRate)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("RIFF")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(36+len(data)))
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(buf, binary.LittleEndian, []uint32{16})
+	_ = binary.Write(buf, binary.LittleEndian, []uint16{1, 1})
+	_ = binary.Write(buf, binary.LittleEndian, []uint32{8000, byteRate})
+	_ = binary.Write(buf, binary.LittleEndian, []uint16{1, 8})
+	buf.WriteString("data")
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	return buf.Bytes()
+}
+
+// Build an mp4 box of the given type and payload
+func newBox(typ string, payload ...[]byte) []byte {
+	buf := new(bytes.Buffer)
+	size := 8
+	for _, p := range payload {
+		size += len(p)
+	}
+	_ = binary.Write(buf, binary.BigEndian, uint32(size))
+	buf.WriteString(typ)
+	for _, p := range payload {
+		buf.Write(p)
+	}
+	return buf.Bytes()
+}
+
+// Build an mp4 with an audio and a video track of the given dimensions
+func newMP4(duration, timescale uint32, w, h uint32) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], timescale)
+	binary.BigEndian.PutUint32(mvhd[16:], duration)
+
+	audio := make([]byte, 84)
+	video := make([]byte, 84)
+	binary.BigEndian.PutUint32(video[76:], w<<16)
+	binary.BigEndian.PutUint32(video[80:], h<<16)
+
+	return bytes.Join([][]byte{
+		newBox("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41")),
+		newBox("moov",
+			newBox("mvhd", mvhd),
+			newBox("trak", newBox("tkhd", audio)),
+			newBox("trak", newBox("tkhd", video)),
+		),
+		newBox("mdat", make([]byte, 64)),
+	}, nil)
+}
+
+func TestReadMediaMeta(t *testing.T) {
+	data := newWAV(3)
+	meta := ReadMediaMeta(bytes.NewReader(data), int64(len(data)))
+	if assert.NotNil(t, meta) {
+		assert.Equal(t, "wav", meta.Format)
+		assert.Equal(t, 3, meta.Duration)
+	}
+
+	data = newMP4(90500, 1000, 640, 360)
+	meta = ReadMediaMeta(bytes.NewReader(data), int64(len(data)))
+	if assert.NotNil(t, meta) {
+		assert.Equal(t, "mp4", meta.Format)
+		assert.Equal(t, 91, meta.Duration)
+		assert.Equal(t, 640, meta.Width)
+		assert.Equal(t, 360, meta.Height)
+	}
+
+	// Truncated and unknown files
+	data = newMP4(90500, 1000, 640, 360)[:40]
+	assert.Nil(t, ReadMediaMeta(bytes.NewReader(data), int64(len(data))))
+	data = []byte(`{"a":1}`)
+	assert.Nil(t, ReadMediaMeta(bytes.NewReader(data), int64(len(data))))
+}
+
+func TestPrepareMedia(t *testing.T) {
+	data := newMP4(5000, 1000, 1920, 1080)
+	var item schema.File
+	PrepareMedia(bytes.NewReader(data), int64(len(data)), &item)
+	assert.Equal(t, 5, item.Duration)
+	assert.Equal(t, 1920, item.Width)
+	assert.Equal(t, 1080, item.Height)
+
+	data = newWAV(2)
+	item = schema.File{}
+	PrepareMedia(bytes.NewReader(data), int64(len(data)), &item)
+	assert.Equal(t, 2, item.Duration)
+	assert.Equal(t, 0, item.Width)
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// DetectMime - Mime type of a file by its extension, the content is sniffed
+// for unknown extensions. The type announced by the client is never trusted.
+func DetectMime(filename string, data []byte) string {
+	if v := mime.TypeByExtension(path.Ext(filename)); v != "" {
+		if t, _, err := mime.ParseMediaType(v); err == nil {
+			return t
+		}
+	}
+	if len(data) == 0 {
+		return "application/octet-stream"
+	}
+	t, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	return t
+}
+
+// IsActiveMime - Check if browsers may run scripts from content of the mime
+// type (html, xml and svg), such files are only served as attachments
+func IsActiveMime(mimeType string) bool {
+	t, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		t = strings.ToLower(strings.TrimSpace(mimeType))
+	}
+	switch {
+	case t == "text/html", t == "text/xml", t == "application/xml":
+		return true
+	case strings.HasSuffix(t, "+xml"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectMime(t *testing.T) {
+	assert.Equal(t, "image/png", DetectMime("cat.png", []byte("<html><script>alert(1)</script></html>")))
+	assert.Equal(t, "image/svg+xml", DetectMime("cat.svg", nil))
+	assert.Equal(t, "text/html", DetectMime("cat", []byte("<html><script>alert(1)</script></html>")))
+	assert.Equal(t, "application/octet-stream", DetectMime("cat", nil))
+}
+
+func TestIsActiveMime(t *testing.T) {
+	for _, v := range []string{"text/html", "Text/HTML; charset=utf-8", "image/svg+xml", "application/xml", "text/xml", "application/xhtml+xml"} {
+		assert.True(t, IsActiveMime(v), v)
+	}
+	for _, v := range []string{"image/png", "application/json", "text/plain", ""} {
+		assert.False(t, IsActiveMime(v), v)
+	}
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IReconcile - Storage reconciliation business logic interface
+type IReconcile interface {
+	// Report the mismatches between the file records and the storage,
+	// with repair records without content and orphaned content are removed
+	Reconcile(ctx context.Context, repair bool) (*schema.ReconcileReport, error)
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"time"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+)
+
+// Get the key used to sign download URLs, falls back to the JWT secret
+func getSigningKey() string {
+	cfg := config.Global()
+	if key := cfg.FileManager.SigningKey; key != "" {
+		return key
+	}
+	return cfg.JWTAuth.SecretKey
+}
+
+// SignFile - Sign a file download for the given expiry (unix seconds)
+func SignFile(UUID string, expiresAt int64) string {
+	return util.HMACSHA256HashString(getSigningKey(), fmt.Sprintf("%s:%d", UUID, expiresAt))
+}
+
+// VerifyFileSignature - Check a download signature and that it is not expired
+func VerifyFileSignature(UUID string, expiresAt int64, signature string) bool {
+	if expiresAt < time.Now().Unix() {
+		return false
+	}
+	return hmac.Equal([]byte(SignFile(UUID, expiresAt)), []byte(signature))
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IFileText - Document text extraction and search business logic interface
+type IFileText interface {
+	// Get the text extracted from a file
+	Get(ctx context.Context, fileID string) (*schema.FileText, error)
+	// Search files by their text
+	Search(ctx context.Context, params schema.FileSearchParam, opts ...schema.FileTextQueryOptions) (*schema.FileSearchResult, error)
+	// Extract the text of stored documents in the background
+	Index(ctx context.Context, creator string, params schema.FileIndexParam) (*schema.FileJob, error)
+}
+
+// SearchWords - Split a search query into lower case words
+func SearchWords(query string) []string {
+	words := strings.Fields(strings.ToLower(query))
+	seen := make(map[string]bool)
+	list := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			list = append(list, w)
+		}
+	}
+	return list
+}
+
+// TruncateText - Cut a text to at most size bytes without splitting a character
+func TruncateText(text string, size int) string {
+	if len(text) <= size {
+		return text
+	}
+	for size > 0 && !utf8.RuneStart(text[size]) {
+		size--
+	}
+	return text[:size]
+}
+
+// Snippet - Excerpt of about width characters around the first match of any word
+func Snippet(text string, words []string, width int) string {
+	rs := []rune(strings.Join(strings.Fields(text), " "))
+	lower := make([]rune, len(rs))
+	for i, r := range rs {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	pos := -1
+	for _, w := range words {
+		if i := runeIndex(lower, []rune(w)); i >= 0 && (pos < 0 || i < pos) {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	start := pos - width/3
+	if start < 0 {
+		start = 0
+	}
+	end := start + width
+	if end > len(rs) {
+		end = len(rs)
+	}
+
+	// Whole words only at the cut edges
+	if start > 0 {
+		if i := runeIndex(rs[start:pos], []rune(" ")); i >= 0 {
+			start += i + 1
+		}
+	}
+	if end < len(rs) {
+		for k := end; k > pos; k-- {
+			if rs[k] == ' ' {
+				end = k
+				break
+			}
+		}
+	}
+
+	snippet := string(rs[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(rs) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+func runeIndex(s, sub []rune) int {
+	if len(sub) == 0 {
+		return -1
+	}
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for k := range sub {
+			if s[i+k] != sub[k] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchWords(t *testing.T) {
+	assert.Equal(t, []string{"annual", "report"}, SearchWords("  Annual REPORT annual "))
+	assert.Empty(t, SearchWords(" "))
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "abc", TruncateText("abc", 10))
+	assert.Equal(t, "Ки", TruncateText("Київ", 5))
+}
+
+func TestSnippet(t *testing.T) {
+	text := "The quarterly report shows that revenue grew in every region while costs stayed flat"
+
+	assert.Equal(t, "…that revenue grew in…", Snippet(text, []string{"grew", "revenue"}, 25))
+	assert.Equal(t, text, Snippet(text, []string{"missing"}, 200))
+	assert.Equal(t, "The quarterly…", Snippet(text, []string{"quarterly"}, 16))
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IFileUsage - File usage business logic interface
+type IFileUsage interface {
+	// Query the places where a file is used
+	Query(ctx context.Context, fileID string) (schema.FileUsages, error)
+	// Track the files referenced by the fields of an entity (field name => text),
+	// replacing the usages tracked for the entity before
+	Track(ctx context.Context, entityType, entityID string, fields map[string]string) error
+	// Untrack all files of an entity
+	Untrack(ctx context.Context, entityType, entityID string) error
+}
+
+var uuidPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// FindFileRefs - Find the possible file references of a text, UUIDs (as used by the
+// file API and download URLs) and paths below the public storage directory dir
+func FindFileRefs(text, dir string) (UUIDs []string, uris []string) {
+	UUIDs = uuidPattern.FindAllString(text, -1)
+	for i, v := range UUIDs {
+		UUIDs[i] = strings.ToLower(v)
+	}
+
+	dir = strings.TrimPrefix(strings.TrimSuffix(dir, "/"), "./")
+	if dir == "" {
+		return
+	}
+	re := regexp.MustCompile(regexp.QuoteMeta(dir) + `/[^\s"'<>()\\?#]+`)
+	for _, v := range re.FindAllString(text, -1) {
+		uris = append(uris, strings.TrimRight(v, ".,;:"))
+	}
+	return
+}
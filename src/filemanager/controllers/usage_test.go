@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindFileRefs(t *testing.T) {
+	text := `<p><img src="/static/images/20200101-1200_cat.jpg"> and <a href="/api/v1/file/3F2504E0-4F89-11D3-9A0C-0305E82C3301/download?inline=true">a pdf</a>.</p>
+{"cover": "7c102b43-c592-4cbf-973a-675ba77e8b7c", "attachment": "static/files/report.pdf."}`
+
+	UUIDs, uris := FindFileRefs(text, "./static/")
+	assert.Equal(t, []string{"3f2504e0-4f89-11d3-9a0c-0305e82c3301", "7c102b43-c592-4cbf-973a-675ba77e8b7c"}, UUIDs)
+	assert.Equal(t, []string{"static/images/20200101-1200_cat.jpg", "static/files/report.pdf"}, uris)
+
+	UUIDs, uris = FindFileRefs("no references here", "static")
+	assert.Empty(t, UUIDs)
+	assert.Empty(t, uris)
+}
@@ -0,0 +1,242 @@
+package dav
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/common/config"
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"golang.org/x/net/webdav"
+)
+
+// fileInfo - os.FileInfo of a folder or file record
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (a *fileInfo) Name() string       { return a.name }
+func (a *fileInfo) Size() int64        { return a.size }
+func (a *fileInfo) ModTime() time.Time { return a.modTime }
+func (a *fileInfo) IsDir() bool        { return a.dir }
+func (a *fileInfo) Sys() interface{}   { return nil }
+
+func (a *fileInfo) Mode() os.FileMode {
+	if a.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func folderInfo(folder *schema.Folder) *fileInfo {
+	return &fileInfo{name: folder.Name, modTime: folder.CreatedAt, dir: true}
+}
+
+func fileItemInfo(item *schema.File) *fileInfo {
+	return &fileInfo{name: item.Filename, size: item.Filesize, modTime: item.CreatedAt}
+}
+
+func (e *entry) stat() os.FileInfo {
+	if e.file != nil {
+		return fileItemInfo(e.file)
+	} else if e.folder != nil {
+		return folderInfo(e.folder)
+	}
+	return &fileInfo{name: "/", dir: true}
+}
+
+// dirFile - Folder opened for listing
+type dirFile struct {
+	fs       *FileSystem
+	ctx      context.Context
+	entry    *entry
+	children []os.FileInfo
+	pos      int
+}
+
+func (f *dirFile) Close() error                                 { return nil }
+func (f *dirFile) Read(p []byte) (int, error)                   { return 0, os.ErrInvalid }
+func (f *dirFile) Seek(offset int64, whence int) (int64, error) { return 0, os.ErrInvalid }
+func (f *dirFile) Write(p []byte) (int, error)                  { return 0, os.ErrPermission }
+func (f *dirFile) Stat() (os.FileInfo, error)                   { return f.entry.stat(), nil }
+
+func (f *dirFile) load() error {
+	folderID := f.entry.folderID()
+	result, err := f.fs.FolderBll.Query(f.ctx, schema.FolderQueryParam{
+		ParentID: &folderID,
+	})
+	if err != nil {
+		return err
+	}
+	fileResult, err := f.fs.FileBll.Query(f.ctx, schema.FileQueryParam{
+		FolderID: &folderID,
+	})
+	if err != nil {
+		return err
+	}
+
+	f.children = make([]os.FileInfo, 0, len(result.Data)+len(fileResult.Data))
+	for _, folder := range result.Data {
+		f.children = append(f.children, folderInfo(folder))
+	}
+	for _, item := range fileResult.Data {
+		if ok, err := f.fs.canRead(f.ctx, item); err != nil {
+			return err
+		} else if ok {
+			f.children = append(f.children, fileItemInfo(item))
+		}
+	}
+	return nil
+}
+
+func (f *dirFile) Readdir(count int) ([]os.FileInfo, error) {
+	if f.children == nil {
+		if err := f.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	rest := f.children[f.pos:]
+	if count <= 0 {
+		f.pos = len(f.children)
+		return rest, nil
+	} else if len(rest) == 0 {
+		return nil, io.EOF
+	} else if count > len(rest) {
+		count = len(rest)
+	}
+	f.pos += count
+	return rest[:count], nil
+}
+
+// contentFile - Stored content of a file opened for reading
+type contentFile struct {
+	*os.File
+	item *schema.File
+}
+
+func (f *contentFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (f *contentFile) Stat() (os.FileInfo, error) {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	info := fileItemInfo(f.item)
+	info.size = fi.Size()
+	return info, nil
+}
+
+// ContentType - Mime type of the file record, saves sniffing the content
+func (f *contentFile) ContentType(ctx context.Context) (string, error) {
+	if f.item.Filemime == "" {
+		return "", webdav.ErrNotImplemented
+	}
+	return f.item.Filemime, nil
+}
+
+// uploadFile - File opened for writing, the content is buffered in a temporary
+// file and uploaded or replaced through the file business logic when closed
+type uploadFile struct {
+	*os.File
+	ctx      context.Context
+	bFile    controllers.IFile
+	folderID string
+	name     string
+	existing *schema.File
+}
+
+func newUploadFile(ctx context.Context, bFile controllers.IFile, folderID, name string) (*uploadFile, error) {
+	f, err := ioutil.TempFile("", "maycmf-dav")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &uploadFile{
+		File:     f,
+		ctx:      ctx,
+		bFile:    bFile,
+		folderID: folderID,
+		name:     name,
+	}, nil
+}
+
+func (f *uploadFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, os.ErrInvalid
+}
+
+func (f *uploadFile) Stat() (os.FileInfo, error) {
+	fi, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &fileInfo{name: f.name, size: fi.Size(), modTime: fi.ModTime()}, nil
+}
+
+// Close - Store the written content
+func (f *uploadFile) Close() error {
+	defer os.Remove(f.File.Name())
+	defer f.File.Close()
+
+	size, err := f.File.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := f.File.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f.File, head)
+	if _, err := f.File.Seek(0, io.SeekStart); err != nil {
+		return errors.WithStack(err)
+	}
+
+	userUUID, _ := icontext.FromUserUUID(f.ctx)
+	item := schema.File{
+		UserUUID: userUUID,
+		FolderID: f.folderID,
+		Filename: f.name,
+		Filesize: size,
+		Filemime: controllers.DetectMime(f.name, head[:n]),
+		FileExt:  path.Ext(f.name),
+	}
+
+	var content io.Reader = f.File
+	if strings.HasPrefix(item.Filemime, "image/") {
+		data, err := ioutil.ReadAll(f.File)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		cfg := config.Global().FileManager
+		data, err = controllers.PrepareImage(data, &item, cfg.ExifStrip, cfg.AutoRotate)
+		if err != nil {
+			return errors.New400Response("Invalid image: " + err.Error())
+		}
+		content = bytes.NewReader(data)
+	} else {
+		controllers.PrepareMedia(f.File, size, &item)
+	}
+
+	if f.existing != nil {
+		_, err = f.bFile.Replace(f.ctx, f.existing.UUID, item, content)
+		return err
+	}
+
+	item.Uri = controllers.StoragePath(false, "", f.name)
+	_, err = f.bFile.Upload(f.ctx, item, content)
+	return err
+}
@@ -0,0 +1,375 @@
+// Package dav serves the media library over WebDAV. Folders are collections
+// and files are resources named by their filename, writes go through the
+// file business logic so uploads are validated and scanned as usual.
+package dav
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/casbin/casbin/v2"
+	"golang.org/x/net/webdav"
+)
+
+// NewFileSystem - Create a WebDAV file system over the media library
+func NewFileSystem(bFile controllers.IFile, bFolder controllers.IFolder, bFileUsage controllers.IFileUsage, e *casbin.SyncedEnforcer) *FileSystem {
+	return &FileSystem{
+		FileBll:      bFile,
+		FolderBll:    bFolder,
+		FileUsageBll: bFileUsage,
+		Enforcer:     e,
+	}
+}
+
+// FileSystem - Media library as webdav.FileSystem, every entry is checked
+// against the casbin permissions of the matching REST route
+type FileSystem struct {
+	FileBll      controllers.IFile
+	FolderBll    controllers.IFolder
+	FileUsageBll controllers.IFileUsage
+	Enforcer     *casbin.SyncedEnforcer
+}
+
+var _ webdav.FileSystem = (*FileSystem)(nil)
+
+// Resolved path, the library root has neither folder nor file
+type entry struct {
+	folder *schema.Folder
+	file   *schema.File
+}
+
+func (e *entry) isDir() bool {
+	return e.file == nil
+}
+
+func (e *entry) folderID() string {
+	if e.folder == nil {
+		return ""
+	}
+	return e.folder.UUID
+}
+
+func splitPath(name string) []string {
+	name = strings.Trim(path.Clean("/"+name), "/")
+	if name == "" {
+		return nil
+	}
+	return strings.Split(name, "/")
+}
+
+func (a *FileSystem) findFolder(ctx context.Context, parentID, name string) (*schema.Folder, error) {
+	result, err := a.FolderBll.Query(ctx, schema.FolderQueryParam{
+		ParentID: &parentID,
+		Name:     name,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(result.Data) == 0 {
+		return nil, nil
+	}
+	return result.Data[0], nil
+}
+
+// Find a file the current user may read, private files of others are hidden
+func (a *FileSystem) findFile(ctx context.Context, folderID, name string) (*schema.File, error) {
+	result, err := a.FileBll.Query(ctx, schema.FileQueryParam{
+		FolderID: &folderID,
+		Filename: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range result.Data {
+		if ok, err := a.canRead(ctx, item); err != nil {
+			return nil, err
+		} else if ok {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+// Resolve a path to its folder or file, os.ErrNotExist when there is none
+func (a *FileSystem) resolve(ctx context.Context, name string) (*entry, error) {
+	parts := splitPath(name)
+	e := new(entry)
+	for i, part := range parts {
+		folder, err := a.findFolder(ctx, e.folderID(), part)
+		if err != nil {
+			return nil, err
+		} else if folder != nil {
+			e.folder = folder
+			continue
+		}
+
+		if i == len(parts)-1 {
+			file, err := a.findFile(ctx, e.folderID(), part)
+			if err != nil {
+				return nil, err
+			} else if file != nil {
+				return &entry{folder: e.folder, file: file}, nil
+			}
+		}
+		return nil, os.ErrNotExist
+	}
+	return e, nil
+}
+
+// Resolve the collection holding a path and the base name within it
+func (a *FileSystem) resolveParent(ctx context.Context, name string) (*entry, string, error) {
+	parts := splitPath(name)
+	if len(parts) == 0 {
+		return nil, "", os.ErrPermission
+	}
+
+	parent, err := a.resolve(ctx, strings.Join(parts[:len(parts)-1], "/"))
+	if err != nil {
+		return nil, "", err
+	} else if !parent.isDir() {
+		return nil, "", os.ErrNotExist
+	}
+	return parent, parts[len(parts)-1], nil
+}
+
+// Mkdir - Create a folder
+func (a *FileSystem) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	parent, base, err := a.resolveParent(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := a.resolve(ctx, name); err == nil {
+		return os.ErrExist
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := a.checkPermission(ctx, "", "POST", "/api/v1/folders"); err != nil {
+		return err
+	}
+
+	creator, _ := icontext.FromUserUUID(ctx)
+	_, err = a.FolderBll.Create(ctx, schema.Folder{
+		Name:     base,
+		ParentID: parent.folderID(),
+		Creator:  creator,
+	})
+	return err
+}
+
+// OpenFile - Open a folder or file, files opened for writing are stored when closed
+func (a *FileSystem) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC) != 0 {
+		return a.create(ctx, name, flag)
+	}
+
+	e, err := a.resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	} else if e.isDir() {
+		return &dirFile{fs: a, ctx: ctx, entry: e}, nil
+	}
+
+	f, err := controllers.OpenContent(e.file.Uri)
+	if err != nil {
+		return nil, err
+	}
+	return &contentFile{File: f, item: e.file}, nil
+}
+
+func (a *FileSystem) create(ctx context.Context, name string, flag int) (webdav.File, error) {
+	parent, base, err := a.resolveParent(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := a.resolve(ctx, name)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	} else if e != nil && (e.isDir() || flag&os.O_EXCL != 0) {
+		return nil, os.ErrExist
+	} else if e == nil && flag&os.O_CREATE == 0 {
+		return nil, os.ErrNotExist
+	}
+
+	if e != nil {
+		err = a.checkFile(ctx, e.file, "PUT", "/api/v1/file/%s/content")
+	} else {
+		err = a.checkPermission(ctx, "", "POST", "/api/v1/file/upload")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := newUploadFile(ctx, a.FileBll, parent.folderID(), base)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil {
+		f.existing = e.file
+	}
+	return f, nil
+}
+
+// Collect the files of a folder and its subfolders, subfolders come before their parents
+func (a *FileSystem) collect(ctx context.Context, folderID string) (schema.Folders, schema.Files, error) {
+	result, err := a.FolderBll.Query(ctx, schema.FolderQueryParam{
+		ParentID: &folderID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var folders schema.Folders
+	var files schema.Files
+	for _, folder := range result.Data {
+		subFolders, subFiles, err := a.collect(ctx, folder.UUID)
+		if err != nil {
+			return nil, nil, err
+		}
+		folders = append(folders, subFolders...)
+		folders = append(folders, folder)
+		files = append(files, subFiles...)
+	}
+
+	fileResult, err := a.FileBll.Query(ctx, schema.FileQueryParam{
+		FolderID: &folderID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	files = append(files, fileResult.Data...)
+	return folders, files, nil
+}
+
+// Refuse the removal as a whole when any of the files is in use
+func (a *FileSystem) checkUsage(ctx context.Context, files schema.Files) error {
+	for _, item := range files {
+		usages, err := a.FileUsageBll.Query(ctx, item.UUID)
+		if err != nil {
+			return err
+		} else if len(usages) > 0 {
+			return errors.NewResponse(409, fmt.Sprintf("File %s is in use (%d usages)", item.Filename, len(usages)), 409)
+		}
+	}
+	return nil
+}
+
+// RemoveAll - Delete a file or a folder with its content, files in use are never deleted
+func (a *FileSystem) RemoveAll(ctx context.Context, name string) error {
+	e, err := a.resolve(ctx, name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !e.isDir() {
+		if err := a.checkFile(ctx, e.file, "DELETE", "/api/v1/file/%s"); err != nil {
+			return err
+		}
+		return a.FileBll.Delete(ctx, e.file.UUID, false)
+	} else if e.folder == nil {
+		return os.ErrPermission
+	}
+
+	folders, files, err := a.collect(ctx, e.folder.UUID)
+	if err != nil {
+		return err
+	}
+
+	// Refuse the removal as a whole when any entry may not be deleted
+	for _, folder := range append(folders, e.folder) {
+		if err := a.checkFolder(ctx, folder, "DELETE", "/api/v1/folders/%s"); err != nil {
+			return err
+		}
+	}
+	for _, item := range files {
+		if err := a.checkFile(ctx, item, "DELETE", "/api/v1/file/%s"); err != nil {
+			return err
+		}
+	}
+	if err := a.checkUsage(ctx, files); err != nil {
+		return err
+	}
+
+	for _, item := range files {
+		if err := a.FileBll.Delete(ctx, item.UUID, false); err != nil {
+			return err
+		}
+	}
+	for _, folder := range append(folders, e.folder) {
+		if err := a.FolderBll.Delete(ctx, folder.UUID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rename - Rename or move a file or folder
+func (a *FileSystem) Rename(ctx context.Context, oldName, newName string) error {
+	e, err := a.resolve(ctx, oldName)
+	if err != nil {
+		return err
+	} else if e.isDir() && e.folder == nil {
+		return os.ErrPermission
+	}
+
+	parent, base, err := a.resolveParent(ctx, newName)
+	if err != nil {
+		return err
+	}
+	if _, err := a.resolve(ctx, newName); err == nil {
+		return os.ErrExist
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if e.isDir() {
+		if err := a.checkFolder(ctx, e.folder, "PUT", "/api/v1/folders/%s"); err != nil {
+			return err
+		}
+
+		item := *e.folder
+		item.Name = base
+		item.ParentID = parent.folderID()
+		_, err = a.FolderBll.Update(ctx, item.UUID, item)
+		return err
+	}
+
+	// The file type is checked on upload only, so it can not change by renaming
+	if !strings.EqualFold(path.Ext(base), path.Ext(e.file.Filename)) {
+		return errors.New400Response("The file extension can not be changed")
+	} else if err := a.checkFile(ctx, e.file, "PUT", "/api/v1/file/%s"); err != nil {
+		return err
+	}
+
+	item, err := a.FileBll.Get(ctx, e.file.UUID, schema.FileQueryOptions{
+		IncludeTags:         true,
+		IncludeTranslations: true,
+	})
+	if err != nil {
+		return err
+	}
+	item.Filename = base
+	item.FolderID = parent.folderID()
+	_, err = a.FileBll.Update(ctx, item.UUID, *item)
+	return err
+}
+
+// Stat - Describe a folder or file
+func (a *FileSystem) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	e, err := a.resolve(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return e.stat(), nil
+}
@@ -0,0 +1,50 @@
+package dav
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MayCMF/core/src/common/config"
+	icontext "github.com/MayCMF/core/src/common/context"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// Check that the current user passes the casbin check of the REST route
+// doing the same, the owner of a file or folder always passes
+func (a *FileSystem) checkPermission(ctx context.Context, owner, method, p string) error {
+	userUUID, ok := icontext.FromUserUUID(ctx)
+	if !ok || userUUID == "" {
+		return errors.ErrInvalidToken
+	} else if userUUID == owner || a.Enforcer == nil || !config.Global().Casbin.Enable {
+		return nil
+	}
+
+	if b, err := a.Enforcer.Enforce(userUUID, p, method); err != nil {
+		return errors.WithStack(err)
+	} else if !b {
+		return errors.ErrNoPerm
+	}
+	return nil
+}
+
+// Private files are only visible to users allowed to download them
+func (a *FileSystem) canRead(ctx context.Context, item *schema.File) (bool, error) {
+	if !item.Private {
+		return true, nil
+	}
+
+	err := a.checkPermission(ctx, item.UserUUID, "GET", fmt.Sprintf("/api/v1/file/%s/download", item.UUID))
+	if err == errors.ErrNoPerm {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (a *FileSystem) checkFile(ctx context.Context, item *schema.File, method, p string) error {
+	return a.checkPermission(ctx, item.UserUUID, method, fmt.Sprintf(p, item.UUID))
+}
+
+func (a *FileSystem) checkFolder(ctx context.Context, folder *schema.Folder, method, p string) error {
+	return a.checkPermission(ctx, folder.Creator, method, fmt.Sprintf(p, folder.UUID))
+}
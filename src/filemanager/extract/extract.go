@@ -0,0 +1,75 @@
+// Package extract reads the plain text of uploaded documents so that their
+// content can be searched. Every format is parsed in pure Go.
+package extract
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode"
+)
+
+// Definition error
+var (
+	ErrUnsupported = errors.New("unsupported document format")
+)
+
+// Func - Text extractor of a document format, r holds the complete document
+type Func func(r io.ReaderAt, size int64) (string, error)
+
+var extractors = map[string]Func{
+	".pdf":  PDF,
+	".docx": DOCX,
+	".xlsx": XLSX,
+	".ods":  ODF,
+	".odt":  ODF,
+}
+
+// Max size of a single part read from a document, protects against zip bombs
+const maxPartSize = 64 << 20
+
+// Supported - Check whether the text of a file extension can be extracted
+func Supported(ext string) bool {
+	_, ok := extractors[strings.ToLower(ext)]
+	return ok
+}
+
+// Text - Extract the plain text of a document by its file extension
+func Text(ext string, r io.ReaderAt, size int64) (string, error) {
+	fn, ok := extractors[strings.ToLower(ext)]
+	if !ok {
+		return "", ErrUnsupported
+	}
+
+	text, err := fn(r, size)
+	if err != nil {
+		return "", err
+	}
+	return normalize(text), nil
+}
+
+func readAll(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxPartSize+1))
+	if err != nil {
+		return nil, err
+	} else if len(data) > maxPartSize {
+		return nil, errors.New("document part too large")
+	}
+	return data, nil
+}
+
+// Collapse runs of blanks, trim the lines and drop empty lines
+func normalize(text string) string {
+	lines := strings.Split(text, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.FieldsFunc(line, func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsControl(r)
+		}), " ")
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
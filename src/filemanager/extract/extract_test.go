@@ -0,0 +1,111 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newZipDoc(parts map[string]string) *bytes.Reader {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, content := range parts {
+		fw, _ := zw.Create(name)
+		_, _ = fw.Write([]byte(content))
+	}
+	_ = zw.Close()
+	return bytes.NewReader(buf.Bytes())
+}
+
+func newPDF(streams ...[]byte) *bytes.Reader {
+	buf := new(bytes.Buffer)
+	buf.WriteString("%PDF-1.4\n")
+	for i, s := range streams {
+		filter := ""
+		if len(s) > 2 && s[0] == 0x78 {
+			filter = " /Filter /FlateDecode"
+		}
+		fmt.Fprintf(buf, "%d 0 obj\n<< /Length %d%s >>\nstream\n", i+4, len(s), filter)
+		buf.Write(s)
+		buf.WriteString("\nendstream\nendobj\n")
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return bytes.NewReader(buf.Bytes())
+}
+
+func deflate(s string) []byte {
+	buf := new(bytes.Buffer)
+	zw := zlib.NewWriter(buf)
+	_, _ = zw.Write([]byte(s))
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestDOCX(t *testing.T) {
+	r := newZipDoc(map[string]string{
+		"word/document.xml": `<?xml version="1.0"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Quarterly</w:t></w:r><w:r><w:t xml:space="preserve"> report</w:t></w:r></w:p>
+<w:p><w:r><w:t>Revenue</w:t><w:tab/><w:t>grew</w:t></w:r></w:p>
+</w:body></w:document>`,
+	})
+
+	text, err := Text(".DOCX", r, r.Size())
+	assert.Nil(t, err)
+	assert.Equal(t, "Quarterly report\nRevenue grew", text)
+}
+
+func TestXLSX(t *testing.T) {
+	r := newZipDoc(map[string]string{
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>Name</t></si><si><r><t>Bo</t></r><r><t>b</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>Score</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>1</v></c><c r="B2"><v>42</v></c></row>
+</sheetData></worksheet>`,
+	})
+
+	text, err := Text(".xlsx", r, r.Size())
+	assert.Nil(t, err)
+	assert.Equal(t, "Name Score\nBob 42", text)
+}
+
+func TestODF(t *testing.T) {
+	r := newZipDoc(map[string]string{
+		"content.xml": `<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet><table:table table:name="Sheet1">
+<table:table-row><table:table-cell><text:p>City</text:p></table:table-cell><table:table-cell><text:p>Kyiv</text:p></table:table-cell></table:table-row>
+<table:table-row><table:table-cell><text:p>Lviv<text:s/>Oblast</text:p></table:table-cell></table:table-row>
+</table:table></office:spreadsheet></office:body></office:document-content>`,
+	})
+
+	text, err := Text(".ods", r, r.Size())
+	assert.Nil(t, err)
+	assert.Equal(t, "City Kyiv\nLviv Oblast", text)
+}
+
+func TestPDF(t *testing.T) {
+	r := newPDF(
+		[]byte("BT /F1 12 Tf 72 712 Td (Hello \\(PDF\\)) Tj 0 -14 Td <48656c6c6f> Tj ET"),
+		deflate("BT /F1 12 Tf 72 600 Td [(Wor) -20 (ld) -400 (again)] TJ ET"),
+	)
+
+	text, err := Text(".pdf", r, r.Size())
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello (PDF)\nHello\nWorld again", text)
+
+	r = bytes.NewReader([]byte("not a pdf"))
+	_, err = Text(".pdf", r, r.Size())
+	assert.NotNil(t, err)
+}
+
+func TestUnsupported(t *testing.T) {
+	assert.False(t, Supported(".json"))
+	assert.True(t, Supported(".PDF"))
+
+	r := bytes.NewReader(nil)
+	_, err := Text(".json", r, 0)
+	assert.Equal(t, ErrUnsupported, err)
+}
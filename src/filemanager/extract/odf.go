@@ -0,0 +1,74 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ODF - Extract the text of an OpenDocument text or spreadsheet, paragraphs
+// and table rows become lines
+func ODF(r io.ReaderAt, size int64) (string, error) {
+	zr, err := openZip(r, size)
+	if err != nil {
+		return "", err
+	}
+	data, err := readPart(zr, "content.xml")
+	if err != nil {
+		return "", err
+	} else if data == nil {
+		return "", errors.New("missing content.xml")
+	}
+
+	var sb strings.Builder
+	body, cellDepth := 0, 0
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "body":
+				body++
+			case "table-cell":
+				cellDepth++
+			case "s":
+				sb.WriteString(" ")
+			case "tab":
+				sb.WriteString("\t")
+			case "line-break":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "body":
+				body--
+			case "table-cell":
+				cellDepth--
+				sb.WriteString("\t")
+			case "table-row":
+				sb.WriteString("\n")
+			case "p", "h":
+				// Paragraphs of a cell stay on the line of their row
+				if cellDepth > 0 {
+					sb.WriteString(" ")
+				} else {
+					sb.WriteString("\n")
+				}
+			}
+		case xml.CharData:
+			if body > 0 {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
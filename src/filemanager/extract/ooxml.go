@@ -0,0 +1,225 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Read a part of a zip based document, nil when the document has no such part
+func readPart(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return readAll(rc)
+	}
+	return nil, nil
+}
+
+// Names of the parts matching a pattern, in natural order (sheet2 before sheet10)
+func partNames(zr *zip.Reader, pattern string) []string {
+	var names []string
+	for _, f := range zr.File {
+		if ok, _ := path.Match(pattern, f.Name); ok {
+			names = append(names, f.Name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func openZip(r io.ReaderAt, size int64) (*zip.Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("invalid document archive: " + err.Error())
+	}
+	return zr, nil
+}
+
+// DOCX - Extract the text of a Word document, paragraphs become lines
+func DOCX(r io.ReaderAt, size int64) (string, error) {
+	zr, err := openZip(r, size)
+	if err != nil {
+		return "", err
+	}
+	data, err := readPart(zr, "word/document.xml")
+	if err != nil {
+		return "", err
+	} else if data == nil {
+		return "", errors.New("missing word/document.xml")
+	}
+
+	var sb strings.Builder
+	inText := false
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// Text of the string items of a spreadsheet, <si> holds rich text runs
+func readSharedStrings(data []byte) ([]string, error) {
+	var list []string
+	var sb strings.Builder
+	inText := false
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				sb.Reset()
+			case "t":
+				inText = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				list = append(list, sb.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return list, nil
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// XLSX - Extract the cell values of an Excel workbook, rows become lines
+func XLSX(r io.ReaderAt, size int64) (string, error) {
+	zr, err := openZip(r, size)
+	if err != nil {
+		return "", err
+	}
+
+	var shared []string
+	data, err := readPart(zr, "xl/sharedStrings.xml")
+	if err != nil {
+		return "", err
+	} else if data != nil {
+		shared, err = readSharedStrings(data)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var sb strings.Builder
+	for _, name := range partNames(zr, "xl/worksheets/sheet*.xml") {
+		data, err := readPart(zr, name)
+		if err != nil {
+			return "", err
+		}
+
+		var cellType string
+		var value strings.Builder
+		inValue := false
+		d := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			tok, err := d.Token()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return "", err
+			}
+
+			switch t := tok.(type) {
+			case xml.StartElement:
+				switch t.Name.Local {
+				case "c":
+					cellType = attr(t, "t")
+					value.Reset()
+				case "v", "t":
+					inValue = true
+				}
+			case xml.EndElement:
+				switch t.Name.Local {
+				case "v", "t":
+					inValue = false
+				case "c":
+					v := value.String()
+					if cellType == "s" {
+						if i, err := strconv.Atoi(v); err == nil && i >= 0 && i < len(shared) {
+							v = shared[i]
+						}
+					}
+					if v != "" {
+						sb.WriteString(v)
+						sb.WriteString("\t")
+					}
+				case "row":
+					sb.WriteString("\n")
+				}
+			case xml.CharData:
+				if inValue {
+					value.Write(t)
+				}
+			}
+		}
+	}
+	return sb.String(), nil
+}
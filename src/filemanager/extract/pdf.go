@@ -0,0 +1,328 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Max size of a decompressed PDF stream
+const maxStreamSize = 16 << 20
+
+// PDF - Extract the text shown by the content streams of a PDF document.
+// Text of fonts with simple encodings is read, glyphs of embedded CID fonts
+// without a standard encoding can not be mapped back to characters.
+func PDF(r io.ReaderAt, size int64) (string, error) {
+	data, err := readAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return "", err
+	}
+
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if !bytes.Contains(head, []byte("%PDF-")) {
+		return "", errors.New("invalid PDF document")
+	} else if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", errors.New("encrypted PDF documents are not supported")
+	}
+
+	var sb strings.Builder
+	pos := 0
+	for {
+		i := bytes.Index(data[pos:], []byte("stream"))
+		if i < 0 {
+			break
+		}
+		start := pos + i
+		pos = start + len("stream")
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			continue
+		}
+
+		// The keyword is followed by an end of line, then the stream data
+		body := pos
+		if body < len(data) && data[body] == '\r' {
+			body++
+		}
+		if body >= len(data) || data[body] != '\n' {
+			continue
+		}
+		body++
+
+		end := bytes.Index(data[body:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		raw := data[body : body+end]
+		pos = body + end + len("endstream")
+
+		content, ok := decodeStream(streamDict(data, start), raw)
+		if ok && bytes.Contains(content, []byte("BT")) {
+			writeContentText(&sb, content)
+		}
+	}
+	return sb.String(), nil
+}
+
+// Dictionary of the stream starting at pos, from the object header to the stream keyword
+func streamDict(data []byte, pos int) []byte {
+	from := pos - 4096
+	if from < 0 {
+		from = 0
+	}
+	dict := data[from:pos]
+	if i := bytes.LastIndex(dict, []byte("obj")); i >= 0 {
+		dict = dict[i:]
+	}
+	return dict
+}
+
+// Decode the data of a content stream, streams of images, fonts and other
+// filters than FlateDecode are skipped
+func decodeStream(dict, raw []byte) ([]byte, bool) {
+	for _, v := range []string{"/Image", "/FontFile", "/XRef", "/ObjStm", "/Metadata"} {
+		if bytes.Contains(dict, []byte(v)) {
+			return nil, false
+		}
+	}
+
+	if !bytes.Contains(dict, []byte("/Filter")) {
+		return raw, true
+	} else if !bytes.Contains(dict, []byte("/FlateDecode")) || bytes.Contains(dict, []byte("/DecodeParms")) {
+		return nil, false
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, false
+	}
+	defer zr.Close()
+
+	// A trailing end of line may break the checksum, the data read so far is kept
+	content, _ := readStream(zr)
+	return content, len(content) > 0
+}
+
+func readStream(r io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := io.Copy(&buf, io.LimitReader(r, maxStreamSize))
+	return buf.Bytes(), err
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// Read a regular token (operator, number or name body) starting at i
+func readToken(content []byte, i int) (string, int) {
+	j := i
+	for j < len(content) && !isPDFSpace(content[j]) && !isPDFDelimiter(content[j]) {
+		j++
+	}
+	return string(content[i:j]), j
+}
+
+// Read a literal string starting at the opening parenthesis
+func readLiteral(content []byte, i int) ([]byte, int) {
+	var out []byte
+	depth := 0
+	for i++; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			switch e := content[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					for k := 0; k < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; k++ {
+						n = n*8 + int(content[i]-'0')
+						i++
+					}
+					i--
+					out = append(out, byte(n))
+				} else {
+					out = append(out, e)
+				}
+			}
+		case c == '(':
+			depth++
+			out = append(out, c)
+		case c == ')':
+			if depth == 0 {
+				return out, i + 1
+			}
+			depth--
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, i
+}
+
+// Read a hex string starting at the opening angle bracket
+func readHex(content []byte, i int) ([]byte, int) {
+	var digits []byte
+	for i++; i < len(content) && content[i] != '>'; i++ {
+		if c := content[i]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, 0, len(digits)/2)
+	for k := 0; k < len(digits); k += 2 {
+		v, err := strconv.ParseUint(string(digits[k:k+2]), 16, 8)
+		if err != nil {
+			break
+		}
+		out = append(out, byte(v))
+	}
+	return out, i + 1
+}
+
+// Skip a dictionary starting at the opening double angle bracket
+func skipDict(content []byte, i int) int {
+	depth := 0
+	for i < len(content)-1 {
+		if content[i] == '<' && content[i+1] == '<' {
+			depth++
+			i += 2
+		} else if content[i] == '>' && content[i+1] == '>' {
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		} else if content[i] == '(' {
+			_, i = readLiteral(content, i)
+		} else {
+			i++
+		}
+	}
+	return len(content)
+}
+
+// Decode a shown string, UTF-16 with byte order mark or a single byte encoding
+func decodePDFString(s []byte) string {
+	if len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff {
+		u := make([]uint16, 0, len(s)/2)
+		for k := 2; k+1 < len(s); k += 2 {
+			u = append(u, uint16(s[k])<<8|uint16(s[k+1]))
+		}
+		return string(utf16.Decode(u))
+	}
+
+	rs := make([]rune, 0, len(s))
+	for _, c := range s {
+		if c >= 0x20 || c == '\t' || c == '\n' {
+			rs = append(rs, rune(c))
+		}
+	}
+	return string(rs)
+}
+
+// Write the text shown by the operators of a content stream
+func writeContentText(sb *strings.Builder, content []byte) {
+	var texts []string
+	var nums []float64
+	lastY, hasY := 0.0, false
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case isPDFSpace(c):
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			var s []byte
+			s, i = readLiteral(content, i)
+			texts = append(texts, decodePDFString(s))
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i = skipDict(content, i)
+		case c == '<':
+			var s []byte
+			s, i = readHex(content, i)
+			texts = append(texts, decodePDFString(s))
+		case c == '[' || c == ']' || c == '{' || c == '}' || c == '>' || c == ')':
+			i++
+		case c == '/':
+			_, i = readToken(content, i+1)
+		default:
+			var tok string
+			tok, i = readToken(content, i)
+			if tok == "" {
+				i++
+				continue
+			}
+			if v, err := strconv.ParseFloat(tok, 64); err == nil {
+				// A large negative adjustment inside TJ separates words
+				if v < -200 && len(texts) > 0 {
+					texts = append(texts, " ")
+				}
+				nums = append(nums, v)
+				continue
+			}
+
+			switch tok {
+			case "Tj", "TJ":
+				sb.WriteString(strings.Join(texts, ""))
+			case "'", "\"":
+				sb.WriteString("\n")
+				sb.WriteString(strings.Join(texts, ""))
+			case "T*", "ET":
+				sb.WriteString("\n")
+			case "Td", "TD":
+				if len(nums) >= 2 && nums[len(nums)-1] != 0 {
+					sb.WriteString("\n")
+				} else {
+					sb.WriteString(" ")
+				}
+			case "Tm":
+				if len(nums) >= 6 {
+					y := nums[len(nums)-1]
+					if hasY && y != lastY {
+						sb.WriteString("\n")
+					} else {
+						sb.WriteString(" ")
+					}
+					lastY, hasY = y, true
+				}
+			case "BI":
+				// Inline image data runs up to the EI operator
+				if k := bytes.Index(content[i:], []byte("EI")); k >= 0 {
+					i += k + 2
+				} else {
+					i = len(content)
+				}
+			}
+			texts = texts[:0]
+			nums = nums[:0]
+		}
+	}
+}
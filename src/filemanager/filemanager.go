@@ -5,13 +5,25 @@ import (
 	"github.com/MayCMF/core/src/filemanager/controllers/implement"
 	"github.com/MayCMF/core/src/filemanager/model"
 	imodel "github.com/MayCMF/core/src/filemanager/model/impl/gorm/model"
+	"github.com/MayCMF/core/src/filemanager/scanner"
 	"go.uber.org/dig"
 )
 
 // Inject - injection controllers implementation
 func InjectControllers(container *dig.Container) error {
+	_ = container.Provide(scanner.NewChain)
 	_ = container.Provide(implement.NewFile)
 	_ = container.Provide(func(b *implement.File) controllers.IFile { return b })
+	_ = container.Provide(implement.NewFolder)
+	_ = container.Provide(func(b *implement.Folder) controllers.IFolder { return b })
+	_ = container.Provide(implement.NewArchive)
+	_ = container.Provide(func(b *implement.Archive) controllers.IArchive { return b })
+	_ = container.Provide(implement.NewFileUsage)
+	_ = container.Provide(func(b *implement.FileUsage) controllers.IFileUsage { return b })
+	_ = container.Provide(implement.NewReconcile)
+	_ = container.Provide(func(b *implement.Reconcile) controllers.IReconcile { return b })
+	_ = container.Provide(implement.NewFileText)
+	_ = container.Provide(func(b *implement.FileText) controllers.IFileText { return b })
 	return nil
 }
 
@@ -19,5 +31,13 @@ func InjectControllers(container *dig.Container) error {
 func InjectStarage(container *dig.Container) error {
 	_ = container.Provide(imodel.NewFile)
 	_ = container.Provide(func(m *imodel.File) model.IFile { return m })
+	_ = container.Provide(imodel.NewFolder)
+	_ = container.Provide(func(m *imodel.Folder) model.IFolder { return m })
+	_ = container.Provide(imodel.NewFileJob)
+	_ = container.Provide(func(m *imodel.FileJob) model.IFileJob { return m })
+	_ = container.Provide(imodel.NewFileUsage)
+	_ = container.Provide(func(m *imodel.FileUsage) model.IFileUsage { return m })
+	_ = container.Provide(imodel.NewFileText)
+	_ = container.Provide(func(m *imodel.FileText) model.IFileText { return m })
 	return nil
 }
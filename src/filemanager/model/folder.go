@@ -0,0 +1,23 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IFolder Folder storage interface
+type IFolder interface {
+	// Query data
+	Query(ctx context.Context, params schema.FolderQueryParam, opts ...schema.FolderQueryOptions) (*schema.FolderQueryResult, error)
+	// Query specified data
+	Get(ctx context.Context, UUID string, opts ...schema.FolderQueryOptions) (*schema.Folder, error)
+	// Create data
+	Create(ctx context.Context, item schema.Folder) error
+	// Update data
+	Update(ctx context.Context, UUID string, item schema.Folder) error
+	// Update parent path
+	UpdateParentPath(ctx context.Context, UUID, parentPath string) error
+	// Delete data
+	Delete(ctx context.Context, UUID string) error
+}
@@ -20,4 +20,10 @@ type IFile interface {
 	Delete(ctx context.Context, UUID string) error
 	// Upload File
 	Upload(ctx context.Context, item schema.File) error
+	// Increase the download counter
+	IncreaseDownloads(ctx context.Context, UUID string) error
+	// Update the scan status and the storage location
+	UpdateScanStatus(ctx context.Context, UUID, status, threat, uri string) error
+	// Update the stored content and its metadata
+	UpdateContent(ctx context.Context, UUID string, item schema.File) error
 }
@@ -2,9 +2,12 @@ package entity
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/MayCMF/core/src/common/entity"
 	"github.com/MayCMF/core/src/filemanager/schema"
+	i18n "github.com/MayCMF/core/src/i18n/model/impl/gorm/entity"
 	"github.com/jinzhu/gorm"
 )
 
@@ -13,6 +16,16 @@ func GetFileDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
 	return entity.GetDBWithModel(ctx, defDB, File{})
 }
 
+// GetFileTagDB - Get the File tag association store
+func GetFileTagDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, FileTag{})
+}
+
+// GetFileTranslationDB - Get the File translation store
+func GetFileTranslationDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, FileTranslation{})
+}
+
 // SchemaFile - File object
 type SchemaFile schema.File
 
@@ -21,23 +34,75 @@ func (a SchemaFile) ToFile() *File {
 	item := &File{
 		UUID:     a.UUID,
 		UID:      &a.UID,
+		FolderID: &a.FolderID,
 		Filename: &a.Filename,
 		Uri:      &a.Uri,
 		Filemime: &a.Filemime,
 		Filesize: a.Filesize,
+		Width:    a.Width,
+		Height:   a.Height,
+		Duration: a.Duration,
+		Private:  &a.Private,
+
+		ScanStatus: &a.ScanStatus,
+		ScanThreat: &a.ScanThreat,
+
+		Orientation: a.Orientation,
+		CameraMake:  &a.CameraMake,
+		CameraModel: &a.CameraModel,
+		TakenAt:     a.TakenAt,
+		Latitude:    a.Latitude,
+		Longitude:   a.Longitude,
 	}
 	return item
 }
 
+// ToFileTags - Convert to File tag association list
+func (a SchemaFile) ToFileTags() []*FileTag {
+	list := make([]*FileTag, len(a.Tags))
+	for i, tag := range a.Tags {
+		list[i] = &FileTag{
+			FileUUID: a.UUID,
+			Tag:      tag,
+		}
+	}
+	return list
+}
+
+// ToFileTranslations - Convert to File translation list
+func (a SchemaFile) ToFileTranslations() []*FileTranslation {
+	list := make([]*FileTranslation, len(a.Translations))
+	for i, item := range a.Translations {
+		list[i] = SchemaFileTranslation(*item).ToFileTranslation(a.UUID)
+	}
+	return list
+}
+
 // File - File entity
 type File struct {
 	entity.Model
-	UUID     string  `gorm:"column:uuid;size:36;index;"`      // UUID code
-	UID      *uint   `gorm:"column:uid;size:50;index;"`       // User ID
-	Filename *string `gorm:"column:filename;size:100;index;"` // File Name
-	Uri      *string `gorm:"column:uri;size:200;"`            // File URI
-	Filemime *string `gorm:"column:filemime;index;"`          // Filemime (image/jpeg, application/msword etc)
-	Filesize int64   `gorm:"column:filesize;size:100;"`       // Filesize in bytes
+	UUID      string  `gorm:"column:uuid;size:36;index;"`      // UUID code
+	UID       *uint   `gorm:"column:uid;size:50;index;"`       // User ID
+	FolderID  *string `gorm:"column:folder_id;size:36;index;"` // Folder UUID
+	Filename  *string `gorm:"column:filename;size:100;index;"` // File Name
+	Uri       *string `gorm:"column:uri;size:200;"`            // File URI
+	Filemime  *string `gorm:"column:filemime;index;"`          // Filemime (image/jpeg, application/msword etc)
+	Filesize  int64   `gorm:"column:filesize;size:100;"`       // Filesize in bytes
+	Width     int     `gorm:"column:width;"`                   // Width in pixels
+	Height    int     `gorm:"column:height;"`                  // Height in pixels
+	Duration  int     `gorm:"column:duration;"`                // Duration in seconds
+	Private   *bool   `gorm:"column:private;index;"`           // Private file
+	Downloads int64   `gorm:"column:downloads;"`               // Download counter
+
+	ScanStatus *string `gorm:"column:scan_status;size:20;index;"` // Scan status
+	ScanThreat *string `gorm:"column:scan_threat;size:200;"`      // Threat reported by the scanner
+
+	Orientation int        `gorm:"column:orientation;"`           // EXIF orientation
+	CameraMake  *string    `gorm:"column:camera_make;size:100;"`  // Camera manufacturer
+	CameraModel *string    `gorm:"column:camera_model;size:100;"` // Camera model
+	TakenAt     *time.Time `gorm:"column:taken_at;index;"`        // Capture time
+	Latitude    *float64   `gorm:"column:latitude;"`              // GPS latitude
+	Longitude   *float64   `gorm:"column:longitude;"`             // GPS longitude
 }
 
 func (a File) String() string {
@@ -58,7 +123,35 @@ func (a File) ToSchemaFile() *schema.File {
 		Uri:       *a.Uri,
 		Filemime:  *a.Filemime,
 		Filesize:  a.Filesize,
+		Width:     a.Width,
+		Height:    a.Height,
+		Duration:  a.Duration,
+		Downloads: a.Downloads,
 		CreatedAt: a.CreatedAt,
+
+		Orientation: a.Orientation,
+		TakenAt:     a.TakenAt,
+		Latitude:    a.Latitude,
+		Longitude:   a.Longitude,
+	}
+	// Files uploaded before folders existed have no folder column value
+	if a.FolderID != nil {
+		item.FolderID = *a.FolderID
+	}
+	if a.Private != nil {
+		item.Private = *a.Private
+	}
+	if a.ScanStatus != nil {
+		item.ScanStatus = *a.ScanStatus
+	}
+	if a.ScanThreat != nil {
+		item.ScanThreat = *a.ScanThreat
+	}
+	if a.CameraMake != nil {
+		item.CameraMake = *a.CameraMake
+	}
+	if a.CameraModel != nil {
+		item.CameraModel = *a.CameraModel
 	}
 	return item
 }
@@ -74,3 +167,83 @@ func (a Files) ToSchemaFiles() []*schema.File {
 	}
 	return list
 }
+
+// FileTag - File tag association entity
+type FileTag struct {
+	entity.Model
+	FileUUID string `gorm:"column:file_uuid;size:36;index;"` // File UUID
+	Tag      string `gorm:"column:tag;size:100;index;"`      // Tag
+}
+
+// TableName - Table Name
+func (a FileTag) TableName() string {
+	return a.Model.TableName("filemanager_tag")
+}
+
+// FileTags - File tag association list
+type FileTags []*FileTag
+
+// GetByFileUUID - Get the tag list based on the file UUID
+func (a FileTags) GetByFileUUID(fileUUID string) []string {
+	var list []string
+	for _, item := range a {
+		if item.FileUUID == fileUUID {
+			list = append(list, item.Tag)
+		}
+	}
+	return list
+}
+
+// SchemaFileTranslation - File translation object
+type SchemaFileTranslation schema.FileTranslation
+
+// ToFileTranslation - Convert to File translation entity
+func (a SchemaFileTranslation) ToFileTranslation(fileUUID string) *FileTranslation {
+	return &FileTranslation{
+		FileUUID: fileUUID,
+		Lang:     a.Lang,
+		Title:    &a.Title,
+		Alt:      &a.Alt,
+		Caption:  &a.Caption,
+	}
+}
+
+// FileTranslation - File translation entity
+type FileTranslation struct {
+	entity.Model
+	FileUUID string        `gorm:"column:file_uuid;size:36;index;"`             // File UUID
+	Language i18n.Language `gorm:"foreignkey:Lang;association_foreignkey:Code"` // Language Code Identifier use Code as foreign key
+	Lang     string        `gorm:"column:language;size:50;index;"`              // Language Code Identifier
+	Title    *string       `gorm:"column:title;size:255;"`                      // File Title
+	Alt      *string       `gorm:"column:alt;size:255;"`                        // Alternative text
+	Caption  *string       `gorm:"column:caption;"`                             // Caption
+}
+
+// TableName - Table Name
+func (a FileTranslation) TableName() string {
+	return fmt.Sprintf("%s%s", entity.GetTablePrefix(), "filemanager_translation")
+}
+
+// ToSchemaFileTranslation - Convert to File translation object
+func (a FileTranslation) ToSchemaFileTranslation() *schema.FileTranslation {
+	return &schema.FileTranslation{
+		Lang:    a.Lang,
+		Title:   *a.Title,
+		Alt:     *a.Alt,
+		Caption: *a.Caption,
+	}
+}
+
+// FileTranslations - File translation list
+type FileTranslations []*FileTranslation
+
+// GetByFileUUID - Get the translation list based on the file UUID
+func (a FileTranslations) GetByFileUUID(fileUUID string) []*schema.FileTranslation {
+	var list []*schema.FileTranslation
+	for _, item := range a {
+		if item.FileUUID == fileUUID {
+			list = append(list, item.ToSchemaFileTranslation())
+		}
+	}
+	return list
+}
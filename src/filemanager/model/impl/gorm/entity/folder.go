@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/jinzhu/gorm"
+)
+
+// GetFolderDB - Get the Folder store
+func GetFolderDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, Folder{})
+}
+
+// SchemaFolder - Folder object
+type SchemaFolder schema.Folder
+
+// ToFolder - Convert to Folder entity
+func (a SchemaFolder) ToFolder() *Folder {
+	item := &Folder{
+		UUID:       a.UUID,
+		Name:       &a.Name,
+		Sequence:   &a.Sequence,
+		ParentID:   &a.ParentID,
+		ParentPath: &a.ParentPath,
+		Creator:    &a.Creator,
+	}
+	return item
+}
+
+// Folder - Folder entity
+type Folder struct {
+	entity.Model
+	UUID       string  `gorm:"column:uuid;size:36;index;"`         // UUID code
+	Name       *string `gorm:"column:name;size:100;index;"`        // Folder name
+	Sequence   *int    `gorm:"column:sequence;index;"`             // Sort value
+	ParentID   *string `gorm:"column:parent_id;size:36;index;"`    // Parent folder UUID
+	ParentPath *string `gorm:"column:parent_path;size:518;index;"` // Parent path
+	Creator    *string `gorm:"column:creator;size:36;"`            // Creator
+}
+
+func (a Folder) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a Folder) TableName() string {
+	return a.Model.TableName("filemanager_folder")
+}
+
+// ToSchemaFolder - Convert to Folder object
+func (a Folder) ToSchemaFolder() *schema.Folder {
+	item := &schema.Folder{
+		UUID:       a.UUID,
+		Name:       *a.Name,
+		Sequence:   *a.Sequence,
+		ParentID:   *a.ParentID,
+		ParentPath: *a.ParentPath,
+		Creator:    *a.Creator,
+		CreatedAt:  a.CreatedAt,
+	}
+	return item
+}
+
+// Folders - Folder list
+type Folders []*Folder
+
+// ToSchemaFolders - Convert to Folder object list
+func (a Folders) ToSchemaFolders() []*schema.Folder {
+	list := make([]*schema.Folder, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaFolder()
+	}
+	return list
+}
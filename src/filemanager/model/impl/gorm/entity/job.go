@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"context"
+	"strings"
+
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/jinzhu/gorm"
+)
+
+// GetFileJobDB - Get the File job store
+func GetFileJobDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, FileJob{})
+}
+
+// SchemaFileJob - File job object
+type SchemaFileJob schema.FileJob
+
+// ToFileJob - Convert to File job entity
+func (a SchemaFileJob) ToFileJob() *FileJob {
+	errors := strings.Join(a.Errors, "\n")
+	item := &FileJob{
+		UUID:      a.UUID,
+		Type:      &a.Type,
+		Status:    &a.Status,
+		Total:     &a.Total,
+		Processed: &a.Processed,
+		Failed:    &a.Failed,
+		Errors:    &errors,
+		Result:    &a.Result,
+		Creator:   &a.Creator,
+	}
+	return item
+}
+
+// FileJob - File job entity
+type FileJob struct {
+	entity.Model
+	UUID      string  `gorm:"column:uuid;size:36;index;"` // UUID code
+	Type      *string `gorm:"column:type;size:20;"`       // Job type
+	Status    *string `gorm:"column:status;size:20;"`     // Job status
+	Total     *int    `gorm:"column:total;"`              // Number of entries
+	Processed *int    `gorm:"column:processed;"`          // Number of entries processed
+	Failed    *int    `gorm:"column:failed;"`             // Number of entries rejected
+	Errors    *string `gorm:"column:errors;type:text;"`   // Rejection reasons, one per line
+	Result    *string `gorm:"column:result;size:200;"`    // Path of the built archive
+	Creator   *string `gorm:"column:creator;size:36;"`    // Creator
+}
+
+func (a FileJob) String() string {
+	return entity.ToString(a)
+}
+
+// TableName - Table Name
+func (a FileJob) TableName() string {
+	return a.Model.TableName("filemanager_job")
+}
+
+// ToSchemaFileJob - Convert to File job object
+func (a FileJob) ToSchemaFileJob() *schema.FileJob {
+	item := &schema.FileJob{
+		UUID:      a.UUID,
+		Type:      *a.Type,
+		Status:    *a.Status,
+		Total:     *a.Total,
+		Processed: *a.Processed,
+		Failed:    *a.Failed,
+		Errors:    []string{},
+		Result:    *a.Result,
+		Creator:   *a.Creator,
+		CreatedAt: a.CreatedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+	if a.Errors != nil && *a.Errors != "" {
+		item.Errors = strings.Split(*a.Errors, "\n")
+	}
+	return item
+}
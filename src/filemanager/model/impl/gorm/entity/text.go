@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/jinzhu/gorm"
+)
+
+// GetFileTextDB - Get the File text store
+func GetFileTextDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, FileText{})
+}
+
+// SchemaFileText - File text object
+type SchemaFileText schema.FileText
+
+// ToFileText - Convert to File text entity
+func (a SchemaFileText) ToFileText() *FileText {
+	item := &FileText{
+		FileID:  a.FileID,
+		Content: &a.Content,
+	}
+	return item
+}
+
+// FileText - File text entity
+type FileText struct {
+	entity.Model
+	FileID  string  `gorm:"column:file_uuid;size:36;index;"` // File UUID
+	Content *string `gorm:"column:content;type:text;"`       // Extracted text
+}
+
+// TableName - Table Name
+func (a FileText) TableName() string {
+	return a.Model.TableName("filemanager_text")
+}
+
+// ToSchemaFileText - Convert to File text object
+func (a FileText) ToSchemaFileText() *schema.FileText {
+	item := &schema.FileText{
+		FileID:    a.FileID,
+		CreatedAt: a.CreatedAt,
+	}
+	if a.Content != nil {
+		item.Content = *a.Content
+	}
+	return item
+}
+
+// FileTexts - File text list
+type FileTexts []*FileText
+
+// ToSchemaFileTexts - Convert to File text object list
+func (a FileTexts) ToSchemaFileTexts() []*schema.FileText {
+	list := make([]*schema.FileText, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaFileText()
+	}
+	return list
+}
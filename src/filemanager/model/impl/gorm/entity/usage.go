@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/common/entity"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/jinzhu/gorm"
+)
+
+// GetFileUsageDB - Get the File usage store
+func GetFileUsageDB(ctx context.Context, defDB *gorm.DB) *gorm.DB {
+	return entity.GetDBWithModel(ctx, defDB, FileUsage{})
+}
+
+// SchemaFileUsage - File usage object
+type SchemaFileUsage schema.FileUsage
+
+// ToFileUsage - Convert to File usage entity
+func (a SchemaFileUsage) ToFileUsage() *FileUsage {
+	item := &FileUsage{
+		FileID:     a.FileID,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Field:      a.Field,
+	}
+	return item
+}
+
+// FileUsage - File usage entity
+type FileUsage struct {
+	entity.Model
+	FileID     string `gorm:"column:file_uuid;size:36;index;"`   // File UUID
+	EntityType string `gorm:"column:entity_type;size:50;index;"` // Entity type
+	EntityID   string `gorm:"column:entity_id;size:36;index;"`   // Entity UUID
+	Field      string `gorm:"column:field;size:100;"`            // Entity field
+}
+
+// TableName - Table Name
+func (a FileUsage) TableName() string {
+	return a.Model.TableName("filemanager_usage")
+}
+
+// ToSchemaFileUsage - Convert to File usage object
+func (a FileUsage) ToSchemaFileUsage() *schema.FileUsage {
+	item := &schema.FileUsage{
+		FileID:     a.FileID,
+		EntityType: a.EntityType,
+		EntityID:   a.EntityID,
+		Field:      a.Field,
+		CreatedAt:  a.CreatedAt,
+	}
+	return item
+}
+
+// FileUsages - File usage list
+type FileUsages []*FileUsage
+
+// ToSchemaFileUsages - Convert to File usage object list
+func (a FileUsages) ToSchemaFileUsages() []*schema.FileUsage {
+	list := make([]*schema.FileUsage, len(a))
+	for i, item := range a {
+		list[i] = item.ToSchemaFileUsage()
+	}
+	return list
+}
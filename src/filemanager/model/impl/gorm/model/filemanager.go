@@ -31,6 +31,9 @@ func (a *File) getQueryOption(opts ...schema.FileQueryOptions) schema.FileQueryO
 // Query - Query data
 func (a *File) Query(ctx context.Context, params schema.FileQueryParam, opts ...schema.FileQueryOptions) (*schema.FileQueryResult, error) {
 	db := entity.GetFileDB(ctx, a.db)
+	if v := params.UUIDs; len(v) > 0 {
+		db = db.Where("uuid IN(?)", v)
+	}
 	if v := params.Filename; v != "" {
 		db = db.Where("filename=?", v)
 	}
@@ -40,6 +43,39 @@ func (a *File) Query(ctx context.Context, params schema.FileQueryParam, opts ...
 	if v := params.Uri; v != "" {
 		db = db.Where("uri=?", v)
 	}
+	if v := params.Uris; len(v) > 0 {
+		db = db.Where("uri IN(?)", v)
+	}
+	if v := params.FolderID; v != nil {
+		if *v == "" {
+			db = db.Where("folder_id=? OR folder_id IS NULL", *v)
+		} else {
+			db = db.Where("folder_id=?", *v)
+		}
+	}
+	if v := params.Tags; len(v) > 0 {
+		subQuery := entity.GetFileTagDB(ctx, a.db).Select("file_uuid").Where("tag IN(?)", v).SubQuery()
+		db = db.Where("uuid IN(?)", subQuery)
+	}
+	if v := params.Type; v != "" {
+		db = db.Where("filemime=? OR filemime LIKE ?", v, v+"/%")
+	}
+	if v := params.Camera; v != "" {
+		db = db.Where("camera_make LIKE ? OR camera_model LIKE ?", "%"+v+"%", "%"+v+"%")
+	}
+	if v := params.HasGPS; v != nil {
+		if *v {
+			db = db.Where("latitude IS NOT NULL")
+		} else {
+			db = db.Where("latitude IS NULL")
+		}
+	}
+	if v := params.ScanStatus; v != "" {
+		db = db.Where("scan_status=?", v)
+	} else {
+		db = db.Where("scan_status IS NULL OR scan_status NOT IN(?)",
+			[]string{schema.ScanStatusQuarantine, schema.ScanStatusInfected})
+	}
 	db = db.Order("id DESC")
 
 	opt := a.getQueryOption(opts...)
@@ -53,9 +89,52 @@ func (a *File) Query(ctx context.Context, params schema.FileQueryParam, opts ...
 		Data:       list.ToSchemaFiles(),
 	}
 
+	err = a.fillSchemaFiles(ctx, qr.Data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	return qr, nil
 }
 
+// Populate File object data
+func (a *File) fillSchemaFiles(ctx context.Context, items []*schema.File, opts ...schema.FileQueryOptions) error {
+	opt := a.getQueryOption(opts...)
+
+	if opt.IncludeTags || opt.IncludeTranslations {
+		fileUUIDs := schema.Files(items).ToUUIDs()
+
+		var tagList entity.FileTags
+		if opt.IncludeTags {
+			items, err := a.queryTags(ctx, fileUUIDs...)
+			if err != nil {
+				return err
+			}
+			tagList = items
+		}
+
+		var translationList entity.FileTranslations
+		if opt.IncludeTranslations {
+			items, err := a.queryTranslations(ctx, fileUUIDs...)
+			if err != nil {
+				return err
+			}
+			translationList = items
+		}
+
+		for i, item := range items {
+			if len(tagList) > 0 {
+				items[i].Tags = tagList.GetByFileUUID(item.UUID)
+			}
+			if len(translationList) > 0 {
+				items[i].Translations = translationList.GetByFileUUID(item.UUID)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Get - Query specified data
 func (a *File) Get(ctx context.Context, UUID string, opts ...schema.FileQueryOptions) (*schema.File, error) {
 	db := entity.GetFileDB(ctx, a.db).Where("uuid=?", UUID)
@@ -67,23 +146,57 @@ func (a *File) Get(ctx context.Context, UUID string, opts ...schema.FileQueryOpt
 		return nil, nil
 	}
 
-	return item.ToSchemaFile(), nil
+	sitem := item.ToSchemaFile()
+	err = a.fillSchemaFiles(ctx, []*schema.File{sitem}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sitem, nil
 }
 
 // Create - Create data
 func (a *File) Create(ctx context.Context, item schema.File) error {
-	file := entity.SchemaFile(item).ToFile()
-	result := entity.GetFileDB(ctx, a.db).Create(file)
-	if err := result.Error; err != nil {
-		return errors.WithStack(err)
+	return model.ExecTrans(ctx, a.db, func(ctx context.Context) error {
+		sitem := entity.SchemaFile(item)
+		result := entity.GetFileDB(ctx, a.db).Create(sitem.ToFile())
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		return a.createRelations(ctx, sitem)
+	})
+}
+
+// Upload - Create data for an uploaded file
+func (a *File) Upload(ctx context.Context, item schema.File) error {
+	return a.Create(ctx, item)
+}
+
+func (a *File) createRelations(ctx context.Context, sitem entity.SchemaFile) error {
+	for _, eitem := range sitem.ToFileTags() {
+		result := entity.GetFileTagDB(ctx, a.db).Create(eitem)
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for _, eitem := range sitem.ToFileTranslations() {
+		result := entity.GetFileTranslationDB(ctx, a.db).Create(eitem)
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
 	}
 	return nil
 }
 
-// Create - Create data
-func (a *File) Upload(ctx context.Context, item schema.File) error {
-	file := entity.SchemaFile(item).ToFile()
-	result := entity.GetFileDB(ctx, a.db).Create(file)
+func (a *File) deleteRelations(ctx context.Context, UUID string) error {
+	result := entity.GetFileTagDB(ctx, a.db).Where("file_uuid=?", UUID).Delete(entity.FileTag{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	result = entity.GetFileTranslationDB(ctx, a.db).Where("file_uuid=?", UUID).Delete(entity.FileTranslation{})
 	if err := result.Error; err != nil {
 		return errors.WithStack(err)
 	}
@@ -92,19 +205,95 @@ func (a *File) Upload(ctx context.Context, item schema.File) error {
 
 // Update - Update data
 func (a *File) Update(ctx context.Context, UUID string, item schema.File) error {
-	file := entity.SchemaFile(item).ToFile()
-	result := entity.GetFileDB(ctx, a.db).Where("uuid=?", UUID).Omit("uuid", "creator").Updates(file)
+	return model.ExecTrans(ctx, a.db, func(ctx context.Context) error {
+		sitem := entity.SchemaFile(item)
+		result := entity.GetFileDB(ctx, a.db).Where("uuid=?", UUID).Omit("uuid", "creator", "downloads", "scan_status", "scan_threat").Updates(sitem.ToFile())
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		// Tags and translations are replaced as a whole
+		if err := a.deleteRelations(ctx, UUID); err != nil {
+			return err
+		}
+		sitem.UUID = UUID
+		return a.createRelations(ctx, sitem)
+	})
+}
+
+// Delete - delete data
+func (a *File) Delete(ctx context.Context, UUID string) error {
+	return model.ExecTrans(ctx, a.db, func(ctx context.Context) error {
+		result := entity.GetFileDB(ctx, a.db).Where("uuid=?", UUID).Delete(entity.File{})
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		return a.deleteRelations(ctx, UUID)
+	})
+}
+
+// IncreaseDownloads - Increase the download counter
+func (a *File) IncreaseDownloads(ctx context.Context, UUID string) error {
+	result := entity.GetFileDB(ctx, a.db).Where("uuid=?", UUID).Update("downloads", gorm.Expr("downloads + ?", 1))
 	if err := result.Error; err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
-// Delete - delete data
-func (a *File) Delete(ctx context.Context, UUID string) error {
-	result := entity.GetFileDB(ctx, a.db).Where("uuid=?", UUID).Delete(entity.File{})
+// UpdateScanStatus - Update the scan status and the storage location
+func (a *File) UpdateScanStatus(ctx context.Context, UUID, status, threat, uri string) error {
+	result := entity.GetFileDB(ctx, a.db).Where("uuid=?", UUID).Updates(map[string]interface{}{
+		"scan_status": status,
+		"scan_threat": threat,
+		"uri":         uri,
+	})
 	if err := result.Error; err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
+
+// UpdateContent - Update the stored content and its metadata
+func (a *File) UpdateContent(ctx context.Context, UUID string, item schema.File) error {
+	result := entity.GetFileDB(ctx, a.db).Where("uuid=?", UUID).Updates(map[string]interface{}{
+		"filename":     item.Filename,
+		"uri":          item.Uri,
+		"filemime":     item.Filemime,
+		"filesize":     item.Filesize,
+		"width":        item.Width,
+		"height":       item.Height,
+		"duration":     item.Duration,
+		"orientation":  item.Orientation,
+		"camera_make":  item.CameraMake,
+		"camera_model": item.CameraModel,
+		"taken_at":     item.TakenAt,
+		"latitude":     item.Latitude,
+		"longitude":    item.Longitude,
+		"scan_status":  item.ScanStatus,
+		"scan_threat":  item.ScanThreat,
+	})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (a *File) queryTags(ctx context.Context, fileUUIDs ...string) (entity.FileTags, error) {
+	var list entity.FileTags
+	result := entity.GetFileTagDB(ctx, a.db).Where("file_uuid IN(?)", fileUUIDs).Find(&list)
+	if err := result.Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return list, nil
+}
+
+func (a *File) queryTranslations(ctx context.Context, fileUUIDs ...string) (entity.FileTranslations, error) {
+	var list entity.FileTranslations
+	result := entity.GetFileTranslationDB(ctx, a.db).Where("file_uuid IN(?)", fileUUIDs).Find(&list)
+	if err := result.Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return list, nil
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/MayCMF/core/src/filemanager/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/jinzhu/gorm"
+)
+
+// NewFolder - Create a Folder storage instance
+func NewFolder(db *gorm.DB) *Folder {
+	return &Folder{db}
+}
+
+// Folder - Folder storage
+type Folder struct {
+	db *gorm.DB
+}
+
+func (a *Folder) getQueryOption(opts ...schema.FolderQueryOptions) schema.FolderQueryOptions {
+	var opt schema.FolderQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Query - Query data
+func (a *Folder) Query(ctx context.Context, params schema.FolderQueryParam, opts ...schema.FolderQueryOptions) (*schema.FolderQueryResult, error) {
+	db := entity.GetFolderDB(ctx, a.db)
+	if v := params.UUIDs; len(v) > 0 {
+		db = db.Where("uuid IN(?)", v)
+	}
+	if v := params.Name; v != "" {
+		db = db.Where("name=?", v)
+	}
+	if v := params.LikeName; v != "" {
+		db = db.Where("name LIKE ?", "%"+v+"%")
+	}
+	if v := params.ParentID; v != nil {
+		db = db.Where("parent_id=?", *v)
+	}
+	if v := params.PrefixParentPath; v != "" {
+		db = db.Where("parent_path LIKE ?", v+"%")
+	}
+	db = db.Order("sequence DESC,id DESC")
+
+	opt := a.getQueryOption(opts...)
+	var list entity.Folders
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qr := &schema.FolderQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaFolders(),
+	}
+
+	return qr, nil
+}
+
+// Get - Query specified data
+func (a *Folder) Get(ctx context.Context, UUID string, opts ...schema.FolderQueryOptions) (*schema.Folder, error) {
+	db := entity.GetFolderDB(ctx, a.db).Where("uuid=?", UUID)
+	var item entity.Folder
+	ok, err := model.FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item.ToSchemaFolder(), nil
+}
+
+// Create - Create data
+func (a *Folder) Create(ctx context.Context, item schema.Folder) error {
+	folder := entity.SchemaFolder(item).ToFolder()
+	result := entity.GetFolderDB(ctx, a.db).Create(folder)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update - Update data
+func (a *Folder) Update(ctx context.Context, UUID string, item schema.Folder) error {
+	folder := entity.SchemaFolder(item).ToFolder()
+	result := entity.GetFolderDB(ctx, a.db).Where("uuid=?", UUID).Omit("uuid", "creator").Updates(folder)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// UpdateParentPath - Update parent path
+func (a *Folder) UpdateParentPath(ctx context.Context, UUID, parentPath string) error {
+	result := entity.GetFolderDB(ctx, a.db).Where("uuid=?", UUID).Update("parent_path", parentPath)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete - delete data
+func (a *Folder) Delete(ctx context.Context, UUID string) error {
+	result := entity.GetFolderDB(ctx, a.db).Where("uuid=?", UUID).Delete(entity.Folder{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
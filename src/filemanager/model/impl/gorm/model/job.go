@@ -0,0 +1,55 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/MayCMF/core/src/filemanager/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/jinzhu/gorm"
+)
+
+// NewFileJob - Create a File job storage instance
+func NewFileJob(db *gorm.DB) *FileJob {
+	return &FileJob{db}
+}
+
+// FileJob - File job storage
+type FileJob struct {
+	db *gorm.DB
+}
+
+// Get - Query specified data
+func (a *FileJob) Get(ctx context.Context, UUID string) (*schema.FileJob, error) {
+	db := entity.GetFileJobDB(ctx, a.db).Where("uuid=?", UUID)
+	var item entity.FileJob
+	ok, err := model.FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item.ToSchemaFileJob(), nil
+}
+
+// Create - Create data
+func (a *FileJob) Create(ctx context.Context, item schema.FileJob) error {
+	job := entity.SchemaFileJob(item).ToFileJob()
+	result := entity.GetFileJobDB(ctx, a.db).Create(job)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Update - Update data
+func (a *FileJob) Update(ctx context.Context, UUID string, item schema.FileJob) error {
+	job := entity.SchemaFileJob(item).ToFileJob()
+	result := entity.GetFileJobDB(ctx, a.db).Where("uuid=?", UUID).Omit("uuid", "type", "creator").Updates(job)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
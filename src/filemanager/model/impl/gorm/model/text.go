@@ -0,0 +1,99 @@
+package model
+
+import (
+	"context"
+	"strings"
+
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/MayCMF/core/src/filemanager/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/jinzhu/gorm"
+)
+
+// NewFileText - Create a File text storage instance
+func NewFileText(db *gorm.DB) *FileText {
+	return &FileText{db}
+}
+
+// FileText - File text storage
+type FileText struct {
+	db *gorm.DB
+}
+
+func (a *FileText) getQueryOption(opts ...schema.FileTextQueryOptions) schema.FileTextQueryOptions {
+	var opt schema.FileTextQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Escape the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Query - Query data
+func (a *FileText) Query(ctx context.Context, params schema.FileTextQueryParam, opts ...schema.FileTextQueryOptions) (*schema.FileTextQueryResult, error) {
+	db := entity.GetFileTextDB(ctx, a.db)
+	if v := params.FileIDs; len(v) > 0 {
+		db = db.Where("file_uuid IN(?)", v)
+	}
+	for _, word := range params.Words {
+		db = db.Where(`LOWER(content) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(word))+"%")
+	}
+	db = db.Order("id DESC")
+
+	opt := a.getQueryOption(opts...)
+	var list entity.FileTexts
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qr := &schema.FileTextQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaFileTexts(),
+	}
+
+	return qr, nil
+}
+
+// Get - Get the text of a file
+func (a *FileText) Get(ctx context.Context, fileID string) (*schema.FileText, error) {
+	db := entity.GetFileTextDB(ctx, a.db).Where("file_uuid=?", fileID)
+	var item entity.FileText
+	ok, err := model.FindOne(ctx, db, &item)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	} else if !ok {
+		return nil, nil
+	}
+
+	return item.ToSchemaFileText(), nil
+}
+
+// Save - Save the text of a file, replacing the current one
+func (a *FileText) Save(ctx context.Context, item schema.FileText) error {
+	return model.ExecTrans(ctx, a.db, func(ctx context.Context) error {
+		if err := a.Delete(ctx, item.FileID); err != nil {
+			return err
+		}
+
+		text := entity.SchemaFileText(item).ToFileText()
+		result := entity.GetFileTextDB(ctx, a.db).Create(text)
+		if err := result.Error; err != nil {
+			return errors.WithStack(err)
+		}
+		return nil
+	})
+}
+
+// Delete - Delete the text of a file
+func (a *FileText) Delete(ctx context.Context, fileID string) error {
+	result := entity.GetFileTextDB(ctx, a.db).Where("file_uuid=?", fileID).Delete(entity.FileText{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
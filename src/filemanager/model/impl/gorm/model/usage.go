@@ -0,0 +1,85 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/model"
+	"github.com/MayCMF/core/src/filemanager/model/impl/gorm/entity"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/jinzhu/gorm"
+)
+
+// NewFileUsage - Create a File usage storage instance
+func NewFileUsage(db *gorm.DB) *FileUsage {
+	return &FileUsage{db}
+}
+
+// FileUsage - File usage storage
+type FileUsage struct {
+	db *gorm.DB
+}
+
+func (a *FileUsage) getQueryOption(opts ...schema.FileUsageQueryOptions) schema.FileUsageQueryOptions {
+	var opt schema.FileUsageQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return opt
+}
+
+// Query - Query data
+func (a *FileUsage) Query(ctx context.Context, params schema.FileUsageQueryParam, opts ...schema.FileUsageQueryOptions) (*schema.FileUsageQueryResult, error) {
+	db := entity.GetFileUsageDB(ctx, a.db)
+	if v := params.FileID; v != "" {
+		db = db.Where("file_uuid=?", v)
+	}
+	if v := params.EntityType; v != "" {
+		db = db.Where("entity_type=?", v)
+	}
+	if v := params.EntityID; v != "" {
+		db = db.Where("entity_id=?", v)
+	}
+	db = db.Order("id DESC")
+
+	opt := a.getQueryOption(opts...)
+	var list entity.FileUsages
+	pr, err := model.WrapPageQuery(ctx, db, opt.PageParam, &list)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	qr := &schema.FileUsageQueryResult{
+		PageResult: pr,
+		Data:       list.ToSchemaFileUsages(),
+	}
+
+	return qr, nil
+}
+
+// Create - Create data
+func (a *FileUsage) Create(ctx context.Context, item schema.FileUsage) error {
+	usage := entity.SchemaFileUsage(item).ToFileUsage()
+	result := entity.GetFileUsageDB(ctx, a.db).Create(usage)
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DeleteByEntity - Delete the usages of an entity
+func (a *FileUsage) DeleteByEntity(ctx context.Context, entityType, entityID string) error {
+	result := entity.GetFileUsageDB(ctx, a.db).Where("entity_type=? AND entity_id=?", entityType, entityID).Delete(entity.FileUsage{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// DeleteByFile - Delete the usages of a file
+func (a *FileUsage) DeleteByFile(ctx context.Context, fileID string) error {
+	result := entity.GetFileUsageDB(ctx, a.db).Where("file_uuid=?", fileID).Delete(entity.FileUsage{})
+	if err := result.Error; err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
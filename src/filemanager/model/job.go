@@ -0,0 +1,17 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IFileJob File job storage interface
+type IFileJob interface {
+	// Query specified data
+	Get(ctx context.Context, UUID string) (*schema.FileJob, error)
+	// Create data
+	Create(ctx context.Context, item schema.FileJob) error
+	// Update data
+	Update(ctx context.Context, UUID string, item schema.FileJob) error
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IFileText File text storage interface
+type IFileText interface {
+	// Query data
+	Query(ctx context.Context, params schema.FileTextQueryParam, opts ...schema.FileTextQueryOptions) (*schema.FileTextQueryResult, error)
+	// Get the text of a file
+	Get(ctx context.Context, fileID string) (*schema.FileText, error)
+	// Save the text of a file, replacing the current one
+	Save(ctx context.Context, item schema.FileText) error
+	// Delete the text of a file
+	Delete(ctx context.Context, fileID string) error
+}
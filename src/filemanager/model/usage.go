@@ -0,0 +1,19 @@
+package model
+
+import (
+	"context"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// IFileUsage File usage storage interface
+type IFileUsage interface {
+	// Query data
+	Query(ctx context.Context, params schema.FileUsageQueryParam, opts ...schema.FileUsageQueryOptions) (*schema.FileUsageQueryResult, error)
+	// Create data
+	Create(ctx context.Context, item schema.FileUsage) error
+	// Delete the usages of an entity
+	DeleteByEntity(ctx context.Context, entityType, entityID string) error
+	// Delete the usages of a file
+	DeleteByFile(ctx context.Context, fileID string) error
+}
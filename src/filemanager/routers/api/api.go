@@ -1,8 +1,10 @@
 package api
 
 import (
+	"github.com/MayCMF/core/src/common/auth"
 	"github.com/MayCMF/core/src/common/middleware"
 	"github.com/MayCMF/core/src/filemanager/routers/api/controllers"
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/dig"
 )
@@ -15,7 +17,13 @@ func RegisterRouter(app *gin.Engine, container *dig.Container) error {
 	}
 
 	return container.Invoke(func(
+		a auth.Auther,
+		e *casbin.SyncedEnforcer,
 		cFile *controllers.File,
+		cFolder *controllers.Folder,
+		cArchive *controllers.Archive,
+		cDav *controllers.Dav,
+		cFileText *controllers.FileText,
 	) error {
 
 		g := app.Group("/api")
@@ -23,6 +31,19 @@ func RegisterRouter(app *gin.Engine, container *dig.Container) error {
 		// Request frequency limit middleware
 		g.Use(middleware.RateLimiterMiddleware())
 
+		// Anonymous users may only download files, the download checks the
+		// permission of private files itself
+		downloadSkipper := middleware.AllowMethodAndRouteSkipper(
+			"GET/api/v1/file/:id/download",
+			"HEAD/api/v1/file/:id/download",
+		)
+
+		// User identity authorization
+		g.Use(middleware.UserAuthMiddleware(a, downloadSkipper))
+
+		// Casbin permission check middleware
+		g.Use(middleware.CasbinMiddleware(e, downloadSkipper))
+
 		v1 := g.Group("/v1")
 		{
 
@@ -33,9 +54,59 @@ func RegisterRouter(app *gin.Engine, container *dig.Container) error {
 				gFile.GET(":id", cFile.Get)
 				gFile.POST("", cFile.Create)
 				gFile.POST("/upload", cFile.Upload)
+				gFile.PATCH(":id/scan", cFile.Scan)
 				gFile.PUT(":id", cFile.Update)
+				gFile.PUT(":id/content", cFile.Replace)
 				gFile.DELETE(":id", cFile.Delete)
+				gFile.GET(":id/usage", cFile.Usage)
+				gFile.GET(":id/text", cFileText.Get)
+				gFile.GET(":id/download", cFile.Download)
+				gFile.HEAD(":id/download", cFile.Download)
+				gFile.GET(":id/signed-url", cFile.SignedURL)
+			}
+
+			// [REGISTERED]/api/v1/folders
+			gFolder := v1.Group("folders")
+			{
+				gFolder.GET("", cFolder.Query)
+				gFolder.GET(":id", cFolder.Get)
+				gFolder.POST("", cFolder.Create)
+				gFolder.PUT(":id", cFolder.Update)
+				gFolder.DELETE(":id", cFolder.Delete)
+			}
+			v1.GET("/folders.tree", cFolder.QueryTree)
+
+			// [REGISTERED]/api/v1/file-archives
+			gArchive := v1.Group("file-archives")
+			{
+				gArchive.GET("", cArchive.Download)
+				gArchive.POST("", cArchive.Upload)
+				gArchive.POST("/jobs", cArchive.Build)
 			}
+
+			// [REGISTERED]/api/v1/file-search
+			gFileSearch := v1.Group("file-search")
+			{
+				gFileSearch.GET("", cFileText.Search)
+				gFileSearch.POST("/index", cFileText.Index)
+			}
+
+			// [REGISTERED]/api/v1/file-jobs
+			gFileJob := v1.Group("file-jobs")
+			{
+				gFileJob.GET(":id", cArchive.GetJob)
+				gFileJob.GET(":id/download", cArchive.DownloadJob)
+			}
+		}
+
+		// [REGISTERED]/dav, the media library as WebDAV share, tokens are also accepted as basic auth password,
+		// except scoped access tokens as the share is not covered by casbin
+		gDav := app.Group(controllers.DavPrefix,
+			middleware.UserBasicAuthMiddleware(a, "MayCMF"),
+			middleware.UnscopedTokenMiddleware(),
+		)
+		for _, method := range controllers.DavMethods {
+			gDav.Handle(method, "/*path", cDav.Serve)
 		}
 
 		return nil
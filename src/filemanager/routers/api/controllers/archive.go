@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// NewArchive - Create an Archive controller
+func NewArchive(bArchive controllers.IArchive) *Archive {
+	return &Archive{
+		ArchiveBll: bArchive,
+	}
+}
+
+// Archive - Bulk zip upload and download
+type Archive struct {
+	ArchiveBll controllers.IArchive
+}
+
+// Upload - Upload a zip archive, its entries are extracted in the background
+// @Tags Archive
+// @Summary Upload a zip archive
+// @Param Authorization header string false "Bearer User Token"
+// @Param MayFile formData file true "Zip archive"
+// @Param FolderID formData string false "Folder UUID"
+// @Param Tags formData string false "Tags (multiple separated by commas)"
+// @Param Private formData bool false "Private files"
+// @Param StripExif formData bool false "Strip jpeg metadata (defaults to the filemanager.exif_strip setting)"
+// @Param AutoRotate formData bool false "Rotate jpeg by EXIF orientation (defaults to the filemanager.auto_rotate setting)"
+// @Success 200 {object} schema.FileJob
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file-archives [post]
+func (a *Archive) Upload(c *gin.Context) {
+	file, err := c.FormFile("MayFile")
+	if err != nil {
+		ginplus.ResError(c, errors.New400Response("Missing zip archive"))
+		return
+	}
+
+	cfg := config.Global().FileManager
+	if cfg.ArchiveSize > 0 && file.Size > cfg.ArchiveSize {
+		ginplus.ResError(c, errors.New400Response("Upload archive too large, The max upload limit is "+strconv.FormatInt(cfg.ArchiveSize, 10)))
+		return
+	}
+
+	params := schema.FileExtractParam{
+		UID:      uint(ginplus.GetUserID(c)),
+		FolderID: c.PostForm("FolderID"),
+	}
+	params.Private, _ = strconv.ParseBool(c.PostForm("Private"))
+	params.StripExif, params.AutoRotate = getImageOptions(c)
+	if v := c.PostForm("Tags"); v != "" {
+		params.Tags = strings.Split(v, ",")
+	}
+
+	zipPath := path.Join(cfg.JobDir, util.MustUUID()+".zip")
+	if err := controllers.CheckDir(cfg.JobDir); err != nil {
+		ginplus.ResError(c, errors.WithStack(err))
+		return
+	}
+	if err := c.SaveUploadedFile(file, zipPath); err != nil {
+		ginplus.ResError(c, errors.WithStack(err))
+		return
+	}
+
+	job, err := a.ArchiveBll.Extract(ginplus.NewContext(c), ginplus.GetUserUUID(c), params, zipPath)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, job)
+}
+
+// Download - Stream a zip archive of the selected files
+// @Tags Archive
+// @Summary Download a zip archive of the selected files
+// @Param Authorization header string false "Bearer User Token"
+// @Param uuids query string false "File UUIDs (multiple separated by commas)"
+// @Param folderID query string false "Folder UUID, files of its subfolders are included"
+// @Success 200 {file} file "Zip archive"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: No files selected}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file-archives [get]
+func (a *Archive) Download(c *gin.Context) {
+	var params schema.FileArchiveParam
+	if v := c.Query("uuids"); v != "" {
+		params.UUIDs = strings.Split(v, ",")
+	}
+	params.FolderID = c.Query("folderID")
+
+	h := c.Writer.Header()
+	h.Set("Content-Type", "application/zip")
+	h.Set("Content-Disposition", "attachment; filename=files-"+time.Now().Format("20060102-1504")+".zip")
+
+	ctx := ginplus.NewContext(c)
+	err := a.ArchiveBll.Write(ctx, c.Writer, params)
+	if err != nil {
+		if !c.Writer.Written() {
+			h.Del("Content-Type")
+			h.Del("Content-Disposition")
+			ginplus.ResError(c, err)
+			return
+		}
+
+		// The response has started, the client gets a truncated archive
+		logger.Errorf(ctx, "Write file archive error: %s", err.Error())
+		c.Abort()
+	}
+}
+
+// Build - Build a zip archive of the selected files in the background
+// @Tags Archive
+// @Summary Build a zip archive of the selected files in the background
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.FileArchiveParam true "Selection"
+// @Success 200 {object} schema.FileJob
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: No files selected}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file-archives/jobs [post]
+func (a *Archive) Build(c *gin.Context) {
+	var params schema.FileArchiveParam
+	if err := ginplus.ParseJSON(c, &params); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	job, err := a.ArchiveBll.Build(ginplus.NewContext(c), ginplus.GetUserUUID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, job)
+}
+
+// GetJob - Query job status and progress
+// @Tags Archive
+// @Summary Query job status and progress
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Job ID"
+// @Success 200 {object} schema.FileJob
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file-jobs/{id} [get]
+func (a *Archive) GetJob(c *gin.Context) {
+	job, err := a.ArchiveBll.GetJob(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, job)
+}
+
+// DownloadJob - Download the zip archive built by a job
+// @Tags Archive
+// @Summary Download the zip archive built by a job
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Job ID"
+// @Success 200 {file} file "Zip archive"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Archive is not ready}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file-jobs/{id}/download [get]
+func (a *Archive) DownloadJob(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	job, err := a.ArchiveBll.GetJob(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	} else if job.Type != schema.FileJobTypeArchive || job.Status != schema.FileJobStatusDone {
+		ginplus.ResError(c, errors.New400Response("Archive is not ready"))
+		return
+	} else if job.Result == "" || !controllers.PathExists(job.Result) {
+		ginplus.ResError(c, errors.ErrNotFound)
+		return
+	}
+
+	c.FileAttachment(job.Result, "files-"+job.CreatedAt.Format("20060102-1504")+".zip")
+
+	// The archive is removed once it is downloaded completely
+	if c.Writer.Status() == http.StatusOK {
+		if err := a.ArchiveBll.RemoveResult(ctx, job.UUID); err != nil {
+			logger.Errorf(ctx, "Remove file archive error: %s", err.Error())
+		}
+	}
+}
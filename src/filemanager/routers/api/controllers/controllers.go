@@ -7,5 +7,9 @@ import (
 // Inject - injection controllers
 func Inject(container *dig.Container) error {
 	_ = container.Provide(NewFile)
+	_ = container.Provide(NewFolder)
+	_ = container.Provide(NewArchive)
+	_ = container.Provide(NewDav)
+	_ = container.Provide(NewFileText)
 	return nil
 }
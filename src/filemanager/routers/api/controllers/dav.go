@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/MayCMF/core/src/common/errors"
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/dav"
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/webdav"
+)
+
+// DavPrefix - Path of the WebDAV share
+const DavPrefix = "/dav"
+
+// DavMethods - Request methods served by the WebDAV share
+var DavMethods = []string{
+	"OPTIONS", "GET", "HEAD", "PUT", "DELETE",
+	"MKCOL", "COPY", "MOVE", "LOCK", "UNLOCK", "PROPFIND", "PROPPATCH",
+}
+
+// NewDav - Create a WebDAV controller
+func NewDav(bFile controllers.IFile, bFolder controllers.IFolder, bFileUsage controllers.IFileUsage, e *casbin.SyncedEnforcer) *Dav {
+	return &Dav{
+		FileSystem: dav.NewFileSystem(bFile, bFolder, bFileUsage, e),
+		LockSystem: webdav.NewMemLS(),
+	}
+}
+
+// Dav - Media library as WebDAV share
+type Dav struct {
+	FileSystem webdav.FileSystem
+	LockSystem webdav.LockSystem
+}
+
+// Serve - Serve a WebDAV request, folders are collections and files their members
+func (a *Dav) Serve(c *gin.Context) {
+	w := &davResponseWriter{ResponseWriter: c.Writer}
+	h := &webdav.Handler{
+		Prefix:     DavPrefix,
+		FileSystem: a.FileSystem,
+		LockSystem: a.LockSystem,
+		Logger: func(r *http.Request, err error) {
+			w.flush(c, err)
+		},
+	}
+	h.ServeHTTP(w, c.Request.WithContext(ginplus.NewContext(c)))
+}
+
+// davResponseWriter - Holds back error responses of the WebDAV handler, which
+// only knows generic statuses, so that business errors keep their own status
+// (a file in use answers 409, a rejected upload 400)
+type davResponseWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *davResponseWriter) WriteHeader(code int) {
+	if code >= 400 {
+		w.status = code
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *davResponseWriter) Write(data []byte) (int, error) {
+	if w.status != 0 {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *davResponseWriter) flush(c *gin.Context, err error) {
+	if w.status == 0 {
+		return
+	}
+
+	status, body := w.status, w.body.Bytes()
+	if res := errors.UnWrapResponse(err); res != nil {
+		status, body = res.StatusCode, []byte(res.Message)
+	} else if err != nil && status >= 500 {
+		logger.StartSpan(ginplus.NewContext(c)).Errorf(err.Error())
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+	_, _ = w.ResponseWriter.Write(body)
+}
@@ -1,25 +1,43 @@
 package controllers
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
 	"path"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/errors"
 	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/common/logger"
 	"github.com/MayCMF/core/src/filemanager/controllers"
 	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 )
 
 // NewFile - Create a File controller
-func NewFile(bFile controllers.IFile) *File {
+func NewFile(bFile controllers.IFile, bFileUsage controllers.IFileUsage, e *casbin.SyncedEnforcer) *File {
 	return &File{
-		FileBll: bFile,
+		FileBll:      bFile,
+		FileUsageBll: bFileUsage,
+		Enforcer:     e,
 	}
 }
 
 // File - Sample File entity
 type File struct {
-	FileBll controllers.IFile
+	FileBll      controllers.IFile
+	FileUsageBll controllers.IFileUsage
+	Enforcer     *casbin.SyncedEnforcer
 }
 
 // Query - Query data
@@ -31,6 +49,12 @@ type File struct {
 // @Param filename query string false "Numbering"
 // @Param name query string false "Name"
 // @Param status query int false "Status (1: Enable 2: Disable)"
+// @Param folderID query string false "Folder UUID (empty for the library root)"
+// @Param tags query string false "Tags (multiple separated by commas)"
+// @Param type query string false "Mime type or prefix (image, video/mp4)"
+// @Param camera query string false "Camera make or model (fuzzy query)"
+// @Param hasGPS query bool false "With or without GPS coordinates"
+// @Param scanStatus query string false "Scan status (quarantine/clean/infected), only visible files when empty"
 // @Success 200 {array} schema.File "Search result: {list:List data,pagination:{current:Page index, pageSize: Page size, total: The total number}}"
 // @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
 // @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
@@ -40,9 +64,23 @@ func (a *File) Query(c *gin.Context) {
 	params.Filename = c.Query("filename")
 	params.LikeFilename = c.Query("filename")
 	params.Uri = c.Query("uri")
+	params.Type = c.Query("type")
+	if v, ok := c.GetQuery("folderID"); ok {
+		params.FolderID = &v
+	}
+	if v := c.Query("tags"); v != "" {
+		params.Tags = strings.Split(v, ",")
+	}
+	params.Camera = c.Query("camera")
+	params.ScanStatus = c.Query("scanStatus")
+	if v, err := strconv.ParseBool(c.Query("hasGPS")); err == nil {
+		params.HasGPS = &v
+	}
 
 	result, err := a.FileBll.Query(ginplus.NewContext(c), params, schema.FileQueryOptions{
-		PageParam: ginplus.GetPaginationParam(c),
+		PageParam:           ginplus.GetPaginationParam(c),
+		IncludeTags:         true,
+		IncludeTranslations: true,
 	})
 	if err != nil {
 		ginplus.ResError(c, err)
@@ -63,7 +101,10 @@ func (a *File) Query(c *gin.Context) {
 // @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
 // @Router /api/v1/file/{id} [get]
 func (a *File) Get(c *gin.Context) {
-	item, err := a.FileBll.Get(ginplus.NewContext(c), c.Param("id"))
+	item, err := a.FileBll.Get(ginplus.NewContext(c), c.Param("id"), schema.FileQueryOptions{
+		IncludeTags:         true,
+		IncludeTranslations: true,
+	})
 	if err != nil {
 		ginplus.ResError(c, err)
 		return
@@ -71,7 +112,7 @@ func (a *File) Get(c *gin.Context) {
 	ginplus.ResSuccess(c, item)
 }
 
-// Create - Create data
+// Create - Create data, the record stays in quarantine until content is uploaded and scanned
 // @Tags File
 // @Summary Create data
 // @Param Authorization header string false "Bearer User Token"
@@ -89,7 +130,7 @@ func (a *File) Create(c *gin.Context) {
 		return
 	}
 
-	item.UUID = ginplus.GetUserUUID(c)
+	item.UserUUID = ginplus.GetUserUUID(c)
 	item.UID = uint(ginplus.GetUserID(c))
 	nitem, err := a.FileBll.Create(ginplus.NewContext(c), item)
 	if err != nil {
@@ -99,51 +140,196 @@ func (a *File) Create(c *gin.Context) {
 	ginplus.ResSuccess(c, nitem)
 }
 
-// Upload - Upload File
+// Upload - Upload File, a batch of several files is stored completely or not at all
 // @Tags File
 // @Summary Upload File
 // @Param Authorization header string false "Bearer User Token"
-// @Param body body schema.File true "Create data"
-// @Success 200 {object} schema.File
+// @Param MayFile formData file true "Files to upload (repeat for a batch)"
+// @Param URL formData string false "Storage subdirectory"
+// @Param FolderID formData string false "Folder UUID"
+// @Param Tags formData string false "Tags (multiple separated by commas)"
+// @Param Private formData bool false "Private file"
+// @Param StripExif formData bool false "Strip jpeg metadata (defaults to the filemanager.exif_strip setting)"
+// @Param AutoRotate formData bool false "Rotate jpeg by EXIF orientation (defaults to the filemanager.auto_rotate setting)"
+// @Success 200 {object} schema.File "A single file, or {list:List data} for a batch"
 // @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
 // @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
 // @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
 // @Router /api/v1/file/upload [post]
 func (a *File) Upload(c *gin.Context) {
 	var item schema.File
-
-	// Multipart form
-	// uid, err := strconv.ParseUint(c.PostForm("UserID"), 10, 32)
-	// item.UID = uint(uid)
-	// item.UserUUID = ginplus.GetUserUUID(c)
 	uri := c.PostForm("URL")
+	item.UID = uint(ginplus.GetUserID(c))
+	item.FolderID = c.PostForm("FolderID")
+	item.Private, _ = strconv.ParseBool(c.PostForm("Private"))
+	if v := c.PostForm("Tags"); v != "" {
+		item.Tags = strings.Split(v, ",")
+	}
+
 	form, err := c.MultipartForm()
 	if err != nil {
-		ginplus.ResError(c, err)
+		ginplus.ResError(c, errors.Wrap400Response(err))
 		return
 	}
 	files := form.File["MayFile"]
-	dst := "static" + item.Uri
-	for _, file := range files {
-		// Upload the file to specific dst.
-		controllers.CheckDir(dst + uri)
-		item.Uri = dst + uri + "/" + time.Now().Format("20060102-1504") + "_" + file.Filename
-		item.Filename = file.Filename
-		item.Filesize = file.Size
-		item.Filemime = file.Header.Get("Content-Type")
-		item.FileExt = path.Ext(file.Filename)
-		item.UID = uint(ginplus.GetUserID(c))
-		nitem, err := a.FileBll.Upload(ginplus.NewContext(c), item)
+	if len(files) == 0 {
+		ginplus.ResError(c, errors.New400Response("Missing upload file"))
+		return
+	}
+
+	strip, rotate := getImageOptions(c)
+	items := make(schema.Files, len(files))
+	contents := make([]io.Reader, len(files))
+	for i, file := range files {
+		fitem := item
+		fitem.Uri = controllers.StoragePath(fitem.Private, uri, file.Filename)
+
+		content, err := a.openUpload(file, &fitem, strip, rotate)
+		if err != nil {
+			ginplus.ResError(c, err)
+			return
+		}
+		defer content.Close()
+		items[i], contents[i] = &fitem, content
+	}
+
+	ctx := ginplus.NewContext(c)
+	if len(items) == 1 {
+		nitem, err := a.FileBll.Upload(ctx, *items[0], contents[0])
 		if err != nil {
 			ginplus.ResError(c, err)
 			return
 		}
-		c.SaveUploadedFile(file, item.Uri)
 		ginplus.ResSuccess(c, nitem)
+		return
 	}
+
+	nitems, err := a.FileBll.UploadBatch(ctx, items, contents)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, nitems)
 }
 
-// Update - Update data
+// Replace - Replace the content of a file, the current content is kept when the new one is rejected
+// @Tags File
+// @Summary Replace the content of a file
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Param MayFile formData file true "New content"
+// @Param StripExif formData bool false "Strip jpeg metadata (defaults to the filemanager.exif_strip setting)"
+// @Param AutoRotate formData bool false "Rotate jpeg by EXIF orientation (defaults to the filemanager.auto_rotate setting)"
+// @Success 200 {object} schema.File
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file/{id}/content [put]
+func (a *File) Replace(c *gin.Context) {
+	file, err := c.FormFile("MayFile")
+	if err != nil {
+		ginplus.ResError(c, errors.New400Response("Missing upload file"))
+		return
+	}
+
+	var item schema.File
+	strip, rotate := getImageOptions(c)
+	content, err := a.openUpload(file, &item, strip, rotate)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	defer content.Close()
+
+	nitem, err := a.FileBll.Replace(ginplus.NewContext(c), c.Param("id"), item, content)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, nitem)
+}
+
+// Fill the item from an uploaded file and open its content, images are
+// processed in memory before being stored. The mime type is detected here,
+// the one sent by the client is ignored.
+func (a *File) openUpload(file *multipart.FileHeader, item *schema.File, strip, rotate bool) (io.ReadCloser, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, errors.WithStack(err)
+	}
+
+	item.Filename = file.Filename
+	item.Filesize = file.Size
+	item.Filemime = controllers.DetectMime(file.Filename, head[:n])
+	item.FileExt = path.Ext(file.Filename)
+
+	if strings.HasPrefix(item.Filemime, "image/") {
+		defer f.Close()
+		data, err := a.processImage(f, item, strip, rotate)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	controllers.PrepareMedia(f, file.Size, item)
+	return f, nil
+}
+
+// Scan - Scan a quarantined file again
+// @Tags File
+// @Summary Scan a quarantined file again
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.File
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: File rejected}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file/{id}/scan [patch]
+func (a *File) Scan(c *gin.Context) {
+	item, err := a.FileBll.Scan(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Get the image processing options of an upload, the configuration can be overridden per upload
+func getImageOptions(c *gin.Context) (strip, rotate bool) {
+	cfg := config.Global().FileManager
+	strip, rotate = cfg.ExifStrip, cfg.AutoRotate
+	if v, err := strconv.ParseBool(c.PostForm("StripExif")); err == nil {
+		strip = v
+	}
+	if v, err := strconv.ParseBool(c.PostForm("AutoRotate")); err == nil {
+		rotate = v
+	}
+	return
+}
+
+// Read image metadata into the item and rotate or strip the image as requested
+func (a *File) processImage(f io.Reader, item *schema.File, strip, rotate bool) ([]byte, error) {
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	data, err = controllers.PrepareImage(data, item, strip, rotate)
+	if err != nil {
+		return nil, errors.New400Response("Invalid image: " + err.Error())
+	}
+	return data, nil
+}
+
+// Update - Update data, fields left out of the request keep their current value
 // @Tags File
 // @Summary Update data
 // @Param Authorization header string false "Bearer User Token"
@@ -152,16 +338,26 @@ func (a *File) Upload(c *gin.Context) {
 // @Success 200 {object} schema.File
 // @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
 // @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
 // @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
 // @Router /api/v1/file/{id} [put]
 func (a *File) Update(c *gin.Context) {
-	var item schema.File
-	if err := ginplus.ParseJSON(c, &item); err != nil {
+	ctx := ginplus.NewContext(c)
+	item, err := a.FileBll.Get(ctx, c.Param("id"), schema.FileQueryOptions{
+		IncludeTags:         true,
+		IncludeTranslations: true,
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	if err := ginplus.ParseJSON(c, item); err != nil {
 		ginplus.ResError(c, err)
 		return
 	}
 
-	nitem, err := a.FileBll.Update(ginplus.NewContext(c), c.Param("id"), item)
+	nitem, err := a.FileBll.Update(ctx, c.Param("id"), *item)
 	if err != nil {
 		ginplus.ResError(c, err)
 		return
@@ -174,15 +370,202 @@ func (a *File) Update(c *gin.Context) {
 // @Summary Delete data
 // @Param Authorization header string false "Bearer User Token"
 // @Param id path string true "Record ID"
+// @Param force query bool false "Delete the file even if it is in use"
 // @Success 200 {object} schema.HTTPStatus "{status:OK}"
 // @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 409 {object} schema.HTTPError "{error:{code:409,message: File is in use}}"
 // @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
 // @Router /api/v1/file/{id} [delete]
 func (a *File) Delete(c *gin.Context) {
-	err := a.FileBll.Delete(ginplus.NewContext(c), c.Param("id"))
+	force, _ := strconv.ParseBool(c.Query("force"))
+	err := a.FileBll.Delete(ginplus.NewContext(c), c.Param("id"), force)
 	if err != nil {
 		ginplus.ResError(c, err)
 		return
 	}
 	ginplus.ResOK(c)
 }
+
+// Usage - Query the places where a file is used
+// @Tags File
+// @Summary Query the places where a file is used
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {array} schema.FileUsage "Search result: {list:List data}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file/{id}/usage [get]
+func (a *File) Usage(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	item, err := a.FileBll.Get(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	usages, err := a.FileUsageBll.Query(ctx, item.UUID)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, usages)
+}
+
+// Check that the current user passes the casbin check for downloading the file,
+// a scoped token must also pass it with the permissions of its scopes
+func (a *File) checkPermission(c *gin.Context, UUID string) error {
+	userUUID := ginplus.GetUserUUID(c)
+	if userUUID == "" {
+		return errors.ErrInvalidToken
+	} else if !config.Global().Casbin.Enable {
+		return nil
+	}
+
+	subjects := []string{userUUID}
+	if scope := ginplus.GetTokenScope(c); scope != "" {
+		subjects = append(subjects, scope)
+	}
+
+	p := fmt.Sprintf("/api/v1/file/%s/download", UUID)
+	for _, sub := range subjects {
+		if b, err := a.Enforcer.Enforce(sub, p, "GET"); err != nil {
+			return errors.WithStack(err)
+		} else if !b {
+			return errors.ErrNoPerm
+		}
+	}
+	return nil
+}
+
+// Download - Download file, private files require permission or a signed URL
+// @Tags File
+// @Summary Download file
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Param expires query int false "Signed URL expiration timestamp"
+// @Param signature query string false "Signed URL signature"
+// @Param inline query bool false "Display in the browser instead of saving"
+// @Param Range header string false "Byte range, e.g. bytes=0-1023"
+// @Success 200 {file} file "File content"
+// @Success 206 {file} file "Partial file content"
+// @Success 304 {string} string "Not modified"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: No access}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file/{id}/download [get]
+func (a *File) Download(c *gin.Context) {
+	item, err := a.FileBll.Get(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	if item.ScanStatus == schema.ScanStatusQuarantine || item.ScanStatus == schema.ScanStatusInfected {
+		ginplus.ResError(c, errors.New400Response("File has not passed the upload scan"))
+		return
+	}
+
+	if item.Private {
+		if signature := c.Query("signature"); signature != "" {
+			expiresAt, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+			if !controllers.VerifyFileSignature(item.UUID, expiresAt, signature) {
+				ginplus.ResError(c, errors.ErrNoPerm)
+				return
+			}
+		} else if err := a.checkPermission(c, item.UUID); err != nil {
+			ginplus.ResError(c, err)
+			return
+		}
+	}
+
+	f, err := controllers.OpenContent(item.Uri)
+	if err != nil {
+		if os.IsNotExist(err) || os.IsPermission(err) {
+			ginplus.ResError(c, errors.ErrNotFound)
+			return
+		}
+		ginplus.ResError(c, errors.WithStack(err))
+		return
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		ginplus.ResError(c, errors.WithStack(err))
+		return
+	}
+
+	// Content browsers could run scripts from is never displayed inline
+	mimeType := item.Filemime
+	if mimeType == "" {
+		mimeType = controllers.DetectMime(item.Filename, nil)
+	}
+	disposition := "attachment"
+	if v, _ := strconv.ParseBool(c.Query("inline")); v && !controllers.IsActiveMime(mimeType) {
+		disposition = "inline"
+	}
+	if v := mime.FormatMediaType(disposition, map[string]string{"filename": item.Filename}); v != "" {
+		disposition = v
+	}
+
+	h := c.Writer.Header()
+	h.Set("Content-Disposition", disposition)
+	h.Set("ETag", fmt.Sprintf(`"%s-%x-%x"`, item.UUID, fi.ModTime().UnixNano(), fi.Size()))
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Content-Security-Policy", "sandbox")
+	h.Set("Content-Type", mimeType)
+
+	// Range, If-Range, If-None-Match and If-Modified-Since are handled here
+	http.ServeContent(c.Writer, c.Request, item.Filename, fi.ModTime(), f)
+
+	// Count full downloads and the first chunk of ranged ones, not revalidations
+	status := c.Writer.Status()
+	if c.Request.Method == http.MethodGet &&
+		(status == http.StatusOK ||
+			(status == http.StatusPartialContent && strings.HasPrefix(c.GetHeader("Range"), "bytes=0-"))) {
+		ctx := ginplus.NewContext(c)
+		if err := a.FileBll.IncreaseDownloads(ctx, item.UUID); err != nil {
+			logger.Errorf(ctx, err.Error())
+		}
+	}
+}
+
+// SignedURL - Generate a temporary download URL
+// @Tags File
+// @Summary Generate a temporary download URL
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Param expired query int false "Lifetime of the URL (unit: second)"
+// @Success 200 {object} schema.FileSignedURL
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: No access}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file/{id}/signed-url [get]
+func (a *File) SignedURL(c *gin.Context) {
+	item, err := a.FileBll.Get(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	// Only users allowed to download the file may hand out links to it
+	if item.Private {
+		if err := a.checkPermission(c, item.UUID); err != nil {
+			ginplus.ResError(c, err)
+			return
+		}
+	}
+
+	expired, _ := strconv.Atoi(c.Query("expired"))
+	if expired <= 0 {
+		expired = config.Global().FileManager.URLExpired
+	}
+	expiresAt := time.Now().Add(time.Duration(expired) * time.Second).Unix()
+
+	ginplus.ResSuccess(c, schema.FileSignedURL{
+		URL: fmt.Sprintf("/api/v1/file/%s/download?expires=%d&signature=%s",
+			item.UUID, expiresAt, controllers.SignFile(item.UUID, expiresAt)),
+		ExpiresAt: expiresAt,
+	})
+}
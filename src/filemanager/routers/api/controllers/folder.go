@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// NewFolder - Create a Folder controller
+func NewFolder(bFolder controllers.IFolder) *Folder {
+	return &Folder{
+		FolderBll: bFolder,
+	}
+}
+
+// Folder - Media library folders
+type Folder struct {
+	FolderBll controllers.IFolder
+}
+
+// Query - Query data
+// @Tags Folder
+// @Summary Query data
+// @Param Authorization header string false "Bearer User Token"
+// @Param current query int true "Page Index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Param name query string false "Name (fuzzy query)"
+// @Param parentID query string false "Parent folder UUID"
+// @Success 200 {array} schema.Folder "Search result: {list:List data,pagination:{current:Page index, pageSize: Page size, total: The total number}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/folders [get]
+func (a *Folder) Query(c *gin.Context) {
+	var params schema.FolderQueryParam
+	params.LikeName = c.Query("name")
+	if v, ok := c.GetQuery("parentID"); ok {
+		params.ParentID = &v
+	}
+
+	result, err := a.FolderBll.Query(ginplus.NewContext(c), params, schema.FolderQueryOptions{
+		PageParam: ginplus.GetPaginationParam(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// QueryTree - Query folder tree
+// @Tags Folder
+// @Summary Query folder tree
+// @Param Authorization header string false "Bearer User Token"
+// @Success 200 {array} schema.FolderTree "Search result: {list: List data}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/folders.tree [get]
+func (a *Folder) QueryTree(c *gin.Context) {
+	result, err := a.FolderBll.Query(ginplus.NewContext(c), schema.FolderQueryParam{})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResList(c, result.Data.ToTrees().ToTree())
+}
+
+// Get - Query specified data
+// @Tags Folder
+// @Summary Query specified data
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.Folder
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/folders/{id} [get]
+func (a *Folder) Get(c *gin.Context) {
+	item, err := a.FolderBll.Get(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, item)
+}
+
+// Create - Create data
+// @Tags Folder
+// @Summary Create data
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.Folder true "Create data"
+// @Success 200 {object} schema.Folder
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/folders [post]
+func (a *Folder) Create(c *gin.Context) {
+	var item schema.Folder
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	item.Creator = ginplus.GetUserUUID(c)
+	nitem, err := a.FolderBll.Create(ginplus.NewContext(c), item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, nitem)
+}
+
+// Update - Update data, a changed parent_id moves the folder
+// @Tags Folder
+// @Summary Update data
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Param body body schema.Folder true "Update data"
+// @Success 200 {object} schema.Folder
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Invalid request parameter}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/folders/{id} [put]
+func (a *Folder) Update(c *gin.Context) {
+	var item schema.Folder
+	if err := ginplus.ParseJSON(c, &item); err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	nitem, err := a.FolderBll.Update(ginplus.NewContext(c), c.Param("id"), item)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, nitem)
+}
+
+// Delete - Delete data
+// @Tags Folder
+// @Summary Delete data
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.HTTPStatus "{status:OK}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Contains children, cannot be deleted}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/folders/{id} [delete]
+func (a *Folder) Delete(c *gin.Context) {
+	err := a.FolderBll.Delete(ginplus.NewContext(c), c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResOK(c)
+}
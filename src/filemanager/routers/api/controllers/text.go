@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"github.com/MayCMF/core/src/common/ginplus"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/gin-gonic/gin"
+)
+
+// NewFileText - Create a document text controller
+func NewFileText(bFile controllers.IFile, bFileText controllers.IFileText) *FileText {
+	return &FileText{
+		FileBll:     bFile,
+		FileTextBll: bFileText,
+	}
+}
+
+// FileText - Document text and full text search
+type FileText struct {
+	FileBll     controllers.IFile
+	FileTextBll controllers.IFileText
+}
+
+// Search - Search files by the text of their content
+// @Tags FileText
+// @Summary Search files by the text of their content
+// @Param Authorization header string false "Bearer User Token"
+// @Param q query string true "Words to search for, files contain all of them"
+// @Param current query int true "Page Index" default(1)
+// @Param pageSize query int true "Paging Size" default(10)
+// @Success 200 {array} schema.FileSearchHit "Search result: {list:List data,pagination:{current:Page index, pageSize: Page size, total: The total number}}"
+// @Failure 400 {object} schema.HTTPError "{error:{code:0,message: Missing search query}}"
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file-search [get]
+func (a *FileText) Search(c *gin.Context) {
+	params := schema.FileSearchParam{
+		Query: c.Query("q"),
+	}
+
+	result, err := a.FileTextBll.Search(ginplus.NewContext(c), params, schema.FileTextQueryOptions{
+		PageParam: ginplus.GetPaginationParam(c),
+	})
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	ginplus.ResPage(c, result.Data, result.PageResult)
+}
+
+// Get - Query the text extracted from a file
+// @Tags FileText
+// @Summary Query the text extracted from a file
+// @Param Authorization header string false "Bearer User Token"
+// @Param id path string true "Record ID"
+// @Success 200 {object} schema.FileText
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 404 {object} schema.HTTPError "{error:{code:0,message: Resource does not exist.}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file/{id}/text [get]
+func (a *FileText) Get(c *gin.Context) {
+	ctx := ginplus.NewContext(c)
+	item, err := a.FileBll.Get(ctx, c.Param("id"))
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+
+	text, err := a.FileTextBll.Get(ctx, item.UUID)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, text)
+}
+
+// Index - Extract the text of the stored documents in the background
+// @Tags FileText
+// @Summary Extract the text of the stored documents in the background
+// @Param Authorization header string false "Bearer User Token"
+// @Param body body schema.FileIndexParam false "Documents to extract"
+// @Success 200 {object} schema.FileJob
+// @Failure 401 {object} schema.HTTPError "{error:{code:0,message: Unauthorized}}"
+// @Failure 500 {object} schema.HTTPError "{error:{code:0,message: Server Error}}"
+// @Router /api/v1/file-search/index [post]
+func (a *FileText) Index(c *gin.Context) {
+	var params schema.FileIndexParam
+	if c.Request.ContentLength > 0 {
+		if err := ginplus.ParseJSON(c, &params); err != nil {
+			ginplus.ResError(c, err)
+			return
+		}
+	}
+
+	job, err := a.FileTextBll.Index(ginplus.NewContext(c), ginplus.GetUserUUID(c), params)
+	if err != nil {
+		ginplus.ResError(c, err)
+		return
+	}
+	ginplus.ResSuccess(c, job)
+}
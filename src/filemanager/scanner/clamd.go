@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// Size of the chunks streamed to clamd, must stay below its StreamMaxLength
+const clamdChunkSize = 64 * 1024
+
+// NewClamd - Create a ClamAV clamd client, network is "unix" or "tcp"
+func NewClamd(network, address string, timeout int) *Clamd {
+	if network == "" {
+		network = "unix"
+	}
+	if timeout <= 0 {
+		timeout = 60
+	}
+	return &Clamd{
+		Network: network,
+		Address: address,
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+}
+
+// Clamd - ClamAV clamd client using the INSTREAM command
+type Clamd struct {
+	Network string
+	Address string
+	Timeout time.Duration
+}
+
+// Name - Scanner name
+func (a *Clamd) Name() string {
+	return "clamd"
+}
+
+func (a *Clamd) dial(ctx context.Context) (net.Conn, error) {
+	d := net.Dialer{Timeout: a.Timeout}
+	conn, err := d.DialContext(ctx, a.Network, a.Address)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(a.Timeout))
+	return conn, nil
+}
+
+// Send a null terminated command and read the null terminated reply
+func (a *Clamd) command(ctx context.Context, cmd string, body io.Reader) (string, error) {
+	conn, err := a.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("z" + cmd + "\x00")); err != nil {
+		return "", err
+	}
+
+	if body != nil {
+		buf := make([]byte, clamdChunkSize)
+		size := make([]byte, 4)
+		for {
+			n, err := body.Read(buf)
+			if n > 0 {
+				binary.BigEndian.PutUint32(size, uint32(n))
+				if _, err := conn.Write(append(size, buf[:n]...)); err != nil {
+					return "", err
+				}
+			}
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return "", err
+			}
+		}
+
+		// A zero length chunk ends the stream
+		if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+			return "", err
+		}
+	}
+
+	reply, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
+
+// Ping - Check that the daemon is reachable
+func (a *Clamd) Ping(ctx context.Context) error {
+	reply, err := a.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	} else if reply != "PONG" {
+		return fmt.Errorf("clamd: unexpected reply %q", reply)
+	}
+	return nil
+}
+
+// Scan - Stream the content to clamd and parse the verdict
+func (a *Clamd) Scan(ctx context.Context, item *schema.File, r io.Reader) (*Result, error) {
+	reply, err := a.command(ctx, "INSTREAM", r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replies look like "stream: OK" or "stream: Eicar-Signature FOUND"
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return &Result{Scanner: a.Name()}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{
+			Scanner:  a.Name(),
+			Infected: true,
+			Threat:   strings.TrimSuffix(reply, " FOUND"),
+		}, nil
+	}
+	return nil, fmt.Errorf("clamd: %s", reply)
+}
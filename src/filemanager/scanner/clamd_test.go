@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Fake clamd daemon, reports every stream containing "EICAR" as infected
+func newFakeClamd(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handleFakeClamd(conn)
+		}
+	}()
+	return l
+}
+
+func handleFakeClamd(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	cmd, err := r.ReadString(0)
+	if err != nil {
+		return
+	}
+
+	switch cmd {
+	case "zPING\x00":
+		_, _ = conn.Write([]byte("PONG\x00"))
+	case "zINSTREAM\x00":
+		var data bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(r, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(&data, r, int64(n)); err != nil {
+				return
+			}
+		}
+
+		if strings.Contains(data.String(), "EICAR") {
+			_, _ = conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		} else {
+			_, _ = conn.Write([]byte("stream: OK\x00"))
+		}
+	default:
+		_, _ = conn.Write([]byte("UNKNOWN COMMAND\x00"))
+	}
+}
+
+func TestClamd(t *testing.T) {
+	l := newFakeClamd(t)
+	defer l.Close()
+
+	ctx := context.Background()
+	c := NewClamd("tcp", l.Addr().String(), 5)
+	assert.Nil(t, c.Ping(ctx))
+
+	result, err := c.Scan(ctx, nil, strings.NewReader("hello world"))
+	assert.Nil(t, err)
+	assert.False(t, result.Infected)
+
+	// Larger than a single chunk
+	content := strings.Repeat("a", clamdChunkSize*2) + "EICAR"
+	result, err = c.Scan(ctx, nil, strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", result.Threat)
+	assert.Equal(t, "clamd", result.Scanner)
+
+	// Unreachable daemon
+	_, err = NewClamd("tcp", "127.0.0.1:1", 1).Scan(ctx, nil, strings.NewReader("x"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/filemanager/schema"
+)
+
+// Result - Scan result of a single scanner
+type Result struct {
+	Scanner  string // Name of the scanner
+	Infected bool   // File is rejected
+	Threat   string // Virus signature or validation failure reason
+}
+
+// Scanner - Upload scanner, antivirus engines and custom validators implement it
+type Scanner interface {
+	// Scanner name, used in logs and results
+	Name() string
+	// Scan the file content, item holds the metadata of the upload
+	Scan(ctx context.Context, item *schema.File, r io.Reader) (*Result, error)
+}
+
+// Func - Adapter to use an ordinary function as a custom validator
+type Func func(ctx context.Context, item *schema.File, r io.Reader) (*Result, error)
+
+type funcScanner struct {
+	name string
+	fn   Func
+}
+
+func (a *funcScanner) Name() string {
+	return a.name
+}
+
+func (a *funcScanner) Scan(ctx context.Context, item *schema.File, r io.Reader) (*Result, error) {
+	return a.fn(ctx, item, r)
+}
+
+// NewFunc - Create a named scanner from a function
+func NewFunc(name string, fn Func) Scanner {
+	return &funcScanner{name: name, fn: fn}
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     []Scanner
+)
+
+// Register - Register a custom scanner, it runs on every upload after the configured ones
+func Register(s Scanner) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry = append(registry, s)
+}
+
+// Unregister - Remove a previously registered custom scanner
+func Unregister(name string) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	for i, s := range registry {
+		if s.Name() == name {
+			registry = append(registry[:i], registry[i+1:]...)
+			return
+		}
+	}
+}
+
+func registered() []Scanner {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	return append([]Scanner{}, registry...)
+}
+
+// NewChain - Create the scanner chain from the configuration
+func NewChain() *Chain {
+	cfg := config.Global().FileManager.Clamd
+
+	var scanners []Scanner
+	if cfg.Enable {
+		scanners = append(scanners, NewClamd(cfg.Network, cfg.Address, cfg.Timeout))
+	}
+	return &Chain{scanners: scanners}
+}
+
+// Chain - Scanner chain, runs the configured and the registered scanners in order
+type Chain struct {
+	scanners []Scanner
+}
+
+// Scan - Scan a stored file, stops at the first scanner rejecting it
+func (a *Chain) Scan(ctx context.Context, item *schema.File, filepath string) (*Result, error) {
+	for _, s := range append(append([]Scanner{}, a.scanners...), registered()...) {
+		result, err := a.scan(ctx, s, item, filepath)
+		if err != nil {
+			return nil, err
+		} else if result != nil && result.Infected {
+			if result.Scanner == "" {
+				result.Scanner = s.Name()
+			}
+			return result, nil
+		}
+	}
+	return &Result{}, nil
+}
+
+func (a *Chain) scan(ctx context.Context, s Scanner, item *schema.File, filepath string) (*Result, error) {
+	f, err := os.Open(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return s.Scan(ctx, item, f)
+}
@@ -5,34 +5,105 @@ import (
 	"time"
 )
 
+// Scan status of an uploaded file
+const (
+	ScanStatusQuarantine = "quarantine" // Waiting for the scanners, not visible yet
+	ScanStatusClean      = "clean"      // Accepted by every scanner
+	ScanStatusInfected   = "infected"   // Rejected, the content has been removed
+)
+
 // file - file object
 type File struct {
-	UUID      string    `json:"uuid"`                         // UUID
-	UID       uint      `json:"uid" binding:"required"`       // User ID
-	UserUUID  string    `json:"user_uuid" binding:"required"` // User ID
-	Filename  string    `json:"filename" binding:"required"`  // File Name
-	Uri       string    `json:"uri"`                          // File URI
-	Filemime  string    `json:"filemime"`                     // Filemime (image/jpeg, application/msword etc)
-	Filesize  int64     `json:"filesize"`                     // Filesize in bytes
-	FileExt   string    `json:"file_ext" binding:"required"`  // File Extention
-	CreatedAt time.Time `json:"created"`                      // File created
+	UUID         string           `json:"uuid"`                        // UUID
+	UID          uint             `json:"uid"`                         // User ID
+	UserUUID     string           `json:"user_uuid"`                   // User ID
+	FolderID     string           `json:"folder_id"`                   // Folder UUID (empty for the library root)
+	Filename     string           `json:"filename" binding:"required"` // File Name
+	Uri          string           `json:"uri"`                         // File URI
+	Filemime     string           `json:"filemime"`                    // Filemime (image/jpeg, application/msword etc)
+	Filesize     int64            `json:"filesize"`                    // Filesize in bytes
+	FileExt      string           `json:"file_ext"`                    // File Extention
+	Width        int              `json:"width"`                       // Width in pixels (images and video)
+	Height       int              `json:"height"`                      // Height in pixels (images and video)
+	Duration     int              `json:"duration"`                    // Duration in seconds (audio and video)
+	Orientation  int              `json:"orientation"`                 // EXIF orientation of the original image
+	CameraMake   string           `json:"camera_make"`                 // Camera manufacturer
+	CameraModel  string           `json:"camera_model"`                // Camera model
+	TakenAt      *time.Time       `json:"taken_at,omitempty"`          // Capture time
+	Latitude     *float64         `json:"latitude,omitempty"`          // GPS latitude
+	Longitude    *float64         `json:"longitude,omitempty"`         // GPS longitude
+	Private      bool             `json:"private"`                     // Private file, only served through the download endpoint
+	Downloads    int64            `json:"downloads"`                   // Download counter
+	ScanStatus   string           `json:"scan_status"`                 // Scan status (quarantine/clean/infected)
+	ScanThreat   string           `json:"scan_threat,omitempty"`       // Threat or reason reported by the rejecting scanner
+	Tags         []string         `json:"tags"`                        // Tag list
+	Translations FileTranslations `json:"translations"`                // Title, alt text and caption per language
+	CreatedAt    time.Time        `json:"created"`                     // File created
+}
+
+// FileSignedURL - Temporary download link
+type FileSignedURL struct {
+	URL       string `json:"url"`        // Signed download URL
+	ExpiresAt int64  `json:"expires_at"` // Expiration timestamp
+}
+
+// FileTranslation - Translatable file metadata
+type FileTranslation struct {
+	Lang    string `json:"language" binding:"required"` // Language Code Identifier
+	Title   string `json:"title"`                       // File Title
+	Alt     string `json:"alt"`                         // Alternative text
+	Caption string `json:"caption"`                     // Caption
+}
+
+// FileTranslations - File translation list
+type FileTranslations []*FileTranslation
+
+// ToMap - Convert to key-value mapping
+func (a FileTranslations) ToMap() map[string]*FileTranslation {
+	m := make(map[string]*FileTranslation)
+	for _, item := range a {
+		m[item.Lang] = item
+	}
+	return m
 }
 
 // fileQueryParam - Query conditions
 type FileQueryParam struct {
-	UUID         string // UUID
-	Filename     string // File Name
-	Uri          string // File URI
-	LikeFilename string // Name (fuzzy query)
+	UUID         string   // UUID
+	UUIDs        []string // UUID list
+	Filename     string   // File Name
+	Uri          string   // File URI
+	Uris         []string // File URI list
+	LikeFilename string   // Name (fuzzy query)
+	FolderID     *string  // Folder UUID ("" for the library root)
+	Tags         []string // Tag list (files carrying any of the tags)
+	Type         string   // Mime type or mime type prefix (image, video/mp4 etc)
+	Camera       string   // Camera make or model (fuzzy query)
+	HasGPS       *bool    // With or without GPS coordinates
+	ScanStatus   string   // Scan status, quarantined and infected files are hidden when empty
 }
 
 // fileQueryOptions - file object query optional parameter item
 type FileQueryOptions struct {
-	PageParam *schema.PaginationParam // Paging parameter
+	PageParam           *schema.PaginationParam // Paging parameter
+	IncludeTags         bool                    // Include tag list
+	IncludeTranslations bool                    // Include translations
 }
 
 // fileQueryResult - file object query result
 type FileQueryResult struct {
-	Data       []*File
+	Data       Files
 	PageResult *schema.PaginationResult
 }
+
+// Files - File list
+type Files []*File
+
+// ToUUIDs - Convert to a list of file UUIDs
+func (a Files) ToUUIDs() []string {
+	list := make([]string, len(a))
+	for i, item := range a {
+		list[i] = item.UUID
+	}
+	return list
+}
@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/common/schema"
+)
+
+// Folder - Virtual media library folder
+type Folder struct {
+	UUID       string    `json:"uuid"`                    // UUID
+	Name       string    `json:"name" binding:"required"` // Folder name
+	Sequence   int       `json:"sequence"`                // Sort value
+	ParentID   string    `json:"parent_id"`               // Parent folder UUID
+	ParentPath string    `json:"parent_path"`             // Parent path
+	Creator    string    `json:"creator"`                 // Creator
+	CreatedAt  time.Time `json:"created_at"`              // Creation time
+}
+
+// FolderQueryParam - Query conditions
+type FolderQueryParam struct {
+	UUIDs            []string // UUID list
+	Name             string   // Folder name
+	LikeName         string   // Folder name (fuzzy query)
+	ParentID         *string  // Parent folder UUID
+	PrefixParentPath string   // Parent path (prefix fuzzy query)
+}
+
+// FolderQueryOptions - Folder object query optional parameter item
+type FolderQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// FolderQueryResult - Folder object query result
+type FolderQueryResult struct {
+	Data       Folders
+	PageResult *schema.PaginationResult
+}
+
+// Folders - Folder list
+type Folders []*Folder
+
+// ToTrees - Convert to folder tree list
+func (a Folders) ToTrees() FolderTrees {
+	list := make(FolderTrees, len(a))
+	for i, item := range a {
+		list[i] = &FolderTree{
+			UUID:       item.UUID,
+			Name:       item.Name,
+			Sequence:   item.Sequence,
+			ParentID:   item.ParentID,
+			ParentPath: item.ParentPath,
+		}
+	}
+	return list
+}
+
+// FolderTree - Folder tree
+type FolderTree struct {
+	UUID       string         `json:"uuid"`               // UUID
+	Name       string         `json:"name"`               // Folder name
+	Sequence   int            `json:"sequence"`           // Sort value
+	ParentID   string         `json:"parent_id"`          // Parent folder UUID
+	ParentPath string         `json:"parent_path"`        // Parent path
+	Children   *[]*FolderTree `json:"children,omitempty"` // Child tree
+}
+
+// FolderTrees - Folder tree list
+type FolderTrees []*FolderTree
+
+// ToTree - Convert to tree structure
+func (a FolderTrees) ToTree() []*FolderTree {
+	mi := make(map[string]*FolderTree)
+	for _, item := range a {
+		mi[item.UUID] = item
+	}
+
+	var list []*FolderTree
+	for _, item := range a {
+		if item.ParentID == "" {
+			list = append(list, item)
+			continue
+		}
+		if pitem, ok := mi[item.ParentID]; ok {
+			if pitem.Children == nil {
+				var children []*FolderTree
+				children = append(children, item)
+				pitem.Children = &children
+				continue
+			}
+			*pitem.Children = append(*pitem.Children, item)
+		}
+	}
+	return list
+}
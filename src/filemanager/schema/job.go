@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"time"
+)
+
+// Type of a file job
+const (
+	FileJobTypeExtract = "extract" // Zip upload being extracted into the library
+	FileJobTypeArchive = "archive" // Zip download being built from a selection
+	FileJobTypeIndex   = "index"   // Text extraction of stored documents
+)
+
+// Status of a file job
+const (
+	FileJobStatusPending = "pending"
+	FileJobStatusRunning = "running"
+	FileJobStatusDone    = "done"
+	FileJobStatusFailed  = "failed"
+)
+
+// FileJob - Background bulk file job
+type FileJob struct {
+	UUID      string    `json:"uuid"`       // UUID
+	Type      string    `json:"type"`       // Job type (extract/archive/index)
+	Status    string    `json:"status"`     // Job status (pending/running/done/failed)
+	Total     int       `json:"total"`      // Number of entries to process
+	Processed int       `json:"processed"`  // Number of entries processed
+	Failed    int       `json:"failed"`     // Number of entries rejected
+	Errors    []string  `json:"errors"`     // Rejection reason per failed entry
+	Result    string    `json:"-"`          // Path of the built archive
+	Creator   string    `json:"creator"`    // Creator
+	CreatedAt time.Time `json:"created_at"` // Creation time
+	UpdatedAt time.Time `json:"updated_at"` // Last progress time
+}
+
+// FileArchiveParam - Selection of files for an archive download
+type FileArchiveParam struct {
+	UUIDs    []string `json:"uuids"`     // File UUID list
+	FolderID string   `json:"folder_id"` // Folder UUID, files of its subfolders are included
+}
+
+// FileExtractParam - Target of an archive upload
+type FileExtractParam struct {
+	UID        uint     // User ID
+	FolderID   string   // Folder UUID
+	Tags       []string // Tag list applied to every entry
+	Private    bool     // Private files
+	StripExif  bool     // Strip jpeg metadata
+	AutoRotate bool     // Rotate jpeg by EXIF orientation
+}
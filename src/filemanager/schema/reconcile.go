@@ -0,0 +1,9 @@
+package schema
+
+// ReconcileReport - Mismatches between the file records and the storage
+type ReconcileReport struct {
+	Checked  int      `json:"checked"`  // Number of records checked
+	Missing  Files    `json:"missing"`  // Records whose content is missing from the storage
+	Orphans  []string `json:"orphans"`  // Stored content without a record
+	Repaired bool     `json:"repaired"` // Whether the mismatches have been repaired
+}
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/common/schema"
+)
+
+// FileText - Plain text extracted from a document for search
+type FileText struct {
+	FileID    string    `json:"file_id"`    // File UUID
+	Content   string    `json:"content"`    // Extracted text
+	CreatedAt time.Time `json:"created_at"` // Extraction time
+}
+
+// FileTextQueryParam - Query conditions
+type FileTextQueryParam struct {
+	FileIDs []string // File UUID list
+	Words   []string // Words the text contains (all of them, case insensitive)
+}
+
+// FileTextQueryOptions - File text object query optional parameter item
+type FileTextQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// FileTextQueryResult - File text object query result
+type FileTextQueryResult struct {
+	Data       FileTexts
+	PageResult *schema.PaginationResult
+}
+
+// FileTexts - File text list
+type FileTexts []*FileText
+
+// FileSearchParam - Full text search conditions
+type FileSearchParam struct {
+	Query string // Words to search for
+}
+
+// FileSearchHit - File matching a search with an excerpt of its text
+type FileSearchHit struct {
+	File    *File  `json:"file"`    // Matching file
+	Snippet string `json:"snippet"` // Excerpt around the first match
+}
+
+// FileSearchResult - Full text search result
+type FileSearchResult struct {
+	Data       []*FileSearchHit
+	PageResult *schema.PaginationResult
+}
+
+// FileIndexParam - Documents to extract the text of
+type FileIndexParam struct {
+	All bool `json:"all"` // Extract again documents that already have a text
+}
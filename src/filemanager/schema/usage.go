@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/MayCMF/core/src/common/schema"
+)
+
+// Type of an entity using a file
+const (
+	FileUsageNode = "node" // Node references or body text
+	FileUsageUser = "user" // User avatar
+)
+
+// FileUsage - Place where a file is used
+type FileUsage struct {
+	FileID     string    `json:"file_id"`     // File UUID
+	EntityType string    `json:"entity_type"` // Type of the entity using the file (node/user etc)
+	EntityID   string    `json:"entity_id"`   // UUID of the entity using the file
+	Field      string    `json:"field"`       // Field of the entity holding the reference
+	CreatedAt  time.Time `json:"created_at"`  // Creation time
+}
+
+// FileUsageQueryParam - Query conditions
+type FileUsageQueryParam struct {
+	FileID     string // File UUID
+	EntityType string // Entity type
+	EntityID   string // Entity UUID
+}
+
+// FileUsageQueryOptions - File usage object query optional parameter item
+type FileUsageQueryOptions struct {
+	PageParam *schema.PaginationParam // Paging parameter
+}
+
+// FileUsageQueryResult - File usage object query result
+type FileUsageQueryResult struct {
+	Data       FileUsages
+	PageResult *schema.PaginationResult
+}
+
+// FileUsages - File usage list
+type FileUsages []*FileUsage
@@ -0,0 +1,201 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	aschema "github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newZip(entries map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, content := range entries {
+		fw, _ := zw.Create(name)
+		_, _ = fw.Write([]byte(content))
+	}
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func readZipNames(t *testing.T, data []byte) []string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.Nil(t, err)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Poll a job until it has finished
+func waitFileJob(t *testing.T, UUID string) *schema.FileJob {
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, newGetRequest("%sv1/file-jobs/%s", nil, apiPrefix, UUID))
+		assert.Equal(t, 200, w.Code)
+		var job schema.FileJob
+		err := parseReader(w.Body, &job)
+		assert.Nil(t, err)
+		if job.Status == schema.FileJobStatusDone || job.Status == schema.FileJobStatusFailed {
+			return &job
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("file job %s did not finish", UUID)
+	return nil
+}
+
+func TestAPIFileArchive(t *testing.T) {
+	const router = apiPrefix + "v1/file-archives"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-archive")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+	cfg.FileManager.JobDir = filepath.Join(dir, "jobs")
+
+	// post /folders
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/folders", &schema.Folder{Name: util.MustUUID()}))
+	assert.Equal(t, 200, w.Code)
+	var folder schema.Folder
+	err = parseReader(w.Body, &folder)
+	assert.Nil(t, err)
+
+	// post /file-archives
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("MayFile", "bulk.zip")
+	_, _ = fw.Write(newZip(map[string]string{
+		"docs/a.json":  `{"a":1}`,
+		"b.json":       `{"b":2}`,
+		"../evil.json": `{"evil":true}`,
+		"run.exe":      "MZ",
+	}))
+	_ = mw.WriteField("FolderID", folder.UUID)
+	_ = mw.Close()
+	req, _ := http.NewRequest("POST", router, body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	var job schema.FileJob
+	err = parseReader(w.Body, &job)
+	assert.Nil(t, err)
+	assert.Equal(t, schema.FileJobTypeExtract, job.Type)
+	assert.Equal(t, 4, job.Total)
+
+	// get /file-jobs/:id
+	done := waitFileJob(t, job.UUID)
+	assert.Equal(t, schema.FileJobStatusDone, done.Status)
+	assert.Equal(t, 4, done.Processed)
+	assert.Equal(t, 2, done.Failed)
+	assert.Equal(t, 2, len(done.Errors))
+
+	// get /file?folderID=
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/file", newPageParam(map[string]string{"folderID": folder.UUID, "pageSize": "10"})))
+	assert.Equal(t, 200, w.Code)
+	var files []*schema.File
+	err = parsePageReader(w.Body, &files)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(files))
+
+	// The extracted files are audited as created by the uploader
+	if len(files) > 0 {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/audit", newPageParam(map[string]string{
+			"entityType": aschema.AuditEntityFile,
+			"entityID":   files[0].UUID,
+		})))
+		assert.Equal(t, 200, w.Code)
+		var audits []*aschema.Audit
+		err = parsePageReader(w.Body, &audits)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(audits))
+		if len(audits) > 0 {
+			assert.Equal(t, config.Global().Root.UserName, audits[0].ActorUUID)
+		}
+	}
+
+	// get /file-archives?folderID=
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, map[string]string{"folderID": folder.UUID}))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Equal(t, []string{"a.json", "b.json"}, readZipNames(t, w.Body.Bytes()))
+
+	// get /file-archives (nothing selected)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, nil))
+	assert.Equal(t, 400, w.Code)
+
+	// post /file-archives/jobs
+	var uuids []string
+	for _, item := range files {
+		uuids = append(uuids, item.UUID)
+	}
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(router+"/jobs", &schema.FileArchiveParam{UUIDs: uuids}))
+	assert.Equal(t, 200, w.Code)
+	var buildJob schema.FileJob
+	err = parseReader(w.Body, &buildJob)
+	assert.Nil(t, err)
+	assert.Equal(t, schema.FileJobTypeArchive, buildJob.Type)
+	assert.Equal(t, config.Global().Root.UserName, buildJob.Creator)
+
+	built := waitFileJob(t, buildJob.UUID)
+	assert.Equal(t, schema.FileJobStatusDone, built.Status)
+	assert.Equal(t, 2, built.Processed)
+
+	// get /file-jobs/:id/download
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%sv1/file-jobs/%s/download", nil, apiPrefix, buildJob.UUID))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, []string{"a.json", "b.json"}, readZipNames(t, w.Body.Bytes()))
+
+	// The archive is removed once it is downloaded
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%sv1/file-jobs/%s/download", nil, apiPrefix, buildJob.UUID))
+	assert.Equal(t, 404, w.Code)
+
+	// Extract jobs have nothing to download
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%sv1/file-jobs/%s/download", nil, apiPrefix, job.UUID))
+	assert.Equal(t, 400, w.Code)
+
+	for _, item := range files {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%sv1/file/%s", apiPrefix, item.UUID))
+		assert.Equal(t, 200, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%sv1/folders/%s", apiPrefix, folder.UUID))
+	assert.Equal(t, 200, w.Code)
+}
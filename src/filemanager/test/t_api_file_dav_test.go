@@ -0,0 +1,216 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/auth"
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/scanner"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	pschema "github.com/MayCMF/core/src/primitives/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDavRequest(method, router, body string, headers map[string]string) *http.Request {
+	req, _ := http.NewRequest(method, "/dav/"+router, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestAPIFileDav(t *testing.T) {
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-dav")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.PrivateDir = filepath.Join(dir, "private")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+
+	scanner.Register(scanner.NewFunc("eicar-dav", func(ctx context.Context, item *schema.File, r io.Reader) (*scanner.Result, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return &scanner.Result{Infected: strings.Contains(string(b), "EICAR"), Threat: "EICAR test file"}, nil
+	}))
+	defer scanner.Unregister("eicar-dav")
+
+	folderName := util.MustUUID()
+	fileName := util.MustUUID() + ".json"
+
+	// mkcol /dav/:folder
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("MKCOL", folderName+"/", "", nil))
+	assert.Equal(t, 201, w.Code)
+
+	// put /dav/:folder/:file
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("PUT", folderName+"/"+fileName, `{"a":1}`, nil))
+	assert.Equal(t, 201, w.Code)
+
+	// get /folders?name= and /file?folderID=
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/folders", newPageParam(map[string]string{"name": folderName})))
+	assert.Equal(t, 200, w.Code)
+	var folders []*schema.Folder
+	err = parsePageReader(w.Body, &folders)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(folders))
+	if len(folders) == 0 {
+		return
+	}
+	folder := folders[0]
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/file", newPageParam(map[string]string{"folderID": folder.UUID})))
+	assert.Equal(t, 200, w.Code)
+	var files []*schema.File
+	err = parsePageReader(w.Body, &files)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+	if len(files) == 0 {
+		return
+	}
+	file := files[0]
+	assert.Equal(t, fileName, file.Filename)
+	assert.Equal(t, "application/json", file.Filemime)
+	assert.Equal(t, schema.ScanStatusClean, file.ScanStatus)
+
+	// propfind /dav/:folder
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("PROPFIND", folderName+"/", "", map[string]string{"Depth": "1"}))
+	assert.Equal(t, 207, w.Code)
+	assert.Contains(t, w.Body.String(), fileName)
+
+	// get /dav/:folder/:file
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("GET", folderName+"/"+fileName, "", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"a":1}`, w.Body.String())
+
+	// post /file/upload (private file in the folder)
+	privateName := util.MustUUID() + ".json"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newUploadFormRequest(apiPrefix+"v1/file/upload", privateName, "application/json",
+		`{"private":true}`, map[string]string{"Private": "true", "FolderID": folder.UUID}))
+	assert.Equal(t, 200, w.Code)
+
+	// A user without permissions does not see the private file and may not change others' entries
+	var token auth.TokenInfo
+	err = container.Invoke(func(a auth.Auther) (err error) {
+		token, err = a.GenerateToken(context.Background(), util.MustUUID())
+		return
+	})
+	assert.Nil(t, err)
+	asStranger := func(req *http.Request) *http.Request {
+		req.SetBasicAuth("dav", token.GetAccessToken())
+		return req
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, asStranger(newDavRequest("PROPFIND", folderName+"/", "", map[string]string{"Depth": "1"})))
+	assert.Equal(t, 207, w.Code)
+	assert.Contains(t, w.Body.String(), fileName)
+	assert.NotContains(t, w.Body.String(), privateName)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, asStranger(newDavRequest("GET", folderName+"/"+privateName, "", nil)))
+	assert.Equal(t, 404, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, asStranger(newDavRequest("PUT", folderName+"/"+fileName, `{"c":3}`, nil)))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, asStranger(newDavRequest("DELETE", folderName+"/"+fileName, "", nil)))
+	assert.Equal(t, 401, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, asStranger(newDavRequest("DELETE", folderName+"/", "", nil)))
+	assert.Equal(t, 401, w.Code)
+
+	// put /dav/:folder/:file (replaces the content of the same record)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("PUT", folderName+"/"+fileName, `{"b":2}`, nil))
+	assert.Equal(t, 201, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("GET", folderName+"/"+fileName, "", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"b":2}`, w.Body.String())
+
+	// put /dav/:folder/:file (rejected by the scanners)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("PUT", folderName+"/"+util.MustUUID()+".json", `{"EICAR":true}`, nil))
+	assert.Equal(t, 400, w.Code)
+
+	// move /dav/:folder/:file
+	movedName := util.MustUUID() + ".json"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("MOVE", folderName+"/"+fileName, "", map[string]string{
+		"Destination": "/dav/" + folderName + "/" + movedName,
+	}))
+	assert.Equal(t, 201, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%sv1/file/%s", nil, apiPrefix, file.UUID))
+	assert.Equal(t, 200, w.Code)
+	var moved schema.File
+	err = parseReader(w.Body, &moved)
+	assert.Nil(t, err)
+	assert.Equal(t, movedName, moved.Filename)
+
+	// post /node (the file is in use)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/node", &pschema.Node{
+		UID:        1,
+		Slug:       util.MustUUID(),
+		Parent:     util.MustUUID(),
+		References: json.RawMessage(`{"cover":"` + file.UUID + `"}`),
+		NodeBodies: pschema.NodeBodies{{Lang: "en", Title: "Dav"}},
+	}))
+	assert.Equal(t, 200, w.Code)
+	var nodeItem pschema.Node
+	err = parseReader(w.Body, &nodeItem)
+	assert.Nil(t, err)
+
+	// delete /dav/:folder (refused as a whole)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("DELETE", folderName+"/", "", nil))
+	assert.Equal(t, 409, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("DELETE", folderName+"/"+movedName, "", nil))
+	assert.Equal(t, 409, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%sv1/node/%s", apiPrefix, nodeItem.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// delete /dav/:folder
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDavRequest("DELETE", folderName+"/", "", nil))
+	assert.Equal(t, 204, w.Code)
+	assert.False(t, pathExists(moved.Uri))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%sv1/folders/%s", nil, apiPrefix, folder.UUID))
+	assert.Equal(t, 404, w.Code)
+}
@@ -0,0 +1,218 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/controllers"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUploadFormRequest(router, filename, mimeType, content string, fields map[string]string) *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for k, v := range fields {
+		_ = mw.WriteField(k, v)
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="MayFile"; filename="%s"`, filename))
+	h.Set("Content-Type", mimeType)
+	fw, _ := mw.CreatePart(h)
+	_, _ = fw.Write([]byte(content))
+	_ = mw.Close()
+
+	req, _ := http.NewRequest("POST", router, body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestAPIFileDownload(t *testing.T) {
+	const router = apiPrefix + "v1/file"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-download")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.PrivateDir = filepath.Join(dir, "private")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+
+	// post /file/upload
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newUploadFormRequest(router+"/upload", util.MustUUID()+".json", "application/json",
+		"private content", map[string]string{"Private": "true"}))
+	assert.Equal(t, 200, w.Code)
+	var addNewItem schema.File
+	err = parseReader(w.Body, &addNewItem)
+	assert.Nil(t, err)
+	assert.True(t, addNewItem.Private)
+
+	// get /file/:id/download (anonymous)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/download", nil, router, addNewItem.UUID))
+	assert.Equal(t, 401, w.Code)
+
+	// get /file/:id/download (signed)
+	expiresAt := time.Now().Add(time.Minute).Unix()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/download", map[string]string{
+		"expires":   strconv.FormatInt(expiresAt, 10),
+		"signature": controllers.SignFile(addNewItem.UUID, expiresAt),
+	}, router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "private content", w.Body.String())
+
+	// get /file/:id/download (tampered expiry)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/download", map[string]string{
+		"expires":   strconv.FormatInt(expiresAt+60, 10),
+		"signature": controllers.SignFile(addNewItem.UUID, expiresAt),
+	}, router, addNewItem.UUID))
+	assert.Equal(t, 401, w.Code)
+
+	// get /file/:id/download (expired)
+	expiredAt := time.Now().Add(-time.Minute).Unix()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/download", map[string]string{
+		"expires":   strconv.FormatInt(expiredAt, 10),
+		"signature": controllers.SignFile(addNewItem.UUID, expiredAt),
+	}, router, addNewItem.UUID))
+	assert.Equal(t, 401, w.Code)
+
+	// delete /file/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAPIFileDownloadRange(t *testing.T) {
+	const router = apiPrefix + "v1/file"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-download")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+
+	// post /file/upload
+	filename := util.MustUUID() + ".json"
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newUploadFormRequest(router+"/upload", filename, "text/plain", "0123456789", nil))
+	assert.Equal(t, 200, w.Code)
+	var addNewItem schema.File
+	err = parseReader(w.Body, &addNewItem)
+	assert.Nil(t, err)
+
+	// get /file/:id/download
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/download", nil, router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "0123456789", w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename="+filename, w.Header().Get("Content-Disposition"))
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+
+	// get /file/:id/download (range)
+	w = httptest.NewRecorder()
+	req := newGetRequest("%s/%s/download", nil, router, addNewItem.UUID)
+	req.Header.Set("Range", "bytes=2-5")
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, 206, w.Code)
+	assert.Equal(t, "2345", w.Body.String())
+	assert.Equal(t, "bytes 2-5/10", w.Header().Get("Content-Range"))
+
+	// get /file/:id/download (revalidate)
+	w = httptest.NewRecorder()
+	req = newGetRequest("%s/%s/download", nil, router, addNewItem.UUID)
+	req.Header.Set("If-None-Match", etag)
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, 304, w.Code)
+
+	// get /file/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s", nil, router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	var getItem schema.File
+	err = parseReader(w.Body, &getItem)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), getItem.Downloads)
+
+	// delete /file/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAPIFileDownloadActive(t *testing.T) {
+	const router = apiPrefix + "v1/file"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-download")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+
+	// post /file/upload (the mime type sent by the client is ignored)
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newUploadFormRequest(router+"/upload", util.MustUUID()+".svg", "text/plain", svg, nil))
+	assert.Equal(t, 200, w.Code)
+	var addNewItem schema.File
+	err = parseReader(w.Body, &addNewItem)
+	assert.Nil(t, err)
+	assert.Equal(t, "image/svg+xml", addNewItem.Filemime)
+
+	// put /file/:id (the mime type is read-only)
+	addNewItem.Filemime = "text/plain"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPutRequest("%s/%s", addNewItem, router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	var putNewItem schema.File
+	err = parseReader(w.Body, &putNewItem)
+	assert.Nil(t, err)
+	assert.Equal(t, "image/svg+xml", putNewItem.Filemime)
+
+	// get /file/:id/download (inline is refused for active content)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/download", map[string]string{"inline": "true"}, router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, svg, w.Body.String())
+	assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment;"))
+	assert.Equal(t, "sandbox", w.Header().Get("Content-Security-Policy"))
+
+	// delete /file/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+}
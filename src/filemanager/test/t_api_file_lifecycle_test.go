@@ -0,0 +1,190 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/scanner"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMultiUploadRequest(method, router string, files map[string]string) *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for filename, content := range files {
+		fw, _ := mw.CreateFormFile("MayFile", filename)
+		_, _ = fw.Write([]byte(content))
+	}
+	_ = mw.Close()
+
+	req, _ := http.NewRequest(method, router, body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestAPIFileLifecycle(t *testing.T) {
+	const router = apiPrefix + "v1/file"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-lifecycle")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+
+	scanner.Register(scanner.NewFunc("eicar", func(ctx context.Context, item *schema.File, r io.Reader) (*scanner.Result, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		} else if strings.Contains(string(b), "BROKEN") {
+			return nil, errors.New("scanner unavailable")
+		}
+		return &scanner.Result{Infected: strings.Contains(string(b), "EICAR"), Threat: "EICAR test file"}, nil
+	}))
+	defer scanner.Unregister("eicar")
+
+	// post /file/upload (batch)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newMultiUploadRequest("POST", router+"/upload", map[string]string{
+		util.MustUUID() + ".json": `{"a":1}`,
+		util.MustUUID() + ".json": `{"b":2}`,
+	}))
+	assert.Equal(t, 200, w.Code)
+	var items []*schema.File
+	err = parsePageReader(w.Body, &items)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(items))
+
+	// post /file/upload (a rejected file fails the whole batch)
+	cleanName, infectedName := util.MustUUID()+".json", util.MustUUID()+".json"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newMultiUploadRequest("POST", router+"/upload", map[string]string{
+		cleanName:    `{"clean":true}`,
+		infectedName: `{"EICAR":true}`,
+	}))
+	assert.Equal(t, 400, w.Code)
+
+	// post /file/upload (the scanner fails)
+	brokenName := util.MustUUID() + ".json"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newMultiUploadRequest("POST", router+"/upload", map[string]string{
+		brokenName: `{"BROKEN":true}`,
+	}))
+	assert.Equal(t, 500, w.Code)
+
+	// No records are left of the failed uploads
+	for _, params := range []map[string]string{
+		{"filename": cleanName},
+		{"filename": infectedName, "scanStatus": schema.ScanStatusInfected},
+		{"filename": brokenName, "scanStatus": schema.ScanStatusQuarantine},
+	} {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newGetRequest(router, newPageParam(params)))
+		assert.Equal(t, 200, w.Code)
+		var leftItems []*schema.File
+		err = parsePageReader(w.Body, &leftItems)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(leftItems))
+	}
+
+	if len(items) == 2 {
+		item := items[0]
+
+		// put /file/:id/content
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newMultiUploadRequest("PUT", router+"/"+item.UUID+"/content", map[string]string{
+			"replaced.json": `{"replaced":true}`,
+		}))
+		assert.Equal(t, 200, w.Code)
+		var replaced schema.File
+		err = parseReader(w.Body, &replaced)
+		assert.Nil(t, err)
+		assert.Equal(t, "replaced.json", replaced.Filename)
+		assert.Equal(t, int64(17), replaced.Filesize)
+		assert.False(t, pathExists(item.Uri))
+		content, err := ioutil.ReadFile(replaced.Uri)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"replaced":true}`, string(content))
+
+		// put /file/:id/content (rejected, the current content is kept)
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newMultiUploadRequest("PUT", router+"/"+item.UUID+"/content", map[string]string{
+			"infected.json": `{"EICAR":true}`,
+		}))
+		assert.Equal(t, 400, w.Code)
+		content, err = ioutil.ReadFile(replaced.Uri)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"replaced":true}`, string(content))
+
+		// delete /file/:id removes the content with the record
+		for _, v := range []*schema.File{&replaced, items[1]} {
+			w = httptest.NewRecorder()
+			engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, v.UUID))
+			assert.Equal(t, 200, w.Code)
+			assert.False(t, pathExists(v.Uri))
+		}
+	}
+
+	// post /file/upload (same name twice) stores both contents apart
+	sameName := util.MustUUID() + ".json"
+	var twins []*schema.File
+	for _, content := range []string{`{"first":true}`, `{"second":true}`} {
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newMultiUploadRequest("POST", router+"/upload", map[string]string{
+			sameName: content,
+		}))
+		assert.Equal(t, 200, w.Code)
+		var uploaded schema.File
+		err = parseReader(w.Body, &uploaded)
+		assert.Nil(t, err)
+		twins = append(twins, &uploaded)
+	}
+	if assert.Equal(t, 2, len(twins)) {
+		assert.NotEqual(t, twins[0].Uri, twins[1].Uri)
+
+		// delete /file/:id keeps the content of the other upload
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, twins[0].UUID))
+		assert.Equal(t, 200, w.Code)
+		assert.False(t, pathExists(twins[0].Uri))
+		content, err := ioutil.ReadFile(twins[1].Uri)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"second":true}`, string(content))
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, twins[1].UUID))
+		assert.Equal(t, 200, w.Code)
+	}
+
+	// Nothing is left behind in storage
+	var stored []string
+	_ = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			stored = append(stored, p)
+		}
+		return nil
+	})
+	assert.Equal(t, 0, len(stored))
+}
+
+func pathExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDocx(paragraphs ...string) string {
+	var body strings.Builder
+	for _, p := range paragraphs {
+		body.WriteString("<w:p><w:r><w:t>" + p + "</w:t></w:r></w:p>")
+	}
+	return string(newZip(map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			body.String() + `</w:body></w:document>`,
+	}))
+}
+
+func TestAPIFileText(t *testing.T) {
+	const router = apiPrefix + "v1/file"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-text")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+	cfg.FileManager.TextSize = 1024
+
+	word := strings.Replace(util.MustUUID(), "-", "", -1)
+
+	// post /file/upload
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newMultiUploadRequest("POST", router+"/upload", map[string]string{
+		util.MustUUID() + ".docx": newDocx("Quarterly report", "Revenue of "+word+" grew"),
+	}))
+	assert.Equal(t, 200, w.Code)
+	var item schema.File
+	err = parseReader(w.Body, &item)
+	assert.Nil(t, err)
+
+	// get /file/:id/text
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/text", nil, router, item.UUID))
+	assert.Equal(t, 200, w.Code)
+	var text schema.FileText
+	err = parseReader(w.Body, &text)
+	assert.Nil(t, err)
+	assert.Equal(t, "Quarterly report\nRevenue of "+word+" grew", text.Content)
+
+	// get /file-search?q=
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/file-search", newPageParam(map[string]string{"q": strings.ToUpper(word) + " revenue"})))
+	assert.Equal(t, 200, w.Code)
+	var hits []*schema.FileSearchHit
+	err = parsePageReader(w.Body, &hits)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(hits))
+	if len(hits) > 0 {
+		assert.Equal(t, item.UUID, hits[0].File.UUID)
+		assert.Equal(t, "Quarterly report Revenue of "+word+" grew", hits[0].Snippet)
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/file-search", newPageParam(map[string]string{"q": word + " missing"})))
+	assert.Equal(t, 200, w.Code)
+	hits = nil
+	err = parsePageReader(w.Body, &hits)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(hits))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/file-search", nil))
+	assert.Equal(t, 400, w.Code)
+
+	// put /file/:id/content (the text follows the content)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newMultiUploadRequest("PUT", router+"/"+item.UUID+"/content", map[string]string{
+		item.Filename: newDocx("Replaced " + word),
+	}))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/text", nil, router, item.UUID))
+	assert.Equal(t, 200, w.Code)
+	err = parseReader(w.Body, &text)
+	assert.Nil(t, err)
+	assert.Equal(t, "Replaced "+word, text.Content)
+
+	// post /file-search/index
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/file-search/index", &schema.FileIndexParam{All: true}))
+	assert.Equal(t, 200, w.Code)
+	var job schema.FileJob
+	err = parseReader(w.Body, &job)
+	assert.Nil(t, err)
+	assert.Equal(t, schema.FileJobTypeIndex, job.Type)
+	assert.True(t, job.Total >= 1)
+
+	done := waitFileJob(t, job.UUID)
+	assert.Equal(t, schema.FileJobStatusDone, done.Status)
+	assert.Equal(t, done.Total, done.Processed)
+
+	// delete /file/:id removes the text
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, item.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(apiPrefix+"v1/file-search", newPageParam(map[string]string{"q": word})))
+	assert.Equal(t, 200, w.Code)
+	hits = nil
+	err = parsePageReader(w.Body, &hits)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(hits))
+}
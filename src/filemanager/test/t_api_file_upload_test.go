@@ -0,0 +1,165 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/scanner"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUploadRequest(router, filename, content string) *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	fw, _ := mw.CreateFormFile("MayFile", filename)
+	_, _ = fw.Write([]byte(content))
+	_ = mw.Close()
+
+	req, _ := http.NewRequest("POST", router, body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestAPIFileUploadScan(t *testing.T) {
+	const router = apiPrefix + "v1/file"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-upload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+
+	scanner.Register(scanner.NewFunc("eicar", func(ctx context.Context, item *schema.File, r io.Reader) (*scanner.Result, error) {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return &scanner.Result{Infected: strings.Contains(string(b), "EICAR"), Threat: "EICAR test file"}, nil
+	}))
+	defer scanner.Unregister("eicar")
+
+	// post /file/upload (clean)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newUploadRequest(router+"/upload", util.MustUUID()+".json", `{"clean":true}`))
+	assert.Equal(t, 200, w.Code)
+	var cleanItem schema.File
+	err = parseReader(w.Body, &cleanItem)
+	assert.Nil(t, err)
+	assert.Equal(t, schema.ScanStatusClean, cleanItem.ScanStatus)
+	assert.True(t, strings.HasPrefix(cleanItem.Uri, cfg.FileManager.Dir))
+	content, err := ioutil.ReadFile(cleanItem.Uri)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"clean":true}`, string(content))
+
+	// post /file/upload (infected)
+	infectedName := util.MustUUID() + ".json"
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newUploadRequest(router+"/upload", infectedName, `{"EICAR":true}`))
+	assert.Equal(t, 400, w.Code)
+
+	// get /file?scanStatus=infected
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, newPageParam(map[string]string{
+		"filename":   infectedName,
+		"scanStatus": schema.ScanStatusInfected,
+	})))
+	assert.Equal(t, 200, w.Code)
+	var pageItems []*schema.File
+	err = parsePageReader(w.Body, &pageItems)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(pageItems))
+	if len(pageItems) > 0 {
+		assert.Equal(t, "EICAR test file", pageItems[0].ScanThreat)
+		assert.Empty(t, pageItems[0].Uri)
+
+		// Infected files are hidden by default
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newGetRequest(router, newPageParam(map[string]string{"filename": infectedName})))
+		var hiddenItems []*schema.File
+		err = parsePageReader(w.Body, &hiddenItems)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(hiddenItems))
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newGetRequest("%s/%s/download", nil, router, pageItems[0].UUID))
+		assert.Equal(t, 400, w.Code)
+
+		w = httptest.NewRecorder()
+		engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, pageItems[0].UUID))
+		assert.Equal(t, 200, w.Code)
+	}
+
+	// Nothing is left behind in quarantine
+	files, _ := ioutil.ReadDir(cfg.FileManager.QuarantineDir)
+	assert.Equal(t, 0, len(files))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, cleanItem.UUID))
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestAPIFileUploadMedia(t *testing.T) {
+	const router = apiPrefix + "v1/file"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-upload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+	cfg.FileManager.AllowFiles = append([]string{".wav"}, oldCfg.AllowFiles...)
+
+	// Two seconds of 8 kHz mono 8 bit samples
+	wav := new(bytes.Buffer)
+	wav.WriteString("RIFF")
+	_ = binary.Write(wav, binary.LittleEndian, uint32(36+16000))
+	wav.WriteString("WAVEfmt ")
+	_ = binary.Write(wav, binary.LittleEndian, []uint32{16, 1<<16 | 1, 8000, 8000, 8<<16 | 1})
+	wav.WriteString("data")
+	_ = binary.Write(wav, binary.LittleEndian, uint32(16000))
+	wav.Write(make([]byte, 16000))
+
+	// post /file/upload
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newUploadRequest(router+"/upload", util.MustUUID()+".wav", wav.String()))
+	assert.Equal(t, 200, w.Code)
+	var item schema.File
+	err = parseReader(w.Body, &item)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, item.Duration)
+
+	// get /file/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s", nil, router, item.UUID))
+	assert.Equal(t, 200, w.Code)
+	var getItem schema.File
+	err = parseReader(w.Body, &getItem)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, getItem.Duration)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, item.UUID))
+	assert.Equal(t, 200, w.Code)
+}
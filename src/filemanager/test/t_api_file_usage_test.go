@@ -0,0 +1,119 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/config"
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	pschema "github.com/MayCMF/core/src/primitives/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIFileUsage(t *testing.T) {
+	const router = apiPrefix + "v1/file"
+	var err error
+
+	dir, err := ioutil.TempDir("", "maycmf-usage")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.Global()
+	oldCfg := cfg.FileManager
+	defer func() { cfg.FileManager = oldCfg }()
+	cfg.FileManager.Dir = filepath.Join(dir, "static")
+	cfg.FileManager.QuarantineDir = filepath.Join(dir, "quarantine")
+
+	// post /file/upload
+	var files []schema.File
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, newUploadFormRequest(router+"/upload", util.MustUUID()+".json", "application/json", "{}", nil))
+		assert.Equal(t, 200, w.Code)
+		var item schema.File
+		err = parseReader(w.Body, &item)
+		assert.Nil(t, err)
+		files = append(files, item)
+	}
+	refItem, bodyItem := files[0], files[1]
+
+	// post /node (references the first file by UUID and the second by path)
+	w := httptest.NewRecorder()
+	node := &pschema.Node{
+		UID:        1,
+		Slug:       util.MustUUID(),
+		Parent:     util.MustUUID(),
+		References: json.RawMessage(`{"cover":"` + refItem.UUID + `"}`),
+		NodeBodies: pschema.NodeBodies{
+			{Lang: "en", Title: "Usage", Body: `<img src="/` + bodyItem.Uri + `">`},
+		},
+	}
+	engine.ServeHTTP(w, newPostRequest(apiPrefix+"v1/node", node))
+	assert.Equal(t, 200, w.Code)
+	var nodeItem pschema.Node
+	err = parseReader(w.Body, &nodeItem)
+	assert.Nil(t, err)
+
+	// get /file/:id/usage
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/usage", nil, router, refItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	var usages []*schema.FileUsage
+	err = parsePageReader(w.Body, &usages)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(usages))
+	if len(usages) > 0 {
+		assert.Equal(t, schema.FileUsageNode, usages[0].EntityType)
+		assert.Equal(t, nodeItem.UUID, usages[0].EntityID)
+		assert.Equal(t, "references", usages[0].Field)
+	}
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/usage", nil, router, bodyItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	usages = nil
+	err = parsePageReader(w.Body, &usages)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(usages))
+	if len(usages) > 0 {
+		assert.Equal(t, "body.en", usages[0].Field)
+	}
+
+	// delete /file/:id (in use)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, refItem.UUID))
+	assert.Equal(t, 409, w.Code)
+
+	// delete /file/:id?force=true
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s?force=true", router, bodyItem.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	// put /node/:id (the reference is dropped)
+	w = httptest.NewRecorder()
+	nodeItem.UID = 1
+	nodeItem.References = json.RawMessage(`{"cover":null}`)
+	engine.ServeHTTP(w, newPutRequest("%sv1/node/%s", nodeItem, apiPrefix, nodeItem.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest("%s/%s/usage", nil, router, refItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	usages = nil
+	err = parsePageReader(w.Body, &usages)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(usages))
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, refItem.UUID))
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%sv1/node/%s", apiPrefix, nodeItem.UUID))
+	assert.Equal(t, 200, w.Code)
+}
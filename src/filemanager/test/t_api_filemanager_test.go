@@ -2,8 +2,10 @@ package test
 
 import (
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/MayCMF/core/src/common/config"
 	"github.com/MayCMF/core/src/common/util"
 	"github.com/MayCMF/core/src/filemanager/schema"
 	"github.com/stretchr/testify/assert"
@@ -17,24 +19,38 @@ func TestAPIFile(t *testing.T) {
 
 	// post /file
 	addItem := &schema.File{
+		UID:      1,
+		UserUUID: util.MustUUID(),
 		Filename: util.MustUUID(),
 		Filemime: util.MustUUID(),
-		Uri:      util.MustUUID(),
+		Uri:      "/etc/passwd",
+		FileExt:  "txt",
+		Tags:     []string{"partial"},
 	}
 	engine.ServeHTTP(w, newPostRequest(router, addItem))
-	assert.Equal(t, 200, w.Filename)
+	assert.Equal(t, 200, w.Code)
 
 	var addNewItem schema.File
 	err = parseReader(w.Body, &addNewItem)
 	assert.Nil(t, err)
 	assert.Equal(t, addItem.Filename, addNewItem.Filename)
 	assert.Equal(t, addItem.Filename, addNewItem.Filename)
-	assert.Equal(t, addItem.Uri, addNewItem.Uri)
 	assert.NotEmpty(t, addNewItem.UUID)
 
+	// The storage location is chosen by the server
+	assert.NotEqual(t, addItem.Uri, addNewItem.Uri)
+	assert.True(t, strings.HasPrefix(addNewItem.Uri, config.Global().FileManager.Dir+"/"))
+
+	// Records without uploaded content stay in quarantine
+	assert.Equal(t, schema.ScanStatusQuarantine, addNewItem.ScanStatus)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPatchRequest("%s/%s/scan", router, addNewItem.UUID))
+	assert.Equal(t, 400, w.Code)
+
 	// query /file
-	engine.ServeHTTP(w, newGetRequest(router, newPageParam()))
-	assert.Equal(t, 200, w.Filename)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newGetRequest(router, newPageParam(map[string]string{"scanStatus": schema.ScanStatusQuarantine})))
+	assert.Equal(t, 200, w.Code)
 	var pageItems []*schema.File
 	err = parsePageReader(w.Body, &pageItems)
 	assert.Nil(t, err)
@@ -45,24 +61,44 @@ func TestAPIFile(t *testing.T) {
 	}
 
 	// put /file/:id
+	w = httptest.NewRecorder()
 	engine.ServeHTTP(w, newGetRequest("%s/%s", nil, router, addNewItem.UUID))
-	assert.Equal(t, 200, w.Filename)
+	assert.Equal(t, 200, w.Code)
 	var putItem schema.File
 	err = parseReader(w.Body, &putItem)
 	assert.Nil(t, err)
 
 	putItem.Filename = util.MustUUID()
+	putItem.UID = addItem.UID
+	putItem.UserUUID = addItem.UserUUID
+	putItem.FileExt = addItem.FileExt
+	putItem.Uri = "/etc/passwd"
+	w = httptest.NewRecorder()
 	engine.ServeHTTP(w, newPutRequest("%s/%s", putItem, router, addNewItem.UUID))
-	assert.Equal(t, 200, w.Filename)
+	assert.Equal(t, 200, w.Code)
 
 	var putNewItem schema.File
 	err = parseReader(w.Body, &putNewItem)
 	assert.Nil(t, err)
 	assert.Equal(t, putItem.Filename, putNewItem.Filename)
+	assert.Equal(t, addNewItem.Uri, putNewItem.Uri)
+
+	// put /file/:id (only the fields sent are changed)
+	filename := util.MustUUID()
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newPutRequest("%s/%s", map[string]string{"filename": filename}, router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	putNewItem = schema.File{}
+	err = parseReader(w.Body, &putNewItem)
+	assert.Nil(t, err)
+	assert.Equal(t, filename, putNewItem.Filename)
+	assert.Equal(t, addNewItem.Uri, putNewItem.Uri)
+	assert.Equal(t, []string{"partial"}, putNewItem.Tags)
 
 	// delete /file/:id
+	w = httptest.NewRecorder()
 	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, addNewItem.UUID))
-	assert.Equal(t, 200, w.Filename)
+	assert.Equal(t, 200, w.Code)
 	err = parseOK(w.Body)
 	assert.Nil(t, err)
 }
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MayCMF/core/src/common/util"
+	"github.com/MayCMF/core/src/filemanager/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIFolder(t *testing.T) {
+	const router = apiPrefix + "v1/folders"
+	var err error
+
+	w := httptest.NewRecorder()
+
+	// post /folders
+	addItem := &schema.Folder{
+		Name:     util.MustUUID(),
+		Sequence: 9999999,
+	}
+	engine.ServeHTTP(w, newPostRequest(router, addItem))
+	assert.Equal(t, 200, w.Code)
+	var addNewItem schema.Folder
+	err = parseReader(w.Body, &addNewItem)
+	assert.Nil(t, err)
+	assert.Equal(t, addItem.Name, addNewItem.Name)
+	assert.NotEmpty(t, addNewItem.UUID)
+
+	// post /folders (child)
+	w = httptest.NewRecorder()
+	addChildItem := &schema.Folder{
+		Name:     util.MustUUID(),
+		ParentID: addNewItem.UUID,
+	}
+	engine.ServeHTTP(w, newPostRequest(router, addChildItem))
+	assert.Equal(t, 200, w.Code)
+	var addNewChildItem schema.Folder
+	err = parseReader(w.Body, &addNewChildItem)
+	assert.Nil(t, err)
+	assert.Equal(t, addNewItem.UUID, addNewChildItem.ParentPath)
+
+	// put /folders/:id (moving a folder below its own child is rejected)
+	w = httptest.NewRecorder()
+	moveItem := addNewItem
+	moveItem.ParentID = addNewChildItem.UUID
+	engine.ServeHTTP(w, newPutRequest("%s/%s", moveItem, router, addNewItem.UUID))
+	assert.Equal(t, 400, w.Code)
+
+	// delete /folders/:id (folders with children cannot be deleted)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, addNewItem.UUID))
+	assert.Equal(t, 400, w.Code)
+
+	// delete /folders/:id
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, addNewChildItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	err = parseOK(w.Body)
+	assert.Nil(t, err)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("%s/%s", router, addNewItem.UUID))
+	assert.Equal(t, 200, w.Code)
+	err = parseOK(w.Body)
+	assert.Nil(t, err)
+}
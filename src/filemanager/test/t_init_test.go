@@ -12,6 +12,7 @@ import (
 	app "github.com/MayCMF/core/src"
 	"github.com/MayCMF/core/src/common/config"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/dig"
 )
 
 const (
@@ -20,7 +21,10 @@ const (
 	apiPrefix  = "/api/"
 )
 
-var engine *gin.Engine
+var (
+	engine    *gin.Engine
+	container *dig.Container
+)
 
 func init() {
 	// Initialize configuration file
@@ -36,7 +40,7 @@ func init() {
 	cfg.Gorm.Debug = false
 	cfg.Gorm.DBType = "sqlite3"
 
-	container, _ := app.BuildContainer()
+	container, _ = app.BuildContainer()
 	engine = app.InitWeb(container)
 }
 
@@ -105,6 +109,11 @@ func newPutRequest(formatRouter string, v interface{}, args ...interface{}) *htt
 	return req
 }
 
+func newPatchRequest(formatRouter string, args ...interface{}) *http.Request {
+	req, _ := http.NewRequest("PATCH", fmt.Sprintf(formatRouter, args...), nil)
+	return req
+}
+
 func newDeleteRequest(formatRouter string, args ...interface{}) *http.Request {
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf(formatRouter, args...), nil)
 	return req
@@ -3,22 +3,30 @@ package implement
 import (
 	"context"
 
+	acontrollers "github.com/MayCMF/core/src/audit/controllers"
+	aschema "github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common"
 	"github.com/MayCMF/core/src/common/errors"
 	comschema "github.com/MayCMF/core/src/common/schema"
 	"github.com/MayCMF/core/src/i18n/model"
 	"github.com/MayCMF/core/src/i18n/schema"
+	transaction "github.com/MayCMF/core/src/transaction/model"
 )
 
 // NewLanguage - Create a Language
-func NewLanguage(mLanguage model.ILanguage) *Language {
+func NewLanguage(trans transaction.ITrans, mLanguage model.ILanguage, bAudit acontrollers.IAudit) *Language {
 	return &Language{
+		TransModel:    trans,
 		LanguageModel: mLanguage,
+		AuditBll:      bAudit,
 	}
 }
 
 // Language - Sample program
 type Language struct {
+	TransModel    transaction.ITrans
 	LanguageModel model.ILanguage
+	AuditBll      acontrollers.IAudit
 }
 
 // Query - Query data
@@ -63,11 +71,23 @@ func (a *Language) Create(ctx context.Context, item schema.Language) (*schema.La
 		return nil, err
 	}
 
-	err = a.LanguageModel.Create(ctx, item)
+	var nitem *schema.Language
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.LanguageModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, item.Code)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityLanguage, aschema.AuditActionCreate, nitem.Code, nil, nitem)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, item.Code)
+	return nitem, nil
 }
 
 // Update - Update Language data
@@ -84,11 +104,23 @@ func (a *Language) Update(ctx context.Context, code string, item schema.Language
 		}
 	}
 
-	err = a.LanguageModel.Update(ctx, code, item)
+	var nitem *schema.Language
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.LanguageModel.Update(ctx, code, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, code)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityLanguage, aschema.AuditActionUpdate, code, oldItem, nitem)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, code)
+	return nitem, nil
 }
 
 // Delete - Delete data
@@ -100,7 +132,13 @@ func (a *Language) Delete(ctx context.Context, code string) error {
 		return errors.ErrNotFound
 	}
 
-	return a.LanguageModel.Delete(ctx, code)
+	return common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.LanguageModel.Delete(ctx, code)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityLanguage, aschema.AuditActionDelete, code, oldItem, nil)
+	})
 }
 
 // UpdateStatus - Update status
@@ -112,5 +150,16 @@ func (a *Language) UpdateStatus(ctx context.Context, code string, status int) er
 		return errors.ErrNotFound
 	}
 
-	return a.LanguageModel.UpdateStatus(ctx, code, status)
+	return common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.LanguageModel.UpdateStatus(ctx, code, status)
+		if err != nil {
+			return err
+		}
+
+		nitem, err := a.LanguageModel.Get(ctx, code)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityLanguage, acontrollers.AuditStatusAction(status), code, oldItem, nitem)
+	})
 }
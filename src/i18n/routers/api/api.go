@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/MayCMF/core/src/common/auth"
 	"github.com/MayCMF/core/src/common/middleware"
 	"github.com/MayCMF/core/src/i18n/routers/api/controllers"
 	"github.com/gin-gonic/gin"
@@ -15,6 +16,7 @@ func RegisterRouter(app *gin.Engine, container *dig.Container) error {
 	}
 
 	return container.Invoke(func(
+		a auth.Auther,
 		cLanguage *controllers.Language,
 		cCountry *controllers.Country,
 	) error {
@@ -24,6 +26,9 @@ func RegisterRouter(app *gin.Engine, container *dig.Container) error {
 		// Request frequency limit middleware
 		g.Use(middleware.RateLimiterMiddleware())
 
+		// Routes are open to anonymous users, a token identifies the user for the audit log
+		g.Use(middleware.OptionalUserAuthMiddleware(a))
+
 		v1 := g.Group("/v1")
 		{
 
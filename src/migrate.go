@@ -2,6 +2,7 @@ package app
 
 import (
 	account "github.com/MayCMF/core/src/account/model/impl/gorm/entity"
+	audit "github.com/MayCMF/core/src/audit/model/impl/gorm/entity"
 	filemanager "github.com/MayCMF/core/src/filemanager/model/impl/gorm/entity"
 	i18n "github.com/MayCMF/core/src/i18n/model/impl/gorm/entity"
 	primitives "github.com/MayCMF/core/src/primitives/model/impl/gorm/entity"
@@ -10,9 +11,21 @@ import (
 
 // AutoMigrate - Automatic mapping data table
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		new(account.User),
 		new(account.UserRole),
+		new(account.UserMFA),
+		new(account.UserSession),
+		new(account.LoginHistory),
+		new(account.AccessToken),
+		new(account.UserIdentity),
+		new(account.OAuthClient),
+		new(account.OAuthScope),
+		new(account.OAuthGrant),
+		new(account.OAuthCode),
+		new(account.UserToken),
+		new(account.PasswordHistory),
+		new(account.Invitation),
 		new(account.Role),
 		new(account.RolePermission),
 		new(account.Permission),
@@ -25,5 +38,21 @@ func AutoMigrate(db *gorm.DB) error {
 		new(primitives.Node),
 		new(primitives.NodeBody),
 		new(filemanager.File),
+		new(filemanager.FileTag),
+		new(filemanager.FileTranslation),
+		new(filemanager.Folder),
+		new(filemanager.FileJob),
+		new(filemanager.FileUsage),
+		new(filemanager.FileText),
+		new(audit.Audit),
 	).Error
+	if err != nil {
+		return err
+	}
+
+	// Password hashes outgrew the sha1 column, sqlite does not enforce sizes
+	if db.Dialect().GetName() != "sqlite3" {
+		err = db.Model(new(account.User)).ModifyColumn("password", "varchar(255)").Error
+	}
+	return err
 }
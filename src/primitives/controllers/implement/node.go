@@ -3,23 +3,35 @@ package implement
 import (
 	"context"
 
+	acontrollers "github.com/MayCMF/core/src/audit/controllers"
+	aschema "github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common"
 	"github.com/MayCMF/core/src/common/errors"
 	commonschema "github.com/MayCMF/core/src/common/schema"
 	"github.com/MayCMF/core/src/common/util"
+	fcontrollers "github.com/MayCMF/core/src/filemanager/controllers"
+	fschema "github.com/MayCMF/core/src/filemanager/schema"
 	"github.com/MayCMF/core/src/primitives/model"
 	"github.com/MayCMF/core/src/primitives/schema"
+	transaction "github.com/MayCMF/core/src/transaction/model"
 )
 
 // NewNode - Create a Node
-func NewNode(mNode model.INode) *Node {
+func NewNode(trans transaction.ITrans, mNode model.INode, bFileUsage fcontrollers.IFileUsage, bAudit acontrollers.IAudit) *Node {
 	return &Node{
-		NodeModel: mNode,
+		TransModel:   trans,
+		NodeModel:    mNode,
+		FileUsageBll: bFileUsage,
+		AuditBll:     bAudit,
 	}
 }
 
 // Node - Sample program
 type Node struct {
-	NodeModel model.INode
+	TransModel   transaction.ITrans
+	NodeModel    model.INode
+	FileUsageBll fcontrollers.IFileUsage
+	AuditBll     acontrollers.IAudit
 }
 
 // Query - Query data
@@ -53,10 +65,39 @@ func (a *Node) checkSlug(ctx context.Context, slug string) error {
 	return nil
 }
 
+// Get a node with its bodies for the audit log
+func (a *Node) getAuditItem(ctx context.Context, UUID string) (*schema.Node, error) {
+	return a.NodeModel.Get(ctx, UUID, schema.NodeQueryOptions{
+		IncludeNodeBodies: true,
+	})
+}
+
 func (a *Node) getUpdate(ctx context.Context, UUID string) (*schema.Node, error) {
+	err := a.trackFiles(ctx, UUID)
+	if err != nil {
+		return nil, err
+	}
 	return a.Get(ctx, UUID)
 }
 
+// Track the files used by the references and the bodies of a node
+func (a *Node) trackFiles(ctx context.Context, UUID string) error {
+	item, err := a.Get(ctx, UUID, schema.NodeQueryOptions{
+		IncludeNodeBodies: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]string{
+		"references": string(item.References),
+	}
+	for _, body := range item.NodeBodies {
+		fields["body."+body.Lang] = body.Body
+	}
+	return a.FileUsageBll.Track(ctx, fschema.FileUsageNode, UUID, fields)
+}
+
 // Create - Create Node data
 func (a *Node) Create(ctx context.Context, item schema.Node) (*schema.Node, error) {
 	err := a.checkSlug(ctx, item.Slug)
@@ -65,16 +106,28 @@ func (a *Node) Create(ctx context.Context, item schema.Node) (*schema.Node, erro
 	}
 
 	item.UUID = util.MustUUID()
-	err = a.NodeModel.Create(ctx, item)
+	var nitem *schema.Node
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.NodeModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, item.UUID)
+		if err != nil {
+			return err
+		}
+		return a.recordAudit(ctx, aschema.AuditActionCreate, item.UUID, nil)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, item.UUID)
+	return nitem, nil
 }
 
 // Update - Update Node data
 func (a *Node) Update(ctx context.Context, UUID string, item schema.Node) (*schema.Node, error) {
-	oldItem, err := a.NodeModel.Get(ctx, UUID)
+	oldItem, err := a.getAuditItem(ctx, UUID)
 	if err != nil {
 		return nil, err
 	} else if oldItem == nil {
@@ -86,21 +139,53 @@ func (a *Node) Update(ctx context.Context, UUID string, item schema.Node) (*sche
 		}
 	}
 
-	err = a.NodeModel.Update(ctx, UUID, item)
+	var nitem *schema.Node
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.NodeModel.Update(ctx, UUID, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		return a.recordAudit(ctx, aschema.AuditActionUpdate, UUID, oldItem)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, UUID)
+	return nitem, nil
 }
 
 // Delete - Delete data
 func (a *Node) Delete(ctx context.Context, UUID string) error {
-	oldItem, err := a.NodeModel.Get(ctx, UUID)
+	oldItem, err := a.getAuditItem(ctx, UUID)
 	if err != nil {
 		return err
 	} else if oldItem == nil {
 		return errors.ErrNotFound
 	}
 
-	return a.NodeModel.Delete(ctx, UUID)
+	return common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.NodeModel.Delete(ctx, UUID)
+		if err != nil {
+			return err
+		}
+
+		err = a.FileUsageBll.Untrack(ctx, fschema.FileUsageNode, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityNode, aschema.AuditActionDelete, UUID, oldItem, nil)
+	})
+}
+
+// Record a saved node with its bodies in the audit log
+func (a *Node) recordAudit(ctx context.Context, action, UUID string, oldItem *schema.Node) error {
+	nitem, err := a.getAuditItem(ctx, UUID)
+	if err != nil {
+		return err
+	}
+	return a.AuditBll.Record(ctx, aschema.AuditEntityNode, action, UUID, oldItem, nitem)
 }
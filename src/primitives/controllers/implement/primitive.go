@@ -3,23 +3,31 @@ package implement
 import (
 	"context"
 
+	acontrollers "github.com/MayCMF/core/src/audit/controllers"
+	aschema "github.com/MayCMF/core/src/audit/schema"
+	"github.com/MayCMF/core/src/common"
 	"github.com/MayCMF/core/src/common/errors"
 	commonschema "github.com/MayCMF/core/src/common/schema"
 	"github.com/MayCMF/core/src/common/util"
 	"github.com/MayCMF/core/src/primitives/model"
 	"github.com/MayCMF/core/src/primitives/schema"
+	transaction "github.com/MayCMF/core/src/transaction/model"
 )
 
 // NewPrimitive - Create a Primitive
-func NewPrimitive(mPrimitive model.IPrimitive) *Primitive {
+func NewPrimitive(trans transaction.ITrans, mPrimitive model.IPrimitive, bAudit acontrollers.IAudit) *Primitive {
 	return &Primitive{
+		TransModel:     trans,
 		PrimitiveModel: mPrimitive,
+		AuditBll:       bAudit,
 	}
 }
 
 // Primitive - Sample program
 type Primitive struct {
+	TransModel     transaction.ITrans
 	PrimitiveModel model.IPrimitive
+	AuditBll       acontrollers.IAudit
 }
 
 // Query - Query data
@@ -65,11 +73,23 @@ func (a *Primitive) Create(ctx context.Context, item schema.Primitive) (*schema.
 	}
 
 	item.UUID = util.MustUUID()
-	err = a.PrimitiveModel.Create(ctx, item)
+	var nitem *schema.Primitive
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.PrimitiveModel.Create(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, item.UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityPrimitive, aschema.AuditActionCreate, nitem.UUID, nil, nitem)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, item.UUID)
+	return nitem, nil
 }
 
 // Update - Update Primitive data
@@ -86,11 +106,23 @@ func (a *Primitive) Update(ctx context.Context, UUID string, item schema.Primiti
 		}
 	}
 
-	err = a.PrimitiveModel.Update(ctx, UUID, item)
+	var nitem *schema.Primitive
+	err = common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.PrimitiveModel.Update(ctx, UUID, item)
+		if err != nil {
+			return err
+		}
+
+		nitem, err = a.getUpdate(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityPrimitive, aschema.AuditActionUpdate, UUID, oldItem, nitem)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return a.getUpdate(ctx, UUID)
+	return nitem, nil
 }
 
 // Delete - Delete data
@@ -102,5 +134,11 @@ func (a *Primitive) Delete(ctx context.Context, UUID string) error {
 		return errors.ErrNotFound
 	}
 
-	return a.PrimitiveModel.Delete(ctx, UUID)
+	return common.ExecTrans(ctx, a.TransModel, func(ctx context.Context) error {
+		err := a.PrimitiveModel.Delete(ctx, UUID)
+		if err != nil {
+			return err
+		}
+		return a.AuditBll.Record(ctx, aschema.AuditEntityPrimitive, aschema.AuditActionDelete, UUID, oldItem, nil)
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	accountIject "github.com/MayCMF/core/src/account"
+	auditIject "github.com/MayCMF/core/src/audit"
 	filemanagerIject "github.com/MayCMF/core/src/filemanager"
 	i18nIject "github.com/MayCMF/core/src/i18n"
 	primitivesIject "github.com/MayCMF/core/src/primitives"
@@ -67,6 +68,7 @@ func InitStore(container *dig.Container) (func(), error) {
 		})
 
 		transaction.InjectStarage(container)
+		auditIject.InjectStarage(container)
 		accountIject.InjectStarage(container)
 		i18nIject.InjectStarage(container)
 		primitivesIject.InjectStarage(container)
@@ -83,8 +85,6 @@ func InitStore(container *dig.Container) (func(), error) {
 func initGorm() (db *gorm.DB, err error) {
 	storConf := config.Global()
 
-	fmt.Printf("config: %#v", storConf.Gorm)
-
 	switch storConf.Gorm.DBType {
 	case "mysql":
 		db, err = gorm.Open("mysql", fmt.Sprintf("%v:%v@tcp(%v:%v)/%v?parseTime=True&loc=Local", storConf.MySQL.User, storConf.MySQL.Password, storConf.MySQL.Host, storConf.MySQL.Port, storConf.MySQL.DBName))
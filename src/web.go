@@ -7,6 +7,7 @@ import (
 	"time"
 
 	accountApi "github.com/MayCMF/core/src/account/routers/api"
+	auditApi "github.com/MayCMF/core/src/audit/routers/api"
 	filemanagerApi "github.com/MayCMF/core/src/filemanager/routers/api"
 	i18nApi "github.com/MayCMF/core/src/i18n/routers/api"
 	primitivesApi "github.com/MayCMF/core/src/primitives/routers/api"
@@ -27,7 +28,7 @@ func InitWeb(container *dig.Container) *gin.Engine {
 	app.NoMethod(middleware.NoMethodHandler())
 	app.NoRoute(middleware.NoRouteHandler())
 
-	apiPrefixes := []string{"/api/"}
+	apiPrefixes := []string{"/api/", "/dav/"}
 
 	// Tracking ID
 	app.Use(middleware.TraceMiddleware(middleware.AllowPathPrefixNoSkipper(apiPrefixes...)))
@@ -53,6 +54,9 @@ func InitWeb(container *dig.Container) *gin.Engine {
 	primitivesApi.RegisterRouter(app, container)
 	// Registration Files /api routing
 	filemanagerApi.RegisterRouter(app, container)
+	// Registration Audit log /api routing
+	err = auditApi.RegisterRouter(app, container)
+	handleError(err)
 
 	// Swagger document
 	if dir := cfg.Swagger; dir != "" {